@@ -0,0 +1,79 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ApiKeyMaxClockSkew is how far the signed timestamp may drift from the
+// server clock in either direction.
+const ApiKeyMaxClockSkew = 60 * time.Second
+
+// ApiKeyTimestampHeader carries the unix timestamp covered by the signature.
+const ApiKeyTimestampHeader = "X-Timestamp"
+
+var (
+	ErrApiKeyMalformed        = errors.New("api key authorization malformed")
+	ErrApiKeyTimestamp        = errors.New("api key timestamp outside allowed skew")
+	ErrApiKeySignature        = errors.New("api key signature mismatch")
+	ErrApiKeyInvalidPublicKey = errors.New("invalid ed25519 public key")
+)
+
+// ParseApiKeyPublicKey decodes a standard base64 Ed25519 public key.
+func ParseApiKeyPublicKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, ErrApiKeyInvalidPublicKey
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// EncodeApiKeyPublicKey is the canonical stored form of a public key.
+func EncodeApiKeyPublicKey(pub ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(pub)
+}
+
+// ParseApiKeyAuthorization splits an "ApiKey <keyID>:<base64sig>" header.
+func ParseApiKeyAuthorization(header string) (string, []byte, error) {
+	header = strings.TrimSpace(header)
+	if len(header) < 7 || !strings.EqualFold(header[:7], "apikey ") {
+		return "", nil, ErrApiKeyMalformed
+	}
+	keyID, encoded, ok := strings.Cut(strings.TrimSpace(header[7:]), ":")
+	keyID = strings.TrimSpace(keyID)
+	if !ok || keyID == "" {
+		return "", nil, ErrApiKeyMalformed
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return "", nil, ErrApiKeyMalformed
+	}
+	return keyID, sig, nil
+}
+
+// ApiKeySigningPayload is the message a caller signs: method, path and unix
+// timestamp joined by newlines.
+func ApiKeySigningPayload(method, path string, timestamp int64) []byte {
+	return []byte(strings.ToUpper(method) + "\n" + path + "\n" + strconv.FormatInt(timestamp, 10))
+}
+
+// VerifyApiKeySignature checks the timestamp against now and the signature
+// against the payload for method and path.
+func VerifyApiKeySignature(pub ed25519.PublicKey, method, path, timestamp string, sig []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return ErrApiKeyTimestamp
+	}
+	skew := now.Sub(time.Unix(ts, 0))
+	if skew > ApiKeyMaxClockSkew || skew < -ApiKeyMaxClockSkew {
+		return ErrApiKeyTimestamp
+	}
+	if !ed25519.Verify(pub, ApiKeySigningPayload(method, path, ts), sig) {
+		return ErrApiKeySignature
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifyApiKeySignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := ed25519.Sign(priv, ApiKeySigningPayload("POST", "/v1/chat/completions", now.Unix()))
+
+	header := "ApiKey key-1:" + base64.StdEncoding.EncodeToString(sig)
+	keyID, parsed, err := ParseApiKeyAuthorization(header)
+	if err != nil || keyID != "key-1" {
+		t.Fatalf("ParseApiKeyAuthorization(%q) = %q, %v", header, keyID, err)
+	}
+	if err := VerifyApiKeySignature(pub, "post", "/v1/chat/completions", ts, parsed, now.Add(59*time.Second)); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+
+	cases := []struct {
+		name      string
+		method    string
+		path      string
+		timestamp string
+		now       time.Time
+		want      error
+	}{
+		{"other path", "POST", "/v1/embeddings", ts, now, ErrApiKeySignature},
+		{"other method", "GET", "/v1/chat/completions", ts, now, ErrApiKeySignature},
+		{"stale", "POST", "/v1/chat/completions", ts, now.Add(61 * time.Second), ErrApiKeyTimestamp},
+		{"future", "POST", "/v1/chat/completions", ts, now.Add(-61 * time.Second), ErrApiKeyTimestamp},
+		{"bad timestamp", "POST", "/v1/chat/completions", "soon", now, ErrApiKeyTimestamp},
+	}
+	for _, tc := range cases {
+		err := VerifyApiKeySignature(pub, tc.method, tc.path, tc.timestamp, parsed, tc.now)
+		if !errors.Is(err, tc.want) {
+			t.Fatalf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestParseApiKeyAuthorizationRejects(t *testing.T) {
+	sig := base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize))
+	for _, header := range []string{
+		"",
+		"Bearer abc",
+		"ApiKey " + sig,
+		"ApiKey :" + sig,
+		"ApiKey key:not-base64",
+		"ApiKey key:" + base64.StdEncoding.EncodeToString([]byte("short")),
+	} {
+		if _, _, err := ParseApiKeyAuthorization(header); err == nil {
+			t.Fatalf("ParseApiKeyAuthorization(%q) accepted", header)
+		}
+	}
+	if _, err := ParseApiKeyPublicKey(base64.StdEncoding.EncodeToString(make([]byte, 31))); err == nil {
+		t.Fatalf("short public key accepted")
+	}
+}
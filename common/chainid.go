@@ -0,0 +1,117 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+// NormalizeChainId parses a chain ID given in decimal ("1") or 0x-prefixed hex
+// ("0x1") form and returns its decimal string representation.
+func NormalizeChainId(id string) (string, error) {
+	trimmed := strings.TrimSpace(id)
+	if trimmed == "" {
+		return "", fmt.Errorf("empty chain id")
+	}
+	base := 10
+	digits := trimmed
+	if strings.HasPrefix(trimmed, "0x") || strings.HasPrefix(trimmed, "0X") {
+		base = 16
+		digits = trimmed[2:]
+	}
+	value, err := strconv.ParseUint(digits, base, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid chain id %q", id)
+	}
+	return strconv.FormatUint(value, 10), nil
+}
+
+// walletChainNames maps the symbolic network names accepted in
+// auth.wallet_allowed_chains to their EVM chain IDs.
+var walletChainNames = map[string]string{
+	"mainnet":  "1",
+	"ethereum": "1",
+	"sepolia":  "11155111",
+	"holesky":  "17000",
+	"optimism": "10",
+	"bsc":      "56",
+	"polygon":  "137",
+	"base":     "8453",
+	"arbitrum": "42161",
+}
+
+// resolveWalletChain accepts a chain ID in decimal or hex form, or a known
+// network name.
+func resolveWalletChain(entry string) (string, error) {
+	if chainId, ok := walletChainNames[strings.ToLower(strings.TrimSpace(entry))]; ok {
+		return chainId, nil
+	}
+	return NormalizeChainId(entry)
+}
+
+// BuildWalletAllowedChains normalizes configured chain IDs and network names
+// into the lookup set stored in config.WalletAllowedChains. Invalid entries are
+// left out and reported together in the returned error, next to the valid set.
+func BuildWalletAllowedChains(chainIds []string) (map[string]struct{}, error) {
+	allowed := make(map[string]struct{}, len(chainIds))
+	var invalid []string
+	for _, chainId := range chainIds {
+		if strings.TrimSpace(chainId) == "" {
+			invalid = append(invalid, fmt.Sprintf("%q", chainId))
+			continue
+		}
+		normalized, err := resolveWalletChain(chainId)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%q", chainId))
+			continue
+		}
+		allowed[normalized] = struct{}{}
+	}
+	if len(invalid) > 0 {
+		return allowed, fmt.Errorf("invalid chain ids %s", strings.Join(invalid, ", "))
+	}
+	return allowed, nil
+}
+
+// WalletAllowedChainList returns the allowed chain IDs in ascending order.
+func WalletAllowedChainList() []string {
+	list := make([]string, 0, len(config.WalletAllowedChains))
+	for chainId := range config.WalletAllowedChains {
+		list = append(list, chainId)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if len(list[i]) != len(list[j]) {
+			return len(list[i]) < len(list[j])
+		}
+		return list[i] < list[j]
+	})
+	return list
+}
+
+// IsWalletChainAllowed reports whether chainId, in decimal or hex form, is in
+// config.WalletAllowedChains. An empty allowlist allows every chain.
+func IsWalletChainAllowed(chainId string) bool {
+	if len(config.WalletAllowedChains) == 0 {
+		return true
+	}
+	normalized, err := NormalizeChainId(chainId)
+	if err != nil {
+		return false
+	}
+	_, ok := config.WalletAllowedChains[normalized]
+	return ok
+}
+
+// ValidateConfig checks settings that cannot be validated field by field while
+// the runtime config is applied.
+func ValidateConfig() error {
+	for chainId := range config.WalletAllowedChains {
+		if normalized, err := NormalizeChainId(chainId); err != nil || normalized != chainId {
+			return fmt.Errorf("invalid auth.wallet_allowed_chains entry %q", chainId)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestNormalizeChainId(t *testing.T) {
+	cases := map[string]string{
+		"1":        "1",
+		"0x1":      "1",
+		"0X89":     "137",
+		" 137 ":    "137",
+		"0xaa36a7": "11155111",
+	}
+	for input, want := range cases {
+		got, err := NormalizeChainId(input)
+		if err != nil {
+			t.Fatalf("NormalizeChainId(%q) error: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("NormalizeChainId(%q) = %q, want %q", input, got, want)
+		}
+	}
+	for _, input := range []string{"", "0x", "mainnet", "-1", "0xzz"} {
+		if _, err := NormalizeChainId(input); err == nil {
+			t.Fatalf("NormalizeChainId(%q) expected error", input)
+		}
+	}
+}
+
+func TestIsWalletChainAllowed_MixedFormats(t *testing.T) {
+	allowed, err := BuildWalletAllowedChains([]string{"1", "0x89"})
+	if err != nil {
+		t.Fatalf("BuildWalletAllowedChains error: %v", err)
+	}
+	prev := config.WalletAllowedChains
+	config.WalletAllowedChains = allowed
+	defer func() { config.WalletAllowedChains = prev }()
+
+	for _, chainId := range []string{"1", "0x1", "137", "0x89"} {
+		if !IsWalletChainAllowed(chainId) {
+			t.Fatalf("IsWalletChainAllowed(%q) = false, want true", chainId)
+		}
+	}
+	for _, chainId := range []string{"5", "0x5", "bogus"} {
+		if IsWalletChainAllowed(chainId) {
+			t.Fatalf("IsWalletChainAllowed(%q) = true, want false", chainId)
+		}
+	}
+}
+
+func BenchmarkIsWalletChainAllowed1000(b *testing.B) {
+	chainIds := make([]string, 0, 1000)
+	for i := 1; i <= 1000; i++ {
+		chainIds = append(chainIds, strconv.Itoa(i))
+	}
+	allowed, err := BuildWalletAllowedChains(chainIds)
+	if err != nil {
+		b.Fatalf("BuildWalletAllowedChains error: %v", err)
+	}
+	prev := config.WalletAllowedChains
+	config.WalletAllowedChains = allowed
+	defer func() { config.WalletAllowedChains = prev }()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		IsWalletChainAllowed("0x3e8")
+	}
+}
+
+func TestBuildWalletAllowedChains_SkipsInvalidEntries(t *testing.T) {
+	allowed, err := BuildWalletAllowedChains([]string{"1", "Sepolia", "polygon", "0x2105", "mainnet-ish", " "})
+	if err == nil {
+		t.Fatalf("expected invalid entries to be reported")
+	}
+	for _, want := range []string{"1", "11155111", "137", "8453"} {
+		if _, ok := allowed[want]; !ok {
+			t.Fatalf("allowed = %v, missing %s", allowed, want)
+		}
+	}
+	if len(allowed) != 4 {
+		t.Fatalf("allowed = %v, want 4 entries", allowed)
+	}
+	if _, err := BuildWalletAllowedChains([]string{"1", "0x89", "base"}); err != nil {
+		t.Fatalf("valid entries reported error: %v", err)
+	}
+}
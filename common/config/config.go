@@ -9,6 +9,31 @@ import (
 
 var SystemName = "Router"
 var ServerAddress = "http://localhost:3011"
+
+// MaxRequestBodyBytes caps request bodies on the /api routes (relay routes
+// excluded); 0 disables the limit.
+var MaxRequestBodyBytes int64 = 4 << 20
+
+// SlowRequestThresholdMs is the latency above which a request is logged as a
+// warning; 0 disables the slow request log.
+var SlowRequestThresholdMs = 5000
+
+// GzipMinSizeBytes is the smallest response body middleware.Gzip compresses.
+var GzipMinSizeBytes = 1024
+
+// RequestTimeoutSeconds bounds how long a request may take before its first
+// byte is written; 0 disables it. RequestTimeoutByGroup overrides it per route
+// group (api, admin, dashboard, relay). Relayed requests are bounded by the
+// channel timeout instead, so relay only applies to the model list routes.
+var RequestTimeoutSeconds = 120
+var RequestTimeoutByGroup = map[string]int{}
+
+// AdminIPAllowlist restricts the admin API to these CIDR ranges or addresses;
+// empty allows every client. TrustProxyHeaders makes such checks use the
+// client IP from X-Forwarded-For instead of the TCP peer.
+var AdminIPAllowlist = []string{}
+var TrustProxyHeaders = false
+
 var Footer = ""
 var Logo = ""
 var TopUpMode = ""
@@ -21,6 +46,11 @@ var TopUpAPITimeoutSeconds = 15
 var TopUpSignSecret = ""
 var TopUpCallbackToken = ""
 var ChatLink = ""
+
+// SlackWebhookURL enables Slack alerts for critical events; SlackAlertChannel
+// overrides the webhook's default channel.
+var SlackWebhookURL = ""
+var SlackAlertChannel = ""
 var QuotaPerUnit = 500 * 1000.0 // $0.002 / 1K tokens
 var FXAutoSyncEnabled = false
 var FXAutoSyncIntervalSeconds = 6 * 60 * 60
@@ -63,8 +93,17 @@ var EmailDomainWhitelist = []string{
 
 var DebugEnabled = false
 var DebugSQLEnabled = false
+
+// GraphQLPlaygroundEnabled serves the GraphQL playground and schema
+// introspection on the admin GraphQL endpoint.
+var GraphQLPlaygroundEnabled = false
 var MemoryCacheEnabled = false
 
+// User read-through cache used by FillUserById; UserCacheSize also bounds the
+// 5-second wallet address cache used by FillUserByWalletAddress.
+var UserCacheSize = 1000
+var UserCacheTTLSeconds = 30
+
 var LogConsumeEnabled = true
 
 var SMTPServer = ""
@@ -92,17 +131,140 @@ var WeChatAccountQRCodeImageURL = ""
 
 // Wallet login
 var AutoRegisterEnabled = false
+
+// WalletLoginEnabled is the startup default for accepting wallet logins;
+// admins can override it at runtime (see model.WalletLoginEnabled).
+var WalletLoginEnabled = true
+
+// WalletBindEnabled is the startup default for binding wallets to existing
+// accounts, independent of WalletLoginEnabled (see model.WalletBindEnabled).
+var WalletBindEnabled = true
+
+// When enabled, auto-registered wallet users start pending admin approval.
+var WalletAutoRegisterRequireApproval = false
+
+// Role ("common" or "admin") and status ("enabled", "pending" or "disabled")
+// given to auto-registered wallet users.
+var WalletAutoRegisterDefaultRole = "common"
+var WalletAutoRegisterDefaultStatus = "enabled"
+
+// WalletAutoRegisterDisplayNameTemplate is a text/template for the display name
+// of auto-registered wallet users with {{.ShortAddress}}, {{.Username}} and
+// {{.CreatedAt}}; empty uses the generated username.
+var WalletAutoRegisterDisplayNameTemplate = "{{.ShortAddress}}"
+
+// Maximum share of failed wallet logins per UTC day before the error budget is
+// exhausted and auto-registration is paused until midnight UTC.
+var WalletErrorSLO = 0.01
+
+// When enabled, auto-registered wallet users get a unique display name and
+// users.display_name is backed by a unique index.
+var WalletUniqueDisplayName = false
+
+// Chain IDs accepted by wallet login/bind, keyed by normalized decimal ID;
+// empty allows any chain.
+var WalletAllowedChains = map[string]struct{}{}
 var JWTSecret = ""
+
+// WalletJWTAlgorithm is HS256 (shared auth.jwt_secret) or RS256 (PEM key files,
+// so other services can verify tokens with the public key only).
+var WalletJWTAlgorithm = "HS256"
+var WalletJWTRSAPrivateKeyFile = ""
+var WalletJWTRSAPublicKeyFile = ""
 var JWTExpireHours = 72
+
+// Offset applied to wallet JWT nbf, negative values backdate it to tolerate clock skew.
+// Its absolute value is also used as verification leeway.
+var WalletJWTNotBeforeSeconds = 0
+
+// WalletJWTIssuer and WalletJWTAudience are written to the iss and aud claims
+// of wallet JWTs and, when set, required on verification.
+var WalletJWTIssuer = ""
+var WalletJWTAudience = ""
+
+// Wallet refresh throttling: max refresh calls per user per hour, and the remaining
+// lifetime above which a token is considered too fresh to refresh.
+var WalletRefreshRateLimit = 10
+var WalletRefreshMinRemainingSeconds = 300
 var RefreshTokenExpireHours = 24 * 30
+
+// Lifetime of the rotating refresh tokens issued by the proto verify endpoint.
+var WalletRefreshTokenExpireDays = 30
 var NonceTTLMinutes = 10
+
+// WalletNonceTTLByChain overrides NonceTTLMinutes per normalized chain ID.
+var WalletNonceTTLByChain = map[string]int{}
+
+// WalletNonceCleanupIntervalMinutes is how often expired in-memory nonces are swept.
+var WalletNonceCleanupIntervalMinutes = 5
+
+// WalletNonceRateLimit caps nonce requests per client IP and per wallet address,
+// e.g. "5/minute"; empty disables it.
+var WalletNonceRateLimit = "5/minute"
+
+// WalletBindRateLimit caps wallet bind attempts per logged-in user, e.g.
+// "10/hour"; empty disables it.
+var WalletBindRateLimit = "10/hour"
+
+// WalletGeoBlockEnabled turns on country filtering of wallet login routes using
+// the MaxMind GeoLite2 country database at GeoIPDBPath. Only one of
+// WalletGeoBlockedCountries and WalletGeoAllowedCountries may be set; both hold
+// upper-case ISO 3166-1 alpha-2 codes.
+var WalletGeoBlockEnabled = false
+var GeoIPDBPath = ""
+var WalletGeoBlockedCountries = []string{}
+var WalletGeoAllowedCountries = []string{}
+
+// WalletNonceFormat is "default" (WalletNonceMessageTemplate or the built-in
+// layout) or "siwe" (EIP-4361 Sign-In with Ethereum messages for Ethereum
+// addresses). WalletSIWEDomain and WalletSIWEURI override the domain and URI
+// derived from ServerAddress.
+var WalletNonceFormat = "default"
+var WalletSIWEDomain = ""
+var WalletSIWEURI = ""
+
+// WalletNonceMessageTemplate is a text/template for the nonce message with
+// {{.Prefix}}, {{.Nonce}}, {{.Address}}, {{.IssuedAt}} and {{.ChainId}};
+// empty keeps the built-in format.
+var WalletNonceMessageTemplate = ""
+
+// WalletNoncePrewarm keeps a pool of WalletNoncePoolSize nonces generated in
+// the background, falling back to inline generation when it runs dry.
+var WalletNoncePrewarm = false
+var WalletNoncePoolSize = 64
+
+// WalletNonceIdempotent returns the pending nonce for an address instead of
+// replacing it, so concurrent challenge requests don't invalidate each other.
+// The trade-off is that a nonce is reused for its whole TTL rather than being
+// fresh per request.
+var WalletNonceIdempotent = false
+
+// WalletVerifyCacheTTLSeconds keeps a successful verify response for frontend
+// retries of the same signature; 0 disables the cache.
+var WalletVerifyCacheTTLSeconds = 10
+
+// WalletNonceStore is "memory" (per process) or "redis" (shared between instances).
+var WalletNonceStore = "memory"
 var RefreshCookieDomain = ""
 var RefreshCookieSecure = false
 var RefreshCookieSameSite = "lax"
 
+// WebAuthn relying party for passkey login; passkeys are disabled while
+// WebAuthnRPID is empty.
+var WebAuthnRPID = ""
+var WebAuthnRPOrigins []string
+
 // Optional fallback secrets (comma-separated env JWT_FALLBACK_SECRETS) for verifying wallet JWTs issued by external services.
 var JWTFallbackSecrets []string
 
+// External issuer JWKS (env EXTERNAL_JWKS_URL); empty disables external JWT verification.
+// External tokens must carry ExternalJWTIssuer as iss and ExternalJWTAudience in aud;
+// both are required whenever ExternalJWKSURL is set.
+var ExternalJWKSURL = ""
+var ExternalJWKSCacheTTLSeconds = 3600
+var ExternalJWTIssuer = ""
+var ExternalJWTAudience = ""
+
 // UCAN auth
 var UcanAud = ""
 
@@ -127,6 +289,32 @@ var UcanTrustedIssuerDIDs []string
 // CORS allowlist (comma-separated env CORS_ALLOWED_ORIGINS)
 var CorsAllowedOrigins []string
 
+// CorsAllowCredentials sends Access-Control-Allow-Credentials (env CORS_ALLOW_CREDENTIALS).
+var CorsAllowCredentials = true
+
+// CorsMaxAgeSeconds is how long browsers may cache a preflight response.
+var CorsMaxAgeSeconds = 12 * 60 * 60
+
+// CorsWalletAllowedOrigins replaces CorsAllowedOrigins on the wallet login
+// routes when set, e.g. to admit dApp origins only there.
+var CorsWalletAllowedOrigins []string
+
+// Security response headers (secure_headers section, env SECURE_HEADERS_*).
+// An empty value leaves that header out; HSTS is off by default because it
+// only belongs on HTTPS deployments.
+var SecureHeadersEnabled = true
+var SecureHeadersHSTS = ""
+var SecureHeadersContentSecurityPolicy = DefaultContentSecurityPolicy
+var SecureHeadersXFrameOptions = "DENY"
+var SecureHeadersReferrerPolicy = "strict-origin-when-cross-origin"
+var SecureHeadersPermissionsPolicy = "camera=(), microphone=(), geolocation=()"
+
+// DefaultContentSecurityPolicy allows the bundled frontend and wallet
+// connectors: extension wallets such as MetaMask inject their provider outside
+// the page CSP, while WalletConnect needs https:/wss: in connect-src for its
+// relay and its verify iframe in frame-src.
+const DefaultContentSecurityPolicy = "default-src 'self'; script-src 'self'; style-src 'self' 'unsafe-inline'; img-src 'self' data: blob: https:; font-src 'self' data:; connect-src 'self' https: wss:; frame-src 'self' https://verify.walletconnect.com https://verify.walletconnect.org; frame-ancestors 'none'; base-uri 'self'; form-action 'self'"
+
 var MessagePusherAddress = ""
 var MessagePusherToken = ""
 
@@ -180,13 +368,24 @@ var (
 var RateLimitKeyExpirationDuration = 20 * time.Minute
 
 var EnableMetric = false
+
+// PrometheusMetricsEnabled exposes the wallet authentication metrics at /metrics.
+var PrometheusMetricsEnabled = false
+
+// OtelEnabled traces every request with OpenTelemetry and exports the spans
+// over OTLP/HTTP to OtelExporterEndpoint.
+var OtelEnabled = false
+var OtelExporterEndpoint = ""
 var MetricQueueSize = 10
 var MetricSuccessRateThreshold = 0.8
 var MetricSuccessChanSize = 1024
 var MetricFailChanSize = 128
 
 var RootWalletAddress = ""
-var RootWalletAddresses []string
+
+// RootWalletAddresses is the set of lower-case root wallet addresses parsed
+// from RootWalletAddress.
+var RootWalletAddresses = map[string]bool{}
 
 var GeminiVersion = "v1"
 
@@ -196,6 +395,18 @@ var LogRotateMaxBackups = 10
 var LogRotateMaxAgeDays = 14
 var LogRotateCompress = false
 
+// wallet_audit.jsonl rotates daily or once it reaches this size.
+var WalletAuditLogMaxMB = 100
+
+// Upper bound for a single relay log export stream.
+var ExportTimeoutMinutes = 10
+
+// SyslogEnabled forwards every api.log line to SyslogAddr over UDP, as a
+// syslog message or, with SyslogProtocol "gelf", as a GELF message.
+var SyslogEnabled = false
+var SyslogAddr = ""
+var SyslogProtocol = "syslog"
+
 var RelayProxy = ""
 var UserContentRequestProxy = ""
 var UserContentRequestTimeout = 30
@@ -203,3 +414,19 @@ var UserContentRequestPrivateHostAllowlist = []string{}
 
 var EnforceIncludeUsage = false
 var TestPrompt = "Output only your specific model name with no additional text."
+
+// RelayUpstreamUserAgent overrides the client User-Agent sent upstream;
+// RelayPassthroughHeaders lists extra client headers forwarded upstream.
+var RelayUpstreamUserAgent = ""
+var RelayPassthroughHeaders = []string{}
+
+// AzureOpenAIMode accepts Azure OpenAI style /openai/deployments/<name>/...
+// relay paths and treats the deployment name as the model when none is given.
+var AzureOpenAIMode = false
+
+// ModelListGlobEnabled lets token model allowlists use "*" wildcards.
+var ModelListGlobEnabled = true
+
+// ResponseRedactPatterns are regexes whose matches are replaced with [REDACTED]
+// in relay responses before they reach the client.
+var ResponseRedactPatterns = []string{}
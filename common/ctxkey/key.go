@@ -3,10 +3,12 @@ package ctxkey
 const (
 	Config              = "config"
 	Id                  = "id"
+	User                = "user"
 	Username            = "username"
 	Role                = "role"
 	CanManageUsers      = "can_manage_users"
 	Status              = "status"
+	WalletAddress       = "wallet_address"
 	Channel             = "channel"
 	ChannelId           = "channel_id"
 	SpecificChannelId   = "specific_channel_id"
@@ -17,6 +19,7 @@ const (
 	ModelMapping        = "model_mapping"
 	ChannelModelConfigs = "channel_model_configs"
 	ChannelName         = "channel_name"
+	ChannelTimeout      = "channel_timeout_seconds"
 	TokenId             = "token_id"
 	TokenName           = "token_name"
 	BaseURL             = "base_url"
@@ -29,4 +32,9 @@ const (
 	RelayErrorType      = "relay_error_type"
 	RelayErrorCode      = "relay_error_code"
 	RelayTermination    = "relay_termination"
+	InputTokens         = "input_tokens"
+	OutputTokens        = "output_tokens"
+	WalletLoginResult   = "wallet_login_result"
+	DeploymentId        = "deployment_id"
+	ModelName           = "model_name"
 )
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -51,3 +52,51 @@ func SetEventStreamHeaders(c *gin.Context) {
 	c.Writer.Header().Set("Transfer-Encoding", "chunked")
 	c.Writer.Header().Set("X-Accel-Buffering", "no")
 }
+
+// Proto error codes carried in status.code by the proto-aligned auth endpoints.
+const (
+	ProtoCodeInvalidArgument   = 2
+	ProtoCodeUnauthenticated   = 3
+	ProtoCodePermissionDenied  = 4
+	ProtoCodeNotFound          = 5
+	ProtoCodeResourceExhausted = 8
+	ProtoCodePendingApproval   = 9
+)
+
+// ProtoCodeForHTTPStatus picks the proto code for errors raised by middleware,
+// which only know the HTTP status.
+func ProtoCodeForHTTPStatus(status int) int {
+	switch {
+	case status == http.StatusUnauthorized:
+		return ProtoCodeUnauthenticated
+	case status == http.StatusForbidden:
+		return ProtoCodePermissionDenied
+	case status == http.StatusNotFound:
+		return ProtoCodeNotFound
+	case status == http.StatusTooManyRequests || status >= http.StatusInternalServerError:
+		return ProtoCodeResourceExhausted
+	default:
+		return ProtoCodeInvalidArgument
+	}
+}
+
+// AbortWithError writes the error envelope of the proto-aligned endpoints and
+// stops the handler chain. success/message are kept for existing web clients;
+// status.code carries protoCode.
+func AbortWithError(c *gin.Context, httpStatus int, protoCode int, message string) {
+	c.AbortWithStatusJSON(httpStatus, ProtoErrorBody(protoCode, message))
+}
+
+// ProtoErrorBody is the envelope written by AbortWithError, for handlers that
+// add their own fields to it.
+func ProtoErrorBody(protoCode int, message string) gin.H {
+	return gin.H{
+		"success": false,
+		"message": message,
+		"data":    nil,
+		"status": gin.H{
+			"code":    protoCode,
+			"message": message,
+		},
+	}
+}
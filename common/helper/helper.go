@@ -121,6 +121,15 @@ func GetTraceID(ctx context.Context) string {
 	return rawTraceID.(string)
 }
 
+func SetRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIdKey, id)
+}
+
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIdKey).(string)
+	return id
+}
+
 func GetResponseID(c *gin.Context) string {
 	logID := c.GetString(TraceIDKey)
 	return fmt.Sprintf("chatcmpl-%s", logID)
@@ -4,4 +4,6 @@ const (
 	TraceIDKey        = "X-Trace-Id"
 	TraceParentHeader = "traceparent"
 	XRequestIDHeader  = "X-Request-Id"
+	// RequestIdKey holds the request id in the gin and request contexts.
+	RequestIdKey = "request_id"
 )
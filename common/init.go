@@ -27,6 +27,7 @@ func printHelp() {
 
 func Init() {
 	flag.Parse()
+	RegisterValidators()
 
 	if *PrintVersion {
 		fmt.Println(Version)
@@ -0,0 +1,260 @@
+package common
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+)
+
+const externalJWKSFetchTimeout = 10 * time.Second
+
+// unknown kids trigger at most one out-of-band refresh per interval, so a key
+// rotated on the IdP is picked up without waiting for the full cache TTL.
+const externalJWKSMinRefreshInterval = time.Minute
+
+var externalJWTSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type externalJWKSCache struct {
+	mu        sync.RWMutex
+	keys      map[string]any
+	fetchedAt time.Time
+	startOnce sync.Once
+	refreshMu sync.Mutex
+}
+
+var externalJWKS externalJWKSCache
+
+// ExternalJWTEnabled reports whether external JWT verification is configured:
+// a JWKS URL plus the issuer and audience the tokens must carry.
+func ExternalJWTEnabled() bool {
+	return strings.TrimSpace(config.ExternalJWKSURL) != "" &&
+		strings.TrimSpace(config.ExternalJWTIssuer) != "" &&
+		strings.TrimSpace(config.ExternalJWTAudience) != ""
+}
+
+// VerifyExternalJWT verifies a JWT signed by an external issuer using the key
+// from EXTERNAL_JWKS_URL that matches the token's kid header. The token must
+// name EXTERNAL_JWT_ISSUER and EXTERNAL_JWT_AUDIENCE and carry an exp, so a
+// token the IdP issued for another client is not accepted here.
+func VerifyExternalJWT(tokenString string) (*jwt.MapClaims, error) {
+	if !ExternalJWTEnabled() {
+		return nil, errors.New("external jwt verification not configured")
+	}
+	externalJWKS.start()
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token header missing kid")
+		}
+		key, ok := externalJWKS.lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("no jwks key for kid %q", kid)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods(externalJWTSigningMethods),
+		jwt.WithLeeway(walletJWTLeeway()),
+		jwt.WithIssuer(config.ExternalJWTIssuer),
+		jwt.WithAudience(config.ExternalJWTAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// start loads the key set once and keeps refreshing it in the background.
+func (c *externalJWKSCache) start() {
+	c.startOnce.Do(func() {
+		if err := c.refresh(); err != nil {
+			logger.SysErrorf("external jwks initial fetch failed: %v", err)
+		}
+		go func() {
+			for {
+				time.Sleep(externalJWKSCacheTTL())
+				if err := c.refresh(); err != nil {
+					logger.SysErrorf("external jwks refresh failed: %v", err)
+				}
+			}
+		}()
+	})
+}
+
+func (c *externalJWKSCache) lookup(kid string) (any, bool) {
+	c.mu.RLock()
+	key, ok := c.keys[kid]
+	stale := time.Since(c.fetchedAt) >= externalJWKSMinRefreshInterval
+	c.mu.RUnlock()
+	if ok || !stale {
+		return key, ok
+	}
+	c.refreshMu.Lock()
+	c.mu.RLock()
+	stale = time.Since(c.fetchedAt) >= externalJWKSMinRefreshInterval
+	c.mu.RUnlock()
+	if stale {
+		if err := c.refresh(); err != nil {
+			logger.SysErrorf("external jwks refresh for kid %s failed: %v", kid, err)
+		}
+	}
+	c.refreshMu.Unlock()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	key, ok = c.keys[kid]
+	return key, ok
+}
+
+func (c *externalJWKSCache) refresh() error {
+	keys, err := fetchExternalJWKS(config.ExternalJWKSURL)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetchedAt = time.Now()
+	if err != nil {
+		return err
+	}
+	c.keys = keys
+	return nil
+}
+
+func externalJWKSCacheTTL() time.Duration {
+	if config.ExternalJWKSCacheTTLSeconds <= 0 {
+		return time.Hour
+	}
+	return time.Duration(config.ExternalJWKSCacheTTLSeconds) * time.Second
+}
+
+func fetchExternalJWKS(url string) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), externalJWKSFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		// The issuer may not have published its key set yet; treat as empty.
+		logger.SysWarnf("external jwks not published yet: %s returned 404", url)
+		return map[string]any{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected jwks status %d", resp.StatusCode)
+	}
+	var body struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+	keys := make(map[string]any, len(body.Keys))
+	for _, jwk := range body.Keys {
+		if jwk.Kid == "" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			logger.SysWarnf("external jwks skip kid=%s: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("rsa exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key length")
+		}
+		return ed25519.PublicKey(raw), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeJWKBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("empty key component")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
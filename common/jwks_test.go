@@ -0,0 +1,87 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestVerifyExternalJWT_Ed25519KeyFromJWKS(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey error: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kid": "k1",
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"use": "sig",
+				"x":   base64.RawURLEncoding.EncodeToString(pub),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	prevURL, prevIssuer, prevAudience := config.ExternalJWKSURL, config.ExternalJWTIssuer, config.ExternalJWTAudience
+	config.ExternalJWKSURL = server.URL
+	config.ExternalJWTIssuer = "https://idp.example.com"
+	config.ExternalJWTAudience = "router"
+	defer func() {
+		config.ExternalJWKSURL, config.ExternalJWTIssuer, config.ExternalJWTAudience = prevURL, prevIssuer, prevAudience
+	}()
+
+	sign := func(claims jwt.MapClaims, kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(priv)
+		if err != nil {
+			t.Fatalf("SignedString error: %v", err)
+		}
+		return signed
+	}
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "0x1111111111111111111111111111111111111111",
+			"iss": "https://idp.example.com",
+			"aud": "router",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	signed := sign(validClaims(), "k1")
+
+	claims, err := VerifyExternalJWT(signed)
+	if err != nil {
+		t.Fatalf("VerifyExternalJWT error: %v", err)
+	}
+	if (*claims)["sub"] != "0x1111111111111111111111111111111111111111" {
+		t.Fatalf("VerifyExternalJWT sub = %v", (*claims)["sub"])
+	}
+
+	if _, err := VerifyExternalJWT(sign(validClaims(), "unknown")); err == nil {
+		t.Fatalf("VerifyExternalJWT accepted token with unknown kid")
+	}
+	for name, mutate := range map[string]func(jwt.MapClaims){
+		"other audience": func(c jwt.MapClaims) { c["aud"] = "another-client" },
+		"other issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"no issuer":      func(c jwt.MapClaims) { delete(c, "iss") },
+		"no audience":    func(c jwt.MapClaims) { delete(c, "aud") },
+		"no expiry":      func(c jwt.MapClaims) { delete(c, "exp") },
+	} {
+		claims := validClaims()
+		mutate(claims)
+		if _, err := VerifyExternalJWT(sign(claims, "k1")); err == nil {
+			t.Fatalf("VerifyExternalJWT accepted token with %s", name)
+		}
+	}
+}
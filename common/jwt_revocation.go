@@ -0,0 +1,204 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+)
+
+// RevocationStore records revoked wallet JWTs until the tokens would have
+// expired anyway, and used wallet signatures (see MarkWalletSignatureUsed). It
+// follows auth.nonce_store: per process in memory, or shared through Redis.
+type RevocationStore interface {
+	// Set stores value under key for ttl.
+	Set(key string, value int64, ttl time.Duration) error
+	// SetIfAbsent stores value only when key is missing or expired, and
+	// reports whether it did.
+	SetIfAbsent(key string, value int64, ttl time.Duration) (bool, error)
+	// Get returns the value if the key exists and has not expired.
+	Get(key string) (int64, bool)
+}
+
+const (
+	jwtRevocationRedisPrefix = "router:jwt_revoked:"
+	jwtRevocationJTIPrefix   = "jti:"
+	jwtRevocationUserPrefix  = "user:"
+)
+
+var (
+	jwtRevocationStoreMutex sync.RWMutex
+	jwtRevocationStore      RevocationStore = newMemoryRevocationStore()
+)
+
+func SetWalletJWTRevocationStore(store RevocationStore) {
+	jwtRevocationStoreMutex.Lock()
+	defer jwtRevocationStoreMutex.Unlock()
+	jwtRevocationStore = store
+}
+
+func getWalletJWTRevocationStore() RevocationStore {
+	jwtRevocationStoreMutex.RLock()
+	defer jwtRevocationStoreMutex.RUnlock()
+	return jwtRevocationStore
+}
+
+// walletJWTMaxLifetime is how long a revocation has to be remembered.
+func walletJWTMaxLifetime() time.Duration {
+	hours := config.JWTExpireHours
+	if config.RefreshTokenExpireHours > hours {
+		hours = config.RefreshTokenExpireHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// RevokeWalletJWT invalidates the access or refresh token with the given jti.
+func RevokeWalletJWT(jti string) error {
+	jti = strings.TrimSpace(jti)
+	if jti == "" {
+		return errors.New("jti 为空")
+	}
+	if err := getWalletJWTRevocationStore().Set(jwtRevocationJTIPrefix+jti, 1, walletJWTMaxLifetime()); err != nil {
+		return err
+	}
+	return revokeStoredWalletToken(jti)
+}
+
+// RevokeWalletJWTsForUser invalidates every wallet token issued to userID up to
+// now, e.g. when the account is disabled.
+func RevokeWalletJWTsForUser(userID string) error {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return errors.New("user id 为空")
+	}
+	return getWalletJWTRevocationStore().Set(jwtRevocationUserPrefix+userID, time.Now().Unix(), walletJWTMaxLifetime())
+}
+
+// IsWalletJWTRevoked reports whether claims belong to a revoked token.
+func IsWalletJWTRevoked(claims *WalletClaims) bool {
+	if claims == nil {
+		return false
+	}
+	store := getWalletJWTRevocationStore()
+	if claims.ID != "" {
+		if _, ok := store.Get(jwtRevocationJTIPrefix + claims.ID); ok {
+			return true
+		}
+		if isStoredWalletTokenRevoked(claims.ID) {
+			return true
+		}
+	}
+	if claims.UserID != "" {
+		if revokedAt, ok := store.Get(jwtRevocationUserPrefix + claims.UserID); ok {
+			return claims.IssuedAt == nil || claims.IssuedAt.Unix() <= revokedAt
+		}
+	}
+	return false
+}
+
+type memoryRevocationEntry struct {
+	value    int64
+	expireAt time.Time
+}
+
+type memoryRevocationStore struct {
+	mu         sync.Mutex
+	entries    map[string]memoryRevocationEntry
+	lastPruned time.Time
+}
+
+// memoryRevocationPruneInterval spaces out the expiry scans, which would
+// otherwise run on every recorded login signature.
+const memoryRevocationPruneInterval = time.Minute
+
+func (s *memoryRevocationStore) pruneLocked(now time.Time) {
+	if now.Sub(s.lastPruned) < memoryRevocationPruneInterval {
+		return
+	}
+	s.lastPruned = now
+	for k, entry := range s.entries {
+		if now.After(entry.expireAt) {
+			delete(s.entries, k)
+		}
+	}
+}
+
+func newMemoryRevocationStore() *memoryRevocationStore {
+	return &memoryRevocationStore{entries: make(map[string]memoryRevocationEntry)}
+}
+
+func (s *memoryRevocationStore) Set(key string, value int64, ttl time.Duration) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked(now)
+	s.entries[key] = memoryRevocationEntry{value: value, expireAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryRevocationStore) SetIfAbsent(key string, value int64, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pruneLocked(now)
+	if entry, ok := s.entries[key]; ok && !now.After(entry.expireAt) {
+		return false, nil
+	}
+	s.entries[key] = memoryRevocationEntry{value: value, expireAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *memoryRevocationStore) Get(key string) (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expireAt) {
+		return 0, false
+	}
+	return entry.value, true
+}
+
+// RedisRevocationStore shares revocations between router instances.
+type RedisRevocationStore struct {
+	client redis.Cmdable
+}
+
+func NewRedisRevocationStore(client redis.Cmdable) *RedisRevocationStore {
+	return &RedisRevocationStore{client: client}
+}
+
+func (s *RedisRevocationStore) Set(key string, value int64, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), walletNonceRedisTimeout)
+	defer cancel()
+	return s.client.Set(ctx, jwtRevocationRedisPrefix+key, value, ttl).Err()
+}
+
+func (s *RedisRevocationStore) SetIfAbsent(key string, value int64, ttl time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), walletNonceRedisTimeout)
+	defer cancel()
+	return s.client.SetNX(ctx, jwtRevocationRedisPrefix+key, value, ttl).Result()
+}
+
+func (s *RedisRevocationStore) Get(key string) (int64, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), walletNonceRedisTimeout)
+	defer cancel()
+	raw, err := s.client.Get(ctx, jwtRevocationRedisPrefix+key).Result()
+	if err != nil {
+		if err != redis.Nil {
+			logger.SysErrorf("get jwt revocation from redis failed key=%s err=%v", key, err)
+		}
+		return 0, false
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestWalletJWTRevocation(t *testing.T) {
+	prevSecret, prevAlgorithm := config.JWTSecret, config.WalletJWTAlgorithm
+	defer func() {
+		config.JWTSecret, config.WalletJWTAlgorithm = prevSecret, prevAlgorithm
+		SetWalletJWTRevocationStore(newMemoryRevocationStore())
+	}()
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = WalletJWTAlgorithmHS256
+	SetWalletJWTRevocationStore(newMemoryRevocationStore())
+
+	first, _, err := GenerateWalletJWT("user-1", "0xabc")
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT error: %v", err)
+	}
+	second, _, _ := GenerateWalletJWT("user-1", "0xabc")
+	claims, err := VerifyWalletJWT(first)
+	if err != nil || claims.ID == "" {
+		t.Fatalf("VerifyWalletJWT = %+v, %v; want claims with jti", claims, err)
+	}
+
+	if err := RevokeWalletJWT(claims.ID); err != nil {
+		t.Fatalf("RevokeWalletJWT error: %v", err)
+	}
+	if _, err := VerifyWalletJWT(first); err == nil {
+		t.Fatalf("revoked token still verifies")
+	}
+	if _, err := VerifyWalletJWT(second); err != nil {
+		t.Fatalf("unrelated token rejected: %v", err)
+	}
+
+	if err := RevokeWalletJWTsForUser("user-1"); err != nil {
+		t.Fatalf("RevokeWalletJWTsForUser error: %v", err)
+	}
+	if _, err := VerifyWalletJWT(second); err == nil {
+		t.Fatalf("token issued before user revocation still verifies")
+	}
+	later := &WalletClaims{UserID: "user-1", RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}
+	if IsWalletJWTRevoked(later) {
+		t.Fatalf("token issued after user revocation reported revoked")
+	}
+}
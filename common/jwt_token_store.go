@@ -0,0 +1,108 @@
+package common
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/yeying-community/router/common/logger"
+)
+
+// WalletTokenStore persists issued wallet JWTs by jti so a token revoked at
+// logout stays rejected across restarts and instances, whatever
+// auth.nonce_store is. The model package installs the database implementation.
+type WalletTokenStore interface {
+	RecordWalletToken(jti, userID string, issuedAt, expiresAt time.Time) error
+	RevokeWalletToken(jti string, revokedAt time.Time) error
+	IsWalletTokenRevoked(jti string) (bool, error)
+}
+
+// walletTokenCacheTTL bounds how long a "not revoked" answer is trusted, i.e.
+// how long a token revoked on another instance may keep working here.
+const walletTokenCacheTTL = 30 * time.Second
+
+var (
+	walletTokenStoreMutex sync.RWMutex
+	walletTokenStore      WalletTokenStore
+	// walletTokenCache holds 1 for revoked and 0 for live jtis
+	walletTokenCache = newMemoryRevocationStore()
+)
+
+func SetWalletTokenStore(store WalletTokenStore) {
+	walletTokenStoreMutex.Lock()
+	defer walletTokenStoreMutex.Unlock()
+	walletTokenStore = store
+}
+
+func getWalletTokenStore() WalletTokenStore {
+	walletTokenStoreMutex.RLock()
+	defer walletTokenStoreMutex.RUnlock()
+	return walletTokenStore
+}
+
+// recordWalletToken persists a freshly signed token. A failure is logged
+// rather than returned: the token is still valid, it just can only be revoked
+// through the revocation store.
+func recordWalletToken(claims WalletClaims) {
+	store := getWalletTokenStore()
+	// binding tokens have no user yet and are tracked by ClaimWalletBindJWT
+	if store == nil || claims.ID == "" || claims.UserID == "" {
+		return
+	}
+	var issuedAt, expiresAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := store.RecordWalletToken(claims.ID, claims.UserID, issuedAt, expiresAt); err != nil {
+		logger.SysErrorf("record wallet jwt failed jti=%s user=%s: %v", claims.ID, claims.UserID, err)
+	}
+}
+
+func revokeStoredWalletToken(jti string) error {
+	store := getWalletTokenStore()
+	if store == nil {
+		return nil
+	}
+	if err := store.RevokeWalletToken(jti, time.Now()); err != nil {
+		return err
+	}
+	return walletTokenCache.Set(jti, 1, walletJWTMaxLifetime())
+}
+
+// isStoredWalletTokenRevoked checks the token table through a short-lived
+// cache. Lookup errors fail open: the revocation store still covers tokens
+// revoked on this instance.
+func isStoredWalletTokenRevoked(jti string) bool {
+	store := getWalletTokenStore()
+	if store == nil || jti == "" {
+		return false
+	}
+	if value, ok := walletTokenCache.Get(jti); ok {
+		return value == 1
+	}
+	revoked, err := store.IsWalletTokenRevoked(jti)
+	if err != nil {
+		logger.SysErrorf("check wallet jwt revocation failed jti=%s: %v", jti, err)
+		return false
+	}
+	if revoked {
+		_ = walletTokenCache.Set(jti, 1, walletJWTMaxLifetime())
+	} else {
+		_ = walletTokenCache.Set(jti, 0, walletTokenCacheTTL)
+	}
+	return revoked
+}
+
+// WalletJWTID returns the jti of a token this server just issued, for
+// responses that hand the token out. The signature is not checked.
+func WalletJWTID(token string) string {
+	var claims WalletClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil {
+		return ""
+	}
+	return claims.ID
+}
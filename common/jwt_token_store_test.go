@@ -0,0 +1,85 @@
+package common
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+type fakeWalletTokenStore struct {
+	mu      sync.Mutex
+	users   map[string]string
+	revoked map[string]bool
+	lookups int
+}
+
+func (s *fakeWalletTokenStore) RecordWalletToken(jti, userID string, issuedAt, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[jti] = userID
+	return nil
+}
+
+func (s *fakeWalletTokenStore) RevokeWalletToken(jti string, revokedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked[jti] = true
+	return nil
+}
+
+func (s *fakeWalletTokenStore) IsWalletTokenRevoked(jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	return s.revoked[jti], nil
+}
+
+func TestWalletTokenStore(t *testing.T) {
+	prevSecret, prevAlgorithm := config.JWTSecret, config.WalletJWTAlgorithm
+	store := &fakeWalletTokenStore{users: map[string]string{}, revoked: map[string]bool{}}
+	defer func() {
+		config.JWTSecret, config.WalletJWTAlgorithm = prevSecret, prevAlgorithm
+		SetWalletJWTRevocationStore(newMemoryRevocationStore())
+		SetWalletTokenStore(nil)
+		walletTokenCache = newMemoryRevocationStore()
+	}()
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = WalletJWTAlgorithmHS256
+	SetWalletJWTRevocationStore(newMemoryRevocationStore())
+	SetWalletTokenStore(store)
+	walletTokenCache = newMemoryRevocationStore()
+
+	token, _, err := GenerateWalletJWT("user-1", "0xabc")
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT error: %v", err)
+	}
+	jti := WalletJWTID(token)
+	if jti == "" || store.users[jti] != "user-1" {
+		t.Fatalf("issued jti %q not recorded: %v", jti, store.users)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := VerifyWalletJWT(token); err != nil {
+			t.Fatalf("VerifyWalletJWT error: %v", err)
+		}
+	}
+	if store.lookups != 1 {
+		t.Fatalf("store lookups = %d, want 1 (cached)", store.lookups)
+	}
+
+	// a revocation made on another instance only reaches the store
+	store.RevokeWalletToken(jti, time.Now())
+	walletTokenCache = newMemoryRevocationStore()
+	if _, err := VerifyWalletJWT(token); err == nil {
+		t.Fatalf("token revoked in the store still verifies")
+	}
+
+	other, _, _ := GenerateWalletJWT("user-1", "0xabc")
+	if err := RevokeWalletJWT(WalletJWTID(other)); err != nil {
+		t.Fatalf("RevokeWalletJWT error: %v", err)
+	}
+	if !store.revoked[WalletJWTID(other)] {
+		t.Fatalf("RevokeWalletJWT did not reach the token store")
+	}
+}
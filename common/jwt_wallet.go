@@ -7,6 +7,9 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/common/metrics"
+	"github.com/yeying-community/router/common/random"
 )
 
 // WalletClaims defines JWT claims for wallet login.
@@ -14,15 +17,18 @@ type WalletClaims struct {
 	UserID        string `json:"user_id"`
 	WalletAddress string `json:"wallet_address"`
 	TokenType     string `json:"token_type,omitempty"`
+	// WebAuthnCredentialID is the base64url passkey credential a token was
+	// issued for; wallet logins leave it empty.
+	WebAuthnCredentialID string `json:"webauthn_credential_id,omitempty"`
+	// WalletType and Email are only set on binding tokens, see
+	// GenerateWalletBindJWT.
+	WalletType string `json:"wallet_type,omitempty"`
+	Email      string `json:"email,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // GenerateWalletJWT issues a JWT for the given user id and wallet address.
 func GenerateWalletJWT(userID string, walletAddress string) (token string, expiresAt time.Time, err error) {
-	secret := []byte(config.JWTSecret)
-	if len(secret) == 0 {
-		return "", time.Time{}, errors.New("auth.jwt_secret not configured")
-	}
 	expiresAt = time.Now().Add(time.Duration(config.JWTExpireHours) * time.Hour)
 	claims := WalletClaims{
 		UserID:        userID,
@@ -31,33 +37,79 @@ func GenerateWalletJWT(userID string, walletAddress string) (token string, expir
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(walletJWTNotBefore()),
 			Subject:   walletAddress,
+			ID:        random.GetUUID(),
 		},
 	}
-	tokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err = tokenObj.SignedString(secret)
+	token, err = signWalletClaims(claims)
+	if err == nil {
+		metrics.IncWalletJWTIssued()
+	}
+	return
+}
+
+// GenerateWebAuthnJWT issues an access token for a passkey login. It has no
+// wallet address; the subject is the user id.
+func GenerateWebAuthnJWT(userID string, credentialID string) (token string, expiresAt time.Time, err error) {
+	expiresAt = time.Now().Add(time.Duration(config.JWTExpireHours) * time.Hour)
+	claims := WalletClaims{
+		UserID:               userID,
+		TokenType:            "access",
+		WebAuthnCredentialID: credentialID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(walletJWTNotBefore()),
+			Subject:   userID,
+			ID:        random.GetUUID(),
+		},
+	}
+	token, err = signWalletClaims(claims)
+	if err == nil {
+		metrics.IncWalletJWTIssued()
+	}
 	return
 }
 
 // VerifyWalletJWT validates token and returns claims.
 func VerifyWalletJWT(tokenString string) (*WalletClaims, error) {
-	claims, err := verifyWithSecrets(tokenString, append([]string{config.JWTSecret}, config.JWTFallbackSecrets...))
+	claims, _, err := VerifyWalletJWTWithRenewal(tokenString)
+	return claims, err
+}
+
+// VerifyWalletJWTWithRenewal validates an access token like VerifyWalletJWT.
+// When it only verifies with one of auth.jwt_fallback_secrets, the same claims
+// are re-signed with auth.jwt_secret and returned as renewed, so clients move
+// to the new secret before the old one is dropped. renewed is empty otherwise.
+func VerifyWalletJWTWithRenewal(tokenString string) (claims *WalletClaims, renewed string, err error) {
+	claims, fallback, err := parseWalletJWTSecret(tokenString)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	if claims.TokenType == "refresh" {
-		return nil, errors.New("refresh token not allowed for access")
+		return nil, "", errors.New("refresh token not allowed for access")
 	}
-	return claims, nil
+	if claims.TokenType == walletBindTokenType {
+		return nil, "", errors.New("binding token not allowed for access")
+	}
+	if IsWalletJWTRevoked(claims) {
+		return nil, "", errors.New("token has been revoked")
+	}
+	if fallback && config.JWTSecret != "" {
+		// the jti, expiry and subject are kept, so revoking either copy
+		// revokes both and the renewed token is not recorded again
+		renewed, err = jwt.NewWithClaims(jwt.SigningMethodHS256, *claims).SignedString([]byte(config.JWTSecret))
+		if err != nil {
+			logger.SysErrorf("re-sign wallet jwt jti=%s failed: %v", claims.ID, err)
+			renewed = ""
+		}
+	}
+	return claims, renewed, nil
 }
 
 // GenerateWalletRefreshJWT issues a refresh token for the given user id and wallet address.
 func GenerateWalletRefreshJWT(userID string, walletAddress string) (token string, expiresAt time.Time, err error) {
-	secret := []byte(config.JWTSecret)
-	if len(secret) == 0 {
-		return "", time.Time{}, errors.New("auth.jwt_secret not configured")
-	}
 	expiresAt = time.Now().Add(time.Duration(config.RefreshTokenExpireHours) * time.Hour)
 	claims := WalletClaims{
 		UserID:        userID,
@@ -66,34 +118,133 @@ func GenerateWalletRefreshJWT(userID string, walletAddress string) (token string
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(walletJWTNotBefore()),
 			Subject:   walletAddress,
+			ID:        random.GetUUID(),
 		},
 	}
-	tokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err = tokenObj.SignedString(secret)
+	token, err = signWalletClaims(claims)
+	if err == nil {
+		metrics.IncWalletJWTIssued()
+	}
 	return
 }
 
 // VerifyWalletRefreshJWT validates refresh token and returns claims.
 func VerifyWalletRefreshJWT(tokenString string) (*WalletClaims, error) {
-	claims, err := verifyWithSecrets(tokenString, append([]string{config.JWTSecret}, config.JWTFallbackSecrets...))
+	claims, err := parseWalletJWT(tokenString)
 	if err != nil {
 		return nil, err
 	}
 	if claims.TokenType != "refresh" {
 		return nil, errors.New("token is not refresh")
 	}
+	if IsWalletJWTRevoked(claims) {
+		return nil, errors.New("token has been revoked")
+	}
 	return claims, nil
 }
 
-// verifyWithSecrets tries multiple secrets in order and returns on first success.
-func verifyWithSecrets(tokenString string, secrets []string) (*WalletClaims, error) {
+// signWalletClaims signs with the RSA private key in RS256 mode and with
+// auth.jwt_secret otherwise. The configured issuer and audience are added here
+// so every token kind carries them, and every signed token is recorded in the
+// WalletTokenStore.
+func signWalletClaims(claims WalletClaims) (string, error) {
+	if config.WalletJWTIssuer != "" {
+		claims.Issuer = config.WalletJWTIssuer
+	}
+	if config.WalletJWTAudience != "" {
+		claims.Audience = jwt.ClaimStrings{config.WalletJWTAudience}
+	}
+	var (
+		token string
+		err   error
+	)
+	if isWalletJWTRS256() {
+		privateKey := getWalletJWTPrivateKey()
+		if privateKey == nil {
+			return "", errors.New("auth.jwt_rsa_private_key_file not configured")
+		}
+		token, err = jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(privateKey)
+	} else {
+		secret := []byte(config.JWTSecret)
+		if len(secret) == 0 {
+			return "", errors.New("auth.jwt_secret not configured")
+		}
+		token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	}
+	if err != nil {
+		return "", err
+	}
+	recordWalletToken(claims)
+	return token, nil
+}
+
+// ParseWalletJWTClaims verifies the signature of an access or refresh token
+// without checking its type or revocation, e.g. to revoke it.
+func ParseWalletJWTClaims(tokenString string) (*WalletClaims, error) {
+	return parseWalletJWT(tokenString)
+}
+
+// InspectWalletJWT verifies the signature of a token but not its exp/nbf/iat,
+// so an expired token can still be described to its holder.
+func InspectWalletJWT(tokenString string) (*WalletClaims, error) {
+	return parseWalletJWT(tokenString, jwt.WithoutClaimsValidation())
+}
+
+// parseWalletJWT verifies the signature with the configured algorithm; HS256
+// also tries the fallback secrets.
+func parseWalletJWT(tokenString string, opts ...jwt.ParserOption) (*WalletClaims, error) {
+	claims, _, err := parseWalletJWTSecret(tokenString, opts...)
+	return claims, err
+}
+
+// parseWalletJWTSecret is parseWalletJWT that also reports whether the token
+// was signed with a fallback secret rather than auth.jwt_secret.
+func parseWalletJWTSecret(tokenString string, opts ...jwt.ParserOption) (*WalletClaims, bool, error) {
+	// tokens lacking a configured iss or aud are rejected like mismatched ones
+	if config.WalletJWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(config.WalletJWTIssuer))
+	}
+	if config.WalletJWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(config.WalletJWTAudience))
+	}
+	var claims *WalletClaims
+	var err error
+	secretIndex := 0
+	if isWalletJWTRS256() {
+		claims, err = verifyWithRSAPublicKey(tokenString, GetWalletJWTPublicKey(), opts...)
+	} else {
+		claims, secretIndex, err = verifyWithSecrets(tokenString, append([]string{config.JWTSecret}, config.JWTFallbackSecrets...), opts...)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return claims, secretIndex > 0, nil
+}
+
+// walletJWTNotBefore returns the nbf for newly issued tokens, shifted by the configured offset.
+func walletJWTNotBefore() time.Time {
+	return time.Now().Add(time.Duration(config.WalletJWTNotBeforeSeconds) * time.Second)
+}
+
+// walletJWTLeeway is the clock skew tolerated when validating exp/nbf/iat.
+func walletJWTLeeway() time.Duration {
+	seconds := config.WalletJWTNotBeforeSeconds
+	if seconds < 0 {
+		seconds = -seconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// verifyWithSecrets tries multiple secrets in order and returns on first
+// success, along with the index of the secret that verified the token.
+func verifyWithSecrets(tokenString string, secrets []string, opts ...jwt.ParserOption) (*WalletClaims, int, error) {
 	if len(secrets) == 0 {
-		return nil, errors.New("auth.jwt_secret not configured")
+		return nil, 0, errors.New("auth.jwt_secret not configured")
 	}
 	var lastErr error
-	for _, sec := range secrets {
+	for i, sec := range secrets {
 		secBytes := []byte(sec)
 		if len(secBytes) == 0 {
 			continue
@@ -103,18 +254,18 @@ func verifyWithSecrets(tokenString string, secrets []string) (*WalletClaims, err
 				return nil, errors.New("unexpected signing method")
 			}
 			return secBytes, nil
-		})
+		}, append([]jwt.ParserOption{jwt.WithLeeway(walletJWTLeeway())}, opts...)...)
 		if err != nil {
 			lastErr = err
 			continue
 		}
 		if claims, ok := parsed.Claims.(*WalletClaims); ok && parsed.Valid {
-			return claims, nil
+			return claims, i, nil
 		}
 		lastErr = errors.New("invalid token")
 	}
 	if lastErr == nil {
 		lastErr = errors.New("invalid token")
 	}
-	return nil, lastErr
+	return nil, 0, lastErr
 }
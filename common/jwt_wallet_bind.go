@@ -0,0 +1,69 @@
+package common
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/yeying-community/router/common/random"
+)
+
+// WalletBindTokenTTL is how long a wallet-first signup has between proving
+// the wallet and choosing a username and password.
+const WalletBindTokenTTL = 10 * time.Minute
+
+const walletBindTokenType = "wallet_bind"
+
+// GenerateWalletBindJWT issues a binding token for a verified wallet that has
+// no account yet. It names no user, so it cannot be used as an access token,
+// and it is not recorded in the WalletTokenStore.
+func GenerateWalletBindJWT(walletAddress, walletType, email string) (token string, expiresAt time.Time, err error) {
+	expiresAt = time.Now().Add(WalletBindTokenTTL)
+	claims := WalletClaims{
+		WalletAddress: walletAddress,
+		WalletType:    walletType,
+		Email:         email,
+		TokenType:     walletBindTokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(walletJWTNotBefore()),
+			Subject:   walletAddress,
+			ID:        random.GetUUID(),
+		},
+	}
+	token, err = signWalletClaims(claims)
+	return
+}
+
+// VerifyWalletBindJWT validates a binding token and returns its claims.
+func VerifyWalletBindJWT(tokenString string) (*WalletClaims, error) {
+	claims, err := parseWalletJWT(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != walletBindTokenType || claims.WalletAddress == "" {
+		return nil, errors.New("token is not a binding token")
+	}
+	if IsWalletJWTRevoked(claims) {
+		return nil, errors.New("binding token already used")
+	}
+	return claims, nil
+}
+
+// ClaimWalletBindJWT marks a verified binding token as used. Only the first
+// caller succeeds, so one token creates at most one account.
+func ClaimWalletBindJWT(claims *WalletClaims) error {
+	if claims == nil || claims.ID == "" {
+		return errors.New("binding token has no jti")
+	}
+	ok, err := getWalletJWTRevocationStore().SetIfAbsent(jwtRevocationJTIPrefix+claims.ID, 1, WalletBindTokenTTL)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("binding token already used")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestWalletBindJWT(t *testing.T) {
+	prevSecret, prevAlgorithm := config.JWTSecret, config.WalletJWTAlgorithm
+	defer func() { config.JWTSecret, config.WalletJWTAlgorithm = prevSecret, prevAlgorithm }()
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = WalletJWTAlgorithmHS256
+
+	token, _, err := GenerateWalletBindJWT("0xabc", WalletTypeEthereum, "a@example.com")
+	if err != nil {
+		t.Fatalf("GenerateWalletBindJWT error: %v", err)
+	}
+	if _, err := VerifyWalletJWT(token); err == nil {
+		t.Fatalf("binding token accepted as an access token")
+	}
+	claims, err := VerifyWalletBindJWT(token)
+	if err != nil {
+		t.Fatalf("VerifyWalletBindJWT error: %v", err)
+	}
+	if claims.WalletAddress != "0xabc" || claims.WalletType != WalletTypeEthereum || claims.Email != "a@example.com" || claims.UserID != "" {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	access, _, _ := GenerateWalletJWT("user-1", "0xabc")
+	if _, err := VerifyWalletBindJWT(access); err == nil {
+		t.Fatalf("access token accepted as a binding token")
+	}
+
+	if err := ClaimWalletBindJWT(claims); err != nil {
+		t.Fatalf("first ClaimWalletBindJWT error: %v", err)
+	}
+	if err := ClaimWalletBindJWT(claims); err == nil {
+		t.Fatalf("binding token claimed twice")
+	}
+	if _, err := VerifyWalletBindJWT(token); err == nil {
+		t.Fatalf("used binding token still verifies")
+	}
+}
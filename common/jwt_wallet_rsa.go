@@ -0,0 +1,115 @@
+package common
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+const (
+	WalletJWTAlgorithmHS256 = "HS256"
+	WalletJWTAlgorithmRS256 = "RS256"
+)
+
+var walletJWTRSAKeys struct {
+	sync.RWMutex
+	private *rsa.PrivateKey
+	public  *rsa.PublicKey
+}
+
+func isWalletJWTRS256() bool {
+	return strings.EqualFold(config.WalletJWTAlgorithm, WalletJWTAlgorithmRS256)
+}
+
+// LoadWalletJWTRSAKeys reads the PEM files named by auth.jwt_rsa_private_key_file
+// and auth.jwt_rsa_public_key_file. A verify-only instance may omit the private
+// key; without a public key file it is derived from the private key.
+func LoadWalletJWTRSAKeys() error {
+	var privateKey *rsa.PrivateKey
+	var publicKey *rsa.PublicKey
+	if path := strings.TrimSpace(config.WalletJWTRSAPrivateKeyFile); path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read jwt rsa private key: %w", err)
+		}
+		privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(raw)
+		if err != nil {
+			return fmt.Errorf("parse jwt rsa private key: %w", err)
+		}
+		publicKey = &privateKey.PublicKey
+	}
+	if path := strings.TrimSpace(config.WalletJWTRSAPublicKeyFile); path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read jwt rsa public key: %w", err)
+		}
+		publicKey, err = jwt.ParseRSAPublicKeyFromPEM(raw)
+		if err != nil {
+			return fmt.Errorf("parse jwt rsa public key: %w", err)
+		}
+	}
+	if publicKey == nil {
+		return errors.New("RS256 needs auth.jwt_rsa_public_key_file or auth.jwt_rsa_private_key_file")
+	}
+	if privateKey != nil && !privateKey.PublicKey.Equal(publicKey) {
+		return errors.New("jwt rsa public key does not match the private key")
+	}
+	walletJWTRSAKeys.Lock()
+	defer walletJWTRSAKeys.Unlock()
+	walletJWTRSAKeys.private = privateKey
+	walletJWTRSAKeys.public = publicKey
+	return nil
+}
+
+// GetWalletJWTPublicKey returns the key that verifies RS256 wallet tokens, for
+// services that check tokens without holding the signing key. It is nil unless
+// auth.jwt_algorithm is RS256.
+func GetWalletJWTPublicKey() *rsa.PublicKey {
+	if !isWalletJWTRS256() {
+		return nil
+	}
+	walletJWTRSAKeys.RLock()
+	defer walletJWTRSAKeys.RUnlock()
+	return walletJWTRSAKeys.public
+}
+
+func getWalletJWTPrivateKey() *rsa.PrivateKey {
+	walletJWTRSAKeys.RLock()
+	defer walletJWTRSAKeys.RUnlock()
+	return walletJWTRSAKeys.private
+}
+
+// WalletJWTSigningConfigured reports whether wallet tokens can be issued.
+func WalletJWTSigningConfigured() bool {
+	if isWalletJWTRS256() {
+		return getWalletJWTPrivateKey() != nil
+	}
+	return strings.TrimSpace(config.JWTSecret) != ""
+}
+
+func verifyWithRSAPublicKey(tokenString string, publicKey *rsa.PublicKey, opts ...jwt.ParserOption) (*WalletClaims, error) {
+	if publicKey == nil {
+		return nil, errors.New("auth.jwt_rsa_public_key_file not configured")
+	}
+	parsed, err := jwt.ParseWithClaims(tokenString, &WalletClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return publicKey, nil
+	}, append([]jwt.ParserOption{jwt.WithLeeway(walletJWTLeeway())}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := parsed.Claims.(*WalletClaims)
+	if !ok || !parsed.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
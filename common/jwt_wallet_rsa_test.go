@@ -0,0 +1,86 @@
+package common
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func writeTestRSAKeys(t *testing.T, key *rsa.PrivateKey) (privatePath, publicPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	privatePath = filepath.Join(dir, "jwt.key")
+	publicPath = filepath.Join(dir, "jwt.pub")
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	if err := os.WriteFile(privatePath, privatePEM, 0o600); err != nil {
+		t.Fatalf("write private key: %v", err)
+	}
+	if err := os.WriteFile(publicPath, publicPEM, 0o644); err != nil {
+		t.Fatalf("write public key: %v", err)
+	}
+	return privatePath, publicPath
+}
+
+func TestWalletJWT_RS256RoundTrip(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	privatePath, publicPath := writeTestRSAKeys(t, key)
+
+	prevAlgorithm, prevPrivate, prevPublic, prevSecret := config.WalletJWTAlgorithm, config.WalletJWTRSAPrivateKeyFile, config.WalletJWTRSAPublicKeyFile, config.JWTSecret
+	defer func() {
+		config.WalletJWTAlgorithm, config.WalletJWTRSAPrivateKeyFile, config.WalletJWTRSAPublicKeyFile, config.JWTSecret = prevAlgorithm, prevPrivate, prevPublic, prevSecret
+	}()
+
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = WalletJWTAlgorithmHS256
+	hsToken, _, err := GenerateWalletJWT("user-1", "0xabc")
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT(HS256) error: %v", err)
+	}
+
+	config.WalletJWTAlgorithm = WalletJWTAlgorithmRS256
+	config.WalletJWTRSAPrivateKeyFile = privatePath
+	config.WalletJWTRSAPublicKeyFile = publicPath
+	if err := LoadWalletJWTRSAKeys(); err != nil {
+		t.Fatalf("LoadWalletJWTRSAKeys() error: %v", err)
+	}
+	if pub := GetWalletJWTPublicKey(); pub == nil || !pub.Equal(&key.PublicKey) {
+		t.Fatalf("GetWalletJWTPublicKey() did not return the configured key")
+	}
+	token, _, err := GenerateWalletJWT("user-1", "0xabc")
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT(RS256) error: %v", err)
+	}
+	claims, err := VerifyWalletJWT(token)
+	if err != nil || claims.UserID != "user-1" {
+		t.Fatalf("VerifyWalletJWT(RS256) = %+v, %v", claims, err)
+	}
+	if _, err := VerifyWalletJWT(hsToken); err == nil {
+		t.Fatalf("VerifyWalletJWT accepted an HS256 token in RS256 mode")
+	}
+
+	// a verify-only instance holds just the public key
+	config.WalletJWTRSAPrivateKeyFile = ""
+	if err := LoadWalletJWTRSAKeys(); err != nil {
+		t.Fatalf("LoadWalletJWTRSAKeys(public only) error: %v", err)
+	}
+	if _, err := VerifyWalletJWT(token); err != nil {
+		t.Fatalf("VerifyWalletJWT(public only) error: %v", err)
+	}
+	if _, _, err := GenerateWalletJWT("user-1", "0xabc"); err == nil {
+		t.Fatalf("GenerateWalletJWT succeeded without a private key")
+	}
+}
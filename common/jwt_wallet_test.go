@@ -0,0 +1,185 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestGenerateWebAuthnJWT(t *testing.T) {
+	prevSecret, prevAlgorithm := config.JWTSecret, config.WalletJWTAlgorithm
+	defer func() { config.JWTSecret, config.WalletJWTAlgorithm = prevSecret, prevAlgorithm }()
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = WalletJWTAlgorithmHS256
+
+	token, _, err := GenerateWebAuthnJWT("user-1", "Y3JlZC0x")
+	if err != nil {
+		t.Fatalf("GenerateWebAuthnJWT error: %v", err)
+	}
+	claims, err := VerifyWalletJWT(token)
+	if err != nil {
+		t.Fatalf("VerifyWalletJWT error: %v", err)
+	}
+	if claims.UserID != "user-1" || claims.WebAuthnCredentialID != "Y3JlZC0x" || claims.WalletAddress != "" || claims.Subject != "user-1" {
+		t.Fatalf("claims = %+v", claims)
+	}
+
+	wallet, _, _ := GenerateWalletJWT("user-1", "0xabc")
+	if claims, _ := VerifyWalletJWT(wallet); claims == nil || claims.WebAuthnCredentialID != "" {
+		t.Fatalf("wallet token carries a credential id: %+v", claims)
+	}
+}
+
+func TestWalletJWTIssuerAudience(t *testing.T) {
+	prevSecret, prevAlgorithm := config.JWTSecret, config.WalletJWTAlgorithm
+	prevIssuer, prevAudience := config.WalletJWTIssuer, config.WalletJWTAudience
+	defer func() {
+		config.JWTSecret, config.WalletJWTAlgorithm = prevSecret, prevAlgorithm
+		config.WalletJWTIssuer, config.WalletJWTAudience = prevIssuer, prevAudience
+	}()
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = WalletJWTAlgorithmHS256
+
+	issue := func(issuer, audience string) string {
+		config.WalletJWTIssuer, config.WalletJWTAudience = issuer, audience
+		token, _, err := GenerateWalletJWT("user-1", "0xabc")
+		if err != nil {
+			t.Fatalf("GenerateWalletJWT error: %v", err)
+		}
+		return token
+	}
+	legacy := issue("", "")
+	own := issue("router.example.com", "router")
+	otherIssuer := issue("other.example.com", "router")
+	otherAudience := issue("router.example.com", "other")
+	issuerOnly := issue("router.example.com", "")
+
+	tests := []struct {
+		name   string
+		token  string
+		wantOK bool
+	}{
+		{"own token", own, true},
+		{"token without iss and aud", legacy, false},
+		{"token without aud", issuerOnly, false},
+		{"other issuer", otherIssuer, false},
+		{"other audience", otherAudience, false},
+	}
+	config.WalletJWTIssuer, config.WalletJWTAudience = "router.example.com", "router"
+	for _, tt := range tests {
+		claims, err := VerifyWalletJWT(tt.token)
+		if (err == nil) != tt.wantOK {
+			t.Fatalf("%s: VerifyWalletJWT err = %v, want ok=%t", tt.name, err, tt.wantOK)
+		}
+		if tt.wantOK && tt.token == own && (claims.Issuer != "router.example.com" || len(claims.Audience) != 1 || claims.Audience[0] != "router") {
+			t.Fatalf("%s: claims iss=%q aud=%v", tt.name, claims.Issuer, claims.Audience)
+		}
+	}
+}
+
+func TestVerifyWalletJWTFallbackSecret(t *testing.T) {
+	prevSecret, prevFallback, prevAlgorithm := config.JWTSecret, config.JWTFallbackSecrets, config.WalletJWTAlgorithm
+	defer func() {
+		config.JWTSecret, config.JWTFallbackSecrets, config.WalletJWTAlgorithm = prevSecret, prevFallback, prevAlgorithm
+	}()
+	config.WalletJWTAlgorithm = WalletJWTAlgorithmHS256
+	config.JWTSecret = "old-secret"
+	config.JWTFallbackSecrets = nil
+	old, _, err := GenerateWalletJWT("user-1", "0xabc")
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT error: %v", err)
+	}
+
+	// rotate: the old secret becomes a fallback
+	config.JWTSecret = "new-secret"
+	config.JWTFallbackSecrets = []string{"old-secret"}
+	claims, renewed, err := VerifyWalletJWTWithRenewal(old)
+	if err != nil {
+		t.Fatalf("token signed with the fallback secret rejected: %v", err)
+	}
+	if claims.UserID != "user-1" || renewed == "" || renewed == old {
+		t.Fatalf("claims = %+v renewed = %q, want user-1 and a re-signed token", claims, renewed)
+	}
+	if _, err := VerifyWalletJWT(old); err != nil {
+		t.Fatalf("VerifyWalletJWT with fallback secret: %v", err)
+	}
+
+	config.JWTFallbackSecrets = nil
+	if _, err := VerifyWalletJWT(old); err == nil {
+		t.Fatalf("old token verified after the fallback secret was dropped")
+	}
+	again, next, err := VerifyWalletJWTWithRenewal(renewed)
+	if err != nil || next != "" {
+		t.Fatalf("renewed token: err=%v renewed=%q, want it valid under the primary secret", err, next)
+	}
+	if again.ID != claims.ID || !again.ExpiresAt.Equal(claims.ExpiresAt.Time) {
+		t.Fatalf("renewed claims %+v differ from %+v", again, claims)
+	}
+}
+
+func TestWalletJWTNotBeforeOffset(t *testing.T) {
+	prevSecret, prevAlgorithm, prevOffset := config.JWTSecret, config.WalletJWTAlgorithm, config.WalletJWTNotBeforeSeconds
+	defer func() {
+		config.JWTSecret, config.WalletJWTAlgorithm, config.WalletJWTNotBeforeSeconds = prevSecret, prevAlgorithm, prevOffset
+	}()
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = WalletJWTAlgorithmHS256
+	config.WalletJWTNotBeforeSeconds = -30
+
+	token, _, err := GenerateWalletJWT("user-1", "0xabc")
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT error: %v", err)
+	}
+	claims, err := VerifyWalletJWT(token)
+	if err != nil {
+		t.Fatalf("VerifyWalletJWT error: %v", err)
+	}
+	if backdated := claims.IssuedAt.Sub(claims.NotBefore.Time); backdated != 30*time.Second {
+		t.Fatalf("nbf is %s before iat, want 30s", backdated)
+	}
+}
+
+func TestWalletJWTLeeway(t *testing.T) {
+	prevSecret, prevAlgorithm, prevOffset := config.JWTSecret, config.WalletJWTAlgorithm, config.WalletJWTNotBeforeSeconds
+	defer func() {
+		config.JWTSecret, config.WalletJWTAlgorithm, config.WalletJWTNotBeforeSeconds = prevSecret, prevAlgorithm, prevOffset
+	}()
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = WalletJWTAlgorithmHS256
+
+	// tokens from an issuer whose clock is 20 seconds off in either direction
+	sign := func(notBefore, expiresAt time.Time) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, WalletClaims{
+			UserID:        "user-1",
+			WalletAddress: "0xabc",
+			TokenType:     "access",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(expiresAt),
+				NotBefore: jwt.NewNumericDate(notBefore),
+				ID:        "leeway-" + notBefore.String(),
+			},
+		}).SignedString([]byte(config.JWTSecret))
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return token
+	}
+	now := time.Now()
+	tokens := map[string]string{
+		"not yet valid": sign(now.Add(20*time.Second), now.Add(time.Hour)),
+		"just expired":  sign(now.Add(-time.Hour), now.Add(-20*time.Second)),
+	}
+	for name, token := range tokens {
+		config.WalletJWTNotBeforeSeconds = 0
+		if _, err := VerifyWalletJWT(token); err == nil {
+			t.Fatalf("%s: verified without leeway", name)
+		}
+		config.WalletJWTNotBeforeSeconds = -30
+		if _, err := VerifyWalletJWT(token); err != nil {
+			t.Fatalf("%s: VerifyWalletJWT error with 30s leeway: %v", name, err)
+		}
+	}
+}
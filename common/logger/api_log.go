@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// apiLogFlushInterval bounds how long a buffered access log line can wait
+// before it reaches api.log.
+const apiLogFlushInterval = time.Second
+
+// bufferedApiLog batches api.log writes; the access log is written on every
+// request, so it is not worth a syscall per line.
+type bufferedApiLog struct {
+	mu  sync.Mutex
+	buf *bufio.Writer
+	out *lumberjack.Logger
+}
+
+var (
+	apiLogMutex sync.Mutex
+	apiLog      *bufferedApiLog
+)
+
+// SetupApiLogFile sends api.log to logDir/api.log, rotated at maxSizeMB and
+// kept for maxAgeDays, replacing the writer SetupApiLogger created. Lines are
+// buffered and flushed every second and by FlushApiLog.
+func SetupApiLogFile(logDir string, maxSizeMB int, maxAgeDays int) {
+	// the default writer must not be installed after this one
+	SetupLogger()
+	if logDir == "" {
+		logDir = "./logs"
+	}
+	_ = os.MkdirAll(logDir, 0755)
+	if maxSizeMB <= 0 {
+		maxSizeMB = 100
+	}
+	if maxAgeDays < 0 {
+		maxAgeDays = 14
+	}
+	maxBackups := config.LogRotateMaxBackups
+	if maxBackups < 0 {
+		maxBackups = 10
+	}
+	out := &lumberjack.Logger{
+		Filename:   filepath.Join(logDir, "api.log"),
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAgeDays,
+		Compress:   config.LogRotateCompress,
+	}
+	next := &bufferedApiLog{buf: bufio.NewWriterSize(out, 64<<10), out: out}
+
+	apiLogMutex.Lock()
+	previous := apiLog
+	apiLog = next
+	apiWriter = next
+	apiLogMutex.Unlock()
+	if previous != nil {
+		previous.close()
+	} else {
+		go flushApiLogPeriodically()
+	}
+}
+
+func (l *bufferedApiLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *bufferedApiLog) flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Flush()
+}
+
+func (l *bufferedApiLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.buf.Flush()
+	_ = l.out.Close()
+}
+
+func flushApiLogPeriodically() {
+	ticker := time.NewTicker(apiLogFlushInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		FlushApiLog()
+	}
+}
+
+// WriteApiLog appends one line to api.log and queues it for the remote
+// endpoint set by SetupApiLogForwarding.
+func WriteApiLog(line []byte) {
+	SetupLogger()
+	apiLogMutex.Lock()
+	writer := apiWriter
+	apiLogMutex.Unlock()
+	if writer == nil {
+		return
+	}
+	if _, err := writer.Write(line); err != nil {
+		SysErrorf("write api log failed: %v", err)
+	}
+	forwardApiLog(line)
+}
+
+// FlushApiLog writes buffered api.log lines to disk; call it before the
+// process exits.
+func FlushApiLog() {
+	apiLogMutex.Lock()
+	current := apiLog
+	apiLogMutex.Unlock()
+	if current == nil {
+		return
+	}
+	if err := current.flush(); err != nil {
+		SysErrorf("flush api log failed: %v", err)
+	}
+}
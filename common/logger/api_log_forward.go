@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	ApiLogForwardSyslog = "syslog"
+	ApiLogForwardGELF   = "gelf"
+)
+
+// apiLogForwardQueue is how many lines may wait for the remote endpoint; lines
+// arriving while it is full are dropped rather than slowing the request down.
+const apiLogForwardQueue = 4096
+
+// apiLogForwarder sends api.log lines to a remote endpoint from its own
+// goroutine.
+type apiLogForwarder struct {
+	lines   chan []byte
+	send    func(line []byte) error
+	close   func() error
+	dropped atomic.Int64
+	done    chan struct{}
+}
+
+// senders hold the read lock, so a forwarder is unpublished under the write
+// lock before its channel is closed
+var (
+	apiLogForwardMutex sync.RWMutex
+	apiLogForward      *apiLogForwarder
+)
+
+// SetupApiLogForwarding forwards every api.log line to addr over UDP, as a
+// syslog message (protocol "syslog") or a GELF 1.1 message ("gelf"). It
+// replaces any previous forwarder.
+func SetupApiLogForwarding(protocol string, addr string) error {
+	var (
+		send      func(line []byte) error
+		closeConn func() error
+	)
+	switch protocol {
+	case "", ApiLogForwardSyslog:
+		writer, err := syslog.Dial("udp", addr, syslog.LOG_INFO|syslog.LOG_LOCAL0, "router")
+		if err != nil {
+			return err
+		}
+		send = func(line []byte) error {
+			return writer.Info(string(bytes.TrimRight(line, "\n")))
+		}
+		closeConn = writer.Close
+	case ApiLogForwardGELF:
+		conn, err := net.Dial("udp", addr)
+		if err != nil {
+			return err
+		}
+		host, _ := os.Hostname()
+		send = func(line []byte) error {
+			payload, err := gelfMessage(host, line, time.Now())
+			if err != nil {
+				return err
+			}
+			_, err = conn.Write(payload)
+			return err
+		}
+		closeConn = conn.Close
+	default:
+		return fmt.Errorf("unknown api log forwarding protocol: %s", protocol)
+	}
+	next := &apiLogForwarder{
+		lines: make(chan []byte, apiLogForwardQueue),
+		send:  send,
+		close: closeConn,
+		done:  make(chan struct{}),
+	}
+	go next.run()
+
+	apiLogForwardMutex.Lock()
+	previous := apiLogForward
+	apiLogForward = next
+	apiLogForwardMutex.Unlock()
+	if previous != nil {
+		previous.stop()
+	}
+	return nil
+}
+
+// StopApiLogForwarding sends the queued lines and closes the connection.
+func StopApiLogForwarding() {
+	apiLogForwardMutex.Lock()
+	current := apiLogForward
+	apiLogForward = nil
+	apiLogForwardMutex.Unlock()
+	if current != nil {
+		current.stop()
+	}
+}
+
+// forwardApiLog queues line without blocking.
+func forwardApiLog(line []byte) {
+	apiLogForwardMutex.RLock()
+	defer apiLogForwardMutex.RUnlock()
+	current := apiLogForward
+	if current == nil {
+		return
+	}
+	// the caller may reuse line once WriteApiLog returns
+	queued := append([]byte(nil), line...)
+	select {
+	case current.lines <- queued:
+	default:
+		if current.dropped.Add(1)%1000 == 1 {
+			SysErrorf("api log forwarding queue full, %d lines dropped", current.dropped.Load())
+		}
+	}
+}
+
+func (f *apiLogForwarder) run() {
+	defer close(f.done)
+	failures := 0
+	for line := range f.lines {
+		if err := f.send(line); err != nil {
+			// UDP errors repeat while the endpoint is down; log a sample
+			if failures%1000 == 0 {
+				SysErrorf("forward api log failed: %v", err)
+			}
+			failures++
+		}
+	}
+}
+
+func (f *apiLogForwarder) stop() {
+	close(f.lines)
+	<-f.done
+	if err := f.close(); err != nil {
+		SysErrorf("close api log forwarding failed: %v", err)
+	}
+}
+
+// gelfMessage wraps an api.log line in a GELF 1.1 message. The line's JSON
+// fields become additional fields so they stay searchable.
+func gelfMessage(host string, line []byte, now time.Time) ([]byte, error) {
+	line = bytes.TrimRight(line, "\n")
+	message := map[string]any{
+		"version":       "1.1",
+		"host":          host,
+		"short_message": string(line),
+		"timestamp":     float64(now.UnixMilli()) / 1000,
+		"level":         6,
+	}
+	var fields map[string]any
+	if json.Unmarshal(line, &fields) == nil {
+		for key, value := range fields {
+			if key == "id" {
+				// "_id" is reserved by GELF
+				key = "entry_id"
+			}
+			message["_"+key] = value
+		}
+	}
+	return json.Marshal(message)
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApiLogForwarding(t *testing.T) {
+	dir := t.TempDir()
+	LogDir = dir
+	SetupApiLogFile(dir, 1, 1)
+	defer StopApiLogForwarding()
+
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp: %v", err)
+	}
+	defer listener.Close()
+	receive := func() string {
+		buf := make([]byte, 8192)
+		_ = listener.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := listener.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("no packet forwarded: %v", err)
+		}
+		return string(buf[:n])
+	}
+	line := `{"path":"/api/status","status":200}` + "\n"
+
+	if err := SetupApiLogForwarding(ApiLogForwardSyslog, listener.LocalAddr().String()); err != nil {
+		t.Fatalf("SetupApiLogForwarding(syslog) error: %v", err)
+	}
+	WriteApiLog([]byte(line))
+	if packet := receive(); !strings.Contains(packet, "router") || !strings.HasSuffix(strings.TrimSpace(packet), strings.TrimSpace(line)) {
+		t.Fatalf("syslog packet = %q", packet)
+	}
+
+	if err := SetupApiLogForwarding(ApiLogForwardGELF, listener.LocalAddr().String()); err != nil {
+		t.Fatalf("SetupApiLogForwarding(gelf) error: %v", err)
+	}
+	WriteApiLog([]byte(line))
+	var message map[string]any
+	if err := json.Unmarshal([]byte(receive()), &message); err != nil {
+		t.Fatalf("gelf packet is not JSON: %v", err)
+	}
+	if message["version"] != "1.1" || message["short_message"] != strings.TrimSpace(line) || message["_path"] != "/api/status" {
+		t.Fatalf("gelf message = %v", message)
+	}
+
+	if err := SetupApiLogForwarding("kafka", listener.LocalAddr().String()); err == nil {
+		t.Fatalf("unknown protocol accepted")
+	}
+}
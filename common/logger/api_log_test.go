@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApiLogFileBuffersUntilFlush(t *testing.T) {
+	dir := t.TempDir()
+	LogDir = dir
+	SetupApiLogFile(dir, 1, 1)
+
+	WriteApiLog([]byte(`{"path":"/api/status"}` + "\n"))
+	path := filepath.Join(dir, "api.log")
+	if raw, _ := os.ReadFile(path); len(raw) != 0 {
+		t.Fatalf("api.log written before flush: %q", raw)
+	}
+	FlushApiLog()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read api.log: %v", err)
+	}
+	if string(raw) != `{"path":"/api/status"}`+"\n" {
+		t.Fatalf("api.log = %q", raw)
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/common/helper"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
@@ -77,9 +78,9 @@ func SetupLogger() {
 
 		SetupApiLogger()
 		SetupRelayLogger()
-		if apiWriter != nil {
-			gin.DefaultWriter = io.MultiWriter(os.Stdout, apiWriter)
-		}
+		// api.log holds the structured access log written by the ApiLogger
+		// middleware, so gin's text access log only goes to stdout
+		gin.DefaultWriter = os.Stdout
 		ginErrorWriter := routerErrorWriter
 		if ginErrorWriter != nil && errorWriter != nil {
 			ginErrorWriter = io.MultiWriter(ginErrorWriter, errorWriter)
@@ -132,12 +133,30 @@ func SysWarnf(format string, a ...any) {
 	logHelper(nil, loggerWarn, fmt.Sprintf(format, a...))
 }
 
+// sysErrorHook, when set, is called with every SysError/SysErrorf message so
+// critical errors can be forwarded to external alerting.
+var sysErrorHook func(msg string)
+
+// SetSysErrorHook registers hook for SysError messages. Call it during startup.
+func SetSysErrorHook(hook func(msg string)) {
+	sysErrorHook = hook
+}
+
 func SysError(s string) {
 	logHelper(nil, loggerError, s)
+	notifySysErrorHook(s)
 }
 
 func SysErrorf(format string, a ...any) {
-	logHelper(nil, loggerError, fmt.Sprintf(format, a...))
+	s := fmt.Sprintf(format, a...)
+	logHelper(nil, loggerError, s)
+	notifySysErrorHook(s)
+}
+
+func notifySysErrorHook(s string) {
+	if sysErrorHook != nil {
+		sysErrorHook(s)
+	}
 }
 
 func Debug(ctx context.Context, msg string) {
@@ -186,13 +205,26 @@ func FatalLogf(format string, a ...any) {
 	logHelper(nil, loggerFatal, fmt.Sprintf(format, a...))
 }
 
-// Loginf writes detailed login/auth related logs to router.log.
+// Loginf writes detailed login/auth related logs to router.log, with the id
+// of the span in ctx when the request is traced.
 func Loginf(ctx context.Context, format string, a ...any) {
-	logHelper(ctx, loggerINFO, "[login] "+fmt.Sprintf(format, a...))
+	logHelper(ctx, loggerINFO, "[login] "+fmt.Sprintf(format, a...)+spanLogID(ctx))
 }
 
 func LoginErrorf(ctx context.Context, format string, a ...any) {
-	logHelper(ctx, loggerError, "[login] "+fmt.Sprintf(format, a...))
+	logHelper(ctx, loggerError, "[login] "+fmt.Sprintf(format, a...)+spanLogID(ctx))
+}
+
+// spanLogID renders the OpenTelemetry span carried by ctx as a log suffix.
+func spanLogID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ""
+	}
+	return " span=" + spanContext.SpanID().String()
 }
 
 // ApiLogf writes per-request api logs to api.log
@@ -220,6 +252,23 @@ func formatLogLine(now time.Time, level loggerLevel, traceID string, lineInfo st
 	return fmt.Sprintf("%v [%s]%s%s %s%s \n", now.Format("2006/01/02 - 15:04:05"), level, traceID, lineInfo, funcName, msg)
 }
 
+// contextLogIDs renders the trace id and, when it differs, the request id
+// carried by ctx as the id column of a log line.
+func contextLogIDs(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ids := ""
+	traceID := helper.GetTraceID(ctx)
+	if traceID != "" {
+		ids = " " + traceID
+	}
+	if requestID := helper.GetRequestID(ctx); requestID != "" && requestID != traceID {
+		ids += " req=" + requestID
+	}
+	return ids
+}
+
 func logHelper(ctx context.Context, level loggerLevel, msg string) {
 	SetupLogger()
 	writer := routerErrorWriter
@@ -232,13 +281,7 @@ func logHelper(ctx context.Context, level loggerLevel, msg string) {
 			writer = gin.DefaultWriter
 		}
 	}
-	var traceID string
-	if ctx != nil {
-		rawTraceID := helper.GetTraceID(ctx)
-		if rawTraceID != "" {
-			traceID = fmt.Sprintf(" %s", rawTraceID)
-		}
-	}
+	traceID := contextLogIDs(ctx)
 	lineInfo, funcName := getLineInfo()
 	now := time.Now()
 	line := formatLogLine(now, level, traceID, lineInfo, funcName, msg)
@@ -259,13 +302,7 @@ func apiLogHelper(ctx context.Context, level loggerLevel, msg string) {
 		logHelper(ctx, level, "[api] "+msg)
 		return
 	}
-	var traceID string
-	if ctx != nil {
-		rawTraceID := helper.GetTraceID(ctx)
-		if rawTraceID != "" {
-			traceID = fmt.Sprintf(" %s", rawTraceID)
-		}
-	}
+	traceID := contextLogIDs(ctx)
 	lineInfo, funcName := getLineInfo()
 	now := time.Now()
 	line := formatLogLine(now, level, traceID, lineInfo, funcName, "[api] "+msg)
@@ -282,13 +319,7 @@ func relayLogHelper(ctx context.Context, level loggerLevel, msg string) {
 		logHelper(ctx, level, "[relay] "+msg)
 		return
 	}
-	var traceID string
-	if ctx != nil {
-		rawTraceID := helper.GetTraceID(ctx)
-		if rawTraceID != "" {
-			traceID = fmt.Sprintf(" %s", rawTraceID)
-		}
-	}
+	traceID := contextLogIDs(ctx)
 	lineInfo, funcName := getLineInfo()
 	now := time.Now()
 	line := formatLogLine(now, level, traceID, lineInfo, funcName, "[relay] "+msg)
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpanLogID(t *testing.T) {
+	if got := spanLogID(nil); got != "" {
+		t.Fatalf("spanLogID(nil) = %q, want empty", got)
+	}
+	if got := spanLogID(context.Background()); got != "" {
+		t.Fatalf("spanLogID(untraced) = %q, want empty", got)
+	}
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	if got := spanLogID(ctx); got != " span=00f067aa0ba902b7" {
+		t.Fatalf("spanLogID(traced) = %q", got)
+	}
+}
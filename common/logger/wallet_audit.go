@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/helper"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const walletAuditFileName = "wallet_audit.jsonl"
+
+// WalletAuditEvent is one step of wallet authentication, or an admin role
+// change, written as a JSON line to wallet_audit.jsonl for auditors.
+type WalletAuditEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Event     string    `json:"event"`
+	Address   string    `json:"address,omitempty"`
+	UserID    string    `json:"user_id,omitempty"`
+	ChainID   string    `json:"chain_id,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason,omitempty"`
+	TraceID   string    `json:"trace_id,omitempty"`
+	// role_change events record the admin who changed UserID's role
+	OperatorID string `json:"operator_id,omitempty"`
+	RoleBefore int    `json:"role_before,omitempty"`
+	RoleAfter  int    `json:"role_after,omitempty"`
+}
+
+// walletAuditWriter rotates on the first write of a new UTC day, and through
+// lumberjack once the file exceeds config.WalletAuditLogMaxMB.
+type walletAuditWriter struct {
+	mu  sync.Mutex
+	out *lumberjack.Logger
+	day string
+}
+
+var (
+	walletAuditOnce sync.Once
+	walletAudit     *walletAuditWriter
+)
+
+func walletAuditDir() string {
+	if LogDir == "" {
+		return "./logs"
+	}
+	return LogDir
+}
+
+func getWalletAuditWriter() *walletAuditWriter {
+	walletAuditOnce.Do(func() {
+		dir := walletAuditDir()
+		_ = os.MkdirAll(dir, 0755)
+		maxSizeMB := config.WalletAuditLogMaxMB
+		if maxSizeMB <= 0 {
+			maxSizeMB = 100
+		}
+		path := filepath.Join(dir, walletAuditFileName)
+		day := ""
+		if info, err := os.Stat(path); err == nil {
+			day = info.ModTime().UTC().Format("2006-01-02")
+		}
+		walletAudit = &walletAuditWriter{
+			out: &lumberjack.Logger{
+				Filename:   path,
+				MaxSize:    maxSizeMB,
+				MaxBackups: config.LogRotateMaxBackups,
+				MaxAge:     config.LogRotateMaxAgeDays,
+				Compress:   config.LogRotateCompress,
+			},
+			day: day,
+		}
+	})
+	return walletAudit
+}
+
+func (w *walletAuditWriter) write(now time.Time, line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	day := now.UTC().Format("2006-01-02")
+	if w.day != "" && w.day != day {
+		if err := w.out.Rotate(); err != nil {
+			return err
+		}
+	}
+	w.day = day
+	_, err := w.out.Write(line)
+	return err
+}
+
+// WriteWalletAudit appends event to wallet_audit.jsonl, filling in the
+// timestamp and trace id when missing. Failures are reported to the system log
+// and never block authentication.
+func WriteWalletAudit(ctx context.Context, event WalletAuditEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	event.Timestamp = event.Timestamp.UTC()
+	if event.TraceID == "" && ctx != nil {
+		event.TraceID = helper.GetTraceID(ctx)
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		SysErrorf("marshal wallet audit event failed: %v", err)
+		return
+	}
+	if err := getWalletAuditWriter().write(event.Timestamp, append(line, '\n')); err != nil {
+		SysErrorf("write wallet audit event failed: %v", err)
+	}
+}
+
+// ReadWalletAuditEvents streams events with from <= timestamp < to, oldest file
+// first, including rotated and compressed backups. A zero from or to leaves
+// that end open. Returning false from fn stops the scan.
+func ReadWalletAuditEvents(from, to time.Time, fn func(WalletAuditEvent) bool) error {
+	return readWalletAuditEvents(walletAuditDir(), from, to, fn)
+}
+
+func readWalletAuditEvents(dir string, from, to time.Time, fn func(WalletAuditEvent) bool) error {
+	files, err := walletAuditFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		keepGoing, err := readWalletAuditFile(path, from, to, fn)
+		if err != nil {
+			return err
+		}
+		if !keepGoing {
+			return nil
+		}
+	}
+	return nil
+}
+
+// walletAuditFiles lists the rotated backups oldest first, then the active
+// file. lumberjack names backups with a sortable timestamp.
+func walletAuditFiles(dir string) ([]string, error) {
+	prefix := strings.TrimSuffix(walletAuditFileName, filepath.Ext(walletAuditFileName))
+	matches, err := filepath.Glob(filepath.Join(dir, prefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+	active := filepath.Join(dir, walletAuditFileName)
+	paths := make([]string, 0, len(matches))
+	hasActive := false
+	for _, path := range matches {
+		if path == active {
+			hasActive = true
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if hasActive {
+		paths = append(paths, active)
+	}
+	return paths, nil
+}
+
+func readWalletAuditFile(path string, from, to time.Time, fn func(WalletAuditEvent) bool) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	var reader io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return false, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event WalletAuditEvent
+		if json.Unmarshal(scanner.Bytes(), &event) != nil {
+			continue
+		}
+		if !from.IsZero() && event.Timestamp.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !event.Timestamp.Before(to) {
+			continue
+		}
+		if !fn(event) {
+			return false, nil
+		}
+	}
+	return true, scanner.Err()
+}
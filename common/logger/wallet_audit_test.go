@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+func TestWalletAuditWriter_DailyRotationAndRangeRead(t *testing.T) {
+	dir := t.TempDir()
+	writer := &walletAuditWriter{out: &lumberjack.Logger{Filename: filepath.Join(dir, walletAuditFileName), MaxSize: 1}}
+	defer writer.out.Close()
+
+	day1 := time.Date(2026, 10, 15, 23, 59, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Minute)
+	events := []WalletAuditEvent{
+		{Timestamp: day1, Event: "nonce", Address: "0xabc", Success: true},
+		{Timestamp: day1.Add(30 * time.Second), Event: "login", Address: "0xabc", Success: false, Reason: "签名无效"},
+		{Timestamp: day2, Event: "login", Address: "0xabc", UserID: "user-1", Success: true},
+	}
+	for _, event := range events {
+		line, _ := json.Marshal(event)
+		if err := writer.write(event.Timestamp, append(line, '\n')); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+	}
+
+	files, err := walletAuditFiles(dir)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("walletAuditFiles = %v, %v; want the active file plus one daily backup", files, err)
+	}
+
+	var got []string
+	err = readWalletAuditEvents(dir, day1.Add(time.Second), time.Time{}, func(event WalletAuditEvent) bool {
+		got = append(got, event.Event)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("readWalletAuditEvents error: %v", err)
+	}
+	if len(got) != 2 || got[0] != "login" || got[1] != "login" {
+		t.Fatalf("events after %s = %v", day1, got)
+	}
+
+	count := 0
+	_ = readWalletAuditEvents(dir, time.Time{}, time.Time{}, func(WalletAuditEvent) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Fatalf("fn returning false should stop the scan, got %d events", count)
+	}
+}
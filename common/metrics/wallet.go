@@ -0,0 +1,127 @@
+// Package metrics keeps wallet authentication and server counters and renders
+// them in the Prometheus text exposition format. It has no dependency on the Prometheus
+// client library; the set of series is small and fixed.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// walletLoginDurationBuckets are the upper bounds, in seconds, of the login
+// latency histogram.
+var walletLoginDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type histogram struct {
+	mu      sync.Mutex
+	bounds  []float64
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, buckets: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+func (h *histogram) snapshot() (buckets []uint64, count uint64, sum float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]uint64(nil), h.buckets...), h.count, h.sum
+}
+
+var (
+	walletNonceGenerated atomic.Uint64
+	walletLoginSuccess   atomic.Uint64
+	walletLoginFailure   atomic.Uint64
+	walletJWTIssued      atomic.Uint64
+	walletLoginDuration  = newHistogram(walletLoginDurationBuckets)
+	panicsRecovered      atomic.Uint64
+)
+
+// IncWalletNonceGenerated counts one issued login nonce.
+func IncWalletNonceGenerated() {
+	walletNonceGenerated.Add(1)
+}
+
+// IncWalletJWTIssued counts one signed wallet JWT, access or refresh.
+func IncWalletJWTIssued() {
+	walletJWTIssued.Add(1)
+}
+
+// ObserveWalletLogin records the outcome and latency of a wallet login attempt.
+func ObserveWalletLogin(success bool, duration time.Duration) {
+	if success {
+		walletLoginSuccess.Add(1)
+	} else {
+		walletLoginFailure.Add(1)
+	}
+	walletLoginDuration.observe(duration.Seconds())
+}
+
+// IncPanicsRecovered counts one handler panic caught by the recovery middleware.
+func IncPanicsRecovered() {
+	panicsRecovered.Add(1)
+}
+
+// WritePrometheus writes every metric in the Prometheus text format.
+func WritePrometheus(w io.Writer) error {
+	buckets, count, sum := walletLoginDuration.snapshot()
+	var err error
+	printf := func(format string, a ...any) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, a...)
+		}
+	}
+
+	printf("# HELP wallet_nonce_generated_total Wallet login nonces issued.\n")
+	printf("# TYPE wallet_nonce_generated_total counter\n")
+	printf("wallet_nonce_generated_total %d\n", walletNonceGenerated.Load())
+
+	printf("# HELP wallet_login_attempts_total Wallet login attempts by result.\n")
+	printf("# TYPE wallet_login_attempts_total counter\n")
+	printf("wallet_login_attempts_total{result=\"success\"} %d\n", walletLoginSuccess.Load())
+	printf("wallet_login_attempts_total{result=\"failure\"} %d\n", walletLoginFailure.Load())
+
+	printf("# HELP wallet_login_duration_seconds Wallet login handling time.\n")
+	printf("# TYPE wallet_login_duration_seconds histogram\n")
+	for i, bound := range walletLoginDurationBuckets {
+		printf("wallet_login_duration_seconds_bucket{le=\"%s\"} %d\n", formatFloat(bound), buckets[i])
+	}
+	printf("wallet_login_duration_seconds_bucket{le=\"+Inf\"} %d\n", count)
+	printf("wallet_login_duration_seconds_sum %s\n", formatFloat(sum))
+	printf("wallet_login_duration_seconds_count %d\n", count)
+
+	printf("# HELP wallet_jwt_issued_total Wallet JWTs signed.\n")
+	printf("# TYPE wallet_jwt_issued_total counter\n")
+	printf("wallet_jwt_issued_total %d\n", walletJWTIssued.Load())
+
+	printf("# HELP panics_recovered_total Handler panics recovered and answered with 500.\n")
+	printf("# TYPE panics_recovered_total counter\n")
+	printf("panics_recovered_total %d\n", panicsRecovered.Load())
+	return err
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
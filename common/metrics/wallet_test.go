@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	IncWalletNonceGenerated()
+	IncWalletJWTIssued()
+	ObserveWalletLogin(true, 20*time.Millisecond)
+	ObserveWalletLogin(false, 3*time.Second)
+	IncPanicsRecovered()
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE wallet_nonce_generated_total counter\nwallet_nonce_generated_total 1\n",
+		`wallet_login_attempts_total{result="success"} 1`,
+		`wallet_login_attempts_total{result="failure"} 1`,
+		`wallet_login_duration_seconds_bucket{le="0.01"} 0`,
+		`wallet_login_duration_seconds_bucket{le="0.025"} 1`,
+		`wallet_login_duration_seconds_bucket{le="5"} 2`,
+		`wallet_login_duration_seconds_bucket{le="+Inf"} 2`,
+		"wallet_login_duration_seconds_count 2\n",
+		"wallet_jwt_issued_total 1\n",
+		"# TYPE panics_recovered_total counter\npanics_recovered_total 1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -17,3 +17,18 @@ func TestIsIpInSubnet(t *testing.T) {
 		So(isIpInSubnet(ctx, ip2, subnet), ShouldBeFalse)
 	})
 }
+
+func TestParseCIDRs(t *testing.T) {
+	Convey("TestParseCIDRs", t, func() {
+		ranges, err := ParseCIDRs([]string{" 10.0.0.0/8 ", "192.168.1.7", "2001:db8::/32", "::1", ""})
+		So(err, ShouldBeNil)
+		So(len(ranges), ShouldEqual, 4)
+		So(ranges[1].String(), ShouldEqual, "192.168.1.7/32")
+		So(ranges[3].String(), ShouldEqual, "::1/128")
+
+		_, err = ParseCIDRs([]string{"10.0.0.0/33"})
+		So(err, ShouldNotBeNil)
+		_, err = ParseCIDRs([]string{"not-an-ip"})
+		So(err, ShouldNotBeNil)
+	})
+}
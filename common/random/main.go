@@ -1,10 +1,12 @@
 package random
 
 import (
-	"github.com/google/uuid"
+	cryptorand "crypto/rand"
 	"math/rand"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 func GetUUID() string {
@@ -37,20 +39,39 @@ func GenerateKey() string {
 	return string(key)
 }
 
+// GetRandomString returns length alphanumeric characters read from
+// crypto/rand; it is used for generated usernames and passwords.
 func GetRandomString(length int) string {
-	rand.Seed(time.Now().UnixNano())
-	key := make([]byte, length)
-	for i := 0; i < length; i++ {
-		key[i] = keyChars[rand.Intn(len(keyChars))]
-	}
-	return string(key)
+	return secureString(keyChars, length)
 }
 
 func GetRandomNumberString(length int) string {
-	rand.Seed(time.Now().UnixNano())
-	key := make([]byte, length)
-	for i := 0; i < length; i++ {
-		key[i] = keyNumbers[rand.Intn(len(keyNumbers))]
+	return secureString(keyNumbers, length)
+}
+
+// secureString draws length characters from alphabet with crypto/rand. Bytes
+// at or above the largest multiple of len(alphabet) are rejected so every
+// character is equally likely.
+func secureString(alphabet string, length int) string {
+	if length <= 0 {
+		return ""
+	}
+	limit := 256 - 256%len(alphabet)
+	key := make([]byte, 0, length)
+	buf := make([]byte, length+length/4+8)
+	for len(key) < length {
+		if _, err := cryptorand.Read(buf); err != nil {
+			panic("crypto/rand unavailable: " + err.Error())
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			key = append(key, alphabet[int(b)%len(alphabet)])
+			if len(key) == length {
+				break
+			}
+		}
 	}
 	return string(key)
 }
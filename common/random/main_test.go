@@ -0,0 +1,41 @@
+package random
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetRandomString(t *testing.T) {
+	seen := make(map[string]bool, 10000)
+	for i := 0; i < 10000; i++ {
+		s := GetRandomString(16)
+		if len(s) != 16 {
+			t.Fatalf("len(GetRandomString(16)) = %d", len(s))
+		}
+		for _, c := range s {
+			if !strings.ContainsRune(keyChars, c) {
+				t.Fatalf("GetRandomString returned %q outside the alphabet", s)
+			}
+		}
+		if seen[s] {
+			t.Fatalf("GetRandomString repeated %q after %d calls", s, i)
+		}
+		seen[s] = true
+	}
+	if got := GetRandomString(0); got != "" {
+		t.Fatalf("GetRandomString(0) = %q", got)
+	}
+}
+
+func TestGetRandomNumberString(t *testing.T) {
+	s := GetRandomNumberString(64)
+	if len(s) != 64 || strings.Trim(s, keyNumbers) != "" {
+		t.Fatalf("GetRandomNumberString(64) = %q", s)
+	}
+}
+
+func BenchmarkGetRandomString(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		GetRandomString(16)
+	}
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseRateLimit parses a limit such as "5/minute" into a request count and the
+// window it applies to. Units are second, minute and hour (or s, m, h). An empty
+// spec or a zero count means no limit and returns 0.
+func ParseRateLimit(spec string) (int, time.Duration, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return 0, 0, nil
+	}
+	countPart, unitPart, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("rate limit %q must look like <count>/<unit>", spec)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(countPart))
+	if err != nil || count < 0 {
+		return 0, 0, fmt.Errorf("invalid rate limit count in %q", spec)
+	}
+	var window time.Duration
+	switch strings.ToLower(strings.TrimSpace(unitPart)) {
+	case "s", "sec", "second":
+		window = time.Second
+	case "m", "min", "minute":
+		window = time.Minute
+	case "h", "hour":
+		window = time.Hour
+	default:
+		return 0, 0, fmt.Errorf("invalid rate limit unit in %q", spec)
+	}
+	if count == 0 {
+		return 0, 0, nil
+	}
+	return count, window, nil
+}
@@ -0,0 +1,33 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		spec       string
+		wantCount  int
+		wantWindow time.Duration
+		wantErr    bool
+	}{
+		{spec: "5/minute", wantCount: 5, wantWindow: time.Minute},
+		{spec: " 10 / h ", wantCount: 10, wantWindow: time.Hour},
+		{spec: "1/second", wantCount: 1, wantWindow: time.Second},
+		{spec: ""},
+		{spec: "0/minute"},
+		{spec: "5", wantErr: true},
+		{spec: "x/minute", wantErr: true},
+		{spec: "5/day", wantErr: true},
+	}
+	for _, tt := range tests {
+		count, window, err := ParseRateLimit(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseRateLimit(%q) err = %v, wantErr %v", tt.spec, err, tt.wantErr)
+		}
+		if count != tt.wantCount || window != tt.wantWindow {
+			t.Fatalf("ParseRateLimit(%q) = %d, %v; want %d, %v", tt.spec, count, window, tt.wantCount, tt.wantWindow)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+)
+
+const (
+	slackAlertsPerMinute = 10
+	slackAlertTimeout    = 10 * time.Second
+)
+
+var (
+	slackAlertLimiterOnce sync.Once
+	slackAlertLimiter     *TokenBucket
+	slackHTTPClient       = &http.Client{Timeout: slackAlertTimeout}
+)
+
+type slackAttachmentField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color  string                 `json:"color"`
+	Title  string                 `json:"title"`
+	Text   string                 `json:"text"`
+	Fields []slackAttachmentField `json:"fields"`
+}
+
+type slackMessage struct {
+	Channel     string            `json:"channel,omitempty"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// InitSlackAlert forwards SysError messages to Slack when SLACK_WEBHOOK_URL is set.
+func InitSlackAlert() {
+	if strings.TrimSpace(config.SlackWebhookURL) == "" {
+		return
+	}
+	logger.SetSysErrorHook(SendSlackAlert)
+	logger.SysLog("slack alerts enabled")
+}
+
+// SendSlackAlert posts msg to the configured Slack webhook in the background.
+// Delivery is best-effort and limited to 10 alerts per minute; alerts over the
+// limit are dropped.
+func SendSlackAlert(msg string) {
+	webhookURL := strings.TrimSpace(config.SlackWebhookURL)
+	if webhookURL == "" {
+		return
+	}
+	slackAlertLimiterOnce.Do(func() {
+		slackAlertLimiter = NewTokenBucket(slackAlertsPerMinute/60.0, slackAlertsPerMinute)
+	})
+	if !slackAlertLimiter.Allow() {
+		return
+	}
+	payload := slackMessage{
+		Channel: strings.TrimSpace(config.SlackAlertChannel),
+		Attachments: []slackAttachment{{
+			Color: "danger",
+			Title: "Router Error",
+			Text:  msg,
+			Fields: []slackAttachmentField{
+				{Title: "version", Value: Version, Short: true},
+				{Title: "time", Value: time.Now().Format(time.RFC3339), Short: true},
+			},
+		}},
+	}
+	go func() {
+		// Failures are logged as warnings: SysError would feed back into this hook.
+		if err := postSlackMessage(webhookURL, payload); err != nil {
+			logger.SysWarnf("failed to send slack alert: %s", err.Error())
+		}
+	}()
+}
+
+func postSlackMessage(webhookURL string, payload slackMessage) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := slackHTTPClient.Post(webhookURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected slack status %d", resp.StatusCode)
+	}
+	return nil
+}
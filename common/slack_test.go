@@ -0,0 +1,45 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestSendSlackAlert_PostsAttachment(t *testing.T) {
+	received := make(chan slackMessage, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload slackMessage
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	prevURL, prevChannel := config.SlackWebhookURL, config.SlackAlertChannel
+	config.SlackWebhookURL, config.SlackAlertChannel = server.URL, "#router-alerts"
+	defer func() { config.SlackWebhookURL, config.SlackAlertChannel = prevURL, prevChannel }()
+
+	SendSlackAlert("db down")
+	select {
+	case payload := <-received:
+		if payload.Channel != "#router-alerts" {
+			t.Fatalf("channel = %q", payload.Channel)
+		}
+		if len(payload.Attachments) != 1 {
+			t.Fatalf("attachments = %d", len(payload.Attachments))
+		}
+		attachment := payload.Attachments[0]
+		if attachment.Color != "danger" || attachment.Title != "Router Error" || attachment.Text != "db down" {
+			t.Fatalf("unexpected attachment: %+v", attachment)
+		}
+		if len(attachment.Fields) != 2 || attachment.Fields[0].Value != Version {
+			t.Fatalf("unexpected fields: %+v", attachment.Fields)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("slack webhook not called")
+	}
+}
@@ -0,0 +1,141 @@
+package common
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+)
+
+// errorBudgetMinSamples keeps a few early failures in a quiet day from
+// exhausting the whole budget.
+const errorBudgetMinSamples = 100
+
+// ErrorBudgetTracker counts events per UTC day against an error-rate target.
+// Once the failure rate exceeds Target the tracker stays in burn mode until the
+// next midnight UTC.
+type ErrorBudgetTracker struct {
+	Target float64
+	Name   string
+
+	mu      sync.Mutex
+	day     string
+	total   int64
+	failed  int64
+	burning bool
+	now     func() time.Time
+}
+
+// ErrorBudgetSnapshot is the tracker state exposed to admins.
+type ErrorBudgetSnapshot struct {
+	Target                 float64 `json:"target"`
+	CurrentErrorRate       float64 `json:"current_error_rate"`
+	BudgetRemainingPercent float64 `json:"budget_remaining_percent"`
+	InBurnMode             bool    `json:"in_burn_mode"`
+	Total                  int64   `json:"total"`
+	Failed                 int64   `json:"failed"`
+}
+
+func NewErrorBudgetTracker(name string, target float64) *ErrorBudgetTracker {
+	return &ErrorBudgetTracker{Name: name, Target: target, now: time.Now}
+}
+
+var (
+	walletAuthBudgetOnce sync.Once
+	walletAuthBudget     *ErrorBudgetTracker
+)
+
+// WalletAuthErrorBudget returns the tracker fed by wallet login attempts. Its
+// target follows auth.wallet_error_slo, so a reloaded value applies to the
+// next event.
+func WalletAuthErrorBudget() *ErrorBudgetTracker {
+	walletAuthBudgetOnce.Do(func() {
+		walletAuthBudget = NewErrorBudgetTracker("wallet auth", config.WalletErrorSLO)
+	})
+	walletAuthBudget.SetTarget(config.WalletErrorSLO)
+	return walletAuthBudget
+}
+
+// SetTarget changes the error-rate target. Burn mode, once entered, still
+// lasts until midnight UTC.
+func (t *ErrorBudgetTracker) SetTarget(target float64) {
+	t.mu.Lock()
+	t.Target = target
+	t.mu.Unlock()
+}
+
+// Record counts one event and enters burn mode when the budget is exhausted.
+func (t *ErrorBudgetTracker) Record(success bool) {
+	t.mu.Lock()
+	t.rollover()
+	t.total++
+	if !success {
+		t.failed++
+	}
+	entered := false
+	if !t.burning && t.total >= errorBudgetMinSamples && t.errorRate() > t.Target {
+		t.burning = true
+		entered = true
+	}
+	total, failed, target := t.total, t.failed, t.Target
+	t.mu.Unlock()
+
+	if entered {
+		msg := fmt.Sprintf("%s error budget exhausted: %d/%d failed today (target %.4f), entering burn mode until midnight UTC", t.Name, failed, total, target)
+		logger.SysWarnf("%s", msg)
+		SendSlackAlert(msg)
+	}
+}
+
+// InBurnMode reports whether non-critical operations should be skipped.
+func (t *ErrorBudgetTracker) InBurnMode() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+	return t.burning
+}
+
+func (t *ErrorBudgetTracker) Snapshot() ErrorBudgetSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+	rate := t.errorRate()
+	remaining := 100.0
+	if t.Target > 0 {
+		remaining = (1 - rate/t.Target) * 100
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	return ErrorBudgetSnapshot{
+		Target:                 t.Target,
+		CurrentErrorRate:       rate,
+		BudgetRemainingPercent: remaining,
+		InBurnMode:             t.burning,
+		Total:                  t.total,
+		Failed:                 t.failed,
+	}
+}
+
+func (t *ErrorBudgetTracker) errorRate() float64 {
+	if t.total == 0 {
+		return 0
+	}
+	return float64(t.failed) / float64(t.total)
+}
+
+func (t *ErrorBudgetTracker) rollover() {
+	now := time.Now
+	if t.now != nil {
+		now = t.now
+	}
+	day := now().UTC().Format("2006-01-02")
+	if t.day != day {
+		t.day = day
+		t.total = 0
+		t.failed = 0
+		t.burning = false
+	}
+}
@@ -0,0 +1,55 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestErrorBudgetTracker_BurnModeAndMidnightReset(t *testing.T) {
+	now := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+	tracker := NewErrorBudgetTracker("wallet auth", 0.01)
+	tracker.now = func() time.Time { return now }
+
+	for i := 0; i < errorBudgetMinSamples-1; i++ {
+		tracker.Record(i%2 == 0)
+	}
+	if tracker.InBurnMode() {
+		t.Fatalf("burn mode entered below the minimum sample size")
+	}
+	tracker.Record(true)
+	if !tracker.InBurnMode() {
+		t.Fatalf("burn mode not entered at %.2f error rate", tracker.Snapshot().CurrentErrorRate)
+	}
+	snapshot := tracker.Snapshot()
+	if snapshot.BudgetRemainingPercent != 0 || snapshot.Total != errorBudgetMinSamples {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if tracker.InBurnMode() {
+		t.Fatalf("burn mode not reset at midnight UTC")
+	}
+	for i := 0; i < errorBudgetMinSamples; i++ {
+		tracker.Record(i != 0)
+	}
+	snapshot = tracker.Snapshot()
+	if snapshot.InBurnMode || snapshot.CurrentErrorRate != 0.01 || snapshot.BudgetRemainingPercent != 0 {
+		t.Fatalf("snapshot at target = %+v", snapshot)
+	}
+}
+
+func TestWalletAuthErrorBudget_FollowsConfiguredTarget(t *testing.T) {
+	prev := config.WalletErrorSLO
+	defer func() { config.WalletErrorSLO = prev }()
+
+	config.WalletErrorSLO = 0.02
+	if got := WalletAuthErrorBudget().Snapshot().Target; got != 0.02 {
+		t.Fatalf("target = %v, want 0.02", got)
+	}
+	config.WalletErrorSLO = 0.05
+	if got := WalletAuthErrorBudget().Snapshot().Target; got != 0.05 {
+		t.Fatalf("target after reload = %v, want 0.05", got)
+	}
+}
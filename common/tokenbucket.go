@@ -0,0 +1,143 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket is a thread-safe token bucket refilled continuously at rate
+// tokens per second, holding at most burst tokens.
+type TokenBucket struct {
+	rate       float64
+	burst      int
+	tokens     float64
+	lastRefill time.Time
+	mu         sync.Mutex
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:       rate,
+		burst:      burst,
+		tokens:     float64(burst),
+		lastRefill: time.Now(),
+	}
+}
+
+func (b *TokenBucket) Allow() bool {
+	return b.AllowN(1)
+}
+
+// AllowN consumes n tokens if available and reports whether it did.
+func (b *TokenBucket) AllowN(n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	if b.tokens < float64(n) {
+		return false
+	}
+	b.tokens -= float64(n)
+	return true
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	if elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > float64(b.burst) {
+			b.tokens = float64(b.burst)
+		}
+	}
+	b.lastRefill = now
+}
+
+// idleSince reports whether the bucket has not been touched since t.
+func (b *TokenBucket) idleSince(t time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastRefill.Before(t)
+}
+
+// TokenBucketStore keeps one TokenBucket per key, all sharing the same rate and burst.
+type TokenBucketStore struct {
+	rate    float64
+	burst   int
+	buckets sync.Map
+	done    chan struct{}
+	close   sync.Once
+}
+
+// NewTokenBucketStore creates a store; when idleExpiration > 0, buckets untouched
+// for that long are dropped periodically (an idle bucket is full anyway).
+func NewTokenBucketStore(rate float64, burst int, idleExpiration time.Duration) *TokenBucketStore {
+	s := &TokenBucketStore{rate: rate, burst: burst, done: make(chan struct{})}
+	if idleExpiration > 0 {
+		go s.clearIdleBuckets(idleExpiration)
+	}
+	return s
+}
+
+// Close stops the idle bucket cleanup.
+func (s *TokenBucketStore) Close() {
+	s.close.Do(func() { close(s.done) })
+}
+
+var (
+	sharedTokenBucketStoresMu sync.Mutex
+	sharedTokenBucketStores   = map[string]*TokenBucketStore{}
+)
+
+// SharedTokenBucketStore returns the store registered under name, so every
+// limiter built for the same name draws from one quota. A call with a
+// different rate or burst, as after a config reload, replaces the store.
+func SharedTokenBucketStore(name string, rate float64, burst int, idleExpiration time.Duration) *TokenBucketStore {
+	sharedTokenBucketStoresMu.Lock()
+	defer sharedTokenBucketStoresMu.Unlock()
+	if store, ok := sharedTokenBucketStores[name]; ok {
+		if store.rate == rate && store.burst == burst {
+			return store
+		}
+		store.Close()
+	}
+	store := NewTokenBucketStore(rate, burst, idleExpiration)
+	sharedTokenBucketStores[name] = store
+	return store
+}
+
+func (s *TokenBucketStore) GetOrCreate(key string) *TokenBucket {
+	if bucket, ok := s.buckets.Load(key); ok {
+		return bucket.(*TokenBucket)
+	}
+	bucket, _ := s.buckets.LoadOrStore(key, NewTokenBucket(s.rate, s.burst))
+	return bucket.(*TokenBucket)
+}
+
+func (s *TokenBucketStore) clearIdleBuckets(idleExpiration time.Duration) {
+	ticker := time.NewTicker(idleExpiration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		cutoff := time.Now().Add(-idleExpiration)
+		s.buckets.Range(func(key, value any) bool {
+			if value.(*TokenBucket).idleSince(cutoff) {
+				s.buckets.Delete(key)
+			}
+			return true
+		})
+	}
+}
+
+// RetryAfter is how long until the bucket holds at least one token.
+func (b *TokenBucket) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	if b.tokens >= 1 || b.rate <= 0 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
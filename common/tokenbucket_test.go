@@ -0,0 +1,61 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_BurstThenRefill(t *testing.T) {
+	bucket := NewTokenBucket(1, 3)
+	for i := 0; i < 3; i++ {
+		if !bucket.Allow() {
+			t.Fatalf("Allow() #%d = false, want true within burst", i+1)
+		}
+	}
+	if bucket.Allow() {
+		t.Fatalf("Allow() = true after burst exhausted, want false")
+	}
+
+	bucket.mu.Lock()
+	bucket.lastRefill = bucket.lastRefill.Add(-2 * time.Second)
+	bucket.mu.Unlock()
+	if !bucket.AllowN(2) {
+		t.Fatalf("AllowN(2) = false after 2s refill, want true")
+	}
+	if bucket.Allow() {
+		t.Fatalf("Allow() = true after refilled tokens consumed, want false")
+	}
+}
+
+func TestTokenBucketStore_GetOrCreateReturnsSameBucket(t *testing.T) {
+	store := NewTokenBucketStore(1, 1, 0)
+	a := store.GetOrCreate("k")
+	if a != store.GetOrCreate("k") {
+		t.Fatalf("GetOrCreate returned different buckets for the same key")
+	}
+	if a == store.GetOrCreate("other") {
+		t.Fatalf("GetOrCreate returned the same bucket for different keys")
+	}
+}
+
+func TestSharedTokenBucketStore_SharedPerNameAndRate(t *testing.T) {
+	a := SharedTokenBucketStore("test:shared", 1, 2, 0)
+	if a != SharedTokenBucketStore("test:shared", 1, 2, 0) {
+		t.Fatalf("same name and limits returned different stores")
+	}
+	if a == SharedTokenBucketStore("test:other", 1, 2, 0) {
+		t.Fatalf("different names returned the same store")
+	}
+	b := SharedTokenBucketStore("test:shared", 1, 5, 0)
+	if a == b {
+		t.Fatalf("changed burst kept the old store")
+	}
+	select {
+	case <-a.done:
+	default:
+		t.Fatalf("replaced store was not closed")
+	}
+	if b != SharedTokenBucketStore("test:shared", 1, 5, 0) {
+		t.Fatalf("replacement store was not registered")
+	}
+}
@@ -15,10 +15,44 @@ func LogQuota(quota int64) string {
 	return fmt.Sprintf("%d 点额度", quota)
 }
 
-// IsValidEthAddress performs a basic checksum/length check
+// IsValidEthAddress checks that addr is a 20-byte hex address. All-lowercase
+// and all-uppercase addresses are accepted as is; mixed case is taken as an
+// EIP-55 checksum and must match it.
 func IsValidEthAddress(addr string) bool {
-	if addr == "" {
+	if addr == "" || !gethCommon.IsHexAddress(addr) {
 		return false
 	}
-	return gethCommon.IsHexAddress(strings.ToLower(addr))
+	digits := ethAddressDigits(addr)
+	if strings.ToLower(digits) == digits || strings.ToUpper(digits) == digits {
+		return true
+	}
+	return digits == ethAddressChecksumDigits(digits)
+}
+
+// IsValidEthAddressStrict additionally requires the EIP-55 checksum, so
+// single-case addresses are rejected unless they contain no letters.
+func IsValidEthAddressStrict(addr string) bool {
+	if addr == "" || !gethCommon.IsHexAddress(addr) {
+		return false
+	}
+	digits := ethAddressDigits(addr)
+	return digits == ethAddressChecksumDigits(digits)
+}
+
+// EthChecksumAddress returns addr in its 0x-prefixed EIP-55 form.
+func EthChecksumAddress(addr string) string {
+	return gethCommon.HexToAddress(addr).Hex()
+}
+
+// ethAddressDigits strips the optional 0x prefix.
+func ethAddressDigits(addr string) string {
+	if len(addr) >= 2 && addr[0] == '0' && (addr[1] == 'x' || addr[1] == 'X') {
+		return addr[2:]
+	}
+	return addr
+}
+
+// ethAddressChecksumDigits returns the EIP-55 casing of 40 hex digits.
+func ethAddressChecksumDigits(digits string) string {
+	return gethCommon.HexToAddress(digits).Hex()[2:]
 }
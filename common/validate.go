@@ -1,9 +1,42 @@
 package common
 
-import "github.com/go-playground/validator/v10"
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
 
 var Validate *validator.Validate
 
 func init() {
 	Validate = validator.New()
 }
+
+var registerValidatorsOnce sync.Once
+
+// RegisterValidators adds the custom tags below to Validate and to gin's
+// binding validator, so they work in binding:"..." struct tags:
+//   - eth_addr: a 0x-prefixed Ethereum address (EIP-55 checked when mixed case)
+//   - solana_addr: a base58 Solana public key
+func RegisterValidators() {
+	registerValidatorsOnce.Do(func() {
+		validators := map[string]validator.Func{
+			"eth_addr": func(fl validator.FieldLevel) bool {
+				return IsValidEthAddress(fl.Field().String())
+			},
+			"solana_addr": func(fl validator.FieldLevel) bool {
+				return IsValidSolanaAddress(fl.Field().String())
+			},
+		}
+		engines := []*validator.Validate{Validate}
+		if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			engines = append(engines, engine)
+		}
+		for _, engine := range engines {
+			for tag, fn := range validators {
+				_ = engine.RegisterValidation(tag, fn)
+			}
+		}
+	})
+}
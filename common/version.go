@@ -0,0 +1,42 @@
+package common
+
+import (
+	"runtime/debug"
+)
+
+const defaultVersion = "v0.0.0"
+
+// Version, BuildTime and GitCommit are normally injected with
+// -ldflags "-X github.com/yeying-community/router/common.Version=...";
+// anything left empty is filled from the embedded build info in init.
+var Version = defaultVersion
+var BuildTime = ""
+var GitCommit = ""
+
+func init() {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	applyBuildInfo(buildInfo)
+}
+
+func applyBuildInfo(buildInfo *debug.BuildInfo) {
+	if Version == defaultVersion {
+		if v := buildInfo.Main.Version; v != "" && v != "(devel)" {
+			Version = v
+		}
+	}
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if GitCommit == "" {
+				GitCommit = setting.Value
+			}
+		case "vcs.time":
+			if BuildTime == "" {
+				BuildTime = setting.Value
+			}
+		}
+	}
+}
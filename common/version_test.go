@@ -0,0 +1,44 @@
+package common
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestVersion_NotEmptyWithoutSpaces(t *testing.T) {
+	if Version == "" {
+		t.Fatalf("Version is empty")
+	}
+	if strings.ContainsAny(Version, " \t\n") {
+		t.Fatalf("Version %q contains whitespace", Version)
+	}
+}
+
+func TestApplyBuildInfo(t *testing.T) {
+	prevVersion, prevCommit, prevTime := Version, GitCommit, BuildTime
+	defer func() { Version, GitCommit, BuildTime = prevVersion, prevCommit, prevTime }()
+
+	Version, GitCommit, BuildTime = defaultVersion, "", ""
+	applyBuildInfo(&debug.BuildInfo{Main: debug.Module{Version: "(devel)"}})
+	if Version != defaultVersion {
+		t.Fatalf("Version = %q after (devel) build info, want %q", Version, defaultVersion)
+	}
+
+	applyBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.time", Value: "2026-10-16T00:00:00Z"},
+		},
+	})
+	if Version != "v1.2.3" || GitCommit != "abc123" || BuildTime != "2026-10-16T00:00:00Z" {
+		t.Fatalf("applyBuildInfo got version=%q commit=%q time=%q", Version, GitCommit, BuildTime)
+	}
+
+	Version = "v9.9.9"
+	applyBuildInfo(&debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}})
+	if Version != "v9.9.9" {
+		t.Fatalf("applyBuildInfo overrode ldflags version, got %q", Version)
+	}
+}
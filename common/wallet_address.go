@@ -0,0 +1,46 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"strings"
+)
+
+const (
+	WalletTypeEthereum = "ethereum"
+	WalletTypeSolana   = "solana"
+	// WalletTypeWebAuthn marks passkey logins. It is not a signature wallet, so
+	// NormalizeWalletType rejects it; passkeys use the webauthn endpoints.
+	WalletTypeWebAuthn = "webauthn"
+)
+
+// NormalizeWalletType maps the wallet_type of a request to a known type; an
+// empty value means Ethereum.
+func NormalizeWalletType(walletType string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(walletType)) {
+	case "", WalletTypeEthereum:
+		return WalletTypeEthereum, true
+	case WalletTypeSolana:
+		return WalletTypeSolana, true
+	default:
+		return "", false
+	}
+}
+
+// IsValidWalletAddress checks addr against the address format of walletType.
+func IsValidWalletAddress(addr, walletType string) bool {
+	normalized, ok := NormalizeWalletType(walletType)
+	if !ok {
+		return false
+	}
+	if normalized == WalletTypeSolana {
+		return IsValidSolanaAddress(addr)
+	}
+	return IsValidEthAddress(addr)
+}
+
+// IsValidSolanaAddress reports whether addr is a base58-encoded Ed25519 public
+// key.
+func IsValidSolanaAddress(addr string) bool {
+	raw, err := DecodeBase58(strings.TrimSpace(addr))
+	return err == nil && len(raw) == ed25519.PublicKeySize
+}
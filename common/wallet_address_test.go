@@ -0,0 +1,90 @@
+package common
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBase58RoundTrip(t *testing.T) {
+	for _, raw := range [][]byte{{0}, {0, 0, 1, 2}, {0xff, 0xee}, bytes.Repeat([]byte{7}, 32)} {
+		encoded := EncodeBase58(raw)
+		decoded, err := DecodeBase58(encoded)
+		if err != nil {
+			t.Fatalf("DecodeBase58(%q) error: %v", encoded, err)
+		}
+		if !bytes.Equal(decoded, raw) {
+			t.Fatalf("round trip %x -> %q -> %x", raw, encoded, decoded)
+		}
+	}
+	if _, err := DecodeBase58("0OIl"); err == nil {
+		t.Fatalf("DecodeBase58 accepted characters outside the alphabet")
+	}
+}
+
+func TestIsValidWalletAddress(t *testing.T) {
+	tests := []struct {
+		name       string
+		addr       string
+		walletType string
+		want       bool
+	}{
+		{name: "ethereum default type", addr: "0x1111111111111111111111111111111111111111", want: true},
+		{name: "ethereum explicit type", addr: "0x1111111111111111111111111111111111111111", walletType: "Ethereum", want: true},
+		{name: "solana system program", addr: "11111111111111111111111111111111", walletType: "solana", want: true},
+		{name: "solana key", addr: "4Nd1mBQtrMJVYVfKf2PJy9NZUZdTAsp7D4xWLs4gDB4T", walletType: "solana", want: true},
+		{name: "solana address as ethereum", addr: "4Nd1mBQtrMJVYVfKf2PJy9NZUZdTAsp7D4xWLs4gDB4T", want: false},
+		{name: "ethereum address as solana", addr: "0x1111111111111111111111111111111111111111", walletType: "solana", want: false},
+		{name: "unknown type", addr: "0x1111111111111111111111111111111111111111", walletType: "bitcoin", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValidWalletAddress(tt.addr, tt.walletType); got != tt.want {
+				t.Fatalf("IsValidWalletAddress(%q, %q) = %v, want %v", tt.addr, tt.walletType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidEthAddress_EIP55(t *testing.T) {
+	// vectors from EIP-55
+	checksummed := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	}
+	for _, addr := range checksummed {
+		if !IsValidEthAddress(addr) || !IsValidEthAddressStrict(addr) {
+			t.Fatalf("checksummed %s rejected", addr)
+		}
+		if got := EthChecksumAddress(strings.ToLower(addr)); got != addr {
+			t.Fatalf("EthChecksumAddress(%s) = %s", strings.ToLower(addr), got)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		addr       string
+		want       bool
+		wantStrict bool
+	}{
+		{name: "all lowercase", addr: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", want: true},
+		{name: "all uppercase", addr: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", want: true},
+		{name: "bad checksum", addr: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"},
+		{name: "digits only", addr: "0x1111111111111111111111111111111111111111", want: true, wantStrict: true},
+		{name: "too short", addr: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea"},
+		{name: "not hex", addr: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beazz"},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValidEthAddress(tt.addr); got != tt.want {
+				t.Fatalf("IsValidEthAddress(%q) = %v, want %v", tt.addr, got, tt.want)
+			}
+			if got := IsValidEthAddressStrict(tt.addr); got != tt.wantStrict {
+				t.Fatalf("IsValidEthAddressStrict(%q) = %v, want %v", tt.addr, got, tt.wantStrict)
+			}
+		})
+	}
+}
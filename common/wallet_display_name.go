@@ -0,0 +1,83 @@
+package common
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/yeying-community/router/common/logger"
+)
+
+// walletDisplayNameMaxLength matches the validate tag on User.DisplayName.
+const walletDisplayNameMaxLength = 20
+
+// WalletDisplayNameData holds the fields available to
+// auth.auto_register_display_name_template.
+type WalletDisplayNameData struct {
+	ShortAddress string
+	Username     string
+	CreatedAt    time.Time
+}
+
+var (
+	walletDisplayNameMutex    sync.RWMutex
+	walletDisplayNameTemplate *template.Template
+)
+
+// SetWalletDisplayNameTemplate validates and installs the text/template used to
+// name auto-registered wallet users; an empty string keeps the username.
+func SetWalletDisplayNameTemplate(text string) error {
+	var tpl *template.Template
+	if strings.TrimSpace(text) != "" {
+		parsed, err := template.New("wallet_display_name").Option("missingkey=error").Parse(text)
+		if err != nil {
+			return err
+		}
+		sample := WalletDisplayNameData{ShortAddress: "0x1234...abcd", Username: "wallet_abc123", CreatedAt: time.Now()}
+		if err := parsed.Execute(&strings.Builder{}, sample); err != nil {
+			return err
+		}
+		tpl = parsed
+	}
+	walletDisplayNameMutex.Lock()
+	defer walletDisplayNameMutex.Unlock()
+	walletDisplayNameTemplate = tpl
+	return nil
+}
+
+// RenderWalletDisplayName renders the configured template for a new wallet
+// user, falling back to the username when there is no template, rendering
+// fails or the result is blank. Results are cut to the display name limit.
+func RenderWalletDisplayName(address, username string, createdAt time.Time) string {
+	walletDisplayNameMutex.RLock()
+	tpl := walletDisplayNameTemplate
+	walletDisplayNameMutex.RUnlock()
+	if tpl == nil {
+		return username
+	}
+	var out strings.Builder
+	data := WalletDisplayNameData{ShortAddress: ShortWalletAddress(address), Username: username, CreatedAt: createdAt}
+	if err := tpl.Execute(&out, data); err != nil {
+		logger.SysErrorf("render wallet display name template failed, using username: %v", err)
+		return username
+	}
+	name := strings.TrimSpace(out.String())
+	if name == "" {
+		return username
+	}
+	if runes := []rune(name); len(runes) > walletDisplayNameMaxLength {
+		name = string(runes[:walletDisplayNameMaxLength])
+	}
+	return name
+}
+
+// ShortWalletAddress keeps the first 6 and last 4 characters of address, e.g.
+// 0x1234...abcd.
+func ShortWalletAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if len(address) <= 10 {
+		return address
+	}
+	return address[:6] + "..." + address[len(address)-4:]
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderWalletDisplayName(t *testing.T) {
+	defer SetWalletDisplayNameTemplate("")
+	const addr = "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	createdAt := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "no template keeps username", want: "wallet_abc123"},
+		{name: "short address", template: "{{.ShortAddress}}", want: "0x5aae...eaed"},
+		{name: "fields combined", template: `{{.Username}} {{.CreatedAt.Format "0102"}}`, want: "wallet_abc123 1016"},
+		{name: "blank result falls back", template: "  ", want: "wallet_abc123"},
+		{name: "truncated to limit", template: "{{.Username}}-{{.Username}}", want: "wallet_abc123-wallet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetWalletDisplayNameTemplate(tt.template); err != nil {
+				t.Fatalf("SetWalletDisplayNameTemplate error: %v", err)
+			}
+			if got := RenderWalletDisplayName(addr, "wallet_abc123", createdAt); got != tt.want {
+				t.Fatalf("RenderWalletDisplayName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{"{{.Missing}}", "{{.ShortAddress"} {
+		if err := SetWalletDisplayNameTemplate(bad); err == nil {
+			t.Fatalf("SetWalletDisplayNameTemplate(%q) accepted", bad)
+		}
+	}
+	if got := ShortWalletAddress("0x12345678"); got != "0x12345678" {
+		t.Fatalf("ShortWalletAddress kept short address as %q", got)
+	}
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/yeying-community/router/common/logger"
+)
+
+const walletSignatureReplayPrefix = "sig:"
+
+// MarkWalletSignatureUsed records sha256(signature) for ttl and reports
+// whether this is its first use. It shares the JWT revocation store, so with
+// auth.nonce_store redis every instance sees the same set. A store error is
+// logged and treated as first use; the nonce check still applies.
+func MarkWalletSignatureUsed(signature string, ttl time.Duration) bool {
+	if ttl <= 0 {
+		ttl = WalletNonceTTL()
+	}
+	key := walletSignatureReplayPrefix + walletSignatureDigest(signature)
+	first, err := getWalletJWTRevocationStore().SetIfAbsent(key, time.Now().Unix(), ttl)
+	if err != nil {
+		logger.SysErrorf("record wallet signature failed err=%v", err)
+		return true
+	}
+	return first
+}
+
+// walletSignatureDigest hashes hex signatures case-insensitively and without
+// their 0x prefix; base58 (Solana) signatures are case-sensitive and hashed
+// as is.
+func walletSignatureDigest(signature string) string {
+	signature = strings.TrimSpace(signature)
+	if strings.HasPrefix(signature, "0x") || strings.HasPrefix(signature, "0X") {
+		signature = strings.ToLower(signature[2:])
+	}
+	sum := sha256.Sum256([]byte(signature))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,33 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMarkWalletSignatureUsed(t *testing.T) {
+	SetWalletJWTRevocationStore(newMemoryRevocationStore())
+	defer SetWalletJWTRevocationStore(newMemoryRevocationStore())
+
+	if !MarkWalletSignatureUsed("0xAB12", time.Minute) {
+		t.Fatalf("first use rejected")
+	}
+	if MarkWalletSignatureUsed("0xAB12", time.Minute) {
+		t.Fatalf("replayed signature accepted")
+	}
+	if MarkWalletSignatureUsed("0xab12", time.Minute) {
+		t.Fatalf("hex signature with different case accepted")
+	}
+	if !MarkWalletSignatureUsed("3Ab", time.Minute) || !MarkWalletSignatureUsed("3ab", time.Minute) {
+		t.Fatalf("base58 signatures should be case-sensitive")
+	}
+}
+
+func TestWalletSignatureDigest(t *testing.T) {
+	if walletSignatureDigest("0xABCD") != walletSignatureDigest(" 0Xabcd ") {
+		t.Fatalf("hex digests differ by case or prefix")
+	}
+	if walletSignatureDigest("Abc") == walletSignatureDigest("abc") {
+		t.Fatalf("base58 digests should differ by case")
+	}
+}
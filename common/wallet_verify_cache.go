@@ -0,0 +1,102 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+// walletVerifyCacheEntry is a successful verify response kept for retries of
+// the same request. The payload is opaque to common.
+type walletVerifyCacheEntry struct {
+	address  string
+	clientIP string
+	payload  any
+	expireAt time.Time
+}
+
+// the cache is process-local: a retry that lands on another instance is
+// verified again and rejected as a replayed signature
+var (
+	walletVerifyCacheMutex sync.Mutex
+	walletVerifyCache      = make(map[string]walletVerifyCacheEntry)
+)
+
+// WalletVerifyCacheKey is sha256(signature+nonce) as hex.
+func WalletVerifyCacheKey(signature, nonce string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(signature) + strings.TrimSpace(nonce)))
+	return hex.EncodeToString(sum[:])
+}
+
+func walletVerifyCacheTTL() time.Duration {
+	return time.Duration(config.WalletVerifyCacheTTLSeconds) * time.Second
+}
+
+// CacheWalletVerifyResponse keeps payload for auth.verify_cache_ttl_seconds so a
+// retry of the same verify from clientIP can be answered without signing in
+// again. It is dropped early when a nonce of address is consumed.
+func CacheWalletVerifyResponse(key, address, clientIP string, payload any) {
+	ttl := walletVerifyCacheTTL()
+	if ttl <= 0 || key == "" {
+		return
+	}
+	now := time.Now()
+	walletVerifyCacheMutex.Lock()
+	defer walletVerifyCacheMutex.Unlock()
+	for k, entry := range walletVerifyCache {
+		if now.After(entry.expireAt) {
+			delete(walletVerifyCache, k)
+		}
+	}
+	walletVerifyCache[key] = walletVerifyCacheEntry{
+		address:  strings.ToLower(address),
+		clientIP: clientIP,
+		payload:  payload,
+		expireAt: now.Add(ttl),
+	}
+}
+
+// GetWalletVerifyResponse returns the cached payload for key when it has not
+// expired and was cached for the same client IP, so an intercepted copy of
+// the request from elsewhere gets nothing.
+func GetWalletVerifyResponse(key, clientIP string) (any, bool) {
+	if walletVerifyCacheTTL() <= 0 {
+		return nil, false
+	}
+	walletVerifyCacheMutex.Lock()
+	defer walletVerifyCacheMutex.Unlock()
+	entry, ok := walletVerifyCache[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expireAt) {
+		delete(walletVerifyCache, key)
+		return nil, false
+	}
+	if entry.clientIP != clientIP {
+		return nil, false
+	}
+	return entry.payload, true
+}
+
+// DropWalletVerifyResponse removes the cached response of key.
+func DropWalletVerifyResponse(key string) {
+	walletVerifyCacheMutex.Lock()
+	defer walletVerifyCacheMutex.Unlock()
+	delete(walletVerifyCache, key)
+}
+
+// invalidateWalletVerifyCache drops the cached responses of address.
+func invalidateWalletVerifyCache(address string) {
+	walletVerifyCacheMutex.Lock()
+	defer walletVerifyCacheMutex.Unlock()
+	for k, entry := range walletVerifyCache {
+		if entry.address == address {
+			delete(walletVerifyCache, k)
+		}
+	}
+}
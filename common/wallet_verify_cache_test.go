@@ -0,0 +1,37 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestWalletVerifyCache(t *testing.T) {
+	prevTTL := config.WalletVerifyCacheTTLSeconds
+	defer func() { config.WalletVerifyCacheTTLSeconds = prevTTL }()
+	SetWalletNonceStore(memoryNonceStore{})
+	config.WalletVerifyCacheTTLSeconds = 10
+
+	key := WalletVerifyCacheKey("0xsig", "nonce-1")
+	if key == WalletVerifyCacheKey("0xsig", "nonce-2") || len(key) != 64 {
+		t.Fatalf("WalletVerifyCacheKey = %q, want a sha256 hex digest per signature+nonce", key)
+	}
+	CacheWalletVerifyResponse(key, "0xABC", "10.0.0.1", "tokens")
+	if payload, ok := GetWalletVerifyResponse(key, "10.0.0.1"); !ok || payload != "tokens" {
+		t.Fatalf("cached response = %v, %t", payload, ok)
+	}
+	if _, ok := GetWalletVerifyResponse(key, "10.0.0.2"); ok {
+		t.Fatalf("cached response served to another client IP")
+	}
+
+	ConsumeWalletNonce("0xabc")
+	if _, ok := GetWalletVerifyResponse(key, "10.0.0.1"); ok {
+		t.Fatalf("cached response survived ConsumeWalletNonce")
+	}
+
+	config.WalletVerifyCacheTTLSeconds = 0
+	CacheWalletVerifyResponse(key, "0xabc", "10.0.0.1", "tokens")
+	if _, ok := GetWalletVerifyResponse(key, "10.0.0.1"); ok {
+		t.Fatalf("cache used with auth.verify_cache_ttl_seconds 0")
+	}
+}
@@ -1,80 +1,324 @@
 package common
 
 import (
+	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/yeying-community/router/common/config"
-	"github.com/yeying-community/router/common/random"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/common/metrics"
 )
 
-type walletNonceValue struct {
-	Nonce    string
-	Message  string
-	ExpireAt time.Time
+const (
+	WalletNonceStoreMemory = "memory"
+	WalletNonceStoreRedis  = "redis"
+)
+
+// WalletNonceEntry is a nonce issued to a wallet address and the message the
+// wallet has to sign.
+type WalletNonceEntry struct {
+	Nonce    string    `json:"nonce"`
+	Message  string    `json:"message"`
+	ChainId  string    `json:"chain_id,omitempty"`
+	IssuedAt time.Time `json:"issued_at"`
+	ExpireAt time.Time `json:"expire_at"`
+	// TTL is the lifetime the nonce was issued with, which depends on the chain.
+	TTL time.Duration `json:"ttl,omitempty"`
+}
+
+// Remaining is how long the nonce stays valid after now.
+func (e WalletNonceEntry) Remaining(now time.Time) time.Duration {
+	if remaining := e.ExpireAt.Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// NonceStore keeps at most one pending nonce per lower-case wallet address.
+type NonceStore interface {
+	// Generate records a freshly issued nonce, replacing any previous one.
+	Generate(address string, entry WalletNonceEntry) error
+	// Get returns the entry if it exists and has not expired.
+	Get(address string) (WalletNonceEntry, bool)
+	// Consume removes the entry after a successful login.
+	Consume(address string)
+	// Count, CountByChain and Stats report stored entries for monitoring.
+	Count() int
+	CountByChain(chainId string) int
+	Stats() NonceStat
 }
 
-// simple in-memory nonce store, valid for 10 minutes
 var (
-	walletNonceMutex sync.Mutex
-	walletNonceMap   = make(map[string]walletNonceValue) // key: lower-case address
-	walletNonceTTL   = 10 * time.Minute
+	walletNonceStoreMutex sync.RWMutex
+	walletNonceStore      NonceStore = memoryNonceStore{}
 )
 
-// GenerateWalletNonce creates a nonce & message and stores them for later verification
-func GenerateWalletNonce(address, messagePrefix, chainId string) (nonce string, message string) {
+// InitWalletNonceStore selects the nonce store, and the JWT revocation store
+// alongside it, from auth.nonce_store, and starts the nonce pool when
+// auth.nonce_prewarm is set. It must be called after InitRedisClient.
+func InitWalletNonceStore() error {
+	switch config.WalletNonceStore {
+	case "", WalletNonceStoreMemory:
+		SetWalletNonceStore(memoryNonceStore{})
+		SetWalletJWTRevocationStore(newMemoryRevocationStore())
+	case WalletNonceStoreRedis:
+		if err := ensureRedisClient(); err != nil {
+			return fmt.Errorf("auth.nonce_store is redis: %w", err)
+		}
+		SetWalletNonceStore(NewRedisNonceStore(RDB))
+		SetWalletJWTRevocationStore(NewRedisRevocationStore(RDB))
+		logger.SysLog("wallet nonces and JWT revocations are stored in Redis")
+	default:
+		return fmt.Errorf("unknown auth.nonce_store: %s", config.WalletNonceStore)
+	}
+	if config.WalletNoncePrewarm {
+		StartWalletNoncePrewarm(config.WalletNoncePoolSize)
+		logger.SysLogf("wallet nonce prewarm enabled, pool size %d", config.WalletNoncePoolSize)
+	} else {
+		StopWalletNoncePrewarm()
+	}
+	return nil
+}
+
+func SetWalletNonceStore(store NonceStore) {
+	walletNonceStoreMutex.Lock()
+	defer walletNonceStoreMutex.Unlock()
+	walletNonceStore = store
+}
+
+func getWalletNonceStore() NonceStore {
+	walletNonceStoreMutex.RLock()
+	defer walletNonceStoreMutex.RUnlock()
+	return walletNonceStore
+}
+
+// GenerateWalletNonce creates a nonce & message and stores them for later
+// verification. An error means the entry could not be stored, so a signature
+// over the message would not verify.
+// With config.WalletNonceIdempotent an unexpired nonce already issued to the
+// address for the same chain is returned as is, keeping its original expiry.
+func GenerateWalletNonce(address, messagePrefix, chainId string) (*WalletNonceEntry, error) {
 	addr := strings.ToLower(address)
-	nonce = random.GetUUID()
+	if config.WalletNonceIdempotent {
+		if entry, ok := getWalletNonceStore().Get(addr); ok && entry.ChainId == chainId {
+			if entry.IssuedAt.IsZero() {
+				// stored before entries recorded their issue time
+				entry.IssuedAt = entry.ExpireAt.Add(-entry.TTL)
+			}
+			return &entry, nil
+		}
+	}
+	nonce := nextWalletNonce()
 	now := time.Now()
-	message = messagePrefix + "\n" +
-		"Nonce: " + nonce + "\n" +
-		"Address: " + address + "\n" +
-		"Issued At: " + now.UTC().Format(time.RFC3339)
-	if chainId != "" {
-		message += "\nChainId: " + chainId
+	ttl := getWalletNonceTTL(chainId)
+	var message string
+	if walletNonceSIWE(address) {
+		nonce = strings.ReplaceAll(nonce, "-", "")
+		message = buildWalletSIWEMessage(address, messagePrefix, chainId, nonce, now, ttl)
+	} else {
+		message = renderWalletNonceMessage(WalletNonceMessageData{
+			Prefix:   messagePrefix,
+			Nonce:    nonce,
+			Address:  address,
+			IssuedAt: now.UTC().Format(time.RFC3339),
+			ChainId:  chainId,
+		})
 	}
 
-	walletNonceMutex.Lock()
-	defer walletNonceMutex.Unlock()
-	walletNonceMap[addr] = walletNonceValue{
+	entry := WalletNonceEntry{
 		Nonce:    nonce,
 		Message:  message,
-		ExpireAt: now.Add(getWalletNonceTTL()),
+		ChainId:  chainId,
+		IssuedAt: now,
+		ExpireAt: now.Add(ttl),
+		TTL:      ttl,
+	}
+	if err := getWalletNonceStore().Generate(addr, entry); err != nil {
+		logger.SysErrorf("store wallet nonce failed addr=%s err=%v", addr, err)
+		return nil, err
 	}
-	cleanupWalletNonces()
-	return
+	metrics.IncWalletNonceGenerated()
+	return &entry, nil
+}
+
+// WalletNonceMessageTemplate describes the message GenerateWalletNonce produces,
+// with placeholders for the per-request values.
+func WalletNonceMessageTemplate(messagePrefix string) string {
+	if config.WalletNonceFormat == WalletNonceFormatSIWE {
+		return FormatSIWEMessage(SIWEFields{
+			Domain:         WalletSIWEDomain(),
+			Address:        "{address}",
+			Statement:      messagePrefix,
+			URI:            WalletSIWEURI(),
+			Version:        "1",
+			ChainId:        "{chain_id}",
+			Nonce:          "{nonce}",
+			IssuedAt:       "{issued_at}",
+			ExpirationTime: "{expiration_time}",
+		})
+	}
+	return renderWalletNonceMessage(WalletNonceMessageData{
+		Prefix:   messagePrefix,
+		Nonce:    "{nonce}",
+		Address:  "{address}",
+		IssuedAt: "{issued_at}",
+		ChainId:  "{chain_id}",
+	})
+}
+
+// WalletNonceTTL is how long a nonce issued for chainId stays valid.
+func WalletNonceTTL(chainId ...string) time.Duration {
+	return getWalletNonceTTL(chainId...)
 }
 
-func getWalletNonceTTL() time.Duration {
+// getWalletNonceTTL prefers the auth.nonce_ttl_by_chain entry for the chain and
+// falls back to auth.nonce_ttl_minutes.
+func getWalletNonceTTL(chainId ...string) time.Duration {
+	if len(chainId) > 0 && strings.TrimSpace(chainId[0]) != "" && len(config.WalletNonceTTLByChain) > 0 {
+		if normalized, err := resolveWalletChain(chainId[0]); err == nil {
+			if minutes := config.WalletNonceTTLByChain[normalized]; minutes > 0 {
+				return time.Duration(minutes) * time.Minute
+			}
+		}
+	}
 	if config.NonceTTLMinutes <= 0 {
 		return walletNonceTTL
 	}
 	return time.Duration(config.NonceTTLMinutes) * time.Minute
 }
 
+// BuildWalletNonceTTLByChain normalizes the chain IDs or network names of
+// auth.nonce_ttl_by_chain. Every entry must resolve and have a positive TTL.
+func BuildWalletNonceTTLByChain(entries map[string]int) (map[string]int, error) {
+	ttls := make(map[string]int, len(entries))
+	for chainId, minutes := range entries {
+		normalized, err := resolveWalletChain(chainId)
+		if err != nil {
+			return nil, fmt.Errorf("chain %q: %w", chainId, err)
+		}
+		if minutes <= 0 {
+			return nil, fmt.Errorf("chain %q: ttl must be positive, got %d", chainId, minutes)
+		}
+		ttls[normalized] = minutes
+	}
+	return ttls, nil
+}
+
 // GetWalletNonce returns stored nonce entry if valid
-func GetWalletNonce(address string) (walletNonceValue, bool) {
+func GetWalletNonce(address string) (WalletNonceEntry, bool) {
+	return getWalletNonceStore().Get(strings.ToLower(address))
+}
+
+// GetWalletNonceCount returns the number of entries in the nonce store,
+// including expired ones not yet cleaned up.
+func GetWalletNonceCount() int {
+	return getWalletNonceStore().Count()
+}
+
+// GetWalletNonceCountByChain counts stored nonces issued for chainId.
+func GetWalletNonceCountByChain(chainId string) int {
+	return getWalletNonceStore().CountByChain(chainId)
+}
+
+// ConsumeWalletNonce removes a nonce (used after successful auth) and any
+// cached verify response of the address.
+func ConsumeWalletNonce(address string) {
+	addr := strings.ToLower(address)
+	getWalletNonceStore().Consume(addr)
+	invalidateWalletVerifyCache(addr)
+}
+
+// walletNonceHasChain matches the recorded chain id, falling back to the
+// ChainId line for entries stored before it was recorded.
+func walletNonceHasChain(entry WalletNonceEntry, chainId string) bool {
+	if entry.ChainId != "" {
+		return entry.ChainId == chainId
+	}
+	line := "ChainId: " + chainId
+	for _, l := range strings.Split(entry.Message, "\n") {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+// simple in-memory nonce store, valid for 10 minutes
+var (
+	walletNonceMutex sync.RWMutex
+	walletNonceMap   = make(map[string]WalletNonceEntry) // key: lower-case address
+	walletNonceTTL   = 10 * time.Minute
+)
+
+// memoryNonceStore is the process-local default. Nonces are lost on restart and
+// are not shared between instances.
+type memoryNonceStore struct{}
+
+func (memoryNonceStore) Generate(address string, entry WalletNonceEntry) error {
 	walletNonceMutex.Lock()
-	defer walletNonceMutex.Unlock()
-	entry, ok := walletNonceMap[strings.ToLower(address)]
+	walletNonceMap[address] = entry
+	walletNonceMutex.Unlock()
+	return nil
+}
+
+func (memoryNonceStore) Get(address string) (WalletNonceEntry, bool) {
+	walletNonceMutex.RLock()
+	defer walletNonceMutex.RUnlock()
+	entry, ok := walletNonceMap[address]
 	if !ok || time.Now().After(entry.ExpireAt) {
-		return walletNonceValue{}, false
+		return WalletNonceEntry{}, false
 	}
 	return entry, true
 }
 
-// ConsumeWalletNonce removes a nonce (used after successful auth)
-func ConsumeWalletNonce(address string) {
+func (memoryNonceStore) Consume(address string) {
 	walletNonceMutex.Lock()
 	defer walletNonceMutex.Unlock()
-	delete(walletNonceMap, strings.ToLower(address))
+	delete(walletNonceMap, address)
+}
+
+func (memoryNonceStore) Count() int {
+	walletNonceMutex.RLock()
+	defer walletNonceMutex.RUnlock()
+	return len(walletNonceMap)
+}
+
+func (memoryNonceStore) CountByChain(chainId string) int {
+	walletNonceMutex.RLock()
+	defer walletNonceMutex.RUnlock()
+	count := 0
+	for _, entry := range walletNonceMap {
+		if walletNonceHasChain(entry, chainId) {
+			count++
+		}
+	}
+	return count
 }
 
+// cleanupWalletNonces collects expired keys under the read lock and only takes
+// the write lock to delete them, so nonce generation isn't blocked by the scan.
+// It runs from the sweeper started by StartWalletNonceCleanup.
 func cleanupWalletNonces() {
 	now := time.Now()
+	expired := make([]string, 0)
+	walletNonceMutex.RLock()
 	for addr, entry := range walletNonceMap {
 		if now.After(entry.ExpireAt) {
+			expired = append(expired, addr)
+		}
+	}
+	walletNonceMutex.RUnlock()
+	if len(expired) == 0 {
+		return
+	}
+
+	walletNonceMutex.Lock()
+	defer walletNonceMutex.Unlock()
+	for _, addr := range expired {
+		// the entry may have been replaced with a fresh nonce since the scan
+		if entry, ok := walletNonceMap[addr]; ok && now.After(entry.ExpireAt) {
 			delete(walletNonceMap, addr)
 		}
 	}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+)
+
+// WalletNonceCleanupInterval returns auth.nonce_cleanup_interval_minutes as a
+// duration, defaulting to 5 minutes.
+func WalletNonceCleanupInterval() time.Duration {
+	if config.WalletNonceCleanupIntervalMinutes <= 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(config.WalletNonceCleanupIntervalMinutes) * time.Minute
+}
+
+// StartWalletNonceCleanup sweeps expired entries from the in-memory nonce store
+// every interval until ctx is cancelled. The Redis store expires keys itself,
+// so the sweep only has work to do with the memory store.
+func StartWalletNonceCleanup(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = WalletNonceCleanupInterval()
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logger.SysLog("wallet nonce cleanup stopped")
+				return
+			case <-ticker.C:
+				cleanupWalletNonces()
+			}
+		}
+	}()
+}
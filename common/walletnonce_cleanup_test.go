@@ -0,0 +1,39 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStartWalletNonceCleanup_RemovesExpiredOnTick(t *testing.T) {
+	walletNonceMutex.Lock()
+	prev := walletNonceMap
+	walletNonceMap = make(map[string]WalletNonceEntry)
+	for i := 0; i < 10; i++ {
+		walletNonceMap[fmt.Sprintf("0xexpired%d", i)] = WalletNonceEntry{ExpireAt: time.Now().Add(-time.Minute)}
+	}
+	walletNonceMap["0xlive"] = WalletNonceEntry{ExpireAt: time.Now().Add(time.Hour)}
+	walletNonceMutex.Unlock()
+	defer func() {
+		walletNonceMutex.Lock()
+		walletNonceMap = prev
+		walletNonceMutex.Unlock()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	StartWalletNonceCleanup(ctx, 10*time.Millisecond)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for GetWalletNonceCount() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("GetWalletNonceCount() = %d after sweeps, want 1", GetWalletNonceCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, ok := GetWalletNonce("0xlive"); !ok {
+		t.Fatalf("unexpired nonce was swept")
+	}
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yeying-community/router/common/random"
+)
+
+const walletNonceProbePrefix = "health-probe:"
+
+// CheckWalletNonceStore writes, reads back and deletes a probe entry in the
+// configured nonce store, so a Redis-backed store is checked end to end.
+func CheckWalletNonceStore() error {
+	store := getWalletNonceStore()
+	address := walletNonceProbePrefix + random.GetUUID()
+	nonce := random.GetUUID()
+	entry := WalletNonceEntry{
+		Nonce:    nonce,
+		ExpireAt: time.Now().Add(30 * time.Second),
+		TTL:      30 * time.Second,
+	}
+	if err := store.Generate(address, entry); err != nil {
+		return err
+	}
+	got, ok := store.Get(address)
+	store.Consume(address)
+	if !ok || got.Nonce != nonce {
+		return errors.New("nonce store probe not readable")
+	}
+	if _, ok := store.Get(address); ok {
+		return errors.New("nonce store probe not deleted")
+	}
+	return nil
+}
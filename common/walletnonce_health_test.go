@@ -0,0 +1,14 @@
+package common
+
+import "testing"
+
+func TestCheckWalletNonceStore(t *testing.T) {
+	SetWalletNonceStore(memoryNonceStore{})
+	before := getWalletNonceStore().Count()
+	if err := CheckWalletNonceStore(); err != nil {
+		t.Fatalf("CheckWalletNonceStore error: %v", err)
+	}
+	if after := getWalletNonceStore().Count(); after != before {
+		t.Fatalf("probe left entries behind: before=%d after=%d", before, after)
+	}
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/yeying-community/router/common/logger"
+)
+
+// WalletNonceMessageData holds the fields available to
+// auth.nonce_message_template.
+type WalletNonceMessageData struct {
+	Prefix   string
+	Nonce    string
+	Address  string
+	IssuedAt string
+	ChainId  string
+}
+
+var (
+	walletNonceMessageMutex    sync.RWMutex
+	walletNonceMessageTemplate *template.Template
+)
+
+// SetWalletNonceMessageTemplate validates and installs a text/template for nonce
+// messages; an empty string restores the built-in format. The template must
+// render the nonce, otherwise signatures could not be tied to it.
+func SetWalletNonceMessageTemplate(text string) error {
+	var tpl *template.Template
+	if strings.TrimSpace(text) != "" {
+		parsed, err := template.New("wallet_nonce_message").Option("missingkey=error").Parse(text)
+		if err != nil {
+			return err
+		}
+		const sentinel = "nonce-sentinel-3f1c"
+		var out strings.Builder
+		if err := parsed.Execute(&out, WalletNonceMessageData{Nonce: sentinel}); err != nil {
+			return err
+		}
+		if !strings.Contains(out.String(), sentinel) {
+			return errors.New("template must include {{.Nonce}}")
+		}
+		tpl = parsed
+	}
+	walletNonceMessageMutex.Lock()
+	defer walletNonceMessageMutex.Unlock()
+	walletNonceMessageTemplate = tpl
+	return nil
+}
+
+func renderWalletNonceMessage(data WalletNonceMessageData) string {
+	walletNonceMessageMutex.RLock()
+	tpl := walletNonceMessageTemplate
+	walletNonceMessageMutex.RUnlock()
+	if tpl != nil {
+		var out strings.Builder
+		err := tpl.Execute(&out, data)
+		if err == nil {
+			return out.String()
+		}
+		logger.SysErrorf("render wallet nonce message template failed, using default: %v", err)
+	}
+	message := fmt.Sprintf("%s\nNonce: %s\nAddress: %s\nIssued At: %s", data.Prefix, data.Nonce, data.Address, data.IssuedAt)
+	if data.ChainId != "" {
+		message += "\nChainId: " + data.ChainId
+	}
+	return message
+}
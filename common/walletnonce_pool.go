@@ -0,0 +1,92 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/yeying-community/router/common/random"
+)
+
+const defaultWalletNoncePoolSize = 64
+
+// walletNoncePool hands out nonces generated ahead of time by a background
+// goroutine, so issuing a nonce doesn't pay for the UUID inline. The refill
+// goroutine blocks on the buffered channel while the pool is full.
+type walletNoncePool struct {
+	ch   chan string
+	stop chan struct{}
+}
+
+var (
+	walletNoncePoolMutex  sync.RWMutex
+	walletNoncePoolActive *walletNoncePool
+)
+
+func newWalletNoncePool(size int) *walletNoncePool {
+	if size <= 0 {
+		size = defaultWalletNoncePoolSize
+	}
+	return &walletNoncePool{
+		ch:   make(chan string, size),
+		stop: make(chan struct{}),
+	}
+}
+
+func (p *walletNoncePool) start() {
+	go func() {
+		for {
+			nonce := random.GetUUID()
+			select {
+			case p.ch <- nonce:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// take returns a pooled nonce, or generates one synchronously when the pool
+// has been drained faster than it refills.
+func (p *walletNoncePool) take() string {
+	select {
+	case nonce := <-p.ch:
+		return nonce
+	default:
+		return random.GetUUID()
+	}
+}
+
+// StartWalletNoncePrewarm replaces the active nonce pool with one of the given
+// size; size <= 0 uses the default of 64.
+func StartWalletNoncePrewarm(size int) {
+	pool := newWalletNoncePool(size)
+	pool.start()
+	walletNoncePoolMutex.Lock()
+	previous := walletNoncePoolActive
+	walletNoncePoolActive = pool
+	walletNoncePoolMutex.Unlock()
+	if previous != nil {
+		close(previous.stop)
+	}
+}
+
+// StopWalletNoncePrewarm stops the refill goroutine; nonces are generated
+// inline again.
+func StopWalletNoncePrewarm() {
+	walletNoncePoolMutex.Lock()
+	previous := walletNoncePoolActive
+	walletNoncePoolActive = nil
+	walletNoncePoolMutex.Unlock()
+	if previous != nil {
+		close(previous.stop)
+	}
+}
+
+func nextWalletNonce() string {
+	walletNoncePoolMutex.RLock()
+	pool := walletNoncePoolActive
+	walletNoncePoolMutex.RUnlock()
+	if pool == nil {
+		return random.GetUUID()
+	}
+	return pool.take()
+}
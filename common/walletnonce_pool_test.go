@@ -0,0 +1,50 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWalletNoncePool_ExhaustedFallsBackToInline(t *testing.T) {
+	// never started, so the pool is empty and every take has to fall back
+	pool := newWalletNoncePool(2)
+	seen := make(map[string]struct{})
+	for i := 0; i < 5; i++ {
+		nonce := pool.take()
+		if nonce == "" {
+			t.Fatalf("take #%d returned empty nonce", i+1)
+		}
+		if _, dup := seen[nonce]; dup {
+			t.Fatalf("take #%d returned duplicate nonce %q", i+1, nonce)
+		}
+		seen[nonce] = struct{}{}
+	}
+}
+
+func TestWalletNoncePool_Refills(t *testing.T) {
+	StartWalletNoncePrewarm(4)
+	defer StopWalletNoncePrewarm()
+
+	deadline := time.Now().Add(time.Second)
+	for len(walletNoncePoolActive.ch) < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("pool not filled, len=%d", len(walletNoncePoolActive.ch))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	address := "0x00000000000000000000000000000000000000cc"
+	defer ConsumeWalletNonce(address)
+	seen := make(map[string]struct{})
+	// drain well past the pool size to exercise the inline fallback
+	for i := 0; i < 20; i++ {
+		nonce := mustGenerateWalletNonce(t, address, "Login", "").Nonce
+		if nonce == "" {
+			t.Fatalf("nonce #%d empty", i+1)
+		}
+		if _, dup := seen[nonce]; dup {
+			t.Fatalf("nonce #%d duplicated", i+1)
+		}
+		seen[nonce] = struct{}{}
+	}
+}
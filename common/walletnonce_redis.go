@@ -0,0 +1,113 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/yeying-community/router/common/logger"
+)
+
+const (
+	walletNonceRedisPrefix  = "router:nonce:"
+	walletNonceRedisTimeout = 3 * time.Second
+)
+
+// RedisNonceStore shares nonces between router instances. Entries expire via
+// the Redis key TTL, so no cleanup is needed.
+type RedisNonceStore struct {
+	client redis.Cmdable
+}
+
+func NewRedisNonceStore(client redis.Cmdable) *RedisNonceStore {
+	return &RedisNonceStore{client: client}
+}
+
+func walletNonceRedisKey(address string) string {
+	return walletNonceRedisPrefix + address
+}
+
+func (s *RedisNonceStore) Generate(address string, entry WalletNonceEntry) error {
+	ttl := time.Until(entry.ExpireAt)
+	if ttl <= 0 {
+		return nil
+	}
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), walletNonceRedisTimeout)
+	defer cancel()
+	return s.client.Set(ctx, walletNonceRedisKey(address), payload, ttl).Err()
+}
+
+func (s *RedisNonceStore) Get(address string) (WalletNonceEntry, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), walletNonceRedisTimeout)
+	defer cancel()
+	raw, err := s.client.Get(ctx, walletNonceRedisKey(address)).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			logger.SysErrorf("get wallet nonce from redis failed addr=%s err=%v", address, err)
+		}
+		return WalletNonceEntry{}, false
+	}
+	var entry WalletNonceEntry
+	if err := json.Unmarshal(raw, &entry); err != nil || time.Now().After(entry.ExpireAt) {
+		return WalletNonceEntry{}, false
+	}
+	return entry, true
+}
+
+func (s *RedisNonceStore) Consume(address string) {
+	ctx, cancel := context.WithTimeout(context.Background(), walletNonceRedisTimeout)
+	defer cancel()
+	if err := s.client.Del(ctx, walletNonceRedisKey(address)).Err(); err != nil {
+		logger.SysErrorf("delete wallet nonce from redis failed addr=%s err=%v", address, err)
+	}
+}
+
+func (s *RedisNonceStore) Count() int {
+	count := 0
+	s.scan(func(string) { count++ })
+	return count
+}
+
+func (s *RedisNonceStore) CountByChain(chainId string) int {
+	count := 0
+	s.scan(func(key string) {
+		ctx, cancel := context.WithTimeout(context.Background(), walletNonceRedisTimeout)
+		defer cancel()
+		raw, err := s.client.Get(ctx, key).Bytes()
+		if err != nil {
+			return
+		}
+		var entry WalletNonceEntry
+		if json.Unmarshal(raw, &entry) == nil && walletNonceHasChain(entry, chainId) {
+			count++
+		}
+	})
+	return count
+}
+
+// scan walks the nonce keyspace with SCAN so monitoring never blocks Redis.
+func (s *RedisNonceStore) scan(fn func(key string)) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*walletNonceRedisTimeout)
+	defer cancel()
+	var cursor uint64
+	for {
+		keys, next, err := s.client.Scan(ctx, cursor, walletNonceRedisPrefix+"*", 1000).Result()
+		if err != nil {
+			logger.SysErrorf("scan wallet nonces in redis failed err=%v", err)
+			return
+		}
+		for _, key := range keys {
+			fn(key)
+		}
+		if next == 0 {
+			return
+		}
+		cursor = next
+	}
+}
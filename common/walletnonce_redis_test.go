@@ -0,0 +1,71 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// TestRedisNonceStore_ConcurrentNodes simulates several router instances, each
+// with its own Redis client, issuing and reading nonces for the same addresses.
+// It needs a disposable Redis: REDIS_TEST_URL=redis://localhost:6379/15.
+func TestRedisNonceStore_ConcurrentNodes(t *testing.T) {
+	url := os.Getenv("REDIS_TEST_URL")
+	if url == "" {
+		t.Skip("REDIS_TEST_URL not set")
+	}
+	opt, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("parse REDIS_TEST_URL: %v", err)
+	}
+	const nodes = 4
+	stores := make([]*RedisNonceStore, nodes)
+	for i := range stores {
+		client := redis.NewClient(opt)
+		defer client.Close()
+		if err := client.Ping(context.Background()).Err(); err != nil {
+			t.Skipf("redis unavailable: %v", err)
+		}
+		stores[i] = NewRedisNonceStore(client)
+	}
+
+	const addresses = 50
+	var wg sync.WaitGroup
+	for n := range stores {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for i := 0; i < addresses; i++ {
+				entry := WalletNonceEntry{Nonce: fmt.Sprintf("node%d-%d", n, i), Message: "ChainId: 1", ExpireAt: time.Now().Add(time.Minute)}
+				if err := stores[n].Generate(fmt.Sprintf("0xtest%040d", i), entry); err != nil {
+					t.Errorf("Generate: %v", err)
+				}
+			}
+		}(n)
+	}
+	wg.Wait()
+
+	for i := 0; i < addresses; i++ {
+		address := fmt.Sprintf("0xtest%040d", i)
+		// every node must see the same last-write-wins nonce
+		first, ok := stores[0].Get(address)
+		if !ok {
+			t.Fatalf("node 0 missing nonce for %s", address)
+		}
+		for n := 1; n < nodes; n++ {
+			entry, ok := stores[n].Get(address)
+			if !ok || entry.Nonce != first.Nonce {
+				t.Fatalf("node %d nonce = %q, %t; node 0 = %q", n, entry.Nonce, ok, first.Nonce)
+			}
+		}
+		stores[nodes-1].Consume(address)
+		if _, ok := stores[0].Get(address); ok {
+			t.Fatalf("nonce for %s still visible after Consume on another node", address)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestGenerateWalletNonce_SIWE(t *testing.T) {
+	prevFormat, prevDomain, prevURI := config.WalletNonceFormat, config.WalletSIWEDomain, config.WalletSIWEURI
+	config.WalletNonceFormat = WalletNonceFormatSIWE
+	config.WalletSIWEDomain = ""
+	config.WalletSIWEURI = "https://app.example.com/login"
+	defer func() {
+		config.WalletNonceFormat, config.WalletSIWEDomain, config.WalletSIWEURI = prevFormat, prevDomain, prevURI
+	}()
+
+	const address = "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+	entry := mustGenerateWalletNonce(t, address, "Login to Router", "0x89")
+	nonce, message := entry.Nonce, entry.Message
+	defer ConsumeWalletNonce(address)
+	if strings.Contains(nonce, "-") || len(nonce) < 8 {
+		t.Fatalf("SIWE nonce = %q, want alphanumeric", nonce)
+	}
+	lines := strings.Split(message, "\n")
+	want := []string{
+		"app.example.com wants you to sign in with your Ethereum account:",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"",
+		"Login to Router",
+		"",
+		"URI: https://app.example.com/login",
+		"Version: 1",
+		"Chain ID: 137",
+		"Nonce: " + nonce,
+	}
+	if len(lines) != len(want)+2 {
+		t.Fatalf("SIWE message has %d lines:\n%s", len(lines), message)
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Fatalf("line %d = %q, want %q", i+1, lines[i], line)
+		}
+	}
+	if !strings.HasPrefix(lines[9], "Issued At: ") || !strings.HasPrefix(lines[10], "Expiration Time: ") {
+		t.Fatalf("SIWE timestamps = %q, %q", lines[9], lines[10])
+	}
+
+	// Solana addresses keep the default format
+	solana := "7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV"
+	message = mustGenerateWalletNonce(t, solana, "Login to Router", "").Message
+	defer ConsumeWalletNonce(solana)
+	if strings.Contains(message, SIWEHeaderSuffix) {
+		t.Fatalf("non-Ethereum address got a SIWE message:\n%s", message)
+	}
+}
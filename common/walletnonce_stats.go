@@ -0,0 +1,64 @@
+package common
+
+import (
+	"context"
+	"time"
+)
+
+// NonceStat describes the nonce store for monitoring. Expiries cover the
+// active entries only and are zero when there are none.
+type NonceStat struct {
+	ActiveCount  int       `json:"active_count"`
+	ExpiredCount int       `json:"expired_count"`
+	OldestExpiry time.Time `json:"oldest_expiry"`
+	NewestExpiry time.Time `json:"newest_expiry"`
+}
+
+func (s *NonceStat) addActive(expireAt time.Time) {
+	s.ActiveCount++
+	if s.OldestExpiry.IsZero() || expireAt.Before(s.OldestExpiry) {
+		s.OldestExpiry = expireAt
+	}
+	if expireAt.After(s.NewestExpiry) {
+		s.NewestExpiry = expireAt
+	}
+}
+
+// WalletNonceStats reports the size and expiry range of the nonce store.
+func WalletNonceStats() NonceStat {
+	return getWalletNonceStore().Stats()
+}
+
+// Stats counts entries past their expiry that the sweeper has not removed yet
+// as expired.
+func (memoryNonceStore) Stats() NonceStat {
+	now := time.Now()
+	var stat NonceStat
+	walletNonceMutex.RLock()
+	defer walletNonceMutex.RUnlock()
+	for _, entry := range walletNonceMap {
+		if now.After(entry.ExpireAt) {
+			stat.ExpiredCount++
+			continue
+		}
+		stat.addActive(entry.ExpireAt)
+	}
+	return stat
+}
+
+// Stats derives the active count from SCAN and the expiries from the key TTLs.
+// Redis drops keys when they expire, so ExpiredCount is always zero.
+func (s *RedisNonceStore) Stats() NonceStat {
+	var stat NonceStat
+	s.scan(func(key string) {
+		ctx, cancel := context.WithTimeout(context.Background(), walletNonceRedisTimeout)
+		defer cancel()
+		ttl, err := s.client.PTTL(ctx, key).Result()
+		if err != nil || ttl <= 0 {
+			// gone since the scan, or stored without a TTL
+			return
+		}
+		stat.addActive(time.Now().Add(ttl))
+	})
+	return stat
+}
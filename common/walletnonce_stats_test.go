@@ -0,0 +1,39 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWalletNonceStats(t *testing.T) {
+	SetWalletNonceStore(memoryNonceStore{})
+	walletNonceMutex.Lock()
+	prev := walletNonceMap
+	walletNonceMap = make(map[string]WalletNonceEntry)
+	walletNonceMap["0xexpired"] = WalletNonceEntry{ExpireAt: time.Now().Add(-time.Minute)}
+	walletNonceMutex.Unlock()
+	defer func() {
+		walletNonceMutex.Lock()
+		walletNonceMap = prev
+		walletNonceMutex.Unlock()
+	}()
+
+	if stat := WalletNonceStats(); stat.ActiveCount != 0 || stat.ExpiredCount != 1 || !stat.OldestExpiry.IsZero() {
+		t.Fatalf("stats with one expired entry = %+v", stat)
+	}
+
+	before := time.Now()
+	for i := 0; i < 3; i++ {
+		GenerateWalletNonce(fmt.Sprintf("0x%040d", i), "Login to Router", "1")
+	}
+	after := time.Now()
+	stat := WalletNonceStats()
+	if stat.ActiveCount != 3 || stat.ExpiredCount != 1 {
+		t.Fatalf("stats = %+v, want 3 active and 1 expired", stat)
+	}
+	ttl := getWalletNonceTTL("1")
+	if stat.OldestExpiry.Before(before.Add(ttl)) || stat.NewestExpiry.After(after.Add(ttl)) || stat.NewestExpiry.Before(stat.OldestExpiry) {
+		t.Fatalf("expiries %s..%s outside %s..%s", stat.OldestExpiry, stat.NewestExpiry, before.Add(ttl), after.Add(ttl))
+	}
+}
@@ -0,0 +1,209 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestWalletNonce_ConcurrentGenerateAndCleanup(t *testing.T) {
+	walletNonceMutex.Lock()
+	prev := walletNonceMap
+	walletNonceMap = make(map[string]WalletNonceEntry)
+	// seed expired entries so cleanup has work to do while generators run
+	for i := 0; i < 50; i++ {
+		walletNonceMap[fmt.Sprintf("0xexpired%d", i)] = WalletNonceEntry{ExpireAt: time.Now().Add(-time.Minute)}
+	}
+	walletNonceMutex.Unlock()
+	defer func() {
+		walletNonceMutex.Lock()
+		walletNonceMap = prev
+		walletNonceMutex.Unlock()
+	}()
+
+	const workers = 100
+	var wg sync.WaitGroup
+	wg.Add(workers * 2)
+	for i := 0; i < workers; i++ {
+		address := fmt.Sprintf("0x%040d", i)
+		go func() {
+			defer wg.Done()
+			issued, err := GenerateWalletNonce(address, "Login to Router", "1")
+			if err != nil {
+				t.Errorf("GenerateWalletNonce(%s) error: %v", address, err)
+				return
+			}
+			entry, ok := GetWalletNonce(address)
+			if !ok || entry.Nonce != issued.Nonce {
+				t.Errorf("GetWalletNonce(%s) = %q, %t; want %q", address, entry.Nonce, ok, issued.Nonce)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			cleanupWalletNonces()
+		}()
+	}
+	wg.Wait()
+
+	walletNonceMutex.RLock()
+	defer walletNonceMutex.RUnlock()
+	if len(walletNonceMap) != workers {
+		t.Fatalf("walletNonceMap has %d entries, want %d", len(walletNonceMap), workers)
+	}
+}
+
+func TestGetWalletNonceCount_AfterCleanup(t *testing.T) {
+	walletNonceMutex.Lock()
+	prev := walletNonceMap
+	walletNonceMap = map[string]WalletNonceEntry{
+		"0xexpired": {ExpireAt: time.Now().Add(-time.Minute)},
+	}
+	walletNonceMutex.Unlock()
+	defer func() {
+		walletNonceMutex.Lock()
+		walletNonceMap = prev
+		walletNonceMutex.Unlock()
+	}()
+
+	for i := 0; i < 5; i++ {
+		chainId := "1"
+		if i%2 == 1 {
+			chainId = "11"
+		}
+		GenerateWalletNonce(fmt.Sprintf("0x%040d", i), "Login to Router", chainId)
+	}
+	cleanupWalletNonces()
+
+	if got := GetWalletNonceCount(); got != 5 {
+		t.Fatalf("GetWalletNonceCount() = %d, want 5", got)
+	}
+	if got := GetWalletNonceCountByChain("1"); got != 3 {
+		t.Fatalf("GetWalletNonceCountByChain(1) = %d, want 3", got)
+	}
+	if got := GetWalletNonceCountByChain("11"); got != 2 {
+		t.Fatalf("GetWalletNonceCountByChain(11) = %d, want 2", got)
+	}
+}
+
+func TestWalletNonceMessageTemplate(t *testing.T) {
+	defer func() { _ = SetWalletNonceMessageTemplate("") }()
+
+	for _, invalid := range []string{"{{.Nonce", "Sign in as {{.Address}}", "{{.Missing}} {{.Nonce}}"} {
+		if err := SetWalletNonceMessageTemplate(invalid); err == nil {
+			t.Fatalf("SetWalletNonceMessageTemplate(%q) = nil, want error", invalid)
+		}
+	}
+
+	if err := SetWalletNonceMessageTemplate("{{.Prefix}}|{{.Address}}|{{.Nonce}}|{{.ChainId}}"); err != nil {
+		t.Fatalf("SetWalletNonceMessageTemplate error: %v", err)
+	}
+	address := "0x00000000000000000000000000000000000000aa"
+	entry := mustGenerateWalletNonce(t, address, "Login to Router", "1")
+	defer ConsumeWalletNonce(address)
+	if want := "Login to Router|" + address + "|" + entry.Nonce + "|1"; entry.Message != want {
+		t.Fatalf("message = %q, want %q", entry.Message, want)
+	}
+	if GetWalletNonceCountByChain("1") < 1 {
+		t.Fatalf("custom-format nonce not counted for its chain")
+	}
+
+	_ = SetWalletNonceMessageTemplate("")
+	if message := mustGenerateWalletNonce(t, address, "Login to Router", "").Message; !strings.HasPrefix(message, "Login to Router\nNonce: ") {
+		t.Fatalf("default message = %q", message)
+	}
+}
+
+func TestGenerateWalletNonce_Idempotent(t *testing.T) {
+	prev := config.WalletNonceIdempotent
+	defer func() { config.WalletNonceIdempotent = prev }()
+	address := "0x00000000000000000000000000000000000000ee"
+	defer ConsumeWalletNonce(address)
+
+	config.WalletNonceIdempotent = true
+	first := mustGenerateWalletNonce(t, address, "Login to Router", "1")
+	second := mustGenerateWalletNonce(t, address, "Login to Router", "1")
+	if *second != *first {
+		t.Fatalf("second nonce = %+v, want the pending %+v", second, first)
+	}
+	if other := mustGenerateWalletNonce(t, address, "Login to Router", "56"); other.Nonce == first.Nonce {
+		t.Fatalf("nonce for another chain reused %q", first.Nonce)
+	}
+
+	config.WalletNonceIdempotent = false
+	first = mustGenerateWalletNonce(t, address, "Login to Router", "1")
+	if second = mustGenerateWalletNonce(t, address, "Login to Router", "1"); second.Nonce == first.Nonce {
+		t.Fatalf("nonce %q reused with idempotency disabled", first.Nonce)
+	}
+}
+
+func TestGenerateWalletNonce_TTLByChain(t *testing.T) {
+	prevTTL, prevByChain := config.NonceTTLMinutes, config.WalletNonceTTLByChain
+	defer func() { config.NonceTTLMinutes, config.WalletNonceTTLByChain = prevTTL, prevByChain }()
+	config.NonceTTLMinutes = 10
+
+	byChain, err := BuildWalletNonceTTLByChain(map[string]int{"polygon": 15, "0x1": 5})
+	if err != nil {
+		t.Fatalf("BuildWalletNonceTTLByChain error: %v", err)
+	}
+	if byChain["137"] != 15 || byChain["1"] != 5 {
+		t.Fatalf("BuildWalletNonceTTLByChain = %v", byChain)
+	}
+	for _, bad := range []map[string]int{{"nope": 5}, {"137": 0}} {
+		if _, err := BuildWalletNonceTTLByChain(bad); err == nil {
+			t.Fatalf("BuildWalletNonceTTLByChain(%v) accepted", bad)
+		}
+	}
+	config.WalletNonceTTLByChain = byChain
+
+	cases := map[string]time.Duration{"137": 15 * time.Minute, "0x89": 15 * time.Minute, "56": 10 * time.Minute, "": 10 * time.Minute}
+	for chainId, want := range cases {
+		if got := WalletNonceTTL(chainId); got != want {
+			t.Fatalf("WalletNonceTTL(%q) = %v, want %v", chainId, got, want)
+		}
+	}
+
+	address := "0x00000000000000000000000000000000000000ef"
+	defer ConsumeWalletNonce(address)
+	GenerateWalletNonce(address, "Login to Router", "137")
+	entry, ok := GetWalletNonce(address)
+	if !ok || entry.TTL != 15*time.Minute {
+		t.Fatalf("stored entry ttl = %v, want 15m", entry.TTL)
+	}
+	if remaining := entry.Remaining(time.Now()); remaining <= 14*time.Minute || remaining > 15*time.Minute {
+		t.Fatalf("Remaining = %v", remaining)
+	}
+	if entry.Remaining(entry.ExpireAt.Add(time.Second)) != 0 {
+		t.Fatalf("Remaining after expiry should be 0")
+	}
+}
+
+func mustGenerateWalletNonce(t *testing.T, address, messagePrefix, chainId string) *WalletNonceEntry {
+	t.Helper()
+	entry, err := GenerateWalletNonce(address, messagePrefix, chainId)
+	if err != nil {
+		t.Fatalf("GenerateWalletNonce(%s) error: %v", address, err)
+	}
+	return entry
+}
+
+func TestGenerateWalletNonce_Entry(t *testing.T) {
+	SetWalletNonceStore(memoryNonceStore{})
+	address := "0x00000000000000000000000000000000000000ef"
+	defer ConsumeWalletNonce(address)
+	before := time.Now()
+	entry := mustGenerateWalletNonce(t, address, "Login to Router", "1")
+	if entry.Nonce == "" || !strings.Contains(entry.Message, entry.Nonce) || entry.ChainId != "1" {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if entry.IssuedAt.Before(before) || !entry.ExpireAt.Equal(entry.IssuedAt.Add(entry.TTL)) {
+		t.Fatalf("issued %s, expires %s, ttl %s", entry.IssuedAt, entry.ExpireAt, entry.TTL)
+	}
+	stored, ok := GetWalletNonce(address)
+	if !ok || stored.Nonce != entry.Nonce || !stored.IssuedAt.Equal(entry.IssuedAt) {
+		t.Fatalf("stored = %+v, want %+v", stored, entry)
+	}
+}
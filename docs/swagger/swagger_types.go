@@ -37,12 +37,45 @@ type WalletChallengeRequest struct {
 	ChainID string `json:"chain_id,omitempty" example:"1"`
 }
 
+type WalletNonceRequest struct {
+	Address    string `json:"address" example:"0x1111111111111111111111111111111111111111"`
+	ChainID    string `json:"chain_id,omitempty" example:"1"`
+	WalletType string `json:"wallet_type,omitempty" example:"ethereum"`
+}
+
 type WalletLoginRequest struct {
 	Address   string `json:"address" example:"0x1111111111111111111111111111111111111111"`
 	Signature string `json:"signature" example:"0xabcdef..."`
 	Nonce     string `json:"nonce,omitempty" example:"abc123"`
 	ChainID   string `json:"chain_id,omitempty" example:"1"`
 	Message   string `json:"message,omitempty" example:"Sign in to Router"`
+	SignType  string `json:"sign_type,omitempty" example:"personal"`
+	// WalletType is ethereum (default) or solana
+	WalletType string `json:"wallet_type,omitempty" example:"ethereum"`
+}
+
+type WalletBindInitRequest struct {
+	WalletLoginRequest
+	Email string `json:"email,omitempty" example:"user@example.com"`
+}
+
+type WalletBindCompleteRequest struct {
+	BindingToken string `json:"binding_token" example:"eyJhbGciOi..."`
+	Username     string `json:"username" example:"alice"`
+	Password     string `json:"password" example:"password123"`
+}
+
+type WalletRefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" example:"9b1f0c..."`
+}
+
+type WalletRevokeRequest struct {
+	Token string `json:"token,omitempty" example:"eyJhbGciOi..."`
+	JTI   string `json:"jti,omitempty" example:"3f9c1a2e-7b4d-4c11-9a0e-5d2f8b6c1e44"`
+}
+
+type WalletUnbindRequest struct {
+	Address string `json:"address" example:"0x1111111111111111111111111111111111111111"`
 }
 
 type OptionUpdateRequest struct {
@@ -413,19 +446,20 @@ type ChannelCreateRequest struct {
 }
 
 type ChannelUpdateRequest struct {
-	ID           string                      `json:"id" example:"openai-main"`
-	Protocol     string                      `json:"protocol,omitempty" example:"openai"`
-	Key          string                      `json:"key,omitempty" example:"sk-***"`
-	Status       int                         `json:"status,omitempty" example:"1"`
-	Name         string                      `json:"name,omitempty" example:"OpenAI Main"`
-	Weight       int                         `json:"weight,omitempty" example:"0"`
-	BaseURL      string                      `json:"base_url,omitempty" example:"https://api.openai.com/v1"`
-	Models       string                      `json:"models,omitempty" example:"gpt-4o-mini,gpt-4o"`
-	ModelConfigs []ChannelModelConfigRequest `json:"model_configs,omitempty"`
-	Priority     int64                       `json:"priority,omitempty" example:"0"`
-	Config       string                      `json:"config,omitempty" example:"{}"`
-	SystemPrompt string                      `json:"system_prompt,omitempty" example:""`
-	TestModel    string                      `json:"test_model,omitempty" example:"gpt-4o-mini"`
+	ID             string                      `json:"id" example:"openai-main"`
+	Protocol       string                      `json:"protocol,omitempty" example:"openai"`
+	Key            string                      `json:"key,omitempty" example:"sk-***"`
+	Status         int                         `json:"status,omitempty" example:"1"`
+	Name           string                      `json:"name,omitempty" example:"OpenAI Main"`
+	Weight         int                         `json:"weight,omitempty" example:"0"`
+	BaseURL        string                      `json:"base_url,omitempty" example:"https://api.openai.com/v1"`
+	Models         string                      `json:"models,omitempty" example:"gpt-4o-mini,gpt-4o"`
+	ModelConfigs   []ChannelModelConfigRequest `json:"model_configs,omitempty"`
+	Priority       int64                       `json:"priority,omitempty" example:"0"`
+	Config         string                      `json:"config,omitempty" example:"{}"`
+	SystemPrompt   string                      `json:"system_prompt,omitempty" example:""`
+	TestModel      string                      `json:"test_model,omitempty" example:"gpt-4o-mini"`
+	TimeoutSeconds int                         `json:"timeout_seconds,omitempty" example:"0"`
 }
 
 type ChannelCreateRecordRequest struct {
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+type apiKeyRegisterRequest struct {
+	PublicKey string `json:"public_key"`
+}
+
+// RegisterApiKey godoc
+// @Summary Register an Ed25519 public key for signed API requests
+// @Tags public
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/public/auth/apikey/register [post]
+// RegisterApiKey links a base64 Ed25519 public key to the current user and
+// returns the key ID used in "Authorization: ApiKey <keyID>:<sig>"
+func RegisterApiKey(c *gin.Context) {
+	userId := c.GetString(ctxkey.Id)
+	if strings.TrimSpace(userId) == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "未登录",
+		})
+		return
+	}
+	var req apiKeyRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.PublicKey) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "缺少 public_key",
+		})
+		return
+	}
+	key, err := model.CreateApiKey(userId, req.PublicKey)
+	if err != nil {
+		message := err.Error()
+		if errors.Is(err, common.ErrApiKeyInvalidPublicKey) {
+			message = "public_key 需为 base64 编码的 Ed25519 公钥"
+		} else if !errors.Is(err, model.ErrApiKeyExists) {
+			logger.Loginf(c.Request.Context(), "register api key failed user=%s err=%v", userId, err)
+			message = "注册 API Key 失败"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": message,
+		})
+		return
+	}
+	logger.Loginf(c.Request.Context(), "api key registered user=%s key=%s", userId, key.Id)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data": gin.H{
+			"key_id":     key.Id,
+			"created_at": key.CreatedAt,
+		},
+	})
+}
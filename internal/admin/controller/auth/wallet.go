@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -12,61 +13,200 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 
 	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/blacklist"
 	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/helper"
 	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/common/message"
 	"github.com/yeying-community/router/common/random"
 	usercontroller "github.com/yeying-community/router/internal/admin/controller/user"
 	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/admin/monitor"
+	"github.com/yeying-community/router/internal/transport/http/middleware"
 )
 
+var errWalletUserPendingApproval = newWalletError(WalletErrUserPendingApproval)
+
+// walletSupportedSignatureTypes lists the signing methods wallet login accepts:
+// the two recoverAddress handles for ethereum wallets and the detached Ed25519
+// signMessage signature of wallet_type solana.
+var walletSupportedSignatureTypes = []string{"personal_sign", "eth_signTypedData_v4", "solana_signMessage"}
+
+const walletChallengeTypesMaxAgeSeconds = 60
+
 type walletNonceRequest struct {
-	Address string `form:"address" json:"address" binding:"required"`
-	ChainId string `form:"chain_id" json:"chain_id"`
+	Address    string `form:"address" json:"address" binding:"required"`
+	ChainId    string `form:"chain_id" json:"chain_id"`
+	WalletType string `form:"wallet_type" json:"wallet_type"`
 }
 
+// walletLoginRequest is bound with bindWalletLoginRequest; the eth_addr and
+// solana_addr tags are registered by common.RegisterValidators.
 type walletLoginRequest struct {
-	Address   string `json:"address"`
-	Signature string `json:"signature"`
+	Address   string `json:"address" binding:"required,eth_addr|solana_addr"`
+	Signature string `json:"signature" binding:"required"`
 	Nonce     string `json:"nonce"`
 	ChainId   string `json:"chain_id"`
 	Message   string `json:"message"`
+	SignType  string `json:"sign_type"` // personal (default) or typed_data
+	// WalletType is ethereum (default) or solana
+	WalletType string `json:"wallet_type"`
 }
 
 const walletRefreshCookieName = "refresh_token"
 
+var errWalletBadRequest = errors.New("参数错误")
+
+// bindWalletLoginRequest decodes and validates a login, verify or bind body.
+// Tag failures are mapped to the wallet error codes clients already handle;
+// other decoding errors return errWalletBadRequest.
+func bindWalletLoginRequest(c *gin.Context, req *walletLoginRequest) error {
+	return walletRequestBindError(c.ShouldBindJSON(req), req)
+}
+
+// walletRequestBindError maps a binding error of a body embedding
+// walletLoginRequest like bindWalletLoginRequest does.
+func walletRequestBindError(err error, req *walletLoginRequest) error {
+	if err == nil {
+		return nil
+	}
+	if strings.EqualFold(strings.TrimSpace(req.WalletType), common.WalletTypeWebAuthn) {
+		return newWalletError(WalletErrUseWebAuthn)
+	}
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) && len(validationErrs) > 0 {
+		switch validationErrs[0].Field() {
+		case "Address":
+			return newWalletError(WalletErrInvalidAddress)
+		case "Signature":
+			return newWalletError(WalletErrMissingSignature)
+		}
+	}
+	return errWalletBadRequest
+}
+
 // WalletNonce godoc
+// @Summary Get wallet nonce (form or JSON body)
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param body body docs.WalletNonceRequest true "Wallet nonce payload"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 400 {object} docs.ErrorResponse
+// @Router /api/v1/public/oauth/wallet/nonce [post]
+// WalletNonce issues a nonce & message to sign
+func WalletNonce(c *gin.Context) {
+	var req walletNonceRequest
+	if err := c.ShouldBind(&req); err != nil || !common.IsValidWalletAddress(req.Address, req.WalletType) {
+		rejectWalletNonceRequest(c, req.Address, req.ChainId, err)
+		return
+	}
+	generateAndRespondNonce(c, req.Address, req.ChainId)
+}
+
+// WalletNonceGET godoc
 // @Summary Get wallet nonce
 // @Tags public
 // @Produce json
 // @Param address query string true "Wallet address"
 // @Param chain_id query string false "Chain ID"
+// @Param wallet_type query string false "ethereum (default) or solana"
 // @Success 200 {object} docs.StandardResponse
 // @Failure 400 {object} docs.ErrorResponse
 // @Router /api/v1/public/oauth/wallet/nonce [get]
-// WalletNonce issues a nonce & message to sign
-func WalletNonce(c *gin.Context) {
-	var req walletNonceRequest
-	if err := c.ShouldBind(&req); err != nil || !common.IsValidEthAddress(req.Address) {
-		logger.Loginf(c.Request.Context(), "wallet nonce invalid param addr=%s err=%v", req.Address, err)
+// WalletNonceGET issues a nonce & message to sign, reading the query string
+func WalletNonceGET(c *gin.Context) {
+	address := strings.TrimSpace(c.Query("address"))
+	chainId := strings.TrimSpace(c.Query("chain_id"))
+	if !common.IsValidWalletAddress(address, c.Query("wallet_type")) {
+		rejectWalletNonceRequest(c, address, chainId, nil)
+		return
+	}
+	generateAndRespondNonce(c, address, chainId)
+}
+
+func rejectWalletNonceRequest(c *gin.Context, addr, chainId string, err error) {
+	logger.Loginf(c.Request.Context(), "wallet nonce invalid param addr=%s err=%v", addr, err)
+	auditWallet(c, walletAuditNonce, addr, "", chainId, errors.New("参数错误，缺少 address"))
+	c.JSON(http.StatusOK, gin.H{
+		"success": false,
+		"message": "参数错误，缺少 address",
+	})
+}
+
+// generateAndRespondNonce is shared by the nonce handlers so they issue nonces
+// with the same TTL, logging and response shape.
+func generateAndRespondNonce(c *gin.Context, addr, chainId string) {
+	if common.IsValidEthAddress(addr) && !common.IsValidEthAddressStrict(addr) {
+		logger.Warnf(c.Request.Context(), "wallet nonce requested for unchecksummed address %s, expected EIP-55 form %s", addr, common.EthChecksumAddress(addr))
+	}
+	entry, err := common.GenerateWalletNonce(addr, "Login to "+config.SystemName, chainId)
+	auditWallet(c, walletAuditNonce, addr, "", chainId, err)
+	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"success": false,
-			"message": "参数错误，缺少 address",
+			"message": "生成 nonce 失败，请重试",
 		})
 		return
 	}
+	logger.Loginf(c.Request.Context(), "wallet nonce generated addr=%s chain=%s nonce=%s", model.NormalizeWalletAddress(addr), chainId, entry.Nonce)
+	// the expiry predates this request when auth.nonce_idempotent returned a
+	// pending nonce
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data": gin.H{
+			"nonce":      entry.Nonce,
+			"message":    entry.Message,
+			"expires_at": entry.ExpireAt.UTC().Format(time.RFC3339),
+		},
+	})
+}
 
-	nonce, message := common.GenerateWalletNonce(req.Address, "Login to "+config.SystemName, req.ChainId)
-	logger.Loginf(c.Request.Context(), "wallet nonce generated addr=%s chain=%s nonce=%s", strings.ToLower(req.Address), req.ChainId, nonce)
-	expireAt := time.Now().Add(time.Duration(config.NonceTTLMinutes) * time.Minute)
+// WalletChallengeTypes godoc
+// @Summary Get supported wallet signature schemes
+// @Tags public
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Router /api/v1/auth/wallet/challenge-types [get]
+// @Router /api/v1/public/oauth/wallet/challenge-types [get]
+// WalletChallengeTypes advertises the wallet signing capabilities of this server
+func WalletChallengeTypes(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", walletChallengeTypesMaxAgeSeconds))
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "",
 		"data": gin.H{
-			"nonce":      nonce,
-			"message":    message,
-			"expires_at": expireAt.UTC().Format(time.RFC3339),
+			"supported_types":   walletSupportedSignatureTypes,
+			"siwe_enabled":      config.WalletNonceFormat == common.WalletNonceFormatSIWE,
+			"supported_chains":  common.WalletAllowedChainList(),
+			"nonce_format":      config.WalletNonceFormat,
+			"nonce_ttl_minutes": config.NonceTTLMinutes,
+			"message_template":  common.WalletNonceMessageTemplate("Login to " + config.SystemName),
+		},
+	})
+}
+
+// WalletChains godoc
+// @Summary List chain IDs accepted for wallet login
+// @Tags public
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Router /api/v1/public/wallet/chains [get]
+// WalletChains returns the active auth.wallet_allowed_chains as decimal chain
+// IDs; an empty list means any chain is accepted
+func WalletChains(c *gin.Context) {
+	chains := common.WalletAllowedChainList()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data": gin.H{
+			"chains":    chains,
+			"any_chain": len(chains) == 0,
 		},
 	})
 }
@@ -83,11 +223,13 @@ func WalletNonce(c *gin.Context) {
 // WalletLogin verifies signature and logs user in
 func WalletLogin(c *gin.Context) {
 	var req walletLoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindWalletLoginRequest(c, &req); err != nil {
 		logger.Loginf(c.Request.Context(), "wallet login bind json failed err=%v", err)
+		auditWallet(c, walletAuditLogin, req.Address, "", req.ChainId, err)
 		c.JSON(http.StatusOK, gin.H{
-			"success": false,
-			"message": "参数错误",
+			"success":    false,
+			"message":    err.Error(),
+			"error_code": walletErrorCode(err),
 		})
 		return
 	}
@@ -95,15 +237,18 @@ func WalletLogin(c *gin.Context) {
 	user, err := walletAuthenticate(c, req)
 	if err != nil {
 		logger.Loginf(c.Request.Context(), "wallet login authenticate failed addr=%s err=%v", strings.ToLower(req.Address), err)
+		auditWallet(c, walletAuditLogin, req.Address, "", req.ChainId, err)
 		c.JSON(http.StatusOK, gin.H{
-			"success": false,
-			"message": err.Error(),
+			"success":    false,
+			"message":    err.Error(),
+			"error_code": walletErrorCode(err),
 		})
 		return
 	}
 
 	if err := usercontroller.SetupSession(user, c); err != nil {
 		logger.LoginErrorf(c.Request.Context(), "wallet login setup session failed user=%s err=%v", user.Id, err)
+		auditWallet(c, walletAuditLogin, req.Address, user.Id, req.ChainId, err)
 		c.JSON(http.StatusOK, gin.H{
 			"success": false,
 			"message": "无法保存会话信息，请重试",
@@ -112,32 +257,24 @@ func WalletLogin(c *gin.Context) {
 	}
 	addr := ""
 	if user.WalletAddress != nil {
-		addr = strings.ToLower(*user.WalletAddress)
+		addr = model.NormalizeWalletAddress(*user.WalletAddress)
 	}
 	token, exp, tokenErr := common.GenerateWalletJWT(user.Id, addr)
 	if tokenErr != nil {
 		logger.LoginErrorf(c.Request.Context(), "wallet jwt generate failed user=%s err=%v", user.Id, tokenErr)
 	}
 	logger.Loginf(c.Request.Context(), "wallet login success user=%s addr=%s role=%d token=%t exp=%s", user.Id, addr, model.EffectiveRole(user), token != "", exp.UTC().Format(time.RFC3339))
-	cleanUser := model.User{
-		Id:             user.Id,
-		Username:       user.Username,
-		DisplayName:    user.DisplayName,
-		Role:           model.ExposedRole(user),
-		Status:         user.Status,
-		WalletAddress:  user.WalletAddress,
-		CanManageUsers: model.CanManageUsers(user),
-	}
 	resp := gin.H{
 		"message": "",
 		"success": true,
-		"data":    cleanUser,
+		"data":    safeUserResponse(user),
 	}
 	if token != "" {
 		resp["token"] = token
+		resp["jti"] = common.WalletJWTID(token)
 		resp["token_expires_at"] = exp.UTC().Format(time.RFC3339)
 	}
-	common.ConsumeWalletNonce(strings.ToLower(req.Address))
+	auditWallet(c, walletAuditLogin, req.Address, user.Id, req.ChainId, nil)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -154,24 +291,29 @@ func WalletLogin(c *gin.Context) {
 // WalletBind binds a wallet to logged-in user
 func WalletBind(c *gin.Context) {
 	var req walletLoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindWalletLoginRequest(c, &req); err != nil {
+		auditWallet(c, walletAuditBind, req.Address, "", req.ChainId, err)
 		c.JSON(http.StatusOK, gin.H{
-			"success": false,
-			"message": "参数错误",
+			"success":    false,
+			"message":    err.Error(),
+			"error_code": walletErrorCode(err),
 		})
 		return
 	}
-	if err := verifyWalletRequest(req); err != nil {
+	if err := verifyWalletRequest(c.Request.Context(), req); err != nil {
+		auditWallet(c, walletAuditBind, req.Address, "", req.ChainId, err)
 		c.JSON(http.StatusOK, gin.H{
-			"success": false,
-			"message": err.Error(),
+			"success":    false,
+			"message":    err.Error(),
+			"error_code": walletErrorCode(err),
 		})
 		return
 	}
-	addr := strings.ToLower(req.Address)
-	session := sessions.Default(c)
-	id, idErr := sessionIDToString(session.Get("id"))
-	if idErr != nil {
+	addr := model.NormalizeWalletAddress(req.Address)
+	walletType, _ := common.NormalizeWalletType(req.WalletType)
+	id := strings.TrimSpace(c.GetString(ctxkey.Id))
+	if id == "" {
+		auditWallet(c, walletAuditBind, addr, "", req.ChainId, errors.New("未登录"))
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
 			"message": "未登录",
@@ -180,6 +322,7 @@ func WalletBind(c *gin.Context) {
 	}
 	user := model.User{Id: id}
 	if err := user.FillUserById(); err != nil {
+		auditWallet(c, walletAuditBind, addr, id, req.ChainId, err)
 		c.JSON(http.StatusOK, gin.H{
 			"success": false,
 			"message": err.Error(),
@@ -187,81 +330,232 @@ func WalletBind(c *gin.Context) {
 		return
 	}
 	if model.IsWalletAddressAlreadyTaken(addr) {
-		exist := model.User{WalletAddress: &addr}
-		if err := exist.FillUserByWalletAddress(); err == nil {
-			if exist.Status == model.UserStatusDeleted {
-				_ = model.DB.Model(&exist).Update("wallet_address", nil)
-			} else if exist.Id != user.Id && (user.WalletAddress == nil || strings.ToLower(*user.WalletAddress) != addr) {
-				c.JSON(http.StatusOK, gin.H{
-					"success": false,
-					"message": "该钱包已绑定其他账户",
-				})
-				return
-			}
+		if err := model.ReleaseWalletAddress(addr); err != nil {
+			auditWallet(c, walletAuditBind, addr, user.Id, req.ChainId, err)
+			c.JSON(http.StatusOK, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
 		}
 	}
-	user.WalletAddress = &addr
-	if err := user.Update(false); err != nil {
+	// the address is added alongside existing wallets rather than replacing them
+	if err := model.BindUserWalletWithDB(model.DB, &user, addr, req.ChainId, walletType); err != nil {
+		auditWallet(c, walletAuditBind, addr, user.Id, req.ChainId, err)
 		c.JSON(http.StatusOK, gin.H{
 			"success": false,
 			"message": err.Error(),
 		})
 		return
 	}
-	common.ConsumeWalletNonce(addr)
+	auditWallet(c, walletAuditBind, addr, user.Id, req.ChainId, nil)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "绑定成功",
 	})
 }
 
-func verifyWalletRequest(req walletLoginRequest) error {
-	if !common.IsValidEthAddress(req.Address) {
-		err := errors.New("无效的钱包地址")
-		logger.Loginf(nil, "wallet verify fail addr=%s err=%v", req.Address, err)
+type walletUnbindRequest struct {
+	Address string `json:"address"`
+}
+
+// WalletUnbind godoc
+// @Summary Unbind wallet from current user
+// @Tags public
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body docs.WalletUnbindRequest true "Wallet unbind payload"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/public/oauth/wallet/unbind [post]
+// @Router /api/v1/public/oauth/wallet/bind [delete]
+// WalletUnbind removes one of the wallets bound to the logged-in user. The last
+// wallet of an account without a password cannot be removed, since the user
+// would have no way left to log in.
+func WalletUnbind(c *gin.Context) {
+	var req walletUnbindRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !(common.IsValidEthAddress(req.Address) || common.IsValidSolanaAddress(req.Address)) {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": "无效的钱包地址",
+		})
+		return
+	}
+	id := strings.TrimSpace(c.GetString(ctxkey.Id))
+	if id == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "未登录",
+		})
+		return
+	}
+	user := model.User{Id: id}
+	if err := user.FillUserById(); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	wallets, err := model.GetUserWallets(user.Id)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	if err := checkWalletUnbindAllowed(&user, wallets, req.Address); err != nil {
+		logger.Loginf(c.Request.Context(), "wallet unbind rejected user=%s addr=%s err=%v", user.Id, model.NormalizeWalletAddress(req.Address), err)
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	if err := model.UnbindUserWallet(&user, req.Address); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	logger.Loginf(c.Request.Context(), "wallet unbind success user=%s addr=%s", user.Id, model.NormalizeWalletAddress(req.Address))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "解绑成功",
+	})
+}
+
+// checkWalletUnbindAllowed refuses to unbind an address the user doesn't own
+// and to unbind the only wallet of an account that has no password.
+func checkWalletUnbindAllowed(user *model.User, wallets []model.UserWallet, address string) error {
+	addr := model.NormalizeWalletAddress(address)
+	bound := make(map[string]struct{}, len(wallets)+1)
+	for _, wallet := range wallets {
+		bound[model.NormalizeWalletAddress(wallet.Address)] = struct{}{}
+	}
+	if user.WalletAddress != nil && strings.TrimSpace(*user.WalletAddress) != "" {
+		bound[model.NormalizeWalletAddress(*user.WalletAddress)] = struct{}{}
+	}
+	if _, ok := bound[addr]; !ok {
+		return errors.New("该钱包未绑定到当前账户")
+	}
+	if len(bound) == 1 && !user.HasPassword {
+		return errors.New("账户未设置密码，无法解绑最后一个钱包")
+	}
+	return nil
+}
+
+func verifyWalletRequest(ctx context.Context, req walletLoginRequest) error {
+	if strings.EqualFold(strings.TrimSpace(req.WalletType), common.WalletTypeWebAuthn) {
+		return newWalletError(WalletErrUseWebAuthn)
+	}
+	walletType, ok := common.NormalizeWalletType(req.WalletType)
+	if !ok {
+		err := newWalletError(WalletErrUnsupportedType)
+		logger.Loginf(ctx, "wallet verify fail addr=%s wallet_type=%s err=%v", req.Address, req.WalletType, err)
+		return err
+	}
+	// the binding tags accept either address format; it must match wallet_type
+	if !common.IsValidWalletAddress(req.Address, walletType) {
+		err := newWalletError(WalletErrInvalidAddress)
+		logger.Loginf(ctx, "wallet verify fail addr=%s err=%v", req.Address, err)
 		return err
 	}
-	if req.Signature == "" {
-		err := errors.New("缺少签名或 nonce")
-		logger.Loginf(nil, "wallet verify fail addr=%s err=%v", req.Address, err)
+	// chain IDs are EVM chain IDs; Solana wallets have none
+	if walletType == common.WalletTypeEthereum && len(config.WalletAllowedChains) > 0 && strings.TrimSpace(req.ChainId) == "" {
+		err := newWalletError(WalletErrChainIdRequired)
+		logger.Loginf(ctx, "wallet verify fail addr=%s err=%v", req.Address, err)
+		return err
+	}
+	if walletType == common.WalletTypeEthereum && req.ChainId != "" && !common.IsWalletChainAllowed(req.ChainId) {
+		err := newWalletError(WalletErrChainNotAllowed)
+		logger.Loginf(ctx, "wallet verify fail addr=%s chain=%s err=%v", req.Address, req.ChainId, err)
 		return err
 	}
 	entry, ok := common.GetWalletNonce(req.Address)
 	if !ok {
-		err := errors.New("nonce 无效或已过期")
-		logger.Loginf(nil, "wallet verify fail addr=%s err=%v", req.Address, err)
+		err := newWalletError(WalletErrNonceExpired)
+		logger.Loginf(ctx, "wallet verify fail addr=%s err=%v", req.Address, err)
 		return err
 	}
 	if req.Nonce != "" && entry.Nonce != req.Nonce {
-		err := errors.New("nonce 无效或已过期")
-		logger.Loginf(nil, "wallet verify fail addr=%s err=%v", req.Address, err)
+		err := newWalletError(WalletErrNonceExpired)
+		logger.Loginf(ctx, "wallet verify fail addr=%s err=%v", req.Address, err)
 		return err
 	}
 
 	message := entry.Message
-	if strings.TrimSpace(req.Message) != "" {
+	// a custom auth.nonce_message_template may not have a "Nonce:" line, so a
+	// verbatim copy of the issued message is accepted as is
+	if strings.TrimSpace(req.Message) != "" && req.Message != entry.Message {
 		message = req.Message
-		nonce := extractNonceFromMessage(message)
+		var nonce string
+		if isSIWEMessage(message) {
+			fields, err := parseSIWEMessage(message)
+			if err == nil {
+				err = checkSIWEFields(fields, req.Address, req.ChainId, time.Now())
+			}
+			if err != nil {
+				logger.Loginf(ctx, "wallet verify fail addr=%s siwe=%v", req.Address, err)
+				if errors.Is(err, errSIWEChainNotAllowed) {
+					return newWalletError(WalletErrChainNotAllowed)
+				}
+				return newWalletError(WalletErrInvalidSIWEMessage)
+			}
+			nonce = fields.Nonce
+		} else {
+			nonce = extractNonceFromMessage(message)
+		}
 		if nonce == "" || nonce != entry.Nonce {
-			err := errors.New("nonce 无效或已过期")
-			logger.Loginf(nil, "wallet verify fail addr=%s err=%v", req.Address, err)
+			err := newWalletError(WalletErrNonceExpired)
+			logger.Loginf(ctx, "wallet verify fail addr=%s err=%v", req.Address, err)
 			return err
 		}
 	}
 
+	if walletType == common.WalletTypeSolana {
+		err := verifySolanaSignature(req.Address, message, req.Signature)
+		auditWallet(nil, walletAuditSignature, req.Address, "", req.ChainId, err)
+		if err != nil {
+			logger.Loginf(ctx, "wallet verify fail addr=%s wallet_type=solana err=%v", req.Address, err)
+			return newWalletError(WalletErrSignatureMismatch)
+		}
+		return claimWalletSignature(ctx, req, entry)
+	}
+
 	// verify signature
-	recovered, err := recoverAddress(message, req.Signature)
+	recovered, err := recoverAddress(message, req.Signature, req.SignType, req.ChainId)
 	if err != nil {
 		logger.SysError("wallet login verify failed: " + err.Error())
-		err2 := errors.New("签名验证失败")
-		logger.Loginf(nil, "wallet verify fail addr=%s err=%v", req.Address, err2)
+		err2 := newWalletError(WalletErrSignatureMismatch)
+		logger.Loginf(ctx, "wallet verify fail addr=%s err=%v", req.Address, err2)
 		return err2
 	}
 	if strings.ToLower(recovered) != strings.ToLower(req.Address) {
-		err := errors.New("签名地址与请求地址不一致")
-		logger.Loginf(nil, "wallet verify fail addr=%s recovered=%s err=%v", req.Address, recovered, err)
+		err := newWalletError(WalletErrAddressMismatch)
+		logger.Loginf(ctx, "wallet verify fail addr=%s recovered=%s err=%v", req.Address, recovered, err)
 		return err
 	}
+	return claimWalletSignature(ctx, req, entry)
+}
+
+// claimWalletSignature records a verified signature for the nonce lifetime so
+// a concurrent or intercepted copy is rejected, and consumes the nonce: wallets
+// sign deterministically, so a retry needs a fresh challenge anyway.
+func claimWalletSignature(ctx context.Context, req walletLoginRequest, entry common.WalletNonceEntry) error {
+	ttl := entry.TTL
+	if ttl <= 0 {
+		ttl = common.WalletNonceTTL(entry.ChainId)
+	}
+	if !common.MarkWalletSignatureUsed(req.Signature, ttl) {
+		err := newWalletError(WalletErrSignatureReplayed)
+		logger.Loginf(ctx, "wallet verify fail addr=%s err=%v", req.Address, err)
+		return err
+	}
+	common.ConsumeWalletNonce(req.Address)
 	return nil
 }
 
@@ -281,65 +575,235 @@ func extractNonceFromMessage(message string) string {
 
 // walletAuthenticate verifies signature & returns an enabled user (create if allowed)
 func walletAuthenticate(c *gin.Context, req walletLoginRequest) (*model.User, error) {
-	if err := verifyWalletRequest(req); err != nil {
+	user, err := authenticateWalletLogin(c, req)
+	if err != nil {
+		monitor.RecordWalletLoginFailure()
+	}
+	recordWalletLoginHistory(c, req, user, err)
+	if err == nil || isWalletServerError(err) {
+		common.WalletAuthErrorBudget().Record(err == nil)
+	}
+	c.Set(ctxkey.WalletLoginResult, err == nil)
+	return user, err
+}
+
+// isWalletServerError reports whether a failed wallet login is the server's
+// fault, such as a database or store error. Rejections the client controls,
+// like a bad signature, an unknown nonce or a pending account, are
+// WalletErrors and stay out of the error budget.
+func isWalletServerError(err error) bool {
+	return err != nil && walletErrorCode(err) == 0
+}
+
+func authenticateWalletLogin(c *gin.Context, req walletLoginRequest) (*model.User, error) {
+	if err := verifyWalletRequest(c.Request.Context(), req); err != nil {
 		return nil, err
 	}
-	addr := strings.ToLower(req.Address)
-	user, err := findOrCreateWalletUser(addr, c.Request.Context())
+	addr := model.NormalizeWalletAddress(req.Address)
+	walletType, _ := common.NormalizeWalletType(req.WalletType)
+	user, err := findOrCreateWalletUser(addr, walletType, c.Request.Context())
 	if err != nil {
 		logger.Loginf(c.Request.Context(), "wallet auth find/create failed addr=%s err=%v", addr, err)
 		return nil, err
 	}
-	if user.Status != model.UserStatusEnabled {
-		err := errors.New("用户已被封禁")
-		logger.Loginf(c.Request.Context(), "wallet auth user disabled addr=%s err=%v", addr, err)
+	if err := walletUserStatusError(user); err != nil {
+		logger.Loginf(c.Request.Context(), "wallet auth user not enabled addr=%s user=%s status=%d", addr, user.Id, user.Status)
 		return nil, err
 	}
-	common.ConsumeWalletNonce(addr)
+	now := helper.GetTimestamp()
+	if err := model.UpdateUserLastLoginAt(user.Id, now); err != nil {
+		logger.LoginErrorf(c.Request.Context(), "wallet auth update last login failed user=%s err=%v", user.Id, err)
+	} else {
+		user.LastLoginAt = now
+	}
 	logger.Loginf(c.Request.Context(), "wallet auth success user=%s addr=%s", user.Id, addr)
 	return user, nil
 }
 
-func findOrCreateWalletUser(addr string, ctx context.Context) (*model.User, error) {
+// walletUserStatusError rejects users that may not log in yet or any more.
+func walletUserStatusError(user *model.User) error {
+	switch user.Status {
+	case model.UserStatusEnabled:
+		return nil
+	case model.UserStatusPendingApproval:
+		return errWalletUserPendingApproval
+	default:
+		return newWalletError(WalletErrUserDisabled)
+	}
+}
+
+// safeUserResponse builds the user payload returned by wallet login endpoints.
+// Only whitelisted fields are copied so credentials never leak into responses.
+func safeUserResponse(user *model.User) gin.H {
+	if user == nil {
+		return gin.H{}
+	}
+	return gin.H{
+		"id":               user.Id,
+		"username":         user.Username,
+		"display_name":     user.DisplayName,
+		"wallet_address":   user.WalletAddress,
+		"role":             model.ExposedRole(user),
+		"status":           user.Status,
+		"group":            user.Group,
+		"has_password":     user.HasPassword,
+		"can_manage_users": model.CanManageUsers(user),
+		"created_at":       user.CreatedAt,
+		"last_login_at":    user.LastLoginAt,
+	}
+}
+
+func init() {
+	middleware.SetWalletUserResolver(func(ctx context.Context, addr string) (*model.User, error) {
+		return findOrCreateWalletUser(model.NormalizeWalletAddress(addr), common.WalletTypeEthereum, ctx)
+	})
+}
+
+// findOrCreateWalletUser resolves the user bound to addr, registering one when
+// auto registration is on. walletType is only used for new registrations.
+func findOrCreateWalletUser(addr string, walletType string, ctx context.Context) (*model.User, error) {
 	user := model.User{WalletAddress: &addr}
 	if !model.IsWalletAddressAlreadyTaken(addr) {
-		if config.AutoRegisterEnabled {
-			return autoCreateWalletUser(addr, ctx)
+		if model.WalletAutoRegisterEnabled() {
+			if common.WalletAuthErrorBudget().InBurnMode() {
+				logger.Loginf(ctx, "wallet auto register skipped in burn mode addr=%s", addr)
+				return nil, newWalletError(WalletErrAutoRegisterPaused)
+			}
+			return autoCreateWalletUser(addr, walletType, ctx)
 		}
-		return nil, errors.New("未找到钱包绑定的账户，请先绑定或由管理员开启自动注册")
+		return nil, newWalletError(WalletErrUserNotFound)
 	}
 
 	if err := user.FillUserByWalletAddress(); err != nil {
 		return nil, err
 	}
 	if user.Status == model.UserStatusDeleted {
-		_ = model.DB.Model(&user).Update("wallet_address", nil)
-		return findOrCreateWalletUser(addr, ctx)
+		if err := model.ReleaseWalletAddress(addr); err != nil {
+			return nil, err
+		}
+		return findOrCreateWalletUser(addr, walletType, ctx)
 	}
 	return &user, nil
 }
 
-func autoCreateWalletUser(addr string, ctx context.Context) (*model.User, error) {
+func autoCreateWalletUser(addr string, walletType string, ctx context.Context) (*model.User, error) {
 	username := "wallet_" + random.GetRandomString(6)
 	for model.IsUsernameAlreadyTaken(username) {
 		username = "wallet_" + random.GetRandomString(6)
 	}
+	displayName, err := uniqueWalletDisplayName(common.RenderWalletDisplayName(addr, username, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	role, status := walletAutoRegisterDefaults()
 	user := model.User{
 		Username:      username,
 		Password:      random.GetRandomString(16),
-		DisplayName:   username,
-		Role:          model.RoleCommonUser,
-		Status:        model.UserStatusEnabled,
+		DisplayName:   displayName,
+		Role:          role,
+		Status:        status,
 		WalletAddress: &addr,
+		WalletType:    walletType,
 		HasPassword:   false,
 	}
 	if err := user.Insert(ctx, ""); err != nil {
 		return nil, err
 	}
+	if user.Status == model.UserStatusPendingApproval {
+		notifyPendingWalletUser(&user)
+	}
 	return &user, nil
 }
 
-func recoverAddress(message, signature string) (string, error) {
+// walletAutoRegisterDefaults maps auth.auto_register_default_role/status to the
+// new user's role and status. auth.auto_register_require_approval still forces
+// pending approval.
+func walletAutoRegisterDefaults() (role int, status int) {
+	role = model.RoleCommonUser
+	if config.WalletAutoRegisterDefaultRole == "admin" {
+		role = model.RoleAdminUser
+	}
+	switch config.WalletAutoRegisterDefaultStatus {
+	case "pending":
+		status = model.UserStatusPendingApproval
+	case "disabled":
+		status = model.UserStatusDisabled
+	default:
+		status = model.UserStatusEnabled
+	}
+	if config.WalletAutoRegisterRequireApproval {
+		status = model.UserStatusPendingApproval
+	}
+	return role, status
+}
+
+// notifyPendingWalletUser pushes a new pending registration to the message
+// pusher webhook so admins can review it.
+func notifyPendingWalletUser(user *model.User) {
+	if config.MessagePusherAddress == "" {
+		return
+	}
+	title := "新钱包用户待审批"
+	content := fmt.Sprintf("用户 %s（%s）通过钱包自动注册，等待管理员审批。", user.Username, *user.WalletAddress)
+	go func() {
+		if err := message.SendMessage(title, content, content); err != nil {
+			logger.SysError(fmt.Sprintf("failed to send pending user notification: %s", err.Error()))
+		}
+	}()
+}
+
+// walletAuthErrorCode maps wallet authentication errors to proto error codes.
+func walletAuthErrorCode(err error) int {
+	if errors.Is(err, errWalletUserPendingApproval) {
+		return common.ProtoCodePendingApproval
+	}
+	return common.ProtoCodeUnauthenticated
+}
+
+const walletDisplayNameMaxAttempts = 5
+
+// uniqueWalletDisplayName appends a numeric suffix to base until it is not used by
+// another user. It is a no-op unless config.WalletUniqueDisplayName is enabled.
+func uniqueWalletDisplayName(base string) (string, error) {
+	if !config.WalletUniqueDisplayName {
+		return base, nil
+	}
+	name := base
+	for i := 1; model.IsDisplayNameAlreadyTaken(name); i++ {
+		if i > walletDisplayNameMaxAttempts {
+			return "", errors.New("无法生成唯一的显示名称，请稍后重试")
+		}
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	return name, nil
+}
+
+// recoverAddress returns the lower-case signer of message. signType is
+// "personal" (personal_sign) or "typed_data" (EIP-712, bound to chainId).
+func recoverAddress(message, signature, signType, chainId string) (string, error) {
+	addr, err := recoverAddressFromSignature(message, signature, signType, chainId)
+	auditWallet(nil, walletAuditSignature, addr, "", chainId, err)
+	return addr, err
+}
+
+func recoverAddressFromSignature(message, signature, signType, chainId string) (string, error) {
+	signType, err := normalizeWalletSignType(signType)
+	if err != nil {
+		return "", err
+	}
+	var hash []byte
+	if signType == walletSignTypeTypedData {
+		hash, err = walletTypedDataHash(message, chainId)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		hash = accounts.TextHash([]byte(message))
+	}
+	return recoverSigner(hash, signature)
+}
+
+func recoverSigner(hash []byte, signature string) (string, error) {
 	sig := strings.TrimPrefix(signature, "0x")
 	raw, err := hex.DecodeString(sig)
 	if err != nil {
@@ -352,7 +816,6 @@ func recoverAddress(message, signature string) (string, error) {
 	if raw[64] >= 27 {
 		raw[64] -= 27
 	}
-	hash := accounts.TextHash([]byte(message))
 	pub, err := crypto.SigToPub(hash, raw)
 	if err != nil {
 		return "", err
@@ -377,23 +840,26 @@ func WalletChallengeProto(c *gin.Context) {
 	var req walletNonceRequest
 	if err := c.ShouldBindJSON(&req); err != nil || !common.IsValidEthAddress(req.Address) {
 		logger.Loginf(c.Request.Context(), "wallet proto challenge bind fail addr=%s err=%v", req.Address, err)
-		writeProtoError(c, 2, "参数错误，缺少 address")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeInvalidArgument, "参数错误，缺少 address")
 		return
 	}
 	addr := strings.ToLower(req.Address)
-	if !model.IsWalletAddressAlreadyTaken(addr) && !config.AutoRegisterEnabled {
+	if !model.IsWalletAddressAlreadyTaken(addr) && !model.WalletAutoRegisterEnabled() {
 		logger.Loginf(c.Request.Context(), "wallet proto challenge reject addr=%s not bound and auto-register disabled", addr)
-		writeProtoError(c, 5, "钱包未绑定账户，请先绑定或由管理员开启自动注册")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeNotFound, "钱包未绑定账户，请先绑定或由管理员开启自动注册")
+		return
+	}
+	entry, err := common.GenerateWalletNonce(addr, "Login to "+config.SystemName, req.ChainId)
+	if err != nil {
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeResourceExhausted, "生成 nonce 失败，请重试")
 		return
 	}
-	nonce, message := common.GenerateWalletNonce(addr, "Login to "+config.SystemName, req.ChainId)
-	logger.Loginf(c.Request.Context(), "wallet proto challenge success addr=%s nonce=%s chain=%s", addr, nonce, req.ChainId)
-	expireAt := time.Now().Add(time.Duration(config.NonceTTLMinutes) * time.Minute)
+	logger.Loginf(c.Request.Context(), "wallet proto challenge success addr=%s nonce=%s chain=%s", addr, entry.Nonce, req.ChainId)
 	body := gin.H{
-		"nonce":      nonce,
-		"message":    message,
+		"nonce":      entry.Nonce,
+		"message":    entry.Message,
 		"address":    addr,
-		"expires_at": expireAt.UTC().Format(time.RFC3339),
+		"expires_at": entry.ExpireAt.UTC().Format(time.RFC3339),
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -414,46 +880,67 @@ func WalletChallengeProto(c *gin.Context) {
 // WalletVerifyProto implements /api/v1/public/common/auth/verify
 func WalletVerifyProto(c *gin.Context) {
 	var req walletLoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindWalletLoginRequest(c, &req); err != nil {
 		logger.Loginf(c.Request.Context(), "wallet proto verify bind fail err=%v", err)
-		writeProtoError(c, 2, "参数错误")
+		body := common.ProtoErrorBody(common.ProtoCodeInvalidArgument, err.Error())
+		body["error_code"] = walletErrorCode(err)
+		c.AbortWithStatusJSON(http.StatusOK, body)
+		return
+	}
+	cacheKey := common.WalletVerifyCacheKey(req.Signature, req.Nonce)
+	if cached, ok := cachedWalletVerifyResponse(c.Request.Context(), cacheKey, c.ClientIP()); ok {
+		if err := usercontroller.SetupSession(cached.user, c); err != nil {
+			logger.LoginErrorf(c.Request.Context(), "wallet proto verify setup session fail user=%s err=%v", cached.user.Id, err)
+			common.AbortWithError(c, http.StatusOK, common.ProtoCodeResourceExhausted, "无法保存会话信息，请重试")
+			return
+		}
+		logger.Loginf(c.Request.Context(), "wallet proto verify served from cache user=%s addr=%s", cached.user.Id, req.Address)
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "",
+			"data":    cached.body,
+		})
 		return
 	}
 	user, err := walletAuthenticate(c, req)
 	if err != nil {
 		logger.Loginf(c.Request.Context(), "wallet proto verify auth fail addr=%s err=%v", req.Address, err)
-		writeProtoError(c, 3, err.Error())
+		body := common.ProtoErrorBody(walletAuthErrorCode(err), err.Error())
+		body["error_code"] = walletErrorCode(err)
+		c.AbortWithStatusJSON(http.StatusOK, body)
 		return
 	}
 	if err := usercontroller.SetupSession(user, c); err != nil {
 		logger.LoginErrorf(c.Request.Context(), "wallet proto verify setup session fail user=%s err=%v", user.Id, err)
-		writeProtoError(c, 8, "无法保存会话信息，请重试")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeResourceExhausted, "无法保存会话信息，请重试")
 		return
 	}
 	addr := ""
 	if user.WalletAddress != nil {
-		addr = strings.ToLower(*user.WalletAddress)
+		addr = model.NormalizeWalletAddress(*user.WalletAddress)
 	}
 	token, exp, tokenErr := common.GenerateWalletJWT(user.Id, addr)
 	if tokenErr != nil {
 		logger.SysError("wallet jwt generate failed: " + tokenErr.Error())
-		writeProtoError(c, 8, "生成 token 失败")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeResourceExhausted, "生成 token 失败")
+		return
+	}
+	refreshToken, refreshExp, refreshErr := model.IssueRefreshToken(user.Id, addr)
+	if refreshErr != nil {
+		logger.SysError("wallet refresh token issue failed: " + refreshErr.Error())
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeResourceExhausted, "生成 refresh token 失败")
 		return
 	}
 	logger.Loginf(c.Request.Context(), "wallet proto verify success user=%s addr=%s token_exp=%s", user.Id, addr, exp.UTC().Format(time.RFC3339))
 	body := gin.H{
-		"token":      token,
-		"expires_at": exp.UTC().Format(time.RFC3339),
-		"user": gin.H{
-			"id":               user.Id,
-			"username":         user.Username,
-			"wallet_address":   user.WalletAddress,
-			"role":             model.ExposedRole(user),
-			"status":           user.Status,
-			"has_password":     user.HasPassword,
-			"can_manage_users": model.CanManageUsers(user),
-		},
-	}
+		"token":              token,
+		"jti":                common.WalletJWTID(token),
+		"expires_at":         exp.UTC().Format(time.RFC3339),
+		"refresh_token":      refreshToken,
+		"refresh_expires_at": refreshExp.UTC().Format(time.RFC3339),
+		"user":               safeUserResponse(user),
+	}
+	common.CacheWalletVerifyResponse(cacheKey, req.Address, c.ClientIP(), walletVerifyCached{user: user, body: body, token: token})
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "",
@@ -461,66 +948,130 @@ func WalletVerifyProto(c *gin.Context) {
 	})
 }
 
+// walletVerifyCached is a successful verify kept for frontend retries: the
+// signature was claimed by the first call, so a retry would otherwise fail as
+// a replay.
+type walletVerifyCached struct {
+	user  *model.User
+	body  gin.H
+	token string
+}
+
+// cachedWalletVerifyResponse returns a cached verify whose access token is
+// still valid, i.e. not revoked by a logout since, with the user reloaded.
+// The user must still be enabled and not banned: an entry of a user disabled
+// or banned since is dropped, and the retry then fails as a replayed
+// signature.
+func cachedWalletVerifyResponse(ctx context.Context, key, clientIP string) (walletVerifyCached, bool) {
+	payload, ok := common.GetWalletVerifyResponse(key, clientIP)
+	if !ok {
+		return walletVerifyCached{}, false
+	}
+	cached, ok := payload.(walletVerifyCached)
+	if !ok {
+		return walletVerifyCached{}, false
+	}
+	if _, err := common.VerifyWalletJWT(cached.token); err != nil {
+		return walletVerifyCached{}, false
+	}
+	user, err := model.GetUserById(cached.user.Id, false)
+	if err == nil {
+		err = walletUserStatusError(user)
+	}
+	if err == nil && blacklist.IsUserBanned(user.Id) {
+		err = newWalletError(WalletErrUserDisabled)
+	}
+	if err != nil {
+		logger.Loginf(ctx, "wallet proto verify cache entry dropped user=%s err=%v", cached.user.Id, err)
+		common.DropWalletVerifyResponse(key)
+		return walletVerifyCached{}, false
+	}
+	cached.user = user
+	return cached, true
+}
+
+type walletRefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 // WalletRefreshToken godoc
 // @Summary Refresh token (proto)
 // @Tags public
 // @Accept json
 // @Produce json
+// @Param body body docs.WalletRefreshTokenRequest false "Refresh token issued by verify"
 // @Success 200 {object} docs.StandardResponse
 // @Failure 400 {object} docs.ErrorResponse
 // @Router /api/v1/public/common/auth/refreshToken [post]
-// WalletRefreshToken implements /api/v1/public/common/auth/refreshToken
+// WalletRefreshToken implements /api/v1/public/common/auth/refreshToken. With a
+// refresh_token in the body it rotates that token and returns a new pair;
+// otherwise it re-issues the access token from the Authorization header.
 func WalletRefreshToken(c *gin.Context) {
+	var req walletRefreshTokenRequest
+	// the body is optional: older clients send only the Authorization header
+	_ = c.ShouldBindJSON(&req)
+	if strings.TrimSpace(req.RefreshToken) != "" {
+		walletRotateRefreshToken(c, req.RefreshToken)
+		return
+	}
 	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
 	if strings.HasPrefix(strings.ToLower(authHeader), "bearer ") {
 		authHeader = strings.TrimSpace(authHeader[7:])
 	}
 	if authHeader == "" {
 		logger.Loginf(c.Request.Context(), "wallet refresh missing token")
-		writeProtoError(c, 3, "缺少 token")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeUnauthenticated, "缺少 token")
 		return
 	}
 	claims, err := common.VerifyWalletJWT(authHeader)
 	if err != nil {
 		logger.Loginf(c.Request.Context(), "wallet refresh verify failed err=%v", err)
-		writeProtoError(c, 3, "token 无效或已过期")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeUnauthenticated, "token 无效或已过期")
+		return
+	}
+	if claims.ExpiresAt != nil && walletRefreshTooEarly(claims.ExpiresAt.Time, time.Now()) {
+		logger.Loginf(c.Request.Context(), "wallet refresh rejected, token still fresh user=%s exp=%s", claims.UserID, claims.ExpiresAt.Time.UTC().Format(time.RFC3339))
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeUnauthenticated, "token 剩余有效期充足，请继续使用当前 token")
+		return
+	}
+	if !allowWalletRefresh(c.Request.Context(), claims.UserID) {
+		logger.Loginf(c.Request.Context(), "wallet refresh rate limited user=%s", claims.UserID)
+		c.Header("Retry-After", "3600")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeResourceExhausted, "刷新频率过快")
 		return
 	}
 	user := model.User{Id: claims.UserID}
 	if err := user.FillUserById(); err != nil {
 		logger.Loginf(c.Request.Context(), "wallet refresh user not found id=%s", claims.UserID)
-		writeProtoError(c, 5, "用户不存在")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeNotFound, "用户不存在")
 		return
 	}
-	userAddr := ""
-	if user.WalletAddress != nil {
-		userAddr = strings.ToLower(*user.WalletAddress)
-	}
-	if user.WalletAddress == nil || userAddr != strings.ToLower(claims.WalletAddress) {
-		logger.Loginf(c.Request.Context(), "wallet refresh addr mismatch token=%s user=%s", claims.WalletAddress, userAddr)
-		writeProtoError(c, 3, "钱包地址不匹配")
+	if !model.UserHasWalletAddress(&user, claims.WalletAddress) {
+		logger.Loginf(c.Request.Context(), "wallet refresh addr mismatch token=%s user=%v", claims.WalletAddress, user.WalletAddress)
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeUnauthenticated, "钱包地址不匹配")
 		return
 	}
 	if user.Status != model.UserStatusEnabled {
 		logger.Loginf(c.Request.Context(), "wallet refresh user disabled id=%s", user.Id)
-		writeProtoError(c, 4, "用户已被封禁")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodePermissionDenied, "用户已被封禁")
 		return
 	}
 	if err := usercontroller.SetupSession(&user, c); err != nil {
 		logger.LoginErrorf(c.Request.Context(), "wallet refresh setup session failed user=%s err=%v", user.Id, err)
-		writeProtoError(c, 8, "无法保存会话信息，请重试")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeResourceExhausted, "无法保存会话信息，请重试")
 		return
 	}
-	addr := strings.ToLower(*user.WalletAddress)
+	addr := model.NormalizeWalletAddress(claims.WalletAddress)
 	token, exp, tokenErr := common.GenerateWalletJWT(user.Id, addr)
 	if tokenErr != nil {
 		logger.LoginErrorf(c.Request.Context(), "wallet refresh generate token failed user=%s err=%v", user.Id, tokenErr)
-		writeProtoError(c, 8, "生成 token 失败")
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeResourceExhausted, "生成 token 失败")
 		return
 	}
 	logger.Loginf(c.Request.Context(), "wallet refresh success user=%s addr=%s exp=%s", user.Id, addr, exp.UTC().Format(time.RFC3339))
 	body := gin.H{
 		"token":      token,
+		"jti":        common.WalletJWTID(token),
 		"expires_at": exp.UTC().Format(time.RFC3339),
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -530,12 +1081,53 @@ func WalletRefreshToken(c *gin.Context) {
 	})
 }
 
-func writeProtoError(c *gin.Context, code int, message string) {
-	_ = code
+// walletRotateRefreshToken exchanges a single-use refresh token for a new
+// access token and refresh token.
+func walletRotateRefreshToken(c *gin.Context, refreshToken string) {
+	old, newRefreshToken, refreshExp, err := model.RotateRefreshToken(refreshToken)
+	if err != nil {
+		logger.Loginf(c.Request.Context(), "wallet refresh token rotate failed err=%v", err)
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeUnauthenticated, model.ErrRefreshTokenInvalid.Error())
+		return
+	}
+	user := model.User{Id: old.UserId}
+	if err := user.FillUserById(); err != nil {
+		logger.Loginf(c.Request.Context(), "wallet refresh token user not found id=%s", old.UserId)
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeNotFound, "用户不存在")
+		return
+	}
+	if old.WalletAddress != "" && !model.UserHasWalletAddress(&user, old.WalletAddress) {
+		logger.Loginf(c.Request.Context(), "wallet refresh token addr mismatch token=%s user=%v", old.WalletAddress, user.WalletAddress)
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeUnauthenticated, "钱包地址不匹配")
+		return
+	}
+	if user.Status != model.UserStatusEnabled {
+		logger.Loginf(c.Request.Context(), "wallet refresh token user disabled id=%s", user.Id)
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodePermissionDenied, "用户已被封禁")
+		return
+	}
+	if err := usercontroller.SetupSession(&user, c); err != nil {
+		logger.LoginErrorf(c.Request.Context(), "wallet refresh token setup session failed user=%s err=%v", user.Id, err)
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeResourceExhausted, "无法保存会话信息，请重试")
+		return
+	}
+	token, exp, tokenErr := common.GenerateWalletJWT(user.Id, old.WalletAddress)
+	if tokenErr != nil {
+		logger.LoginErrorf(c.Request.Context(), "wallet refresh token generate access token failed user=%s err=%v", user.Id, tokenErr)
+		common.AbortWithError(c, http.StatusOK, common.ProtoCodeResourceExhausted, "生成 token 失败")
+		return
+	}
+	logger.Loginf(c.Request.Context(), "wallet refresh token rotated user=%s addr=%s exp=%s refresh_exp=%s", user.Id, old.WalletAddress, exp.UTC().Format(time.RFC3339), refreshExp.UTC().Format(time.RFC3339))
 	c.JSON(http.StatusOK, gin.H{
-		"success": false,
-		"message": message,
-		"data":    nil,
+		"success": true,
+		"message": "",
+		"data": gin.H{
+			"token":              token,
+			"jti":                common.WalletJWTID(token),
+			"expires_at":         exp.UTC().Format(time.RFC3339),
+			"refresh_token":      newRefreshToken,
+			"refresh_expires_at": refreshExp.UTC().Format(time.RFC3339),
+		},
 	})
 }
 
@@ -559,21 +1151,23 @@ func WalletChallengeWeb3(c *gin.Context) {
 		return
 	}
 	addr := strings.ToLower(req.Address)
-	if !model.IsWalletAddressAlreadyTaken(addr) && !config.AutoRegisterEnabled {
+	if !model.IsWalletAddressAlreadyTaken(addr) && !model.WalletAutoRegisterEnabled() {
 		logger.Loginf(c.Request.Context(), "wallet web3 challenge reject addr=%s not bound and auto-register disabled", addr)
 		writeWeb3Error(c, 5, "钱包未绑定账户，请先绑定或由管理员开启自动注册")
 		return
 	}
-	now := time.Now()
-	nonce, message := common.GenerateWalletNonce(addr, "Login to "+config.SystemName, req.ChainId)
-	expiresAt := now.Add(time.Duration(config.NonceTTLMinutes) * time.Minute)
-	logger.Loginf(c.Request.Context(), "wallet web3 challenge success addr=%s nonce=%s chain=%s", addr, nonce, req.ChainId)
+	entry, err := common.GenerateWalletNonce(addr, "Login to "+config.SystemName, req.ChainId)
+	if err != nil {
+		writeWeb3Error(c, 8, "生成 nonce 失败，请重试")
+		return
+	}
+	logger.Loginf(c.Request.Context(), "wallet web3 challenge success addr=%s nonce=%s chain=%s", addr, entry.Nonce, req.ChainId)
 	writeWeb3OK(c, gin.H{
 		"address":   addr,
-		"challenge": message,
-		"nonce":     nonce,
-		"issuedAt":  now.UnixMilli(),
-		"expiresAt": expiresAt.UnixMilli(),
+		"challenge": entry.Message,
+		"nonce":     entry.Nonce,
+		"issuedAt":  entry.IssuedAt.UnixMilli(),
+		"expiresAt": entry.ExpireAt.UnixMilli(),
 	})
 }
 
@@ -589,15 +1183,19 @@ func WalletChallengeWeb3(c *gin.Context) {
 // WalletVerifyWeb3 implements /api/v1/public/auth/verify
 func WalletVerifyWeb3(c *gin.Context) {
 	var req walletLoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindWalletLoginRequest(c, &req); err != nil {
 		logger.Loginf(c.Request.Context(), "wallet web3 verify bind fail err=%v", err)
-		writeWeb3Error(c, 2, "参数错误")
+		if walletErrorCode(err) == 0 {
+			writeWeb3Error(c, 2, err.Error())
+			return
+		}
+		writeWeb3AuthError(c, err)
 		return
 	}
 	user, err := walletAuthenticate(c, req)
 	if err != nil {
 		logger.Loginf(c.Request.Context(), "wallet web3 verify auth fail addr=%s err=%v", req.Address, err)
-		writeWeb3Error(c, 3, err.Error())
+		writeWeb3AuthError(c, err)
 		return
 	}
 	if err := usercontroller.SetupSession(user, c); err != nil {
@@ -607,7 +1205,7 @@ func WalletVerifyWeb3(c *gin.Context) {
 	}
 	addr := ""
 	if user.WalletAddress != nil {
-		addr = strings.ToLower(*user.WalletAddress)
+		addr = model.NormalizeWalletAddress(*user.WalletAddress)
 	}
 	accessToken, accessExp, tokenErr := common.GenerateWalletJWT(user.Id, addr)
 	if tokenErr != nil {
@@ -626,6 +1224,7 @@ func WalletVerifyWeb3(c *gin.Context) {
 	writeWeb3OK(c, gin.H{
 		"address":          addr,
 		"token":            accessToken,
+		"jti":              common.WalletJWTID(accessToken),
 		"expiresAt":        accessExp.UnixMilli(),
 		"refreshExpiresAt": refreshExp.UnixMilli(),
 	})
@@ -659,12 +1258,8 @@ func WalletRefreshWeb3(c *gin.Context) {
 		writeWeb3Error(c, 5, "用户不存在")
 		return
 	}
-	userAddr := ""
-	if user.WalletAddress != nil {
-		userAddr = strings.ToLower(*user.WalletAddress)
-	}
-	if user.WalletAddress == nil || userAddr != strings.ToLower(claims.WalletAddress) {
-		logger.Loginf(c.Request.Context(), "wallet web3 refresh addr mismatch token=%s user=%s", claims.WalletAddress, userAddr)
+	if !model.UserHasWalletAddress(&user, claims.WalletAddress) {
+		logger.Loginf(c.Request.Context(), "wallet web3 refresh addr mismatch token=%s user=%v", claims.WalletAddress, user.WalletAddress)
 		writeWeb3Error(c, 3, "钱包地址不匹配")
 		return
 	}
@@ -678,7 +1273,7 @@ func WalletRefreshWeb3(c *gin.Context) {
 		writeWeb3Error(c, 8, "无法保存会话信息，请重试")
 		return
 	}
-	addr := strings.ToLower(*user.WalletAddress)
+	addr := model.NormalizeWalletAddress(claims.WalletAddress)
 	accessToken, accessExp, tokenErr := common.GenerateWalletJWT(user.Id, addr)
 	if tokenErr != nil {
 		logger.LoginErrorf(c.Request.Context(), "wallet web3 refresh generate token failed user=%s err=%v", user.Id, tokenErr)
@@ -696,6 +1291,7 @@ func WalletRefreshWeb3(c *gin.Context) {
 	writeWeb3OK(c, gin.H{
 		"address":          addr,
 		"token":            accessToken,
+		"jti":              common.WalletJWTID(accessToken),
 		"expiresAt":        accessExp.UnixMilli(),
 		"refreshExpiresAt": refreshExp.UnixMilli(),
 	})
@@ -714,6 +1310,7 @@ func WalletLogoutWeb3(c *gin.Context) {
 	session := sessions.Default(c)
 	session.Clear()
 	_ = session.Save()
+	revokeLogoutWalletTokens(c)
 	clearWalletRefreshCookie(c)
 	writeWeb3OK(c, gin.H{
 		"logout": true,
@@ -738,6 +1335,17 @@ func writeWeb3Error(c *gin.Context, code int, message string) {
 	})
 }
 
+// writeWeb3AuthError is writeWeb3Error plus the wallet error_code of err.
+func writeWeb3AuthError(c *gin.Context, err error) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":       walletAuthErrorCode(err),
+		"message":    err.Error(),
+		"error_code": walletErrorCode(err),
+		"data":       nil,
+		"timestamp":  time.Now().UnixMilli(),
+	})
+}
+
 func setWalletRefreshCookie(c *gin.Context, token string, expiresAt time.Time) {
 	maxAge := int(time.Until(expiresAt).Seconds())
 	if maxAge < 0 {
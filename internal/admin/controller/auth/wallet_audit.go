@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+const (
+	walletAuditNonce     = "nonce"
+	walletAuditLogin     = "login"
+	walletAuditBind      = "bind"
+	walletAuditSignature = "signature_recover"
+	walletAuditImport    = "admin_import"
+	// wallet-first signup: signature check, then account creation
+	walletAuditBindInit     = "bind_init"
+	walletAuditBindComplete = "bind_complete"
+)
+
+// auditWallet records one wallet auth step in the audit log; err == nil marks
+// success and its message becomes the reason otherwise. c may be nil for steps
+// that run outside a request.
+func auditWallet(c *gin.Context, event, address, userID, chainID string, err error) {
+	entry := logger.WalletAuditEvent{
+		Event:   event,
+		Address: model.NormalizeWalletAddress(address),
+		UserID:  userID,
+		ChainID: strings.TrimSpace(chainID),
+		Success: err == nil,
+	}
+	if err != nil {
+		entry.Reason = err.Error()
+	}
+	ctx := context.Background()
+	if c != nil {
+		ctx = c.Request.Context()
+		entry.IP = c.ClientIP()
+		entry.UserAgent = c.Request.UserAgent()
+	}
+	logger.WriteWalletAudit(ctx, entry)
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// walletBindInitRequest is a signed wallet challenge of a user without an
+// account, plus the email to register with.
+type walletBindInitRequest struct {
+	walletLoginRequest
+	Email string `json:"email"`
+}
+
+type walletBindCompleteRequest struct {
+	BindingToken string `json:"binding_token" binding:"required"`
+	Username     string `json:"username" binding:"required"`
+	Password     string `json:"password" binding:"required"`
+}
+
+// WalletBindInit godoc
+// @Summary Verify a wallet before registering (wallet-first signup)
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param body body docs.WalletBindInitRequest true "Wallet bind init payload"
+// @Success 200 {object} docs.StandardResponse
+// @Router /api/v1/public/oauth/wallet/bind/init [post]
+// WalletBindInit checks a signed challenge for an unbound wallet and returns a
+// short-lived binding token. Nothing is written to the database.
+func WalletBindInit(c *gin.Context) {
+	if err := walletSignupAllowed(); err != nil {
+		c.JSON(http.StatusOK, gin.H{"success": false, "message": err.Error()})
+		return
+	}
+	var req walletBindInitRequest
+	if err := walletRequestBindError(c.ShouldBindJSON(&req), &req.walletLoginRequest); err != nil {
+		rejectWalletBindSignup(c, walletAuditBindInit, req.Address, req.ChainId, err)
+		return
+	}
+	email := strings.TrimSpace(req.Email)
+	if email != "" {
+		if err := common.Validate.Var(email, "email,max=50"); err != nil {
+			rejectWalletBindSignup(c, walletAuditBindInit, req.Address, req.ChainId, errWalletBadRequest)
+			return
+		}
+		if model.IsEmailAlreadyTaken(email) {
+			rejectWalletBindSignup(c, walletAuditBindInit, req.Address, req.ChainId, errWalletSignupEmailTaken)
+			return
+		}
+	}
+	if err := verifyWalletRequest(c.Request.Context(), req.walletLoginRequest); err != nil {
+		rejectWalletBindSignup(c, walletAuditBindInit, req.Address, req.ChainId, err)
+		return
+	}
+	addr := model.NormalizeWalletAddress(req.Address)
+	if err := ensureWalletAddressFree(addr); err != nil {
+		rejectWalletBindSignup(c, walletAuditBindInit, addr, req.ChainId, err)
+		return
+	}
+	walletType, _ := common.NormalizeWalletType(req.WalletType)
+	token, exp, err := common.GenerateWalletBindJWT(addr, walletType, email)
+	if err != nil {
+		logger.LoginErrorf(c.Request.Context(), "wallet bind token generate failed addr=%s err=%v", addr, err)
+		rejectWalletBindSignup(c, walletAuditBindInit, addr, req.ChainId, err)
+		return
+	}
+	auditWallet(c, walletAuditBindInit, addr, "", req.ChainId, nil)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data": gin.H{
+			"binding_token": token,
+			"expires_at":    exp.UTC().Format(time.RFC3339),
+		},
+	})
+}
+
+// WalletBindComplete godoc
+// @Summary Create a password account with a pre-bound wallet
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param body body docs.WalletBindCompleteRequest true "Wallet bind complete payload"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/public/oauth/wallet/bind/complete [post]
+// WalletBindComplete redeems a binding token from WalletBindInit and creates
+// the user with the wallet already bound. Each token creates one account.
+func WalletBindComplete(c *gin.Context) {
+	if err := walletSignupAllowed(); err != nil {
+		c.JSON(http.StatusOK, gin.H{"success": false, "message": err.Error()})
+		return
+	}
+	var req walletBindCompleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{"success": false, "message": errWalletBadRequest.Error()})
+		return
+	}
+	claims, err := common.VerifyWalletBindJWT(strings.TrimSpace(req.BindingToken))
+	if err != nil {
+		logger.Loginf(c.Request.Context(), "wallet bind token rejected err=%v", err)
+		bindErr := newWalletError(WalletErrBindTokenInvalid)
+		auditWallet(c, walletAuditBindComplete, "", "", "", bindErr)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":    false,
+			"message":    bindErr.Error(),
+			"error_code": bindErr.Code,
+		})
+		return
+	}
+	addr := claims.WalletAddress
+	user := model.User{
+		Username:      strings.TrimSpace(req.Username),
+		Password:      req.Password,
+		DisplayName:   strings.TrimSpace(req.Username),
+		Email:         claims.Email,
+		WalletAddress: &addr,
+		WalletType:    claims.WalletType,
+		HasPassword:   true,
+	}
+	if err := common.Validate.Struct(&user); err != nil {
+		c.JSON(http.StatusOK, gin.H{"success": false, "message": "用户名或密码格式不正确"})
+		return
+	}
+	if model.IsUsernameAlreadyTaken(user.Username) {
+		c.JSON(http.StatusOK, gin.H{"success": false, "message": "用户名已被占用"})
+		return
+	}
+	// the wallet may have been bound elsewhere since the token was issued
+	if err := ensureWalletAddressFree(addr); err != nil {
+		rejectWalletBindSignup(c, walletAuditBindComplete, addr, "", err)
+		return
+	}
+	if err := common.ClaimWalletBindJWT(claims); err != nil {
+		logger.Loginf(c.Request.Context(), "wallet bind token claim failed addr=%s err=%v", addr, err)
+		rejectWalletBindSignup(c, walletAuditBindComplete, addr, "", newWalletError(WalletErrBindTokenInvalid))
+		return
+	}
+	if err := user.Insert(c.Request.Context(), ""); err != nil {
+		logger.LoginErrorf(c.Request.Context(), "wallet bind signup create user failed addr=%s err=%v", addr, err)
+		auditWallet(c, walletAuditBindComplete, addr, "", "", err)
+		c.JSON(http.StatusOK, gin.H{"success": false, "message": err.Error()})
+		return
+	}
+	logger.Loginf(c.Request.Context(), "wallet bind signup success user=%s addr=%s", user.Id, addr)
+	auditWallet(c, walletAuditBindComplete, addr, user.Id, "", nil)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    safeUserResponse(&user),
+	})
+}
+
+var errWalletSignupEmailTaken = errors.New("邮箱地址已被占用")
+
+// walletSignupAllowed applies the password registration switches, since a
+// completed wallet-first signup is a password account.
+func walletSignupAllowed() error {
+	if !config.RegisterEnabled {
+		return errors.New("管理员关闭了新用户注册")
+	}
+	if !config.PasswordRegisterEnabled {
+		return errors.New("管理员关闭了通过密码进行注册")
+	}
+	return nil
+}
+
+// ensureWalletAddressFree fails when addr belongs to an account, first
+// releasing it from a deleted one.
+func ensureWalletAddressFree(addr string) error {
+	if !model.IsWalletAddressAlreadyTaken(addr) {
+		return nil
+	}
+	if err := model.ReleaseWalletAddress(addr); err != nil {
+		return err
+	}
+	if model.IsWalletAddressAlreadyTaken(addr) {
+		return newWalletError(WalletErrAddressTaken)
+	}
+	return nil
+}
+
+func rejectWalletBindSignup(c *gin.Context, event, addr, chainID string, err error) {
+	auditWallet(c, event, addr, "", chainID, err)
+	c.JSON(http.StatusOK, gin.H{
+		"success":    false,
+		"message":    err.Error(),
+		"error_code": walletErrorCode(err),
+	})
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestWalletBindSignup_RejectsBeforeTouchingDB(t *testing.T) {
+	common.RegisterValidators()
+	prevSecret, prevAlgorithm := config.JWTSecret, config.WalletJWTAlgorithm
+	prevRegister, prevPassword := config.RegisterEnabled, config.PasswordRegisterEnabled
+	defer func() {
+		config.JWTSecret, config.WalletJWTAlgorithm = prevSecret, prevAlgorithm
+		config.RegisterEnabled, config.PasswordRegisterEnabled = prevRegister, prevPassword
+	}()
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = common.WalletJWTAlgorithmHS256
+	config.RegisterEnabled, config.PasswordRegisterEnabled = true, true
+
+	used, _, err := common.GenerateWalletBindJWT("0x1111111111111111111111111111111111111111", common.WalletTypeEthereum, "")
+	if err != nil {
+		t.Fatalf("GenerateWalletBindJWT error: %v", err)
+	}
+	claims, _ := common.VerifyWalletBindJWT(used)
+	if err := common.ClaimWalletBindJWT(claims); err != nil {
+		t.Fatalf("ClaimWalletBindJWT error: %v", err)
+	}
+	access, _, _ := common.GenerateWalletJWT("user-1", "0x1111111111111111111111111111111111111111")
+
+	engine := testutil.NewTestEngine()
+	engine.POST("/init", WalletBindInit)
+	engine.POST("/complete", WalletBindComplete)
+	cases := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   int
+	}{
+		{"init invalid address", "/init", `{"address":"0x123","signature":"0x00","email":"a@example.com"}`, http.StatusOK, WalletErrInvalidAddress},
+		{"complete garbage token", "/complete", `{"binding_token":"abc","username":"alice","password":"password123"}`, http.StatusUnauthorized, WalletErrBindTokenInvalid},
+		{"complete used token", "/complete", `{"binding_token":"` + used + `","username":"alice","password":"password123"}`, http.StatusUnauthorized, WalletErrBindTokenInvalid},
+		{"complete access token", "/complete", `{"binding_token":"` + access + `","username":"alice","password":"password123"}`, http.StatusUnauthorized, WalletErrBindTokenInvalid},
+	}
+	for _, tc := range cases {
+		w := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodPost, tc.target, tc.body))
+		var resp struct {
+			Success   bool `json:"success"`
+			ErrorCode int  `json:"error_code"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: decode response: %v", tc.name, err)
+		}
+		if w.Code != tc.wantStatus || resp.Success || resp.ErrorCode != tc.wantCode {
+			t.Fatalf("%s: status=%d body=%s, want status %d code %d", tc.name, w.Code, w.Body.String(), tc.wantStatus, tc.wantCode)
+		}
+	}
+
+	config.PasswordRegisterEnabled = false
+	w := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodPost, "/complete", `{}`))
+	if !strings.Contains(w.Body.String(), "管理员关闭了通过密码进行注册") {
+		t.Fatalf("password registration switch ignored: %s", w.Body.String())
+	}
+}
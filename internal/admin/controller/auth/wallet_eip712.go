@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+)
+
+const (
+	walletSignTypePersonal  = "personal"
+	walletSignTypeTypedData = "typed_data"
+
+	walletTypedDataVersion     = "1"
+	walletTypedDataPrimaryType = "Login"
+)
+
+// walletLoginTypedData is the EIP-712 payload signed with eth_signTypedData_v4:
+// the nonce message wrapped in a Login struct under a domain named after
+// config.SystemName and bound to the request chain id.
+func walletLoginTypedData(message, chainId string) (apitypes.TypedData, error) {
+	normalized, err := common.NormalizeChainId(chainId)
+	if err != nil || normalized == "" {
+		return apitypes.TypedData{}, errors.New("typed_data 签名需要有效的 chain_id")
+	}
+	domainChainId, ok := math.ParseBig256(normalized)
+	if !ok {
+		return apitypes.TypedData{}, errors.New("typed_data 签名需要有效的 chain_id")
+	}
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+			},
+			walletTypedDataPrimaryType: {
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: walletTypedDataPrimaryType,
+		Domain: apitypes.TypedDataDomain{
+			Name:    config.SystemName,
+			Version: walletTypedDataVersion,
+			ChainId: (*math.HexOrDecimal256)(domainChainId),
+		},
+		Message: apitypes.TypedDataMessage{
+			"contents": message,
+		},
+	}, nil
+}
+
+// walletTypedDataHash is the EIP-712 digest of walletLoginTypedData.
+func walletTypedDataHash(message, chainId string) ([]byte, error) {
+	typedData, err := walletLoginTypedData(message, chainId)
+	if err != nil {
+		return nil, err
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	return hash, err
+}
+
+func normalizeWalletSignType(signType string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(signType)) {
+	case "", walletSignTypePersonal:
+		return walletSignTypePersonal, nil
+	case walletSignTypeTypedData:
+		return walletSignTypeTypedData, nil
+	default:
+		return "", errors.New("不支持的签名类型")
+	}
+}
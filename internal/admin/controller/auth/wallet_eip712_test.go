@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// TestRecoverSigner_EIP712MailVector checks the "Ether Mail" example from the
+// EIP-712 specification: digest and signature by the "Cow" key.
+func TestRecoverSigner_EIP712MailVector(t *testing.T) {
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallet", Type: "address"},
+			},
+			"Mail": {
+				{Name: "from", Type: "Person"},
+				{Name: "to", Type: "Person"},
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Ether Mail",
+			Version:           "1",
+			ChainId:           math.NewHexOrDecimal256(1),
+			VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+		},
+		Message: apitypes.TypedDataMessage{
+			"from": map[string]interface{}{
+				"name":   "Cow",
+				"wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+			},
+			"to": map[string]interface{}{
+				"name":   "Bob",
+				"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+			},
+			"contents": "Hello, Bob!",
+		},
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatalf("TypedDataAndHash error: %v", err)
+	}
+	if got := hex.EncodeToString(hash); got != "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2" {
+		t.Fatalf("digest = %s", got)
+	}
+	signature := "0x" +
+		"4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" +
+		"1c"
+	signer, err := recoverSigner(hash, signature)
+	if err != nil {
+		t.Fatalf("recoverSigner error: %v", err)
+	}
+	if signer != "0xcd2a3d9f938e13cd947ec05abc7fe734df8dd826" {
+		t.Fatalf("signer = %s", signer)
+	}
+}
+
+func TestRecoverAddress_TypedData(t *testing.T) {
+	key, err := crypto.ToECDSA(math.PaddedBigBytes(big.NewInt(0x1234567), 32))
+	if err != nil {
+		t.Fatalf("ToECDSA error: %v", err)
+	}
+	address := strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+	message := "Login to Router\nNonce: abc\nAddress: " + address
+
+	hash, err := walletTypedDataHash(message, "1")
+	if err != nil {
+		t.Fatalf("walletTypedDataHash error: %v", err)
+	}
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+	sig[64] += 27
+	signature := "0x" + hex.EncodeToString(sig)
+
+	if got, err := recoverAddress(message, signature, "typed_data", "0x1"); err != nil || got != address {
+		t.Fatalf("recoverAddress(typed_data) = %s, %v; want %s", got, err, address)
+	}
+	// the domain binds the chain id and the digest differs from personal_sign
+	if got, _ := recoverAddress(message, signature, "typed_data", "5"); got == address {
+		t.Fatalf("typed_data signature accepted for another chain")
+	}
+	if got, _ := recoverAddress(message, signature, "personal", ""); got == address {
+		t.Fatalf("typed_data signature accepted as personal_sign")
+	}
+	if _, err := recoverAddress(message, signature, "typed_data", ""); err == nil {
+		t.Fatalf("typed_data without chain_id accepted")
+	}
+	if _, err := recoverAddress(message, signature, "eth_sign", "1"); err == nil {
+		t.Fatalf("unknown sign_type accepted")
+	}
+}
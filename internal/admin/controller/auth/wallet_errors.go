@@ -0,0 +1,77 @@
+package auth
+
+import "errors"
+
+// Wallet authentication error codes, returned as error_code next to message.
+// The table is mirrored in docs/接口文档.md; codes are never reused.
+const (
+	WalletErrNonceExpired        = 1001
+	WalletErrSignatureMismatch   = 1002
+	WalletErrAddressMismatch     = 1003
+	WalletErrInvalidAddress      = 1004
+	WalletErrUnsupportedType     = 1005
+	WalletErrMissingSignature    = 1006
+	WalletErrChainIdRequired     = 1007
+	WalletErrChainNotAllowed     = 1008
+	WalletErrUserPendingApproval = 1009
+	WalletErrUserDisabled        = 1010
+	WalletErrUserNotFound        = 1011
+	WalletErrAutoRegisterPaused  = 1012
+	WalletErrUseWebAuthn         = 1013
+	WalletErrSignatureReplayed   = 1014
+	WalletErrInvalidSIWEMessage  = 1015
+	WalletErrAddressTaken        = 1016
+	WalletErrBindTokenInvalid    = 1017
+)
+
+// walletErrorMessages holds the default message of every code.
+var walletErrorMessages = map[int]string{
+	WalletErrNonceExpired:        "nonce 无效或已过期",
+	WalletErrSignatureMismatch:   "签名验证失败",
+	WalletErrAddressMismatch:     "签名地址与请求地址不一致",
+	WalletErrInvalidAddress:      "无效的钱包地址",
+	WalletErrUnsupportedType:     "不支持的钱包类型",
+	WalletErrMissingSignature:    "缺少签名或 nonce",
+	WalletErrChainIdRequired:     "chain_id 为必填项",
+	WalletErrChainNotAllowed:     "不支持的链 ID",
+	WalletErrUserPendingApproval: "账户正在审批，请等待管理员确认",
+	WalletErrUserDisabled:        "用户已被封禁",
+	WalletErrUserNotFound:        "未找到钱包绑定的账户，请先绑定或由管理员开启自动注册",
+	WalletErrAutoRegisterPaused:  "钱包登录错误率过高，自动注册已暂停，请稍后再试",
+	WalletErrUseWebAuthn:         "通行密钥请使用 /api/v1/public/auth/webauthn 登录",
+	WalletErrSignatureReplayed:   "签名已被使用，请重新获取 nonce",
+	WalletErrInvalidSIWEMessage:  "SIWE 签名消息无效、已过期或与当前站点不匹配",
+	WalletErrAddressTaken:        "该钱包已绑定其他账户，请直接使用钱包登录",
+	WalletErrBindTokenInvalid:    "绑定凭证无效、已使用或已过期，请重新签名",
+}
+
+// WalletError is a wallet authentication failure with a stable code that
+// clients can branch on or localize instead of matching Message.
+type WalletError struct {
+	Code    int
+	Message string
+}
+
+func newWalletError(code int) *WalletError {
+	return &WalletError{Code: code, Message: walletErrorMessages[code]}
+}
+
+func (e *WalletError) Error() string {
+	return e.Message
+}
+
+// Is matches any WalletError with the same code.
+func (e *WalletError) Is(target error) bool {
+	var other *WalletError
+	return errors.As(target, &other) && other.Code == e.Code
+}
+
+// walletErrorCode returns the wallet error code of err, or 0 when err is not
+// a WalletError.
+func walletErrorCode(err error) int {
+	var walletErr *WalletError
+	if errors.As(err, &walletErr) {
+		return walletErr.Code
+	}
+	return 0
+}
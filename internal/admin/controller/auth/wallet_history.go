@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+const maxWalletHistoryPageSize = 100
+
+// recordWalletLoginHistory stores the outcome of a wallet login. Failed
+// attempts are attributed to the account that owns the address, if any, so
+// its owner can see them.
+func recordWalletLoginHistory(c *gin.Context, req walletLoginRequest, user *model.User, err error) {
+	if model.DB == nil {
+		return
+	}
+	addr := model.NormalizeWalletAddress(req.Address)
+	entry := model.WalletLoginHistory{
+		WalletAddress: addr,
+		ChainId:       strings.TrimSpace(req.ChainId),
+		Ip:            c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+		Success:       err == nil,
+	}
+	if user != nil {
+		entry.UserId = user.Id
+	} else if addr != "" {
+		owner := model.User{WalletAddress: &addr}
+		if owner.FillUserByWalletAddress() == nil {
+			entry.UserId = owner.Id
+		}
+	}
+	if err != nil {
+		entry.Reason = err.Error()
+	}
+	if createErr := model.CreateWalletLoginHistory(&entry); createErr != nil {
+		logger.LoginErrorf(c.Request.Context(), "record wallet login history failed addr=%s err=%v", addr, createErr)
+	}
+}
+
+func parseWalletHistoryPageParams(c *gin.Context) (page int, pageSize int) {
+	page = 1
+	if raw := strings.TrimSpace(c.Query("page")); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	pageSize = config.ItemsPerPage
+	if raw := strings.TrimSpace(c.Query("page_size")); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			pageSize = parsed
+		}
+	}
+	if pageSize > maxWalletHistoryPageSize {
+		pageSize = maxWalletHistoryPageSize
+	}
+	return page, pageSize
+}
+
+// WalletLoginHistory godoc
+// @Summary Wallet login history of the current user
+// @Tags public
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Param user_id query string false "User ID, admin only"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Failure 403 {object} docs.ErrorResponse
+// @Router /api/v1/public/oauth/wallet/history [get]
+// WalletLoginHistory lists wallet login attempts, newest first, for the
+// current user or, for admins, the user named by user_id
+func WalletLoginHistory(c *gin.Context) {
+	userId := strings.TrimSpace(c.GetString(ctxkey.Id))
+	if userId == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "未登录",
+		})
+		return
+	}
+	if target := strings.TrimSpace(c.Query("user_id")); target != "" && target != userId {
+		if c.GetInt(ctxkey.Role) < model.RoleAdminUser {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"message": "无权查看其他用户的登录记录",
+			})
+			return
+		}
+		userId = target
+	}
+	page, pageSize := parseWalletHistoryPageParams(c)
+	rows, total, err := model.ListWalletLoginHistoryPage(userId, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data": gin.H{
+			"items":     rows,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
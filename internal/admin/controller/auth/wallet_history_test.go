@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestParseWalletHistoryPageParams(t *testing.T) {
+	tests := []struct {
+		query        string
+		wantPage     int
+		wantPageSize int
+	}{
+		{"", 1, config.ItemsPerPage},
+		{"?page=3&page_size=20", 3, 20},
+		{"?page=0&page_size=-1", 1, config.ItemsPerPage},
+		{"?page=abc&page_size=1000", 1, maxWalletHistoryPageSize},
+	}
+	for _, tt := range tests {
+		c, _ := testutil.NewTestGinContext(http.MethodGet, "/history"+tt.query, nil)
+		page, pageSize := parseWalletHistoryPageParams(c)
+		if page != tt.wantPage || pageSize != tt.wantPageSize {
+			t.Fatalf("query %q = (%d, %d), want (%d, %d)", tt.query, page, pageSize, tt.wantPage, tt.wantPageSize)
+		}
+	}
+}
+
+func TestWalletLoginHistory_OtherUserRequiresAdmin(t *testing.T) {
+	c, recorder := testutil.NewTestGinContext(http.MethodGet, "/history?user_id=user-2", nil)
+	c.Set(ctxkey.Id, "user-1")
+	c.Set(ctxkey.Role, model.RoleCommonUser)
+	WalletLoginHistory(c)
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", recorder.Code)
+	}
+}
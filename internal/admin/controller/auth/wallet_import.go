@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// WalletImportMaxBytes caps the CSV accepted by ImportWalletBindings.
+const WalletImportMaxBytes int64 = 10 << 20
+
+type walletImportRow struct {
+	Line          int    `json:"line"`
+	UserId        string `json:"user_id"`
+	WalletAddress string `json:"wallet_address"`
+	ChainId       string `json:"chain_id"`
+}
+
+type walletImportFailure struct {
+	walletImportRow
+	Reason string `json:"reason"`
+}
+
+// parseWalletImportCSV reads a CSV with a user_id,wallet_address,chain_id
+// header; the columns may come in any order and chain_id may be omitted.
+// Line numbers are those of the file, so failures can be found in it.
+func parseWalletImportCSV(r io.Reader) ([]walletImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSV 文件为空")
+		}
+		return nil, err
+	}
+	columns := map[string]int{"user_id": -1, "wallet_address": -1, "chain_id": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	if columns["user_id"] < 0 || columns["wallet_address"] < 0 {
+		return nil, errors.New("CSV 表头需包含 user_id 与 wallet_address")
+	}
+	field := func(record []string, name string) string {
+		if i := columns[name]; i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var rows []walletImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		row := walletImportRow{
+			Line:          line,
+			UserId:        field(record, "user_id"),
+			WalletAddress: field(record, "wallet_address"),
+			ChainId:       field(record, "chain_id"),
+		}
+		if row.UserId == "" && row.WalletAddress == "" && row.ChainId == "" {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ImportWalletBindings godoc
+// @Summary Bulk-import wallet bindings from CSV (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV with user_id,wallet_address,chain_id"
+// @Success 200 {object} docs.StandardResponse
+// @Router /api/v1/admin/wallet/import [post]
+// ImportWalletBindings binds wallet addresses to existing accounts without a
+// signature, for migrating users from another system. Each row is bound on its
+// own; failed rows are reported and do not stop the import.
+func ImportWalletBindings(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": "请上传 CSV 文件（字段名 file）",
+		})
+		return
+	}
+	if fileHeader.Size > WalletImportMaxBytes {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": fmt.Sprintf("CSV 文件过大，最大允许 %d 字节", WalletImportMaxBytes),
+		})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+	rows, err := parseWalletImportCSV(io.LimitReader(file, WalletImportMaxBytes))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": "CSV 解析失败: " + err.Error(),
+		})
+		return
+	}
+	failures := make([]walletImportFailure, 0)
+	successCount := 0
+	for _, row := range rows {
+		if err := importWalletBinding(c, row); err != nil {
+			failures = append(failures, walletImportFailure{walletImportRow: row, Reason: err.Error()})
+			continue
+		}
+		successCount++
+	}
+	logger.Loginf(c.Request.Context(), "wallet import by=%s rows=%d success=%d failure=%d", c.GetString(ctxkey.Id), len(rows), successCount, len(failures))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data": gin.H{
+			"success_count": successCount,
+			"failure_count": len(failures),
+			"failures":      failures,
+		},
+	})
+}
+
+func importWalletBinding(c *gin.Context, row walletImportRow) error {
+	err := bindImportedWallet(row)
+	auditWallet(c, walletAuditImport, row.WalletAddress, row.UserId, row.ChainId, err)
+	return err
+}
+
+func bindImportedWallet(row walletImportRow) error {
+	if row.UserId == "" {
+		return errors.New("user_id 为空")
+	}
+	walletType := common.WalletTypeEthereum
+	if !common.IsValidWalletAddress(row.WalletAddress, walletType) {
+		walletType = common.WalletTypeSolana
+		if !common.IsValidWalletAddress(row.WalletAddress, walletType) {
+			return newWalletError(WalletErrInvalidAddress)
+		}
+	}
+	chainId := row.ChainId
+	if chainId != "" && walletType == common.WalletTypeEthereum {
+		normalized, err := common.NormalizeChainId(chainId)
+		if err != nil {
+			return errors.New("无效的 chain_id")
+		}
+		chainId = normalized
+	}
+	user := model.User{Id: row.UserId}
+	if err := user.FillUserById(); err != nil {
+		return errors.New("用户不存在")
+	}
+	if user.Status == model.UserStatusDeleted {
+		return errors.New("用户已删除")
+	}
+	addr := model.NormalizeWalletAddress(row.WalletAddress)
+	if model.IsWalletAddressAlreadyTaken(addr) {
+		if model.UserHasWalletAddress(&user, addr) {
+			return nil
+		}
+		return model.ErrWalletBoundToOtherUser
+	}
+	previous := user.WalletAddress
+	if err := model.BindUserWalletWithDB(model.DB, &user, addr, chainId, walletType); err != nil {
+		return err
+	}
+	model.RevokeOnWalletAddressChange(user.Id, previous, user.WalletAddress)
+	return nil
+}
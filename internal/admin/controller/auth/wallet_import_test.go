@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWalletImportCSV(t *testing.T) {
+	input := "\ufeffwallet_address, user_id ,chain_id\n" +
+		"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed,u-1,1\n" +
+		"\n" +
+		"0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359,u-2\n"
+	rows, err := parseWalletImportCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseWalletImportCSV error: %v", err)
+	}
+	want := []walletImportRow{
+		{Line: 2, UserId: "u-1", WalletAddress: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", ChainId: "1"},
+		{Line: 4, UserId: "u-2", WalletAddress: "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %+v, want %+v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Fatalf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"", "user_id,chain_id\nu-1,1\n", "user_id,wallet_address\n\"u-1,0xabc\n"} {
+		if _, err := parseWalletImportCSV(strings.NewReader(bad)); err == nil {
+			t.Fatalf("parseWalletImportCSV(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/logger"
+)
+
+// WalletLogout godoc
+// @Summary Logout and revoke the wallet JWT
+// @Tags public
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Router /api/v1/public/oauth/wallet/logout [post]
+// WalletLogout clears the session and revokes the bearer token and the refresh
+// cookie token, so they are rejected even though they have not expired.
+func WalletLogout(c *gin.Context) {
+	session := sessions.Default(c)
+	session.Clear()
+	_ = session.Save()
+	revoked := revokeLogoutWalletTokens(c)
+	clearWalletRefreshCookie(c)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    gin.H{"revoked": revoked},
+	})
+}
+
+// revokeLogoutWalletTokens revokes the wallet JWTs presented with a logout
+// request and returns their jtis. Invalid or expired tokens are skipped: there
+// is nothing left to revoke.
+func revokeLogoutWalletTokens(c *gin.Context) []string {
+	var tokens []string
+	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
+	if strings.HasPrefix(strings.ToLower(authHeader), "bearer ") {
+		tokens = append(tokens, strings.TrimSpace(authHeader[7:]))
+	}
+	if refreshToken, err := c.Cookie(walletRefreshCookieName); err == nil && strings.TrimSpace(refreshToken) != "" {
+		tokens = append(tokens, strings.TrimSpace(refreshToken))
+	}
+	revoked := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		claims, err := common.ParseWalletJWTClaims(token)
+		if err != nil || claims.ID == "" {
+			continue
+		}
+		if err := common.RevokeWalletJWT(claims.ID); err != nil {
+			logger.LoginErrorf(c.Request.Context(), "wallet logout revoke failed user=%s jti=%s err=%v", claims.UserID, claims.ID, err)
+			continue
+		}
+		logger.Loginf(c.Request.Context(), "wallet logout revoked user=%s jti=%s type=%s", claims.UserID, claims.ID, claims.TokenType)
+		revoked = append(revoked, claims.ID)
+	}
+	return revoked
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+)
+
+const (
+	walletRefreshRateLimitMark   = "walletRefresh:"
+	walletRefreshRateLimitWindow = time.Hour
+)
+
+// allowWalletRefresh counts one refresh call for userID and reports whether it
+// is still within config.WalletRefreshRateLimit for the current hour.
+func allowWalletRefresh(ctx context.Context, userID string) bool {
+	limit := config.WalletRefreshRateLimit
+	if limit <= 0 {
+		return true
+	}
+	key := walletRefreshRateLimitMark + userID
+	if common.RedisEnabled && common.RDB != nil {
+		count, err := common.RDB.Incr(ctx, key).Result()
+		if err != nil {
+			logger.LoginErrorf(ctx, "wallet refresh rate limit incr failed user=%s err=%v", userID, err)
+			return true
+		}
+		if count == 1 {
+			if err := common.RDB.Expire(ctx, key, walletRefreshRateLimitWindow).Err(); err != nil {
+				logger.LoginErrorf(ctx, "wallet refresh rate limit expire failed user=%s err=%v", userID, err)
+			}
+		}
+		return count <= int64(limit)
+	}
+	// looked up per call so a reloaded limit takes effect
+	rate := float64(limit) / walletRefreshRateLimitWindow.Seconds()
+	buckets := common.SharedTokenBucketStore(walletRefreshRateLimitMark, rate, limit, walletRefreshRateLimitWindow)
+	return buckets.GetOrCreate(key).Allow()
+}
+
+// walletRefreshTooEarly reports whether the token still has more lifetime left
+// than config.WalletRefreshMinRemainingSeconds.
+func walletRefreshTooEarly(expiresAt, now time.Time) bool {
+	minRemaining := config.WalletRefreshMinRemainingSeconds
+	if minRemaining <= 0 || expiresAt.IsZero() {
+		return false
+	}
+	return expiresAt.Sub(now) > time.Duration(minRemaining)*time.Second
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestWalletRefreshTooEarly(t *testing.T) {
+	prev := config.WalletRefreshMinRemainingSeconds
+	defer func() { config.WalletRefreshMinRemainingSeconds = prev }()
+	now := time.Now()
+	tests := map[string]struct {
+		minRemaining int
+		expiresAt    time.Time
+		want         bool
+	}{
+		"plenty left":    {minRemaining: 300, expiresAt: now.Add(time.Hour), want: true},
+		"about to end":   {minRemaining: 300, expiresAt: now.Add(time.Minute)},
+		"check disabled": {minRemaining: 0, expiresAt: now.Add(time.Hour)},
+		"no expiry":      {minRemaining: 300},
+	}
+	for name, tt := range tests {
+		config.WalletRefreshMinRemainingSeconds = tt.minRemaining
+		if got := walletRefreshTooEarly(tt.expiresAt, now); got != tt.want {
+			t.Fatalf("%s: walletRefreshTooEarly = %t, want %t", name, got, tt.want)
+		}
+	}
+}
+
+type walletRefreshResponse struct {
+	Success bool `json:"success"`
+	Status  struct {
+		Code int `json:"code"`
+	} `json:"status"`
+}
+
+func refreshWalletToken(t *testing.T, token string) (walletRefreshResponse, http.Header) {
+	t.Helper()
+	c, recorder := testutil.NewTestGinContext(http.MethodPost, "/api/v1/public/common/auth/refreshToken", nil)
+	testutil.SetBearerToken(c, token)
+	WalletRefreshToken(c)
+	var resp walletRefreshResponse
+	if err := testutil.DecodeJSON(recorder, &resp); err != nil {
+		t.Fatalf("decode response %q: %v", recorder.Body.String(), err)
+	}
+	return resp, recorder.Header()
+}
+
+func useWalletRefreshConfig(t *testing.T, minRemaining, rateLimit int) {
+	t.Helper()
+	prevSecret, prevAlgorithm, prevRedis := config.JWTSecret, config.WalletJWTAlgorithm, common.RedisEnabled
+	prevMinRemaining, prevRateLimit := config.WalletRefreshMinRemainingSeconds, config.WalletRefreshRateLimit
+	t.Cleanup(func() {
+		config.JWTSecret, config.WalletJWTAlgorithm, common.RedisEnabled = prevSecret, prevAlgorithm, prevRedis
+		config.WalletRefreshMinRemainingSeconds, config.WalletRefreshRateLimit = prevMinRemaining, prevRateLimit
+	})
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = common.WalletJWTAlgorithmHS256
+	common.RedisEnabled = false
+	config.WalletRefreshMinRemainingSeconds = minRemaining
+	config.WalletRefreshRateLimit = rateLimit
+	model.BindUserRepository(model.UserRepository{
+		GetUserById:  func(id string, selectAll bool) (*model.User, error) { return nil, gorm.ErrRecordNotFound },
+		FillUserById: func(user *model.User) error { return gorm.ErrRecordNotFound },
+	})
+}
+
+func TestWalletRefreshToken_RejectsFreshToken(t *testing.T) {
+	useWalletRefreshConfig(t, 300, 10)
+	token, _, err := common.GenerateWalletJWT("refresh-fresh", "0x00000000000000000000000000000000000000aa")
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT error: %v", err)
+	}
+	resp, _ := refreshWalletToken(t, token)
+	if resp.Success || resp.Status.Code != common.ProtoCodeUnauthenticated {
+		t.Fatalf("response = %+v, want a token with hours left rejected", resp)
+	}
+}
+
+func TestWalletRefreshToken_RateLimitsPerUser(t *testing.T) {
+	useWalletRefreshConfig(t, 0, 2)
+	token, _, err := common.GenerateWalletJWT("refresh-limited", "0x00000000000000000000000000000000000000aa")
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		// past the limiter the unknown user is reported as not found
+		if resp, _ := refreshWalletToken(t, token); resp.Status.Code != common.ProtoCodeNotFound {
+			t.Fatalf("refresh %d: response = %+v, want it past the rate limit", i+1, resp)
+		}
+	}
+	resp, header := refreshWalletToken(t, token)
+	if resp.Success || resp.Status.Code != common.ProtoCodeResourceExhausted || header.Get("Retry-After") != "3600" {
+		t.Fatalf("third refresh: response = %+v Retry-After = %q, want it rate limited", resp, header.Get("Retry-After"))
+	}
+
+	other, _, err := common.GenerateWalletJWT("refresh-other", "0x00000000000000000000000000000000000000aa")
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT error: %v", err)
+	}
+	if resp, _ := refreshWalletToken(t, other); resp.Status.Code != common.ProtoCodeNotFound {
+		t.Fatalf("other user: response = %+v, want a separate limit", resp)
+	}
+}
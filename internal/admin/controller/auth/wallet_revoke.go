@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+)
+
+type walletRevokeRequest struct {
+	Token string `json:"token"`
+	JTI   string `json:"jti"`
+}
+
+// RevokeWalletToken godoc
+// @Summary Revoke a wallet JWT (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body docs.WalletRevokeRequest true "Token or jti to revoke"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/auth/wallet/revoke [post]
+// RevokeWalletToken invalidates an access or refresh token before it expires
+func RevokeWalletToken(c *gin.Context) {
+	var req walletRevokeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": "参数错误",
+		})
+		return
+	}
+	jti := strings.TrimSpace(req.JTI)
+	if token := strings.TrimSpace(req.Token); token != "" {
+		claims, err := common.ParseWalletJWTClaims(token)
+		if err != nil {
+			c.JSON(http.StatusOK, gin.H{
+				"success": false,
+				"message": "token 无效或已过期",
+			})
+			return
+		}
+		if claims.ID == "" {
+			c.JSON(http.StatusOK, gin.H{
+				"success": false,
+				"message": "token 缺少 jti，无法单独吊销",
+			})
+			return
+		}
+		jti = claims.ID
+	}
+	if jti == "" {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": "请提供 token 或 jti",
+		})
+		return
+	}
+	if err := common.RevokeWalletJWT(jti); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	logger.Loginf(c.Request.Context(), "wallet jwt revoked jti=%s by=%s", jti, c.GetString(ctxkey.Id))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    gin.H{"jti": jti},
+	})
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+type rootWalletAddressesRequest struct {
+	Addresses []string `json:"addresses"`
+}
+
+// GetRootWalletAddresses godoc
+// @Summary List root wallet addresses (root)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/wallet/root-addresses [get]
+func GetRootWalletAddresses(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    model.ListRootWalletAddresses(),
+	})
+}
+
+// UpdateRootWalletAddresses godoc
+// @Summary Replace root wallet addresses (root)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/wallet/root-addresses [put]
+// UpdateRootWalletAddresses replaces the whole list; other instances pick it
+// up within a minute
+func UpdateRootWalletAddresses(c *gin.Context) {
+	var req rootWalletAddressesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "无效的参数",
+		})
+		return
+	}
+	addresses, err := model.ReplaceRootWalletAddresses(req.Addresses)
+	if err != nil {
+		message := err.Error()
+		if !errors.Is(err, model.ErrRootWalletAddressesEmpty) {
+			logger.Loginf(c.Request.Context(), "update root wallet addresses failed: %v", err)
+			message = "更新 root 钱包地址失败"
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": message,
+		})
+		return
+	}
+	logger.Loginf(c.Request.Context(), "root wallet addresses updated by=%s count=%d", c.GetString(ctxkey.Id), len(addresses))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    addresses,
+	})
+}
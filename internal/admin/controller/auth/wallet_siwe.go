@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yeying-community/router/common"
+)
+
+// isSIWEMessage reports whether message starts with an EIP-4361 header line.
+func isSIWEMessage(message string) bool {
+	firstLine, _, _ := strings.Cut(strings.TrimLeft(message, "\r\n"), "\n")
+	return strings.HasSuffix(strings.TrimRight(firstLine, "\r"), common.SIWEHeaderSuffix)
+}
+
+// parseSIWEMessage reads the fields of an EIP-4361 message. Fields the router
+// does not issue (Not Before, Request ID, Resources) are accepted and ignored.
+func parseSIWEMessage(message string) (common.SIWEFields, error) {
+	var fields common.SIWEFields
+	lines := strings.Split(strings.ReplaceAll(strings.TrimSpace(message), "\r\n", "\n"), "\n")
+	if len(lines) < 2 || !strings.HasSuffix(lines[0], common.SIWEHeaderSuffix) {
+		return fields, errors.New("missing SIWE header")
+	}
+	fields.Domain = strings.TrimSuffix(lines[0], common.SIWEHeaderSuffix)
+	fields.Address = strings.TrimSpace(lines[1])
+	targets := map[string]*string{
+		"URI":             &fields.URI,
+		"Version":         &fields.Version,
+		"Chain ID":        &fields.ChainId,
+		"Nonce":           &fields.Nonce,
+		"Issued At":       &fields.IssuedAt,
+		"Expiration Time": &fields.ExpirationTime,
+	}
+	inFields := false
+	for _, line := range lines[2:] {
+		if key, value, ok := strings.Cut(line, ": "); ok {
+			if target, known := targets[key]; known {
+				*target = strings.TrimSpace(value)
+				inFields = true
+				continue
+			}
+		}
+		if !inFields && strings.TrimSpace(line) != "" && fields.Statement == "" {
+			fields.Statement = strings.TrimSpace(line)
+		}
+	}
+	switch {
+	case fields.Domain == "":
+		return fields, errors.New("missing SIWE domain")
+	case fields.Address == "":
+		return fields, errors.New("missing SIWE address")
+	case fields.URI == "":
+		return fields, errors.New("missing SIWE URI")
+	case fields.Version != "1":
+		return fields, fmt.Errorf("unsupported SIWE version %q", fields.Version)
+	case fields.ChainId == "":
+		return fields, errors.New("missing SIWE chain ID")
+	case fields.Nonce == "":
+		return fields, errors.New("missing SIWE nonce")
+	case fields.IssuedAt == "":
+		return fields, errors.New("missing SIWE issued-at")
+	}
+	return fields, nil
+}
+
+// errSIWEChainNotAllowed marks a SIWE message signed for a chain outside
+// auth.allowed_chain_ids.
+var errSIWEChainNotAllowed = errors.New("SIWE chain ID not allowed")
+
+// checkSIWEFields ties a parsed SIWE message to the login request: it must be
+// addressed to this site, name the signing address, be signed for an allowed
+// chain that matches chainId when the request names one, and not have expired.
+func checkSIWEFields(fields common.SIWEFields, address string, chainId string, now time.Time) error {
+	if fields.Domain != common.WalletSIWEDomain() {
+		return fmt.Errorf("SIWE domain %q does not match %q", fields.Domain, common.WalletSIWEDomain())
+	}
+	if !strings.EqualFold(fields.Address, address) {
+		return fmt.Errorf("SIWE address %s does not match %s", fields.Address, address)
+	}
+	siweChain, err := common.NormalizeChainId(fields.ChainId)
+	if err != nil {
+		return fmt.Errorf("invalid SIWE chain ID %q", fields.ChainId)
+	}
+	if !common.IsWalletChainAllowed(siweChain) {
+		return fmt.Errorf("%w: %s", errSIWEChainNotAllowed, siweChain)
+	}
+	if strings.TrimSpace(chainId) != "" {
+		requested, err := common.NormalizeChainId(chainId)
+		if err != nil || requested != siweChain {
+			return fmt.Errorf("SIWE chain ID %s does not match %s", siweChain, chainId)
+		}
+	}
+	if fields.ExpirationTime != "" {
+		expireAt, err := time.Parse(time.RFC3339, fields.ExpirationTime)
+		if err != nil {
+			return fmt.Errorf("invalid SIWE expiration time %q", fields.ExpirationTime)
+		}
+		if !now.Before(expireAt) {
+			return errors.New("SIWE message expired")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestParseSIWEMessage(t *testing.T) {
+	issued := common.SIWEFields{
+		Domain:         "app.example.com",
+		Address:        "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		Statement:      "Login to Router",
+		URI:            "https://app.example.com",
+		Version:        "1",
+		ChainId:        "1",
+		Nonce:          "3f1c9a7e0b2d4c6f",
+		IssuedAt:       "2026-10-16T08:00:00Z",
+		ExpirationTime: "2026-10-16T08:10:00Z",
+	}
+	message := common.FormatSIWEMessage(issued)
+	if !isSIWEMessage(message) || isSIWEMessage("Login to Router\nNonce: abc") {
+		t.Fatalf("isSIWEMessage misdetects messages")
+	}
+	fields, err := parseSIWEMessage(strings.ReplaceAll(message, "\n", "\r\n"))
+	if err != nil {
+		t.Fatalf("parseSIWEMessage: %v", err)
+	}
+	if fields != issued {
+		t.Fatalf("parseSIWEMessage = %+v, want %+v", fields, issued)
+	}
+
+	// the statement is optional
+	issued.Statement = ""
+	if fields, err := parseSIWEMessage(common.FormatSIWEMessage(issued)); err != nil || fields.Nonce != issued.Nonce {
+		t.Fatalf("parseSIWEMessage without statement = %+v, %v", fields, err)
+	}
+	for _, bad := range []string{
+		"",
+		"Login to Router\nNonce: abc",
+		strings.Replace(message, "Version: 1", "Version: 2", 1),
+		strings.Replace(message, "Nonce: "+issued.Nonce+"\n", "", 1),
+	} {
+		if _, err := parseSIWEMessage(bad); err == nil {
+			t.Fatalf("parseSIWEMessage(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestCheckSIWEFields(t *testing.T) {
+	prevDomain, prevChains := config.WalletSIWEDomain, config.WalletAllowedChains
+	config.WalletSIWEDomain = "app.example.com"
+	defer func() { config.WalletSIWEDomain, config.WalletAllowedChains = prevDomain, prevChains }()
+	allowed, err := common.BuildWalletAllowedChains([]string{"1", "137"})
+	if err != nil {
+		t.Fatalf("BuildWalletAllowedChains error: %v", err)
+	}
+	config.WalletAllowedChains = allowed
+
+	now := time.Date(2026, 10, 16, 8, 5, 0, 0, time.UTC)
+	fields := common.SIWEFields{
+		Domain:         "app.example.com",
+		Address:        "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		ChainId:        "1",
+		ExpirationTime: "2026-10-16T08:10:00Z",
+	}
+	if err := checkSIWEFields(fields, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "0x1", now); err != nil {
+		t.Fatalf("checkSIWEFields: %v", err)
+	}
+	if err := checkSIWEFields(fields, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "", now.Add(10*time.Minute)); err == nil {
+		t.Fatalf("expired message accepted")
+	}
+	other := fields
+	other.Domain = "evil.example.com"
+	if err := checkSIWEFields(other, fields.Address, "", now); err == nil {
+		t.Fatalf("foreign domain accepted")
+	}
+	if err := checkSIWEFields(fields, "0x0000000000000000000000000000000000000001", "", now); err == nil {
+		t.Fatalf("address mismatch accepted")
+	}
+	if err := checkSIWEFields(fields, fields.Address, "137", now); err == nil {
+		t.Fatalf("chain ID mismatch with the request accepted")
+	}
+	unlisted := fields
+	unlisted.ChainId = "56"
+	if err := checkSIWEFields(unlisted, fields.Address, "", now); !errors.Is(err, errSIWEChainNotAllowed) {
+		t.Fatalf("chain outside the allowlist: err = %v, want errSIWEChainNotAllowed", err)
+	}
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/yeying-community/router/common"
+)
+
+// verifySolanaSignature checks a detached Ed25519 signature, as produced by the
+// signMessage API of Solana wallets, over the raw message bytes. The address
+// is the base58 public key; the signature may be base58 or base64.
+func verifySolanaSignature(address, message, signature string) error {
+	publicKey, err := common.DecodeBase58(strings.TrimSpace(address))
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("无效的 Solana 地址")
+	}
+	sig, err := decodeSolanaSignature(strings.TrimSpace(signature))
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), []byte(message), sig) {
+		return errors.New("签名与地址不匹配")
+	}
+	return nil
+}
+
+func decodeSolanaSignature(signature string) ([]byte, error) {
+	if raw, err := common.DecodeBase58(signature); err == nil && len(raw) == ed25519.SignatureSize {
+		return raw, nil
+	}
+	if raw, err := base64.StdEncoding.DecodeString(signature); err == nil && len(raw) == ed25519.SignatureSize {
+		return raw, nil
+	}
+	return nil, errors.New("签名长度异常")
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// WalletStatus godoc
+// @Summary Wallet binding status of the current user
+// @Tags public
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/public/oauth/wallet/status [get]
+// WalletStatus reports the bound wallet, the accepted chains and whether a
+// nonce is waiting to be signed for that wallet
+func WalletStatus(c *gin.Context) {
+	user := model.User{Id: c.GetString(ctxkey.Id)}
+	if strings.TrimSpace(user.Id) == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "未登录",
+		})
+		return
+	}
+	if err := user.FillUserById(); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    walletStatusData(&user),
+	})
+}
+
+func walletStatusData(user *model.User) gin.H {
+	boundAddress := ""
+	if user.WalletAddress != nil {
+		boundAddress = model.NormalizeWalletAddress(*user.WalletAddress)
+	}
+	data := gin.H{
+		"bound_address":         boundAddress,
+		"chain_id_allowlist":    common.WalletAllowedChainList(),
+		"auto_register_enabled": model.WalletAutoRegisterEnabled(),
+		"nonce_pending":         false,
+	}
+	if boundAddress == "" {
+		return data
+	}
+	// the store only returns unexpired entries
+	if entry, ok := common.GetWalletNonce(boundAddress); ok {
+		data["nonce_pending"] = true
+		data["nonce_expire_at"] = entry.ExpireAt.Unix()
+		data["nonce_remaining_seconds"] = int64(entry.Remaining(time.Now()).Seconds())
+		if entry.TTL > 0 {
+			data["nonce_ttl_seconds"] = int64(entry.TTL.Seconds())
+		}
+	}
+	return data
+}
@@ -0,0 +1,478 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/blacklist"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestSafeUserResponse_OmitsSensitiveFields(t *testing.T) {
+	addr := "0x1111111111111111111111111111111111111111"
+	user := &model.User{
+		Id:            "user-1",
+		Username:      "wallet_abc123",
+		Password:      "$2a$10$secret-password-hash",
+		DisplayName:   "wallet_abc123",
+		Role:          model.RoleCommonUser,
+		Status:        model.UserStatusEnabled,
+		Email:         "user@example.com",
+		WalletAddress: &addr,
+		AccessToken:   "secret-access-token",
+		CreatedAt:     1700000000,
+		LastLoginAt:   1700000100,
+	}
+
+	resp := safeUserResponse(user)
+	for _, key := range []string{"password", "access_token", "verification_code", "totp_secret"} {
+		if _, ok := resp[key]; ok {
+			t.Fatalf("safeUserResponse exposed sensitive field %q", key)
+		}
+	}
+
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("json.Marshal error: %v", err)
+	}
+	for _, secret := range []string{user.Password, user.AccessToken} {
+		if strings.Contains(string(raw), secret) {
+			t.Fatalf("safeUserResponse payload contains secret %q: %s", secret, raw)
+		}
+	}
+
+	if resp["id"] != "user-1" {
+		t.Fatalf("safeUserResponse id = %v, want %q", resp["id"], "user-1")
+	}
+	if resp["created_at"] != int64(1700000000) {
+		t.Fatalf("safeUserResponse created_at = %v, want %d", resp["created_at"], 1700000000)
+	}
+	if resp["last_login_at"] != int64(1700000100) {
+		t.Fatalf("safeUserResponse last_login_at = %v, want %d", resp["last_login_at"], 1700000100)
+	}
+}
+
+func TestWalletAuthErrorCode(t *testing.T) {
+	if got := walletAuthErrorCode(errWalletUserPendingApproval); got != 9 {
+		t.Fatalf("walletAuthErrorCode(pending) = %d, want 9", got)
+	}
+	if got := walletAuthErrorCode(errors.New("签名验证失败")); got != 3 {
+		t.Fatalf("walletAuthErrorCode(other) = %d, want 3", got)
+	}
+}
+
+func TestVerifyWalletRequest_RequiresChainIdWhenAllowlistConfigured(t *testing.T) {
+	prev := config.WalletAllowedChains
+	config.WalletAllowedChains = map[string]struct{}{"1": {}}
+	defer func() { config.WalletAllowedChains = prev }()
+
+	err := verifyWalletRequest(context.Background(), walletLoginRequest{
+		Address:   "0x1111111111111111111111111111111111111111",
+		Signature: "0x00",
+	})
+	if err == nil || err.Error() != "chain_id 为必填项" {
+		t.Fatalf("verifyWalletRequest error = %v, want chain_id 为必填项", err)
+	}
+}
+
+func TestCheckWalletUnbindAllowed(t *testing.T) {
+	primary := "0x1111111111111111111111111111111111111111"
+	second := "0x2222222222222222222222222222222222222222"
+	tests := []struct {
+		name        string
+		hasPassword bool
+		wallets     []model.UserWallet
+		address     string
+		wantErr     bool
+	}{
+		{name: "last wallet without password", wallets: []model.UserWallet{{Address: primary}}, address: primary, wantErr: true},
+		{name: "last wallet with password", hasPassword: true, wallets: []model.UserWallet{{Address: primary}}, address: primary},
+		{name: "legacy primary without row", address: primary, wantErr: true},
+		{name: "one of two wallets", wallets: []model.UserWallet{{Address: primary}, {Address: second}}, address: " " + second},
+		{name: "not bound", hasPassword: true, wallets: []model.UserWallet{{Address: primary}}, address: second, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := primary
+			user := &model.User{Id: "user-1", WalletAddress: &addr, HasPassword: tt.hasPassword}
+			err := checkWalletUnbindAllowed(user, tt.wallets, tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkWalletUnbindAllowed() err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWalletStatusData(t *testing.T) {
+	if data := walletStatusData(&model.User{Id: "u-none"}); data["bound_address"] != "" || data["nonce_pending"] != false {
+		t.Fatalf("unbound user status = %v", data)
+	}
+
+	address := "0x00000000000000000000000000000000000000Bb"
+	user := &model.User{Id: "u-status", WalletAddress: &address}
+	if data := walletStatusData(user); data["bound_address"] != strings.ToLower(address) || data["nonce_pending"] != false {
+		t.Fatalf("status without nonce = %v", data)
+	}
+	common.GenerateWalletNonce(strings.ToLower(address), "Login to Router", "")
+	defer common.ConsumeWalletNonce(strings.ToLower(address))
+	data := walletStatusData(user)
+	if data["nonce_pending"] != true {
+		t.Fatalf("status with nonce = %v", data)
+	}
+	if _, ok := data["nonce_expire_at"].(int64); !ok {
+		t.Fatalf("nonce_expire_at missing: %v", data)
+	}
+}
+
+func TestVerifySolanaSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey error: %v", err)
+	}
+	address := common.EncodeBase58(publicKey)
+	message := "Login to Router\nNonce: abc"
+	signature := ed25519.Sign(privateKey, []byte(message))
+
+	if err := verifySolanaSignature(address, message, common.EncodeBase58(signature)); err != nil {
+		t.Fatalf("base58 signature rejected: %v", err)
+	}
+	if err := verifySolanaSignature(address, message, base64.StdEncoding.EncodeToString(signature)); err != nil {
+		t.Fatalf("base64 signature rejected: %v", err)
+	}
+	if err := verifySolanaSignature(address, message+"x", common.EncodeBase58(signature)); err == nil {
+		t.Fatalf("signature over another message accepted")
+	}
+	otherKey, _, _ := ed25519.GenerateKey(nil)
+	if err := verifySolanaSignature(common.EncodeBase58(otherKey), message, common.EncodeBase58(signature)); err == nil {
+		t.Fatalf("signature from another key accepted")
+	}
+}
+
+func TestWalletNonceHandlers_ShareResponse(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.GET("/nonce", WalletNonceGET)
+	engine.POST("/nonce", WalletNonce)
+	address := "0x00000000000000000000000000000000000000dd"
+	defer common.ConsumeWalletNonce(address)
+
+	requests := []*http.Request{
+		testutil.NewTestRequest(http.MethodGet, "/nonce?address="+address+"&chain_id=1", nil),
+		testutil.NewTestRequest(http.MethodPost, "/nonce", `{"address":"`+address+`","chain_id":"1"}`),
+	}
+	for _, req := range requests {
+		recorder := testutil.ServeTestRequest(engine, req)
+		var resp struct {
+			Success bool `json:"success"`
+			Data    struct {
+				Nonce     string `json:"nonce"`
+				ExpiresAt string `json:"expires_at"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil || !resp.Success {
+			t.Fatalf("%s nonce response = %s, err=%v", req.Method, recorder.Body.String(), err)
+		}
+		entry, ok := common.GetWalletNonce(address)
+		if !ok || entry.Nonce != resp.Data.Nonce || entry.ChainId != "1" {
+			t.Fatalf("%s stored nonce = %+v, response nonce %q", req.Method, entry, resp.Data.Nonce)
+		}
+	}
+
+	recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/nonce?address=not-an-address", nil))
+	if !strings.Contains(recorder.Body.String(), `"success":false`) {
+		t.Fatalf("invalid address response = %s", recorder.Body.String())
+	}
+}
+
+func TestWalletTokenInfoData(t *testing.T) {
+	prevSecret, prevAlgorithm, prevExpire := config.JWTSecret, config.WalletJWTAlgorithm, config.JWTExpireHours
+	defer func() {
+		config.JWTSecret, config.WalletJWTAlgorithm, config.JWTExpireHours = prevSecret, prevAlgorithm, prevExpire
+	}()
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = common.WalletJWTAlgorithmHS256
+	config.JWTExpireHours = 1
+
+	valid, expiresAt, err := common.GenerateWalletJWT("user-1", "0xabc")
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT error: %v", err)
+	}
+	data, err := walletTokenInfoData(valid, time.Now())
+	if err != nil || data["is_valid"] != true || data["user_id"] != "user-1" || data["expires_at"] != expiresAt.Unix() {
+		t.Fatalf("valid token info = %v, %v", data, err)
+	}
+
+	config.JWTExpireHours = -1
+	expired, _, _ := common.GenerateWalletJWT("user-1", "0xabc")
+	data, err = walletTokenInfoData(expired, time.Now())
+	if err != nil || data["is_valid"] != false || data["wallet_address"] != "0xabc" {
+		t.Fatalf("expired token info = %v, %v; want is_valid false", data, err)
+	}
+
+	refresh, _, _ := common.GenerateWalletRefreshJWT("user-1", "0xabc")
+	if _, err := walletTokenInfoData(refresh, time.Now()); err == nil {
+		t.Fatalf("refresh token accepted")
+	}
+	config.JWTSecret = "other-secret"
+	if _, err := walletTokenInfoData(expired, time.Now()); err == nil {
+		t.Fatalf("token signed with another secret accepted")
+	}
+}
+
+func TestWalletErrorCodes(t *testing.T) {
+	for code := WalletErrNonceExpired; code <= WalletErrBindTokenInvalid; code++ {
+		if walletErrorMessages[code] == "" {
+			t.Fatalf("wallet error code %d has no default message", code)
+		}
+	}
+	wrapped := fmt.Errorf("login: %w", newWalletError(WalletErrUserPendingApproval))
+	if !errors.Is(wrapped, errWalletUserPendingApproval) || walletAuthErrorCode(wrapped) != 9 {
+		t.Fatalf("wrapped pending error not recognised: %v", wrapped)
+	}
+	if errors.Is(newWalletError(WalletErrNonceExpired), errWalletUserPendingApproval) {
+		t.Fatalf("different wallet error codes matched")
+	}
+	if got := walletErrorCode(wrapped); got != WalletErrUserPendingApproval {
+		t.Fatalf("walletErrorCode(wrapped) = %d", got)
+	}
+	if got := walletErrorCode(errors.New("db down")); got != 0 {
+		t.Fatalf("walletErrorCode(plain) = %d, want 0", got)
+	}
+	err := verifyWalletRequest(context.Background(), walletLoginRequest{Address: "0x1111111111111111111111111111111111111111", Signature: "0x00", WalletType: "solana"})
+	if got := walletErrorCode(err); got != WalletErrInvalidAddress {
+		t.Fatalf("verifyWalletRequest code = %d, want %d", got, WalletErrInvalidAddress)
+	}
+}
+
+func TestBindWalletLoginRequest(t *testing.T) {
+	common.RegisterValidators()
+	const ethAddress = "0x1111111111111111111111111111111111111111"
+	const solanaAddress = "7EcDhSYGxXyscszYEp35KHN8vvw3svAuLKTzXwCFLtV"
+	cases := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantErr  error
+	}{
+		{"ethereum", `{"address":"` + ethAddress + `","signature":"0x00"}`, 0, nil},
+		{"solana", `{"address":"` + solanaAddress + `","signature":"sig","wallet_type":"solana"}`, 0, nil},
+		{"missing address", `{"signature":"0x00"}`, WalletErrInvalidAddress, nil},
+		{"invalid address", `{"address":"0x123","signature":"0x00"}`, WalletErrInvalidAddress, nil},
+		{"bad checksum", `{"address":"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD","signature":"0x00"}`, WalletErrInvalidAddress, nil},
+		{"missing signature", `{"address":"` + ethAddress + `"}`, WalletErrMissingSignature, nil},
+		{"empty signature", `{"address":"` + ethAddress + `","signature":""}`, WalletErrMissingSignature, nil},
+		{"webauthn", `{"wallet_type":"webauthn"}`, WalletErrUseWebAuthn, nil},
+		{"malformed json", `{"address":`, 0, errWalletBadRequest},
+	}
+	for _, tc := range cases {
+		c, _ := testutil.NewTestGinContext(http.MethodPost, "/verify", tc.body)
+		var req walletLoginRequest
+		err := bindWalletLoginRequest(c, &req)
+		if got := walletErrorCode(err); got != tc.wantCode {
+			t.Fatalf("%s: error code = %d (%v), want %d", tc.name, got, err, tc.wantCode)
+		}
+		if tc.wantCode == 0 && err != tc.wantErr {
+			t.Fatalf("%s: error = %v, want %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestWalletAutoRegisterDefaults(t *testing.T) {
+	prevRole, prevStatus, prevApproval := config.WalletAutoRegisterDefaultRole, config.WalletAutoRegisterDefaultStatus, config.WalletAutoRegisterRequireApproval
+	defer func() {
+		config.WalletAutoRegisterDefaultRole, config.WalletAutoRegisterDefaultStatus, config.WalletAutoRegisterRequireApproval = prevRole, prevStatus, prevApproval
+	}()
+
+	cases := []struct {
+		role, status string
+		approval     bool
+		wantRole     int
+		wantStatus   int
+	}{
+		{"common", "enabled", false, model.RoleCommonUser, model.UserStatusEnabled},
+		{"admin", "disabled", false, model.RoleAdminUser, model.UserStatusDisabled},
+		{"common", "pending", false, model.RoleCommonUser, model.UserStatusPendingApproval},
+		{"common", "enabled", true, model.RoleCommonUser, model.UserStatusPendingApproval},
+	}
+	for _, tc := range cases {
+		config.WalletAutoRegisterDefaultRole, config.WalletAutoRegisterDefaultStatus, config.WalletAutoRegisterRequireApproval = tc.role, tc.status, tc.approval
+		role, status := walletAutoRegisterDefaults()
+		if role != tc.wantRole || status != tc.wantStatus {
+			t.Fatalf("walletAutoRegisterDefaults(%s, %s, %t) = %d, %d; want %d, %d", tc.role, tc.status, tc.approval, role, status, tc.wantRole, tc.wantStatus)
+		}
+	}
+}
+
+func TestWalletUserStatusError_PendingUserCannotLogin(t *testing.T) {
+	config.WalletAutoRegisterDefaultStatus = "pending"
+	defer func() { config.WalletAutoRegisterDefaultStatus = "enabled" }()
+	_, status := walletAutoRegisterDefaults()
+
+	err := walletUserStatusError(&model.User{Status: status})
+	if !errors.Is(err, errWalletUserPendingApproval) || walletErrorCode(err) != WalletErrUserPendingApproval {
+		t.Fatalf("pending user error = %v, want pending approval", err)
+	}
+	if walletAuthErrorCode(err) != common.ProtoCodePendingApproval {
+		t.Fatalf("pending user proto code = %d", walletAuthErrorCode(err))
+	}
+	if err := walletUserStatusError(&model.User{Status: model.UserStatusDisabled}); walletErrorCode(err) != WalletErrUserDisabled {
+		t.Fatalf("disabled user error = %v", err)
+	}
+	if err := walletUserStatusError(&model.User{Status: model.UserStatusEnabled}); err != nil {
+		t.Fatalf("enabled user rejected: %v", err)
+	}
+}
+
+func TestIsWalletServerError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{newWalletError(WalletErrSignatureMismatch), false},
+		{newWalletError(WalletErrNonceExpired), false},
+		{fmt.Errorf("login: %w", errWalletUserPendingApproval), false},
+		{errors.New("database is locked"), true},
+	}
+	for _, tc := range cases {
+		if got := isWalletServerError(tc.err); got != tc.want {
+			t.Fatalf("isWalletServerError(%v) = %t, want %t", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestWalletChallengeTypes_ListsSolana(t *testing.T) {
+	c, recorder := testutil.NewTestGinContext(http.MethodGet, "/api/v1/public/oauth/wallet/challenge-types", nil)
+	WalletChallengeTypes(c)
+	var resp struct {
+		Data struct {
+			SupportedTypes []string `json:"supported_types"`
+		} `json:"data"`
+	}
+	if err := testutil.DecodeJSON(recorder, &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !strings.Contains(strings.Join(resp.Data.SupportedTypes, ","), "solana_signMessage") {
+		t.Fatalf("supported_types = %v, want solana_signMessage", resp.Data.SupportedTypes)
+	}
+}
+
+func TestWalletChallengeTypes_SIWEEnabled(t *testing.T) {
+	prev := config.WalletNonceFormat
+	defer func() { config.WalletNonceFormat = prev }()
+	for _, format := range []string{common.WalletNonceFormatDefault, common.WalletNonceFormatSIWE} {
+		config.WalletNonceFormat = format
+		c, recorder := testutil.NewTestGinContext(http.MethodGet, "/api/v1/public/oauth/wallet/challenge-types", nil)
+		WalletChallengeTypes(c)
+		var resp struct {
+			Data struct {
+				SIWEEnabled bool   `json:"siwe_enabled"`
+				NonceFormat string `json:"nonce_format"`
+			} `json:"data"`
+		}
+		if err := testutil.DecodeJSON(recorder, &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if want := format == common.WalletNonceFormatSIWE; resp.Data.SIWEEnabled != want || resp.Data.NonceFormat != format {
+			t.Fatalf("nonce_format %s: siwe_enabled = %t nonce_format = %q, want %t and %q", format, resp.Data.SIWEEnabled, resp.Data.NonceFormat, want, format)
+		}
+	}
+}
+
+func TestCachedWalletVerifyResponse_RechecksUser(t *testing.T) {
+	prevSecret, prevAlgorithm, prevTTL := config.JWTSecret, config.WalletJWTAlgorithm, config.WalletVerifyCacheTTLSeconds
+	defer func() {
+		config.JWTSecret, config.WalletJWTAlgorithm, config.WalletVerifyCacheTTLSeconds = prevSecret, prevAlgorithm, prevTTL
+	}()
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = common.WalletJWTAlgorithmHS256
+	config.WalletVerifyCacheTTLSeconds = 10
+
+	users := map[string]*model.User{
+		"verify-cache-enabled":  {Id: "verify-cache-enabled", Status: model.UserStatusEnabled},
+		"verify-cache-banned":   {Id: "verify-cache-banned", Status: model.UserStatusEnabled},
+		"verify-cache-disabled": {Id: "verify-cache-disabled", Status: model.UserStatusDisabled},
+	}
+	model.BindUserRepository(model.UserRepository{
+		GetUserById: func(id string, selectAll bool) (*model.User, error) {
+			if user, ok := users[id]; ok {
+				copied := *user
+				return &copied, nil
+			}
+			return nil, gorm.ErrRecordNotFound
+		},
+	})
+	blacklist.BanUser("verify-cache-banned")
+	defer blacklist.UnbanUser("verify-cache-banned")
+
+	for id, wantHit := range map[string]bool{
+		"verify-cache-enabled":  true,
+		"verify-cache-banned":   false,
+		"verify-cache-disabled": false,
+	} {
+		token, _, err := common.GenerateWalletJWT(id, "0xabc")
+		if err != nil {
+			t.Fatalf("GenerateWalletJWT error: %v", err)
+		}
+		key := common.WalletVerifyCacheKey("0xsig-"+id, "nonce")
+		// the cached user is stale: it was enabled when the response was cached
+		common.CacheWalletVerifyResponse(key, "0xabc", "10.0.0.1", walletVerifyCached{
+			user:  &model.User{Id: id, Status: model.UserStatusEnabled},
+			token: token,
+		})
+		cached, ok := cachedWalletVerifyResponse(context.Background(), key, "10.0.0.1")
+		if ok != wantHit {
+			t.Fatalf("%s: hit = %t, want %t", id, ok, wantHit)
+		}
+		if ok && cached.user.Status != users[id].Status {
+			t.Fatalf("%s: cached user = %+v, want the reloaded user", id, cached.user)
+		}
+		if _, stillCached := common.GetWalletVerifyResponse(key, "10.0.0.1"); stillCached != wantHit {
+			t.Fatalf("%s: entry kept = %t, want %t", id, stillCached, wantHit)
+		}
+	}
+}
+
+func TestUniqueWalletDisplayName(t *testing.T) {
+	prev := config.WalletUniqueDisplayName
+	defer func() { config.WalletUniqueDisplayName = prev }()
+	taken := map[string]bool{}
+	model.BindUserRepository(model.UserRepository{
+		GetUserById:               func(id string, selectAll bool) (*model.User, error) { return nil, gorm.ErrRecordNotFound },
+		IsDisplayNameAlreadyTaken: func(name string) bool { return taken[name] },
+	})
+
+	taken["wallet_abc"] = true
+	config.WalletUniqueDisplayName = false
+	if name, err := uniqueWalletDisplayName("wallet_abc"); err != nil || name != "wallet_abc" {
+		t.Fatalf("disabled: name = %q err = %v, want the base name kept", name, err)
+	}
+
+	config.WalletUniqueDisplayName = true
+	taken["wallet_abc_1"] = true
+	if name, err := uniqueWalletDisplayName("wallet_abc"); err != nil || name != "wallet_abc_2" {
+		t.Fatalf("enabled: name = %q err = %v, want wallet_abc_2", name, err)
+	}
+	if name, err := uniqueWalletDisplayName("wallet_new"); err != nil || name != "wallet_new" {
+		t.Fatalf("free name: name = %q err = %v, want wallet_new", name, err)
+	}
+
+	for i := 2; i <= walletDisplayNameMaxAttempts; i++ {
+		taken[fmt.Sprintf("wallet_abc_%d", i)] = true
+	}
+	if name, err := uniqueWalletDisplayName("wallet_abc"); err == nil {
+		t.Fatalf("exhausted: name = %q, want an error after %d attempts", name, walletDisplayNameMaxAttempts)
+	}
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/logger"
+)
+
+// WalletTokenInfo godoc
+// @Summary Describe the wallet JWT in the Authorization header
+// @Tags public
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/public/oauth/wallet/token/info [get]
+// WalletTokenInfo decodes the caller's wallet JWT for display. An expired token
+// is reported with is_valid false; nothing is issued or refreshed
+func WalletTokenInfo(c *gin.Context) {
+	bearer := strings.TrimSpace(c.GetHeader("Authorization"))
+	if strings.HasPrefix(strings.ToLower(bearer), "bearer ") {
+		bearer = strings.TrimSpace(bearer[7:])
+	}
+	if bearer == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "未提供 token",
+		})
+		return
+	}
+	data, err := walletTokenInfoData(bearer, time.Now())
+	if err != nil {
+		logger.Loginf(c.Request.Context(), "wallet token info rejected err=%v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "token 无效",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    data,
+	})
+}
+
+// walletTokenInfoData returns an error for tokens that are forged, revoked or
+// not access tokens; only expiry turns into is_valid false.
+func walletTokenInfoData(token string, now time.Time) (gin.H, error) {
+	claims, verifyErr := common.VerifyWalletJWT(token)
+	valid := verifyErr == nil
+	if !valid {
+		inspected, err := common.InspectWalletJWT(token)
+		if err != nil || inspected.TokenType == "refresh" || inspected.ExpiresAt == nil || now.Before(inspected.ExpiresAt.Time) {
+			return nil, verifyErr
+		}
+		claims = inspected
+	}
+	data := gin.H{
+		"user_id":        claims.UserID,
+		"wallet_address": claims.WalletAddress,
+		"is_valid":       valid,
+	}
+	if claims.IssuedAt != nil {
+		data["issued_at"] = claims.IssuedAt.Unix()
+	}
+	if claims.ExpiresAt != nil {
+		data["expires_at"] = claims.ExpiresAt.Unix()
+	}
+	return data, nil
+}
@@ -0,0 +1,374 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/logger"
+	usercontroller "github.com/yeying-community/router/internal/admin/controller/user"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// The ceremony state between begin and finish is kept in the cookie session.
+const (
+	webauthnRegistrationSessionKey = "webauthn_registration"
+	webauthnLoginSessionKey        = "webauthn_login"
+)
+
+var errWebAuthnDisabled = errors.New("未开启通行密钥登录")
+
+func newWebAuthn() (*webauthn.WebAuthn, error) {
+	if config.WebAuthnRPID == "" {
+		return nil, errWebAuthnDisabled
+	}
+	return webauthn.New(&webauthn.Config{
+		RPID:          config.WebAuthnRPID,
+		RPDisplayName: config.SystemName,
+		RPOrigins:     config.WebAuthnRPOrigins,
+	})
+}
+
+// webauthnUser adapts a user and their stored passkeys to webauthn.User. The
+// user id is the WebAuthn user handle, which discoverable logins resolve.
+type webauthnUser struct {
+	user        *model.User
+	credentials []webauthn.Credential
+}
+
+func (u *webauthnUser) WebAuthnID() []byte {
+	return []byte(u.user.Id)
+}
+
+func (u *webauthnUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *webauthnUser) WebAuthnDisplayName() string {
+	if u.user.DisplayName != "" {
+		return u.user.DisplayName
+	}
+	return u.user.Username
+}
+
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func loadWebAuthnUser(user *model.User) (*webauthnUser, error) {
+	rows, err := model.GetWebAuthnCredentialsByUserId(user.Id)
+	if err != nil {
+		return nil, err
+	}
+	credentials := make([]webauthn.Credential, 0, len(rows))
+	for _, row := range rows {
+		credentials = append(credentials, webauthnCredentialFromModel(row))
+	}
+	return &webauthnUser{user: user, credentials: credentials}, nil
+}
+
+func webauthnCredentialFromModel(row model.WebAuthnCredential) webauthn.Credential {
+	id, _ := base64.RawURLEncoding.DecodeString(row.CredentialId)
+	var transports []protocol.AuthenticatorTransport
+	for _, transport := range strings.Split(row.Transports, ",") {
+		if transport != "" {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+	}
+	return webauthn.Credential{
+		ID:              id,
+		PublicKey:       row.PublicKey,
+		AttestationType: row.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			UserPresent:    true,
+			UserVerified:   row.UserVerified,
+			BackupEligible: row.BackupEligible,
+			BackupState:    row.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:       row.AAGUID,
+			SignCount:    uint32(row.SignCount),
+			CloneWarning: row.CloneWarning,
+		},
+	}
+}
+
+func webauthnCredentialToModel(userId string, credential *webauthn.Credential) *model.WebAuthnCredential {
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+	return &model.WebAuthnCredential{
+		UserId:          userId,
+		CredentialId:    base64.RawURLEncoding.EncodeToString(credential.ID),
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		Transports:      strings.Join(transports, ","),
+		UserVerified:    credential.Flags.UserVerified,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		SignCount:       int64(credential.Authenticator.SignCount),
+	}
+}
+
+func saveWebAuthnSession(c *gin.Context, key string, data *webauthn.SessionData) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	session := sessions.Default(c)
+	session.Set(key, string(raw))
+	return session.Save()
+}
+
+// takeWebAuthnSession returns the pending ceremony and removes it, so each
+// challenge can be answered once.
+func takeWebAuthnSession(c *gin.Context, key string) (*webauthn.SessionData, error) {
+	session := sessions.Default(c)
+	raw, _ := session.Get(key).(string)
+	session.Delete(key)
+	_ = session.Save()
+	if raw == "" {
+		return nil, errors.New("no pending webauthn ceremony")
+	}
+	var data webauthn.SessionData
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func currentWebAuthnUser(c *gin.Context) (*webauthnUser, error) {
+	user := model.User{Id: c.GetString(ctxkey.Id)}
+	if strings.TrimSpace(user.Id) == "" {
+		return nil, errors.New("未登录")
+	}
+	if err := user.FillUserById(); err != nil {
+		return nil, err
+	}
+	return loadWebAuthnUser(&user)
+}
+
+func webauthnFail(c *gin.Context, message string) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": false,
+		"message": message,
+	})
+}
+
+// WebAuthnRegisterBegin godoc
+// @Summary Start passkey registration for the current user
+// @Tags public
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/public/auth/webauthn/register/begin [post]
+// WebAuthnRegisterBegin returns the credential creation options for
+// navigator.credentials.create
+func WebAuthnRegisterBegin(c *gin.Context) {
+	wa, err := newWebAuthn()
+	if err != nil {
+		webauthnFail(c, err.Error())
+		return
+	}
+	user, err := currentWebAuthnUser(c)
+	if err != nil {
+		webauthnFail(c, err.Error())
+		return
+	}
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, credential := range user.credentials {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+	options, sessionData, err := wa.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		logger.Loginf(c.Request.Context(), "webauthn register begin failed user=%s err=%v", user.user.Id, err)
+		webauthnFail(c, "无法开始注册通行密钥")
+		return
+	}
+	if err := saveWebAuthnSession(c, webauthnRegistrationSessionKey, sessionData); err != nil {
+		webauthnFail(c, "无法保存会话信息，请重试")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    options,
+	})
+}
+
+// WebAuthnRegisterFinish godoc
+// @Summary Finish passkey registration for the current user
+// @Tags public
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/public/auth/webauthn/register/finish [post]
+// WebAuthnRegisterFinish verifies the attestation posted by the browser and
+// stores the new passkey
+func WebAuthnRegisterFinish(c *gin.Context) {
+	wa, err := newWebAuthn()
+	if err != nil {
+		webauthnFail(c, err.Error())
+		return
+	}
+	user, err := currentWebAuthnUser(c)
+	if err != nil {
+		webauthnFail(c, err.Error())
+		return
+	}
+	sessionData, err := takeWebAuthnSession(c, webauthnRegistrationSessionKey)
+	if err != nil {
+		webauthnFail(c, "注册已过期，请重新开始")
+		return
+	}
+	credential, err := wa.FinishRegistration(user, *sessionData, c.Request)
+	if err != nil {
+		logger.Loginf(c.Request.Context(), "webauthn register finish failed user=%s err=%v", user.user.Id, err)
+		webauthnFail(c, "通行密钥验证失败")
+		return
+	}
+	record := webauthnCredentialToModel(user.user.Id, credential)
+	if err := model.CreateWebAuthnCredential(record); err != nil {
+		logger.LoginErrorf(c.Request.Context(), "webauthn credential save failed user=%s err=%v", user.user.Id, err)
+		webauthnFail(c, "保存通行密钥失败")
+		return
+	}
+	logger.Loginf(c.Request.Context(), "webauthn credential registered user=%s credential=%s", user.user.Id, record.CredentialId)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data": gin.H{
+			"credential_id": record.CredentialId,
+			"created_at":    record.CreatedAt,
+		},
+	})
+}
+
+// WebAuthnLoginBegin godoc
+// @Summary Start a passkey login
+// @Tags public
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Router /api/v1/public/auth/webauthn/login/begin [post]
+// WebAuthnLoginBegin returns assertion options for a discoverable login, so
+// the browser offers whichever passkeys it holds for this site
+func WebAuthnLoginBegin(c *gin.Context) {
+	wa, err := newWebAuthn()
+	if err != nil {
+		webauthnFail(c, err.Error())
+		return
+	}
+	options, sessionData, err := wa.BeginDiscoverableLogin()
+	if err != nil {
+		logger.Loginf(c.Request.Context(), "webauthn login begin failed err=%v", err)
+		webauthnFail(c, "无法开始通行密钥登录")
+		return
+	}
+	if err := saveWebAuthnSession(c, webauthnLoginSessionKey, sessionData); err != nil {
+		webauthnFail(c, "无法保存会话信息，请重试")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    options,
+	})
+}
+
+// WebAuthnLoginFinish godoc
+// @Summary Finish a passkey login
+// @Tags public
+// @Accept json
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Router /api/v1/public/auth/webauthn/login/finish [post]
+// WebAuthnLoginFinish verifies the assertion, then logs the user in like
+// WalletLogin: a session plus an access token carrying webauthn_credential_id
+func WebAuthnLoginFinish(c *gin.Context) {
+	wa, err := newWebAuthn()
+	if err != nil {
+		webauthnFail(c, err.Error())
+		return
+	}
+	sessionData, err := takeWebAuthnSession(c, webauthnLoginSessionKey)
+	if err != nil {
+		webauthnFail(c, "登录已过期，请重新开始")
+		return
+	}
+	var user *model.User
+	credential, err := wa.FinishDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+		found := model.User{Id: string(userHandle)}
+		if err := found.FillUserById(); err != nil {
+			return nil, err
+		}
+		user = &found
+		return loadWebAuthnUser(&found)
+	}, *sessionData, c.Request)
+	if err != nil || user == nil {
+		logger.Loginf(c.Request.Context(), "webauthn login verify failed err=%v", err)
+		webauthnFail(c, "通行密钥验证失败")
+		return
+	}
+	credentialId := base64.RawURLEncoding.EncodeToString(credential.ID)
+	if credential.Authenticator.CloneWarning {
+		logger.SysWarnf("webauthn sign count regressed, credential may be cloned user=%s credential=%s", user.Id, credentialId)
+		webauthnFail(c, "通行密钥状态异常，请联系管理员")
+		return
+	}
+	if user.Status == model.UserStatusPendingApproval {
+		webauthnFail(c, errWalletUserPendingApproval.Error())
+		return
+	}
+	if user.Status != model.UserStatusEnabled {
+		webauthnFail(c, walletErrorMessages[WalletErrUserDisabled])
+		return
+	}
+	if err := model.UpdateWebAuthnCredentialUsage(credentialId, int64(credential.Authenticator.SignCount), credential.Authenticator.CloneWarning, credential.Flags.BackupState); err != nil {
+		logger.LoginErrorf(c.Request.Context(), "webauthn credential update failed credential=%s err=%v", credentialId, err)
+	}
+	now := helper.GetTimestamp()
+	if err := model.UpdateUserLastLoginAt(user.Id, now); err == nil {
+		user.LastLoginAt = now
+	}
+	if err := usercontroller.SetupSession(user, c); err != nil {
+		webauthnFail(c, "无法保存会话信息，请重试")
+		return
+	}
+	token, exp, tokenErr := common.GenerateWebAuthnJWT(user.Id, credentialId)
+	if tokenErr != nil {
+		logger.LoginErrorf(c.Request.Context(), "webauthn jwt generate failed user=%s err=%v", user.Id, tokenErr)
+	}
+	logger.Loginf(c.Request.Context(), "webauthn login success user=%s credential=%s", user.Id, credentialId)
+	resp := gin.H{
+		"success": true,
+		"message": "",
+		"data":    safeUserResponse(user),
+	}
+	if token != "" {
+		resp["token"] = token
+		resp["jti"] = common.WalletJWTID(token)
+		resp["token_expires_at"] = exp.UTC().Format(time.RFC3339)
+	}
+	c.JSON(http.StatusOK, resp)
+}
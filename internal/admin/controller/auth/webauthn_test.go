@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+func TestWebAuthnCredentialModelRoundTrip(t *testing.T) {
+	credential := &webauthn.Credential{
+		ID:              []byte{0x01, 0xfe, 0x7f},
+		PublicKey:       []byte("cose-key"),
+		AttestationType: "none",
+		Transport:       []protocol.AuthenticatorTransport{protocol.USB, protocol.Internal},
+		Flags:           webauthn.CredentialFlags{UserPresent: true, UserVerified: true, BackupEligible: true},
+		Authenticator:   webauthn.Authenticator{AAGUID: []byte{0xaa}, SignCount: 7},
+	}
+	record := webauthnCredentialToModel("user-1", credential)
+	if record.UserId != "user-1" || record.CredentialId != "Af5_" || record.Transports != "usb,internal" {
+		t.Fatalf("webauthnCredentialToModel = %+v", record)
+	}
+	back := webauthnCredentialFromModel(*record)
+	if !bytes.Equal(back.ID, credential.ID) || !bytes.Equal(back.PublicKey, credential.PublicKey) {
+		t.Fatalf("round trip lost key material: %+v", back)
+	}
+	if back.Authenticator.SignCount != 7 || !back.Flags.BackupEligible || back.Flags.BackupState || len(back.Transport) != 2 {
+		t.Fatalf("round trip lost authenticator state: %+v", back)
+	}
+}
@@ -2,6 +2,7 @@ package channel
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -16,6 +17,7 @@ import (
 )
 
 const maxChannelListPageSize = 100
+const maxChannelBulkUpdateSize = 100
 
 type updateChannelTestModelRequest struct {
 	ID        string `json:"id"`
@@ -166,13 +168,18 @@ func parseChannelListPageParams(c *gin.Context) (page int, pageSize int, keyword
 	return page, pageSize, keyword
 }
 
+func parseIncludeDeleted(c *gin.Context) bool {
+	raw := strings.TrimSpace(c.Query("include_deleted"))
+	return raw == "1" || strings.EqualFold(raw, "true")
+}
+
 func parseCompactMode(c *gin.Context) bool {
 	raw := strings.TrimSpace(c.Query("compact"))
 	return raw == "1" || strings.EqualFold(raw, "true")
 }
 
-func listChannelsPage(page int, pageSize int, keyword string) (channelListPageData, error) {
-	rows, total, err := channelsvc.ListPage(page, pageSize, keyword)
+func listChannelsPage(page int, pageSize int, keyword string, includeDeleted bool) (channelListPageData, error) {
+	rows, total, err := channelsvc.ListPage(page, pageSize, keyword, includeDeleted)
 	if err != nil {
 		return channelListPageData{}, err
 	}
@@ -210,12 +217,13 @@ func isModelInChannelModels(testModel string, models string) bool {
 // @Param page_size query int false "Page size"
 // @Param keyword query string false "Keyword"
 // @Param compact query int false "Compact mode (1=true)"
+// @Param include_deleted query bool false "Include soft-deleted channels"
 // @Success 200 {object} docs.StandardResponse
 // @Failure 401 {object} docs.ErrorResponse
 // @Router /api/v1/admin/channels [get]
 func GetChannels(c *gin.Context) {
 	page, pageSize, keyword := parseChannelListPageParams(c)
-	data, err := listChannelsPage(page, pageSize, keyword)
+	data, err := listChannelsPage(page, pageSize, keyword, parseIncludeDeleted(c))
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"success": false,
@@ -430,6 +438,109 @@ func DeleteChannel(c *gin.Context) {
 	return
 }
 
+// SoftDeleteChannel godoc
+// @Summary Soft delete channel and disable its bound tokens (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Channel ID"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/channels/{id} [delete]
+func SoftDeleteChannel(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	tokensDisabled, err := channelsvc.SoftDeleteByID(id)
+	if err != nil {
+		logChannelAdminWarn(c, "soft_delete", stringField("channel_id", id), stringField("reason", err.Error()))
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	logChannelAdminInfo(c, "soft_delete", stringField("channel_id", id), int64Field("tokens_disabled", tokensDisabled))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data": gin.H{
+			"tokens_disabled": tokensDisabled,
+		},
+	})
+}
+
+// BulkUpdateChannels godoc
+// @Summary Bulk update channel weight, priority and status (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body []model.ChannelBulkUpdate true "Channel bulk update payload"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/channels/bulk [patch]
+func BulkUpdateChannels(c *gin.Context) {
+	items := make([]model.ChannelBulkUpdate, 0)
+	if err := c.ShouldBindJSON(&items); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": "无效的参数",
+		})
+		return
+	}
+	if err := validateChannelBulkUpdate(items); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	results, err := channelsvc.BulkUpdate(items)
+	if err != nil {
+		logChannelAdminWarn(c, "bulk_update", intField("count", len(items)), stringField("reason", err.Error()))
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	summary := make([]string, 0, len(results))
+	for _, result := range results {
+		summary = append(summary, result.Id+":"+strings.Join(result.UpdatedFields, "+"))
+	}
+	logChannelAdminInfo(c, "bulk_update", intField("count", len(results)), stringField("changes", strings.Join(summary, ",")))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    results,
+	})
+}
+
+func validateChannelBulkUpdate(items []model.ChannelBulkUpdate) error {
+	if len(items) == 0 {
+		return fmt.Errorf("更新列表不能为空")
+	}
+	if len(items) > maxChannelBulkUpdateSize {
+		return fmt.Errorf("单次最多更新 %d 个渠道", maxChannelBulkUpdateSize)
+	}
+	seen := make(map[string]struct{}, len(items))
+	for i := range items {
+		items[i].Id = strings.TrimSpace(items[i].Id)
+		id := items[i].Id
+		if id == "" {
+			return fmt.Errorf("渠道 ID 不能为空")
+		}
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("渠道 ID 重复：%s", id)
+		}
+		seen[id] = struct{}{}
+		if status := items[i].Status; status != nil &&
+			*status != model.ChannelStatusEnabled && *status != model.ChannelStatusManuallyDisabled {
+			return fmt.Errorf("渠道 %s 的状态无效", id)
+		}
+	}
+	return nil
+}
+
 // DeleteDisabledChannel godoc
 // @Summary Delete disabled channels (admin)
 // @Tags admin
@@ -468,6 +579,27 @@ func DeleteDisabledChannel(c *gin.Context) {
 // @Failure 401 {object} docs.ErrorResponse
 // @Router /api/v1/admin/channel [put]
 func UpdateChannel(c *gin.Context) {
+	updateChannel(c, "")
+}
+
+// UpdateChannelById godoc
+// @Summary Update channel by id (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Channel ID"
+// @Param body body docs.ChannelUpdateRequest true "Channel update payload"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/channels/{id} [put]
+func UpdateChannelById(c *gin.Context) {
+	updateChannel(c, c.Param("id"))
+}
+
+// updateChannel applies a channel update payload; a non-empty pathID overrides
+// the id in the body.
+func updateChannel(c *gin.Context, pathID string) {
 	rawBody, err := io.ReadAll(c.Request.Body)
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
@@ -505,6 +637,10 @@ func UpdateChannel(c *gin.Context) {
 	_, channel.NameProvided = rawFields["name"]
 	_, channel.ModelsProvided = rawFields["models"]
 	_, channel.ModelConfigsProvided = rawFields["model_configs"]
+	_, channel.TimeoutProvided = rawFields["timeout_seconds"]
+	if pathID = strings.TrimSpace(pathID); pathID != "" {
+		channel.Id = pathID
+	}
 	channel.NormalizeModelConfigState()
 	err = channelsvc.Update(&channel)
 	if err != nil {
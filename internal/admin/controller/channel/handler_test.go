@@ -0,0 +1,44 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+
+	adminmodel "github.com/yeying-community/router/internal/admin/model"
+)
+
+func TestValidateChannelBulkUpdate(t *testing.T) {
+	enabled, deleted := adminmodel.ChannelStatusEnabled, adminmodel.ChannelStatusDeleted
+	tooMany := make([]adminmodel.ChannelBulkUpdate, maxChannelBulkUpdateSize+1)
+	for i := range tooMany {
+		tooMany[i].Id = strings.Repeat("c", i+1)
+	}
+	tests := map[string]struct {
+		items   []adminmodel.ChannelBulkUpdate
+		wantErr string
+	}{
+		"valid":          {items: []adminmodel.ChannelBulkUpdate{{Id: " a ", Status: &enabled}, {Id: "b"}}},
+		"empty":          {items: nil, wantErr: "不能为空"},
+		"too many":       {items: tooMany, wantErr: "单次最多更新"},
+		"blank id":       {items: []adminmodel.ChannelBulkUpdate{{Id: " "}}, wantErr: "渠道 ID 不能为空"},
+		"duplicate id":   {items: []adminmodel.ChannelBulkUpdate{{Id: "a"}, {Id: " a"}}, wantErr: "渠道 ID 重复"},
+		"invalid status": {items: []adminmodel.ChannelBulkUpdate{{Id: "a", Status: &deleted}}, wantErr: "状态无效"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateChannelBulkUpdate(tt.items)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateChannelBulkUpdate error: %v", err)
+				}
+				if tt.items[0].Id != "a" {
+					t.Fatalf("id = %q, want it trimmed", tt.items[0].Id)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
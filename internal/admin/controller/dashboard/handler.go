@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/yeying-community/router/common/helper"
 	"github.com/yeying-community/router/internal/admin/model"
+	dashboardsvc "github.com/yeying-community/router/internal/admin/service/dashboard"
 )
 
 const (
@@ -933,3 +934,27 @@ func GetDashboard(c *gin.Context) {
 		"data":    payload,
 	})
 }
+
+// GetStatsDashboard godoc
+// @Summary Get admin system metrics snapshot
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/stats/dashboard [get]
+func GetStatsDashboard(c *gin.Context) {
+	stats, err := dashboardsvc.GetStats()
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    stats,
+	})
+}
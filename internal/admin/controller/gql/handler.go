@@ -0,0 +1,97 @@
+package gql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+const (
+	maxQueryDepth      = 5
+	maxQueryComplexity = 100
+)
+
+// NewHandler serves GraphQL queries over POST. Schema introspection is only
+// enabled together with the playground. Each request gets its own apiKeys
+// loader.
+func NewHandler() gin.HandlerFunc {
+	server := handler.New(NewExecutableSchema(Config{Resolvers: &Resolver{}}))
+	server.AddTransport(transport.POST{})
+	server.Use(depthLimit{max: maxQueryDepth})
+	server.Use(extension.FixedComplexityLimit(maxQueryComplexity))
+	if config.GraphQLPlaygroundEnabled {
+		server.Use(extension.Introspection{})
+	}
+	return func(c *gin.Context) {
+		server.ServeHTTP(c.Writer, c.Request.WithContext(withAPIKeyLoader(c.Request.Context())))
+	}
+}
+
+// NewPlaygroundHandler serves the GraphQL playground for endpoint.
+func NewPlaygroundHandler(endpoint string) gin.HandlerFunc {
+	page := playground.Handler("Router GraphQL", endpoint)
+	return func(c *gin.Context) {
+		page.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// depthLimit rejects operations whose fields nest deeper than max. Fragments
+// count as the fields they contain, and introspection fields are not counted.
+type depthLimit struct {
+	max int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = depthLimit{}
+
+func (depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (depthLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d depthLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if rc.Operation == nil {
+		return nil
+	}
+	if depth := selectionDepth(rc.Operation.SelectionSet); depth > d.max {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.max)
+		err.Extensions = map[string]interface{}{"code": "DEPTH_LIMIT_EXCEEDED"}
+		return err
+	}
+	return nil
+}
+
+func selectionDepth(set ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range set {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			depth = max(depth, 1+selectionDepth(s.SelectionSet))
+		case *ast.InlineFragment:
+			depth = max(depth, selectionDepth(s.SelectionSet))
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				depth = max(depth, selectionDepth(s.Definition.SelectionSet))
+			}
+		}
+	}
+	return depth
+}
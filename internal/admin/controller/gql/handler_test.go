@@ -0,0 +1,84 @@
+package gql
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+type graphQLResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func serveGraphQL(t *testing.T, handler gin.HandlerFunc, query string, variables map[string]any) graphQLResponse {
+	t.Helper()
+	engine := testutil.NewTestEngine()
+	engine.POST("/api/v1/admin/graphql", handler)
+	recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodPost, "/api/v1/admin/graphql",
+		map[string]any{"query": query, "variables": variables}))
+	var resp graphQLResponse
+	if err := testutil.DecodeJSON(recorder, &resp); err != nil {
+		t.Fatalf("decode response %q: %v", recorder.Body.String(), err)
+	}
+	return resp
+}
+
+func TestNewHandler_RejectsOverLimitQueries(t *testing.T) {
+	var aliases []string
+	for i := 0; i <= maxQueryComplexity/2; i++ {
+		aliases = append(aliases, fmt.Sprintf("c%d: channels { id }", i))
+	}
+	tests := map[string]struct {
+		query string
+		want  string
+	}{
+		"complexity":    {query: "{ " + strings.Join(aliases, " ") + " }", want: "COMPLEXITY_LIMIT_EXCEEDED"},
+		"introspection": {query: "{ __schema { queryType { name } } }", want: "introspection disabled"},
+	}
+	handler := NewHandler()
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := serveGraphQL(t, handler, tt.query, nil)
+			if len(resp.Errors) == 0 || (resp.Errors[0].Extensions["code"] != tt.want && resp.Errors[0].Message != tt.want) {
+				t.Fatalf("errors = %+v, want %s", resp.Errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectionDepth(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		want  int
+	}{
+		"flat":             {query: "{ channels { id name } }", want: 2},
+		"nested":           {query: "{ users { items { apiKeys { id } } } }", want: 4},
+		"inline fragment":  {query: "{ users { ... on UserPage { items { id } } } }", want: 3},
+		"introspection":    {query: "{ __typename channels { id __typename } }", want: 2},
+		"over the limit":   {query: "{ a { b { c { d { e { f } } } } } }", want: 6},
+		"deepest sibling":  {query: "{ a { b } c { d { e } } }", want: 3},
+		"empty selections": {query: "{ a }", want: 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc, err := parser.ParseQuery(&ast.Source{Input: tt.query})
+			if err != nil {
+				t.Fatalf("ParseQuery error: %v", err)
+			}
+			if got := selectionDepth(doc.Operations[0].SelectionSet); got != tt.want {
+				t.Fatalf("depth = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
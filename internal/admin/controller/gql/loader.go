@@ -0,0 +1,70 @@
+package gql
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+type apiKeyLoaderKey struct{}
+
+// apiKeyLoader batches the apiKeys lookups of one request. users registers the
+// ids of the page it returns, and the first apiKeys field loads the keys of all
+// of them with a single query instead of one query per user.
+type apiKeyLoader struct {
+	mu      sync.Mutex
+	pending []string
+	keys    map[string][]model.ApiKey
+}
+
+func withAPIKeyLoader(ctx context.Context) context.Context {
+	return context.WithValue(ctx, apiKeyLoaderKey{}, &apiKeyLoader{keys: map[string][]model.ApiKey{}})
+}
+
+// apiKeyLoaderFor returns the loader of the request, or a fresh one when ctx
+// carries none.
+func apiKeyLoaderFor(ctx context.Context) *apiKeyLoader {
+	if loader, ok := ctx.Value(apiKeyLoaderKey{}).(*apiKeyLoader); ok {
+		return loader
+	}
+	return &apiKeyLoader{keys: map[string][]model.ApiKey{}}
+}
+
+// register queues the users whose keys the next load fetches.
+func (l *apiKeyLoader) register(users []model.User) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, user := range users {
+		if _, ok := l.keys[user.Id]; !ok {
+			l.pending = append(l.pending, user.Id)
+		}
+	}
+}
+
+// load returns the keys of userID, newest first, fetching the keys of every
+// queued user along with it.
+func (l *apiKeyLoader) load(ctx context.Context, userID string) ([]model.ApiKey, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if keys, ok := l.keys[userID]; ok {
+		return keys, nil
+	}
+	ids := l.pending
+	if !slices.Contains(ids, userID) {
+		ids = append(ids, userID)
+	}
+	keys := make([]model.ApiKey, 0)
+	if err := model.DB.WithContext(ctx).Where("user_id IN ?", ids).Order("created_at desc").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	l.pending = nil
+	for _, id := range ids {
+		l.keys[id] = make([]model.ApiKey, 0)
+	}
+	for _, key := range keys {
+		l.keys[key.UserId] = append(l.keys[key.UserId], key)
+	}
+	return l.keys[userID], nil
+}
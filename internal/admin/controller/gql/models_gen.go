@@ -0,0 +1,85 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package gql
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+type PageInput struct {
+	// 1-based.
+	Page *int `json:"page,omitempty"`
+	// Defaults to the admin page size, at most 100.
+	PageSize *int `json:"pageSize,omitempty"`
+}
+
+type Query struct {
+}
+
+// Deleted users are never returned. keyword matches like GET /api/v1/admin/user/search, and also matches any wallet bound to the user.
+type UserFilter struct {
+	Keyword *string `json:"keyword,omitempty"`
+	Role    *int    `json:"role,omitempty"`
+	Status  *int    `json:"status,omitempty"`
+	Group   *string `json:"group,omitempty"`
+}
+
+type UserPage struct {
+	Items    []model.User `json:"items"`
+	Total    int          `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"pageSize"`
+}
+
+type ChannelStatus string
+
+const (
+	ChannelStatusUnknown          ChannelStatus = "UNKNOWN"
+	ChannelStatusEnabled          ChannelStatus = "ENABLED"
+	ChannelStatusManuallyDisabled ChannelStatus = "MANUALLY_DISABLED"
+	ChannelStatusAutoDisabled     ChannelStatus = "AUTO_DISABLED"
+	ChannelStatusCreating         ChannelStatus = "CREATING"
+	ChannelStatusDeleted          ChannelStatus = "DELETED"
+)
+
+var AllChannelStatus = []ChannelStatus{
+	ChannelStatusUnknown,
+	ChannelStatusEnabled,
+	ChannelStatusManuallyDisabled,
+	ChannelStatusAutoDisabled,
+	ChannelStatusCreating,
+	ChannelStatusDeleted,
+}
+
+func (e ChannelStatus) IsValid() bool {
+	switch e {
+	case ChannelStatusUnknown, ChannelStatusEnabled, ChannelStatusManuallyDisabled, ChannelStatusAutoDisabled, ChannelStatusCreating, ChannelStatusDeleted:
+		return true
+	}
+	return false
+}
+
+func (e ChannelStatus) String() string {
+	return string(e)
+}
+
+func (e *ChannelStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ChannelStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ChannelStatus", str)
+	}
+	return nil
+}
+
+func (e ChannelStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
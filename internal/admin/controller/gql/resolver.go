@@ -0,0 +1,72 @@
+// Package gql serves the read-only admin GraphQL API. The schema lives in
+// schema.graphqls; generated.go and models_gen.go are generated from it.
+package gql
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+const (
+	maxPageSize = 100
+	// maxAuditLogs bounds auditLogs to the newest entries in its range.
+	maxAuditLogs = 1000
+)
+
+var channelStatusNames = map[int]ChannelStatus{
+	model.ChannelStatusUnknown:          ChannelStatusUnknown,
+	model.ChannelStatusEnabled:          ChannelStatusEnabled,
+	model.ChannelStatusManuallyDisabled: ChannelStatusManuallyDisabled,
+	model.ChannelStatusAutoDisabled:     ChannelStatusAutoDisabled,
+	model.ChannelStatusCreating:         ChannelStatusCreating,
+	model.ChannelStatusDeleted:          ChannelStatusDeleted,
+}
+
+var channelStatusValues = func() map[ChannelStatus]int {
+	values := make(map[ChannelStatus]int, len(channelStatusNames))
+	for value, name := range channelStatusNames {
+		values[name] = value
+	}
+	return values
+}()
+
+// Resolver is the root resolver. It holds no state: the resolvers query
+// model.DB with the request context.
+type Resolver struct{}
+
+func pageParams(page *PageInput) (int, int) {
+	pageNum, pageSize := 1, config.ItemsPerPage
+	if page != nil {
+		if page.Page != nil && *page.Page > 0 {
+			pageNum = *page.Page
+		}
+		if page.PageSize != nil && *page.PageSize > 0 {
+			pageSize = *page.PageSize
+		}
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return pageNum, pageSize
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(*value)
+}
+
+// unixTime converts a unix timestamp in seconds; 0 means unset.
+func unixTime(seconds int64) *time.Time {
+	if seconds <= 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t
+}
@@ -0,0 +1,182 @@
+package gql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.55
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// LastUsedAt is the resolver for the lastUsedAt field.
+func (r *aPIKeyResolver) LastUsedAt(ctx context.Context, obj *model.ApiKey) (*time.Time, error) {
+	return unixTime(obj.LastUsedAt), nil
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *aPIKeyResolver) CreatedAt(ctx context.Context, obj *model.ApiKey) (*time.Time, error) {
+	return unixTime(obj.CreatedAt), nil
+}
+
+// Status is the resolver for the status field.
+func (r *channelResolver) Status(ctx context.Context, obj *model.Channel) (ChannelStatus, error) {
+	if status, ok := channelStatusNames[obj.Status]; ok {
+		return status, nil
+	}
+	return ChannelStatusUnknown, nil
+}
+
+// Weight is the resolver for the weight field.
+func (r *channelResolver) Weight(ctx context.Context, obj *model.Channel) (int, error) {
+	if obj.Weight == nil {
+		return 0, nil
+	}
+	return int(*obj.Weight), nil
+}
+
+// Priority is the resolver for the priority field.
+func (r *channelResolver) Priority(ctx context.Context, obj *model.Channel) (int, error) {
+	if obj.Priority == nil {
+		return 0, nil
+	}
+	return int(*obj.Priority), nil
+}
+
+// TestTime is the resolver for the testTime field.
+func (r *channelResolver) TestTime(ctx context.Context, obj *model.Channel) (*time.Time, error) {
+	return unixTime(obj.TestTime), nil
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *channelResolver) CreatedAt(ctx context.Context, obj *model.Channel) (*time.Time, error) {
+	return unixTime(obj.CreatedTime), nil
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, filter *UserFilter, page *PageInput) (*UserPage, error) {
+	pageNum, pageSize := pageParams(page)
+	query := model.DB.WithContext(ctx).Model(&model.User{}).Where("status != ?", model.UserStatusDeleted)
+	if filter != nil {
+		if keyword := stringValue(filter.Keyword); keyword != "" {
+			likeKeyword := keyword + "%"
+			query = query.Where(
+				"(id = ? OR username LIKE ? OR email LIKE ? OR display_name LIKE ? OR wallet_address LIKE ? OR "+
+					"EXISTS (SELECT 1 FROM user_wallets WHERE user_wallets.user_id = users.id AND user_wallets.address LIKE ?))",
+				keyword, likeKeyword, likeKeyword, likeKeyword, likeKeyword, likeKeyword,
+			)
+		}
+		if filter.Role != nil {
+			query = query.Where("role = ?", *filter.Role)
+		}
+		if filter.Status != nil {
+			query = query.Where("status = ?", *filter.Status)
+		}
+		if group := stringValue(filter.Group); group != "" {
+			query = query.Where(`"group" = ?`, group)
+		}
+	}
+	// a new session, so Count does not leak into the page query
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	users := make([]model.User, 0, pageSize)
+	if err := query.Omit("password", "access_token").
+		Order("created_at desc").
+		Limit(pageSize).
+		Offset((pageNum - 1) * pageSize).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	apiKeyLoaderFor(ctx).register(users)
+	return &UserPage{Items: users, Total: int(total), Page: pageNum, PageSize: pageSize}, nil
+}
+
+// Channels is the resolver for the channels field.
+func (r *queryResolver) Channels(ctx context.Context, status *ChannelStatus) ([]model.Channel, error) {
+	query := model.DB.WithContext(ctx).Omit("key").Order("created_time desc")
+	if status != nil {
+		query = query.Where("status = ?", channelStatusValues[*status])
+	} else {
+		query = query.Where("status <> ?", model.ChannelStatusDeleted)
+	}
+	channels := make([]model.Channel, 0)
+	if err := query.Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// AuditLogs is the resolver for the auditLogs field.
+func (r *queryResolver) AuditLogs(ctx context.Context, userID *string, from *time.Time, to *time.Time) ([]logger.WalletAuditEvent, error) {
+	var fromTime, toTime time.Time
+	if from != nil {
+		fromTime = *from
+	}
+	if to != nil {
+		toTime = *to
+	}
+	userFilter := stringValue(userID)
+	events := make([]logger.WalletAuditEvent, 0)
+	err := logger.ReadWalletAuditEvents(fromTime, toTime, func(event logger.WalletAuditEvent) bool {
+		if userFilter != "" && event.UserID != userFilter {
+			return ctx.Err() == nil
+		}
+		events = append(events, event)
+		if len(events) > 2*maxAuditLogs {
+			events = append(events[:0], events[len(events)-maxAuditLogs:]...)
+		}
+		return ctx.Err() == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(events) > maxAuditLogs {
+		events = events[len(events)-maxAuditLogs:]
+	}
+	slices.Reverse(events)
+	return events, nil
+}
+
+// CreatedAt is the resolver for the createdAt field.
+func (r *userResolver) CreatedAt(ctx context.Context, obj *model.User) (*time.Time, error) {
+	return unixTime(obj.CreatedAt), nil
+}
+
+// LastLoginAt is the resolver for the lastLoginAt field.
+func (r *userResolver) LastLoginAt(ctx context.Context, obj *model.User) (*time.Time, error) {
+	return unixTime(obj.LastLoginAt), nil
+}
+
+// APIKeys is the resolver for the apiKeys field.
+func (r *userResolver) APIKeys(ctx context.Context, obj *model.User) ([]model.ApiKey, error) {
+	return apiKeyLoaderFor(ctx).load(ctx, obj.Id)
+}
+
+// APIKey returns APIKeyResolver implementation.
+func (r *Resolver) APIKey() APIKeyResolver { return &aPIKeyResolver{r} }
+
+// Channel returns ChannelResolver implementation.
+func (r *Resolver) Channel() ChannelResolver { return &channelResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type aPIKeyResolver struct{ *Resolver }
+type channelResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,155 @@
+package gql
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// useDryRunDB points model.DB at a dry-run database and returns the SQL of
+// every query it is sent.
+func useDryRunDB(t *testing.T) *[]string {
+	t.Helper()
+	prevDB := model.DB
+	t.Cleanup(func() { model.DB = prevDB })
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	statements := []string{}
+	_ = db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	})
+	model.DB = db
+	return &statements
+}
+
+func TestUsers_FiltersAndPages(t *testing.T) {
+	statements := useDryRunDB(t)
+	resp := serveGraphQL(t, NewHandler(), `query($filter: UserFilter, $page: PageInput) {
+		users(filter: $filter, page: $page) { total page pageSize items { id } }
+	}`, map[string]any{
+		"filter": map[string]any{"keyword": "ali", "role": 10, "group": "vip"},
+		"page":   map[string]any{"page": 3, "pageSize": 500},
+	})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+	users := resp.Data["users"].(map[string]any)
+	if users["page"] != float64(3) || users["pageSize"] != float64(maxPageSize) {
+		t.Fatalf("users = %v, want page 3 of size %d", users, maxPageSize)
+	}
+	if len(*statements) != 2 {
+		t.Fatalf("statements = %q, want a count and a page query", *statements)
+	}
+	for _, want := range []string{
+		"status != 3", "username LIKE \"ali%\"", "role = 10", `"group" = "vip"`,
+		"EXISTS (SELECT 1 FROM user_wallets WHERE user_wallets.user_id = users.id AND user_wallets.address LIKE \"ali%\")",
+	} {
+		for _, stmt := range *statements {
+			if !strings.Contains(stmt, want) {
+				t.Fatalf("statement %q is missing %q", stmt, want)
+			}
+		}
+	}
+	page := (*statements)[1]
+	if !strings.Contains(page, "LIMIT 100 OFFSET 200") || strings.Contains(page, "`password`") {
+		t.Fatalf("page query = %q, want LIMIT 100 OFFSET 200 without the password", page)
+	}
+}
+
+func TestUsers_LoadsAPIKeysInOneQuery(t *testing.T) {
+	statements := useDryRunDB(t)
+	_ = model.DB.Callback().Query().After("gorm:query").Register("test:rows", func(tx *gorm.DB) {
+		switch dest := tx.Statement.Dest.(type) {
+		case *[]model.User:
+			*dest = []model.User{{Id: "keys-a"}, {Id: "keys-b"}, {Id: "keys-c"}}
+		case *[]model.ApiKey:
+			*dest = []model.ApiKey{{Id: "key-2", UserId: "keys-b"}, {Id: "key-1", UserId: "keys-a"}, {Id: "key-0", UserId: "keys-b"}}
+		}
+	})
+	resp := serveGraphQL(t, NewHandler(), `{ users { items { id apiKeys { id } } } }`, nil)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+	if len(*statements) != 3 {
+		t.Fatalf("statements = %q, want a count, a page and one api key query", *statements)
+	}
+	if keys := (*statements)[2]; !strings.Contains(keys, `user_id IN ("keys-a","keys-b","keys-c")`) {
+		t.Fatalf("api key query = %q, want the keys of the whole page", keys)
+	}
+	want := map[string]string{"keys-a": "key-1", "keys-b": "key-2,key-0", "keys-c": ""}
+	for _, item := range resp.Data["users"].(map[string]any)["items"].([]any) {
+		user := item.(map[string]any)
+		ids := []string{}
+		for _, key := range user["apiKeys"].([]any) {
+			ids = append(ids, key.(map[string]any)["id"].(string))
+		}
+		if got := strings.Join(ids, ","); got != want[user["id"].(string)] {
+			t.Fatalf("%s apiKeys = %s, want %s", user["id"], got, want[user["id"].(string)])
+		}
+	}
+}
+
+func TestChannels_StatusFilter(t *testing.T) {
+	tests := map[string]struct {
+		variables map[string]any
+		want      string
+	}{
+		"default":  {want: "status <> 5"},
+		"disabled": {variables: map[string]any{"status": "AUTO_DISABLED"}, want: "status = 3"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			statements := useDryRunDB(t)
+			resp := serveGraphQL(t, NewHandler(), `query($status: ChannelStatus) { channels(status: $status) { id } }`, tt.variables)
+			if len(resp.Errors) > 0 {
+				t.Fatalf("errors = %+v", resp.Errors)
+			}
+			if len(*statements) != 1 || !strings.Contains((*statements)[0], tt.want) {
+				t.Fatalf("statements = %q, want %q", *statements, tt.want)
+			}
+			if strings.Contains((*statements)[0], "key") {
+				t.Fatalf("statement %q selects the channel key", (*statements)[0])
+			}
+		})
+	}
+}
+
+func TestAuditLogs_NewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	prevDir := logger.LogDir
+	logger.LogDir = dir
+	defer func() { logger.LogDir = prevDir }()
+	lines := []string{
+		`{"timestamp":"2026-01-01T00:00:00Z","event":"login","user_id":"u1","success":true}`,
+		`{"timestamp":"2026-01-02T00:00:00Z","event":"login","user_id":"u2","success":true}`,
+		`{"timestamp":"2026-01-03T00:00:00Z","event":"bind","user_id":"u1","success":false,"reason":"taken"}`,
+		`{"timestamp":"2026-01-04T00:00:00Z","event":"login","user_id":"u1","success":true}`,
+	}
+	if err := os.WriteFile(filepath.Join(dir, "wallet_audit.jsonl"), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("write audit log: %v", err)
+	}
+
+	resp := serveGraphQL(t, NewHandler(), `query($userId: ID, $from: DateTime, $to: DateTime) {
+		auditLogs(userId: $userId, from: $from, to: $to) { timestamp event success reason }
+	}`, map[string]any{"userId": "u1", "from": "2026-01-01T00:00:00Z", "to": "2026-01-04T00:00:00Z"})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors = %+v", resp.Errors)
+	}
+	logs := resp.Data["auditLogs"].([]any)
+	if len(logs) != 2 {
+		t.Fatalf("auditLogs = %v, want the two u1 entries in range", logs)
+	}
+	first := logs[0].(map[string]any)
+	if first["event"] != "bind" || first["reason"] != "taken" || logs[1].(map[string]any)["event"] != "login" {
+		t.Fatalf("auditLogs = %v, want newest first", logs)
+	}
+}
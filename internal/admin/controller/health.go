@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+const (
+	healthCheckTimeout = 3 * time.Second
+	healthStatusOK     = "ok"
+	healthStatusError  = "error"
+)
+
+// GetHealthLive godoc
+// @Summary Liveness probe
+// @Tags public
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /health/live [get]
+// GetHealthLive always answers 200 while the process can serve requests.
+func GetHealthLive(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": healthStatusOK,
+	})
+}
+
+// GetHealth godoc
+// @Summary Readiness probe with subsystem status
+// @Tags public
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /health/ready [get]
+// GetHealth serves /health and /health/ready. It reports the database, the
+// wallet nonce store and whether wallet login is on, and returns 503 when a
+// critical check fails.
+func GetHealth(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	ready := true
+	database := healthStatusOK
+	if err := model.PingDB(ctx); err != nil {
+		logger.SysErrorf("health check database failed: %v", err)
+		database = healthStatusError
+		ready = false
+	}
+	nonceStore := healthStatusOK
+	if err := common.CheckWalletNonceStore(); err != nil {
+		logger.SysErrorf("health check nonce store failed: %v", err)
+		nonceStore = healthStatusError
+		ready = false
+	}
+
+	status, code := healthStatusOK, http.StatusOK
+	if !ready {
+		status, code = healthStatusError, http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"status":         status,
+		"database":       database,
+		"nonce_store":    nonceStore,
+		"wallet_enabled": model.WalletLoginEnabled(),
+	})
+}
@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestHealthProbes(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.GET("/health/live", GetHealthLive)
+	engine.GET("/health/ready", GetHealth)
+
+	recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/health/live", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("live status = %d, want 200", recorder.Code)
+	}
+
+	// no database in tests: readiness must fail while the memory nonce store passes
+	recorder = testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/health/ready", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("ready status = %d, want 503", recorder.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["database"] != "error" || body["nonce_store"] != "ok" {
+		t.Fatalf("body = %v", body)
+	}
+	if _, ok := body["wallet_enabled"].(bool); !ok {
+		t.Fatalf("wallet_enabled missing: %v", body)
+	}
+}
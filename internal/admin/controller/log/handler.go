@@ -1,13 +1,20 @@
 package log
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
 	"github.com/yeying-community/router/internal/admin/model"
 	"github.com/yeying-community/router/internal/admin/presenter"
 	logsvc "github.com/yeying-community/router/internal/admin/service/log"
@@ -611,3 +618,83 @@ func DeleteHistoryLogs(c *gin.Context) {
 	})
 	return
 }
+
+const (
+	relayLogExportBatchSize  = 500
+	relayLogExportFlushEvery = 100
+)
+
+// ExportRelayLogs godoc
+// @Summary Export relay (consume) logs as NDJSON (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Produce application/x-ndjson
+// @Param start_timestamp query int false "Start timestamp (unix)"
+// @Param end_timestamp query int false "End timestamp (unix)"
+// @Success 200 {string} string "NDJSON stream"
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/relay-logs/export [get]
+func ExportRelayLogs(c *gin.Context) {
+	startTimestamp, _ := strconv.ParseInt(c.Query("start_timestamp"), 10, 64)
+	endTimestamp, _ := strconv.ParseInt(c.Query("end_timestamp"), 10, 64)
+	if startTimestamp != 0 && endTimestamp != 0 && startTimestamp > endTimestamp {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": "开始时间不能晚于结束时间",
+		})
+		return
+	}
+	filenameTo := endTimestamp
+	if filenameTo == 0 {
+		filenameTo = time.Now().Unix()
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(config.ExportTimeoutMinutes)*time.Minute)
+	defer cancel()
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Accept-Ranges", "none")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="relay_logs_%d_%d.ndjson"`, startTimestamp, filenameTo))
+	useGzip := strings.Contains(c.GetHeader("Accept-Encoding"), "gzip")
+	if useGzip {
+		c.Header("Content-Encoding", "gzip")
+		c.Header("Vary", "Accept-Encoding")
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		out := w
+		var gz *gzip.Writer
+		if useGzip {
+			gz = gzip.NewWriter(w)
+			defer gz.Close()
+			out = gz
+		}
+		flush := func() {
+			if gz != nil {
+				_ = gz.Flush()
+			}
+			c.Writer.Flush()
+		}
+		encoder := json.NewEncoder(out)
+		written := 0
+		err := logsvc.StreamConsumeLogs(ctx, startTimestamp, endTimestamp, relayLogExportBatchSize, func(batch []*model.Log) error {
+			for _, row := range batch {
+				if err := encoder.Encode(row); err != nil {
+					return err
+				}
+				written++
+				if written%relayLogExportFlushEvery == 0 {
+					flush()
+				}
+			}
+			return nil
+		})
+		flush()
+		if err != nil {
+			logger.Errorf(ctx, "relay log export stopped after %d records: %v", written, err)
+		} else {
+			logger.Infof(ctx, "relay log export finished records=%d", written)
+		}
+		return false
+	})
+}
@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+// useRelayLogs serves rows for consume log queries of a dry-run log database
+// and returns the SQL of the queries it is sent.
+func useRelayLogs(t *testing.T, rows []*model.Log) *[]string {
+	t.Helper()
+	prevLogDB := model.LOG_DB
+	t.Cleanup(func() { model.LOG_DB = prevLogDB })
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	statements := []string{}
+	_ = db.Callback().Query().After("gorm:query").Register("test:rows", func(tx *gorm.DB) {
+		statements = append(statements, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		if dest, ok := tx.Statement.Dest.(*[]*model.Log); ok {
+			*dest = rows
+			tx.RowsAffected = int64(len(rows))
+		}
+	})
+	model.LOG_DB = db
+	return &statements
+}
+
+func exportRelayLogs(t *testing.T, query string, acceptGzip bool) (*http.Response, []byte) {
+	t.Helper()
+	engine := testutil.NewTestEngine()
+	engine.GET("/api/v1/admin/relay-logs/export", ExportRelayLogs)
+	// gin streams need a connection that can report the client going away
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/admin/relay-logs/export"+query, nil)
+	if err != nil {
+		t.Fatalf("NewRequest error: %v", err)
+	}
+	if acceptGzip {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("export request error: %v", err)
+	}
+	defer resp.Body.Close()
+	var body io.Reader = resp.Body
+	if acceptGzip {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader error: %v", err)
+		}
+		body = gz
+	}
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	return resp, payload
+}
+
+func decodeNDJSON(t *testing.T, payload []byte) []model.Log {
+	t.Helper()
+	logs := []model.Log{}
+	scanner := bufio.NewScanner(strings.NewReader(string(payload)))
+	for scanner.Scan() {
+		var row model.Log
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		logs = append(logs, row)
+	}
+	return logs
+}
+
+func TestExportRelayLogs_StreamsNDJSON(t *testing.T) {
+	rows := []*model.Log{
+		{Id: "log-1", Type: model.LogTypeConsume, CreatedAt: 150, ModelName: "gpt-4o"},
+		{Id: "log-2", Type: model.LogTypeConsume, CreatedAt: 160, ModelName: "gpt-4o-mini"},
+	}
+	for _, acceptGzip := range []bool{false, true} {
+		statements := useRelayLogs(t, rows)
+		resp, payload := exportRelayLogs(t, "?start_timestamp=100&end_timestamp=200", acceptGzip)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Fatalf("gzip %t: status = %d content type = %q, want an NDJSON stream", acceptGzip, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		if got := resp.Header.Get("Content-Disposition"); got != `attachment; filename="relay_logs_100_200.ndjson"` {
+			t.Fatalf("gzip %t: Content-Disposition = %q", acceptGzip, got)
+		}
+		if acceptGzip != (resp.Header.Get("Content-Encoding") == "gzip") {
+			t.Fatalf("gzip %t: Content-Encoding = %q", acceptGzip, resp.Header.Get("Content-Encoding"))
+		}
+		logs := decodeNDJSON(t, payload)
+		if len(logs) != 2 || logs[0].Id != "log-1" || logs[1].ModelName != "gpt-4o-mini" {
+			t.Fatalf("gzip %t: exported = %+v, want both rows in order", acceptGzip, logs)
+		}
+		if len(*statements) != 1 || !strings.Contains((*statements)[0], "type = 2 AND created_at >= 100 AND created_at <= 200") {
+			t.Fatalf("gzip %t: statements = %q, want one consume log query in range", acceptGzip, *statements)
+		}
+	}
+}
+
+func TestExportRelayLogs_RejectsReversedRange(t *testing.T) {
+	statements := useRelayLogs(t, nil)
+	resp, payload := exportRelayLogs(t, "?start_timestamp=200&end_timestamp=100", false)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(payload), `"success":false`) {
+		t.Fatalf("status = %d body = %s, want a rejection", resp.StatusCode, payload)
+	}
+	if len(*statements) != 0 {
+		t.Fatalf("statements = %q, want no query", *statements)
+	}
+}
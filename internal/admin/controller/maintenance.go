@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/i18n"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/transport/http/middleware"
+)
+
+type MaintenanceRequest struct {
+	Enabled    bool   `json:"enabled"`
+	Message    string `json:"message"`
+	AllowAdmin bool   `json:"allow_admin"`
+}
+
+// GetMaintenanceStatus godoc
+// @Summary Get maintenance mode status
+// @Tags public
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Router /api/v1/system/maintenance [get]
+func GetMaintenanceStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    middleware.GetMaintenanceState(),
+	})
+}
+
+// SetMaintenanceMode godoc
+// @Summary Toggle maintenance mode (root)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body MaintenanceRequest true "Maintenance payload"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 400 {object} docs.ErrorResponse
+// @Router /api/v1/admin/system/maintenance [put]
+func SetMaintenanceMode(c *gin.Context) {
+	var req MaintenanceRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": i18n.Translate(c, "invalid_parameter"),
+		})
+		return
+	}
+	middleware.SetMaintenanceMode(middleware.MaintenanceState{
+		Enabled:    req.Enabled,
+		Message:    req.Message,
+		AllowAdmin: req.AllowAdmin,
+	})
+	state := middleware.GetMaintenanceState()
+	logger.SysLogf("maintenance mode set enabled=%t allow_admin=%t by admin %s: %s",
+		state.Enabled, state.AllowAdmin, c.GetString(ctxkey.Id), state.Message)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    state,
+	})
+}
@@ -53,13 +53,31 @@ func GetStatus(c *gin.Context) {
 			"password_login_enabled":    config.PasswordLoginEnabled,
 			"password_register_enabled": config.PasswordRegisterEnabled,
 			"register_enabled":          config.RegisterEnabled,
-			"jwt_enabled":               config.JWTSecret != "",
+			"jwt_enabled":               common.WalletJWTSigningConfigured(),
 			"jwt_expire_hours":          config.JWTExpireHours,
 		},
 	})
 	return
 }
 
+// GetVersion godoc
+// @Summary Get build version
+// @Tags public
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Router /api/v1/system/version [get]
+func GetVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data": gin.H{
+			"version":    common.Version,
+			"build_time": common.BuildTime,
+			"git_commit": common.GitCommit,
+		},
+	})
+}
+
 // GetNotice godoc
 // @Summary Get system notice
 // @Tags public
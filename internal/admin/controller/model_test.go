@@ -2,17 +2,16 @@ package controller
 
 import (
 	"encoding/json"
-	"net/http/httptest"
+	"net/http"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/testutil"
 )
 
 func TestBuildOpenAIModelsForRequestOwnedByFromProviderStats(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(recorder)
+	c, _ := testutil.NewTestGinContext(http.MethodGet, "/v1/models", nil)
 	c.Set(ctxkey.AvailableModels, "gpt-5.4,claude-sonnet-4-6")
 
 	original := loadGroupModelProvidersFn
@@ -56,9 +55,7 @@ func TestBuildOpenAIModelsForRequestOwnedByFromProviderStats(t *testing.T) {
 }
 
 func TestBuildOpenAIModelsForRequestFailsWhenProviderMissing(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(recorder)
+	c, _ := testutil.NewTestGinContext(http.MethodGet, "/v1/models", nil)
 	c.Set(ctxkey.AvailableModels, "gpt-5.4,claude-sonnet-4-6")
 
 	original := loadGroupModelProvidersFn
@@ -78,9 +75,7 @@ func TestBuildOpenAIModelsForRequestFailsWhenProviderMissing(t *testing.T) {
 }
 
 func TestListModelsOwnedByUsesProviderStats(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(recorder)
+	c, recorder := testutil.NewTestGinContext(http.MethodGet, "/v1/models", nil)
 	c.Set(ctxkey.AvailableModels, "gpt-5.4,claude-sonnet-4-6")
 
 	original := loadGroupModelProvidersFn
@@ -143,9 +138,7 @@ func TestListModelsOwnedByUsesProviderStats(t *testing.T) {
 }
 
 func TestListModelsFailsWhenProviderMissing(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(recorder)
+	c, recorder := testutil.NewTestGinContext(http.MethodGet, "/v1/models", nil)
 	c.Set(ctxkey.AvailableModels, "gpt-5.4")
 
 	original := loadGroupModelProvidersFn
@@ -170,8 +163,6 @@ func TestListModelsFailsWhenProviderMissing(t *testing.T) {
 }
 
 func TestRetrieveModelSharesListOwnedByLogic(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-
 	original := loadGroupModelProvidersFn
 	originalEndpoints := loadGroupModelSupportedEndpointsFn
 	loadGroupModelProvidersFn = func(groupID string, modelNames []string) (map[string]string, error) {
@@ -190,8 +181,7 @@ func TestRetrieveModelSharesListOwnedByLogic(t *testing.T) {
 	})
 
 	{
-		recorder := httptest.NewRecorder()
-		c, _ := gin.CreateTestContext(recorder)
+		c, recorder := testutil.NewTestGinContext(http.MethodGet, "/v1/models", nil)
 		c.Set(ctxkey.AvailableModels, "gpt-5.4")
 		c.Params = gin.Params{{Key: "model", Value: "gpt-5.4"}}
 
@@ -212,8 +202,7 @@ func TestRetrieveModelSharesListOwnedByLogic(t *testing.T) {
 	}
 
 	{
-		recorder := httptest.NewRecorder()
-		c, _ := gin.CreateTestContext(recorder)
+		c, recorder := testutil.NewTestGinContext(http.MethodGet, "/v1/models", nil)
 		c.Set(ctxkey.AvailableModels, "gpt-5.4")
 		c.Params = gin.Params{{Key: "model", Value: "not-exist"}}
 
@@ -232,9 +221,7 @@ func TestRetrieveModelSharesListOwnedByLogic(t *testing.T) {
 }
 
 func TestRetrieveModelFailsWhenProviderMissing(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(recorder)
+	c, recorder := testutil.NewTestGinContext(http.MethodGet, "/v1/models", nil)
 	c.Set(ctxkey.AvailableModels, "gpt-5.4")
 	c.Params = gin.Params{{Key: "model", Value: "gpt-5.4"}}
 
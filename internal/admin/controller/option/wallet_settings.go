@@ -0,0 +1,76 @@
+package option
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/i18n"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+type walletSettingRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// UpdateWalletLoginEnabled godoc
+// @Summary Toggle wallet login (root)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/settings/wallet_login_enabled [put]
+func UpdateWalletLoginEnabled(c *gin.Context) {
+	updateWalletSetting(c, model.SetWalletLoginEnabled)
+}
+
+// UpdateWalletBindEnabled godoc
+// @Summary Toggle wallet binding (root)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/settings/wallet_bind_enabled [put]
+func UpdateWalletBindEnabled(c *gin.Context) {
+	updateWalletSetting(c, model.SetWalletBindEnabled)
+}
+
+// UpdateWalletAutoRegisterEnabled godoc
+// @Summary Toggle wallet auto-registration (root)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/settings/wallet_auto_register_enabled [put]
+func UpdateWalletAutoRegisterEnabled(c *gin.Context) {
+	updateWalletSetting(c, model.SetWalletAutoRegisterEnabled)
+}
+
+func updateWalletSetting(c *gin.Context, set func(bool) error) {
+	var req walletSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Enabled == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": i18n.Translate(c, "invalid_parameter"),
+		})
+		return
+	}
+	if err := set(*req.Enabled); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    gin.H{"enabled": *req.Enabled},
+	})
+}
@@ -337,6 +337,7 @@ func resolveRetrySelectionFailureReason(stats dbmodel.SatisfiedChannelSelectionS
 }
 
 func processChannelRelayError(ctx context.Context, userId string, groupID string, channelId string, channelName string, requestModel string, requestPath string, err model.ErrorWithStatusCode) {
+	monitor.RecordRelayError()
 	msg := relaylogging.NewFields("UPSTREAM_ERR").
 		String("channel_id", channelId).
 		String("channel_name", channelName).
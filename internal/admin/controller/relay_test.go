@@ -2,19 +2,13 @@ package controller
 
 import (
 	"net/http"
-	"net/http/httptest"
 	"testing"
 
-	"github.com/gin-gonic/gin"
+	"github.com/yeying-community/router/internal/testutil"
 )
 
 func TestRelayNotFoundDisablesCaching(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-
-	recorder := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(recorder)
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/group/channel-options", nil)
-	c.Request = req
+	c, recorder := testutil.NewTestGinContext(http.MethodGet, "/api/v1/admin/group/channel-options", nil)
 
 	RelayNotFound(c)
 
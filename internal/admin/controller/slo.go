@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+)
+
+// GetWalletAuthSLO godoc
+// @Summary Get wallet auth error budget (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/slo/wallet-auth [get]
+func GetWalletAuthSLO(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    common.WalletAuthErrorBudget().Snapshot(),
+	})
+}
+
+// GetWalletNonceStats godoc
+// @Summary Get wallet nonce store stats (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/wallet/nonce/stats [get]
+func GetWalletNonceStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    common.WalletNonceStats(),
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yeying-community/router/common/config"
@@ -161,6 +162,9 @@ func validateToken(c *gin.Context, token model.Token) error {
 			return fmt.Errorf("无效的网段：%s", err.Error())
 		}
 	}
+	if strings.TrimSpace(token.ChannelId) != "" && !model.IsAdmin(c.GetString(ctxkey.Id)) {
+		return fmt.Errorf("普通用户不支持指定渠道")
+	}
 	return nil
 }
 
@@ -204,6 +208,7 @@ func AddToken(c *gin.Context) {
 		UnlimitedQuota: token.UnlimitedQuota,
 		Models:         token.Models,
 		Subnet:         token.Subnet,
+		ChannelId:      strings.TrimSpace(token.ChannelId),
 	}
 	err = tokensvc.Create(&cleanToken)
 	if err != nil {
@@ -313,6 +318,7 @@ func UpdateToken(c *gin.Context) {
 		cleanToken.UnlimitedQuota = token.UnlimitedQuota
 		cleanToken.Models = token.Models
 		cleanToken.Subnet = token.Subnet
+		cleanToken.ChannelId = strings.TrimSpace(token.ChannelId)
 	}
 	err = tokensvc.Update(cleanToken)
 	if err != nil {
@@ -0,0 +1,116 @@
+package user
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+	usersvc "github.com/yeying-community/router/internal/admin/service/user"
+)
+
+var userStatusByName = map[string]int{
+	"enabled":          model.UserStatusEnabled,
+	"disabled":         model.UserStatusDisabled,
+	"pending_approval": model.UserStatusPendingApproval,
+}
+
+// GetUsersByStatus godoc
+// @Summary List users by status (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param status query string true "enabled / disabled / pending_approval"
+// @Param page query int false "Page number"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/users [get]
+func GetUsersByStatus(c *gin.Context) {
+	status, ok := userStatusByName[strings.TrimSpace(c.Query("status"))]
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": "status 参数无效",
+		})
+		return
+	}
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	users, total, err := usersvc.GetAllByStatus(status, (page-1)*config.ItemsPerPage, config.ItemsPerPage)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    exposedUsers(users),
+		"meta": gin.H{
+			"total":     total,
+			"page":      page,
+			"page_size": config.ItemsPerPage,
+		},
+	})
+}
+
+// ApproveUser godoc
+// @Summary Approve a pending user (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/users/{id}/approve [post]
+func ApproveUser(c *gin.Context) {
+	user, err := usersvc.ApprovePending(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	logger.SysLogf("user %s approved by admin %s", user.Id, c.GetString(ctxkey.Id))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    exposedUser(user),
+	})
+}
+
+// RejectUser godoc
+// @Summary Reject a pending user (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/users/{id}/reject [post]
+func RejectUser(c *gin.Context) {
+	user, err := usersvc.RejectPending(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	logger.SysLogf("user %s rejected by admin %s", user.Id, c.GetString(ctxkey.Id))
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    exposedUser(user),
+	})
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/yeying-community/router/internal/admin/presenter"
 	logsvc "github.com/yeying-community/router/internal/admin/service/log"
 	usersvc "github.com/yeying-community/router/internal/admin/service/user"
+	"github.com/yeying-community/router/internal/transport/http/middleware"
 	"gorm.io/gorm"
 )
 
@@ -511,6 +512,22 @@ func GetUser(c *gin.Context) {
 	return
 }
 
+// GetUserCacheStats godoc
+// @Summary Get user cache stats (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/cache/users/stats [get]
+func GetUserCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    model.GetUserCacheStats(),
+	})
+}
+
 // GetUserActivePackageSubscription godoc
 // @Summary Get current active package subscription for user (admin)
 // @Tags admin
@@ -1145,21 +1162,27 @@ func GetAffCode(c *gin.Context) {
 // @Failure 401 {object} docs.ErrorResponse
 // @Router /api/v1/public/user/self [get]
 func GetSelf(c *gin.Context) {
-	id := c.GetString(ctxkey.Id)
-	user, err := usersvc.GetByID(id, false)
-	if err != nil {
-		c.JSON(http.StatusOK, gin.H{
-			"success": false,
-			"message": err.Error(),
-		})
-		return
+	user := middleware.CurrentUser(c)
+	if user == nil {
+		var err error
+		user, err = usersvc.GetByID(c.GetString(ctxkey.Id), false)
+		if err != nil {
+			c.JSON(http.StatusOK, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+	self := *user
+	if err := model.HydrateUserWallets(&self); err != nil {
+		logger.SysErrorf("load wallets for user %s failed: %v", self.Id, err)
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "",
-		"data":    exposedUser(user),
+		"data":    exposedUser(&self),
 	})
-	return
 }
 
 func parseGroupReferences(raw string) []string {
@@ -2139,6 +2162,96 @@ func ManageUser(c *gin.Context) {
 	return
 }
 
+type setUserRoleRequest struct {
+	Role int `json:"role"`
+}
+
+// roleChangeAuditEvent is the wallet audit log event of SetUserRole.
+const roleChangeAuditEvent = "role_change"
+
+// SetUserRole godoc
+// @Summary Assign user role (admin)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param body body setUserRoleRequest true "Role payload"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Failure 403 {object} docs.ErrorResponse
+// @Router /api/v1/admin/users/{id}/role [put]
+func SetUserRole(c *gin.Context) {
+	id := strings.TrimSpace(c.Param("id"))
+	var req setUserRoleRequest
+	if id == "" || c.ShouldBindJSON(&req) != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": i18n.Translate(c, "invalid_parameter"),
+		})
+		return
+	}
+	if req.Role != model.RoleCommonUser && req.Role != model.RoleAdminUser {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": "无效的角色",
+		})
+		return
+	}
+	user, err := usersvc.GetByID(id, false)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	if model.IsProtectedRootUser(user) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"message": "无法修改系统级管理员用户的角色",
+		})
+		return
+	}
+	myRole := c.GetInt(ctxkey.Role)
+	if myRole <= req.Role || myRole <= model.EffectiveRole(user) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"message": "无权分配同权限等级或更高权限等级的角色",
+		})
+		return
+	}
+	previousRole := user.Role
+	if previousRole != req.Role {
+		user.Role = req.Role
+		if err := usersvc.Update(user, false); err != nil {
+			c.JSON(http.StatusOK, gin.H{
+				"success": false,
+				"message": err.Error(),
+			})
+			return
+		}
+		operatorID := c.GetString(ctxkey.Id)
+		logger.Infof(c.Request.Context(), "[user-admin] action=role_change operator=%s user=%s before=%d after=%d", operatorID, user.Id, previousRole, req.Role)
+		usersvc.RecordLog(c.Request.Context(), user.Id, model.LogTypeManage, fmt.Sprintf("管理员 %s 将用户角色从 %d 修改为 %d", operatorID, previousRole, req.Role))
+		logger.WriteWalletAudit(c.Request.Context(), logger.WalletAuditEvent{
+			Event:      roleChangeAuditEvent,
+			UserID:     user.Id,
+			IP:         c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+			Success:    true,
+			OperatorID: operatorID,
+			RoleBefore: previousRole,
+			RoleAfter:  req.Role,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    exposedUser(user),
+	})
+}
+
 func EmailBind(c *gin.Context) {
 	email := c.Query("email")
 	code := c.Query("code")
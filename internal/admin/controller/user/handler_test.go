@@ -0,0 +1,125 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+// useRoleTarget serves target for every user lookup of a dry-run database and
+// returns the SQL of the updates sent to it.
+func useRoleTarget(t *testing.T, target model.User) *[]string {
+	t.Helper()
+	prevDB := model.DB
+	t.Cleanup(func() { model.DB = prevDB })
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	_ = db.Callback().Query().After("gorm:query").Register("test:user", func(tx *gorm.DB) {
+		if dest, ok := tx.Statement.Dest.(*model.User); ok {
+			*dest = target
+		}
+	})
+	updates := []string{}
+	_ = db.Callback().Update().After("gorm:update").Register("test:capture", func(tx *gorm.DB) {
+		updates = append(updates, tx.Statement.SQL.String())
+	})
+	model.DB = db
+	// mustLogRepo checks GetAllLogs to tell a bound repository
+	model.BindLogRepository(model.LogRepository{
+		GetAllLogs: func(logType int, startTimestamp int64, endTimestamp int64, modelName string, username string, tokenName string, groupID string, startIdx int, num int, channel string) ([]*model.Log, error) {
+			return nil, nil
+		},
+		RecordLog: func(ctx context.Context, userId string, logType int, content string) {},
+	})
+	return &updates
+}
+
+func putUserRole(t *testing.T, callerRole int, targetID string, body any) (int, string) {
+	t.Helper()
+	c, recorder := testutil.NewTestGinContext(http.MethodPut, "/api/v1/admin/users/"+targetID+"/role", body)
+	c.AddParam("id", targetID)
+	c.Set(ctxkey.Id, "role-operator")
+	c.Set(ctxkey.Role, callerRole)
+	SetUserRole(c)
+	return recorder.Code, recorder.Body.String()
+}
+
+func TestSetUserRole_RequiresStrictlyHigherRole(t *testing.T) {
+	target := model.User{Id: "role-target", Username: "target", Role: model.RoleCommonUser, Status: model.UserStatusEnabled}
+	updates := useRoleTarget(t, target)
+
+	if code, body := putUserRole(t, model.RoleAdminUser, target.Id, map[string]int{"role": model.RoleAdminUser}); code != http.StatusForbidden {
+		t.Fatalf("admin assigning admin: status = %d body = %s, want 403", code, body)
+	}
+	useRoleTarget(t, model.User{Id: "role-admin", Username: "admin", Role: model.RoleAdminUser, Status: model.UserStatusEnabled})
+	if code, body := putUserRole(t, model.RoleAdminUser, "role-admin", map[string]int{"role": model.RoleCommonUser}); code != http.StatusForbidden {
+		t.Fatalf("admin demoting admin: status = %d body = %s, want 403", code, body)
+	}
+	if len(*updates) != 0 {
+		t.Fatalf("rejected role changes issued updates: %q", *updates)
+	}
+}
+
+func TestSetUserRole_RejectsInvalidRole(t *testing.T) {
+	target := model.User{Id: "role-target", Username: "target", Role: model.RoleCommonUser, Status: model.UserStatusEnabled}
+	updates := useRoleTarget(t, target)
+
+	for _, body := range []any{
+		map[string]int{"role": model.RoleRootUser},
+		map[string]int{"role": 5},
+		map[string]int{"role": model.RoleGuestUser},
+		"not json",
+	} {
+		code, resp := putUserRole(t, model.RoleRootUser, target.Id, body)
+		if code != http.StatusOK || !strings.Contains(resp, `"success":false`) {
+			t.Fatalf("body %v: status = %d response = %s, want a rejection", body, code, resp)
+		}
+	}
+	if len(*updates) != 0 {
+		t.Fatalf("invalid roles issued updates: %q", *updates)
+	}
+}
+
+func TestSetUserRole_WritesRoleChangeAudit(t *testing.T) {
+	dir := t.TempDir()
+	prevDir := logger.LogDir
+	logger.LogDir = dir
+	defer func() { logger.LogDir = prevDir }()
+
+	target := model.User{Id: "role-audit-target", Username: "target", Role: model.RoleCommonUser, Status: model.UserStatusEnabled}
+	updates := useRoleTarget(t, target)
+	start := time.Now().Add(-time.Second)
+
+	code, body := putUserRole(t, model.RoleRootUser, target.Id, map[string]int{"role": model.RoleAdminUser})
+	if code != http.StatusOK || !strings.Contains(body, `"success":true`) || len(*updates) != 1 {
+		t.Fatalf("status = %d body = %s updates = %q, want the role saved", code, body, *updates)
+	}
+
+	var events []logger.WalletAuditEvent
+	if err := logger.ReadWalletAuditEvents(start, time.Time{}, func(event logger.WalletAuditEvent) bool {
+		if event.Event == roleChangeAuditEvent && event.UserID == target.Id {
+			events = append(events, event)
+		}
+		return true
+	}); err != nil {
+		t.Fatalf("ReadWalletAuditEvents error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("role_change events = %+v, want one", events)
+	}
+	if event := events[0]; event.OperatorID != "role-operator" || event.RoleBefore != model.RoleCommonUser || event.RoleAfter != model.RoleAdminUser || !event.Success {
+		t.Fatalf("role_change event = %+v, want operator role-operator and role 1 -> 10", event)
+	}
+}
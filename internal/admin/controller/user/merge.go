@@ -0,0 +1,60 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/i18n"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+type mergeUsersRequest struct {
+	SourceId string `json:"source_id"`
+	TargetId string `json:"target_id"`
+}
+
+// MergeUsers godoc
+// @Summary Merge a wallet-registered user into another user (admin)
+// @Description Moves the wallets, tokens, API keys, balance and quota of source_id to target_id, then deletes source_id.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body mergeUsersRequest true "Source and target user IDs"
+// @Success 200 {object} docs.StandardResponse
+// @Failure 401 {object} docs.ErrorResponse
+// @Router /api/v1/admin/users/merge [post]
+func MergeUsers(c *gin.Context) {
+	var req mergeUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": i18n.Translate(c, "invalid_parameter"),
+		})
+		return
+	}
+	if err := model.MergeWalletUser(req.SourceId, req.TargetId); err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	logger.SysLogf("user %s merged into %s by admin %s", req.SourceId, req.TargetId, c.GetString(ctxkey.Id))
+	user, err := model.GetUserById(req.TargetId, false)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "",
+		"data":    exposedUser(user),
+	})
+}
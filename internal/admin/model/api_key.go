@@ -0,0 +1,74 @@
+package model
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/random"
+)
+
+const ApiKeysTableName = "api_keys"
+
+var ErrApiKeyExists = errors.New("该公钥已注册")
+
+// ApiKey is an Ed25519 public key registered by a user for signed
+// machine-to-machine requests. Id is the opaque key ID callers put in the
+// Authorization header.
+type ApiKey struct {
+	Id         string `json:"id" gorm:"type:char(36);primaryKey"`
+	UserId     string `json:"user_id" gorm:"type:char(36);not null;index"`
+	PublicKey  string `json:"public_key" gorm:"type:varchar(64);not null;uniqueIndex"`
+	LastUsedAt int64  `json:"last_used_at" gorm:"bigint;not null;default:0"`
+	CreatedAt  int64  `json:"created_at" gorm:"bigint"`
+}
+
+func (ApiKey) TableName() string {
+	return ApiKeysTableName
+}
+
+// Ed25519PublicKey decodes the stored public key.
+func (k *ApiKey) Ed25519PublicKey() (ed25519.PublicKey, error) {
+	return common.ParseApiKeyPublicKey(k.PublicKey)
+}
+
+// CreateApiKey registers publicKey for the user. The key is stored in
+// canonical base64 so the same key cannot be registered twice.
+func CreateApiKey(userId, publicKey string) (*ApiKey, error) {
+	pub, err := common.ParseApiKeyPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	key := ApiKey{
+		Id:        random.GetUUID(),
+		UserId:    strings.TrimSpace(userId),
+		PublicKey: common.EncodeApiKeyPublicKey(pub),
+		CreatedAt: helper.GetTimestamp(),
+	}
+	var count int64
+	if err := DB.Model(&ApiKey{}).Where("public_key = ?", key.PublicKey).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrApiKeyExists
+	}
+	if err := DB.Create(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func GetApiKeyById(id string) (*ApiKey, error) {
+	var key ApiKey
+	if err := DB.First(&key, "id = ?", strings.TrimSpace(id)).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// TouchApiKey records the time of the last verified request.
+func TouchApiKey(id string) error {
+	return DB.Model(&ApiKey{}).Where("id = ?", id).Update("last_used_at", helper.GetTimestamp()).Error
+}
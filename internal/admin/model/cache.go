@@ -490,6 +490,46 @@ func RefreshUserGroupCaches(userIDs ...string) {
 	}
 }
 
+// RefreshUserCaches drops every cached view of the users: the in-memory user
+// and wallet entries and the Redis quota, status and group keys.
+func RefreshUserCaches(userIDs ...string) {
+	userIDs = normalizeTrimmedValuesPreserveOrder(userIDs)
+	for _, userID := range userIDs {
+		if userID != "" {
+			InvalidateUserCache(userID)
+		}
+	}
+	if !common.RedisEnabled || common.RDB == nil {
+		return
+	}
+	for _, userID := range userIDs {
+		if userID == "" {
+			continue
+		}
+		for _, prefix := range []string{"user_quota", "user_enabled", "user_effective_group", "user_group"} {
+			if err := common.RedisDel(fmt.Sprintf("%s:%s", prefix, userID)); err != nil {
+				logger.SysError("Redis delete user cache error: " + err.Error())
+			}
+		}
+	}
+}
+
+// RefreshTokenCaches drops the cached tokens with the given keys, e.g. after
+// they moved to another user.
+func RefreshTokenCaches(keys ...string) {
+	if !common.RedisEnabled || common.RDB == nil {
+		return
+	}
+	for _, key := range normalizeTrimmedValuesPreserveOrder(keys) {
+		if key == "" {
+			continue
+		}
+		if err := common.RedisDel(fmt.Sprintf("token:%s", key)); err != nil {
+			logger.SysError("Redis delete token error: " + err.Error())
+		}
+	}
+}
+
 func SyncChannelCache(frequency int) {
 	for {
 		time.Sleep(time.Duration(frequency) * time.Second)
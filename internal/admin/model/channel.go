@@ -17,6 +17,10 @@ const (
 	ChannelStatusManuallyDisabled = 2 // also don't use 0
 	ChannelStatusAutoDisabled     = 3
 	ChannelStatusCreating         = 4
+	ChannelStatusDeleted          = 5 // soft-deleted, purged permanently once hard_delete_at passes
+
+	// ChannelHardDeleteRetentionSeconds is how long a soft-deleted channel (and its logs) is kept.
+	ChannelHardDeleteRetentionSeconds = 90 * 24 * 60 * 60
 
 	ChannelIdentifierMaxLength = 64
 )
@@ -48,10 +52,29 @@ type Channel struct {
 	Config               string         `json:"config"`
 	SystemPrompt         *string        `json:"system_prompt" gorm:"type:text"`
 	TestModel            string         `json:"test_model" gorm:"type:varchar(255);default:''"`
+	HardDeleteAt         int64          `json:"hard_delete_at" gorm:"bigint;default:0;index"`
+	TimeoutSeconds       int            `json:"timeout_seconds" gorm:"default:0"` // 0 means relay.timeout_seconds
 	KeySet               bool           `json:"key_set" gorm:"-"`
 	ModelsProvided       bool           `json:"-" gorm:"-"`
 	ModelConfigsProvided bool           `json:"-" gorm:"-"`
 	NameProvided         bool           `json:"-" gorm:"-"`
+	TimeoutProvided      bool           `json:"-" gorm:"-"`
+}
+
+// ChannelBulkUpdate describes one row of an atomic multi-channel update.
+// Nil fields are left untouched; a non-zero UpdatedAt enables optimistic locking.
+type ChannelBulkUpdate struct {
+	Id        string `json:"id"`
+	Weight    *uint  `json:"weight,omitempty"`
+	Priority  *int64 `json:"priority,omitempty"`
+	Status    *int   `json:"status,omitempty"`
+	UpdatedAt int64  `json:"updated_at,omitempty"`
+}
+
+type ChannelBulkUpdateResult struct {
+	Id            string   `json:"id"`
+	UpdatedFields []string `json:"updated_fields"`
+	UpdatedAt     int64    `json:"updated_at"`
 }
 
 type ChannelConfig struct {
@@ -130,6 +153,16 @@ func (channel *Channel) ValidateIdentifier() error {
 	return ValidateChannelIdentifier(channel.Name)
 }
 
+func (channel *Channel) ValidateTimeout() error {
+	if channel == nil {
+		return fmt.Errorf("渠道不能为空")
+	}
+	if channel.TimeoutSeconds < 0 {
+		return fmt.Errorf("渠道超时时间不能小于 0")
+	}
+	return nil
+}
+
 func (channel *Channel) DisplayName() string {
 	if channel == nil {
 		return ""
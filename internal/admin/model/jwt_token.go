@@ -0,0 +1,75 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yeying-community/router/common"
+)
+
+const JWTTokensTableName = "jwt_tokens"
+
+// JWTToken records an issued wallet JWT by its jti so it can be revoked on
+// logout. RevokedAt is nil while the token is live.
+type JWTToken struct {
+	Jti       string `json:"jti" gorm:"type:char(36);primaryKey"`
+	UserId    string `json:"user_id" gorm:"type:char(36);not null;default:'';index"`
+	IssuedAt  int64  `json:"issued_at" gorm:"bigint;not null;default:0"`
+	ExpiresAt int64  `json:"expires_at" gorm:"bigint;not null;default:0;index"`
+	RevokedAt *int64 `json:"revoked_at" gorm:"bigint"`
+}
+
+func (JWTToken) TableName() string {
+	return JWTTokensTableName
+}
+
+// jwtTokenStore backs common.WalletTokenStore with the jwt_tokens table.
+type jwtTokenStore struct{}
+
+// UseJWTTokenStore makes wallet JWT issuance and revocation go through the
+// jwt_tokens table. It must run after InitDB.
+func UseJWTTokenStore() {
+	common.SetWalletTokenStore(jwtTokenStore{})
+}
+
+func (jwtTokenStore) RecordWalletToken(jti, userID string, issuedAt, expiresAt time.Time) error {
+	record := JWTToken{
+		Jti:       jti,
+		UserId:    userID,
+		IssuedAt:  issuedAt.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error
+}
+
+// RevokeWalletToken keeps the first revocation time when a token is revoked
+// twice. Tokens issued before the table existed have no row; the revocation
+// store still covers them.
+func (jwtTokenStore) RevokeWalletToken(jti string, revokedAt time.Time) error {
+	return DB.Model(&JWTToken{}).
+		Where("jti = ? AND revoked_at IS NULL", strings.TrimSpace(jti)).
+		Update("revoked_at", revokedAt.Unix()).Error
+}
+
+func (jwtTokenStore) IsWalletTokenRevoked(jti string) (bool, error) {
+	var record JWTToken
+	err := DB.Select("jti", "revoked_at").Where("jti = ?", strings.TrimSpace(jti)).Take(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return record.RevokedAt != nil, nil
+}
+
+// PruneExpiredJWTTokens deletes rows of tokens that expired before now; an
+// expired token is rejected on its own, so its row no longer matters.
+func PruneExpiredJWTTokens(now int64) (int64, error) {
+	result := DB.Where("expires_at > 0 AND expires_at < ?", now).Delete(&JWTToken{})
+	return result.RowsAffected, result.Error
+}
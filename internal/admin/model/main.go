@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"strings"
@@ -104,7 +105,10 @@ func migrateDB() error {
 	defer func() {
 		_ = closeDB(migrationDB)
 	}()
-	return runMainVersionedMigrations(migrationDB)
+	if err := runMainVersionedMigrations(migrationDB); err != nil {
+		return err
+	}
+	return ensureUserDisplayNameUniqueIndexWithDB(migrationDB)
 }
 
 func InitLogDB() {
@@ -174,6 +178,18 @@ func closeDB(db *gorm.DB) error {
 	return err
 }
 
+// PingDB checks that the main database answers.
+func PingDB(ctx context.Context) error {
+	if DB == nil {
+		return errors.New("database not initialized")
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 func CloseDB() error {
 	if LOG_DB != DB {
 		err := closeDB(LOG_DB)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/common/helper"
 	"github.com/yeying-community/router/common/logger"
 	"gorm.io/gorm"
@@ -845,10 +846,113 @@ func runMainVersionedMigrations(db *gorm.DB) error {
 				return nil
 			},
 		},
+		{
+			Version:     "202610161030_channel_soft_delete",
+			Description: "add channel hard_delete_at column and token channel binding for channel soft delete",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&Channel{}, &Token{})
+			},
+		},
+		{
+			Version:     "202610161400_user_last_login_at",
+			Description: "add last_login_at column to users",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&User{})
+			},
+		},
+		{
+			Version:     "202610161500_channel_timeout_seconds",
+			Description: "add timeout_seconds column to channels",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&Channel{})
+			},
+		},
+		{
+			Version:     "202610161600_user_wallets",
+			Description: "create user_wallets and backfill from users.wallet_address",
+			Up: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&UserWallet{}); err != nil {
+					return err
+				}
+				return tx.Exec(`
+					INSERT INTO user_wallets (address, user_id, chain_id, created_at)
+					SELECT LOWER(wallet_address), id, '', created_at
+					FROM users
+					WHERE wallet_address IS NOT NULL AND wallet_address <> ''
+					ON CONFLICT DO NOTHING
+				`).Error
+			},
+		},
+		{
+			Version:     "202610161700_refresh_tokens",
+			Description: "create refresh_tokens for rotating wallet refresh tokens",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&RefreshToken{})
+			},
+		},
+		{
+			Version:     "202610161800_user_wallet_type",
+			Description: "add wallet_type column to user_wallets for Solana wallets",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&UserWallet{})
+			},
+		},
+		{
+			Version:     "202610161900_api_keys",
+			Description: "create api_keys for Ed25519 signed API requests",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&ApiKey{})
+			},
+		},
+		{
+			Version:     "202610162000_wallet_root_addresses",
+			Description: "create wallet_root_addresses seeded from bootstrap.root_wallet_address",
+			Up: func(tx *gorm.DB) error {
+				return seedWalletRootAddressesWithDB(tx)
+			},
+		},
+		{
+			Version:     "202610162100_user_webauthn_credentials",
+			Description: "create user_webauthn_credentials for passkey login",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&WebAuthnCredential{})
+			},
+		},
+		{
+			Version:     "202610162200_wallet_login_history",
+			Description: "create wallet_login_history for per-user wallet login events",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&WalletLoginHistory{})
+			},
+		},
+		{
+			Version:     "202610162300_jwt_tokens",
+			Description: "create jwt_tokens to revoke wallet JWTs by jti",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&JWTToken{})
+			},
+		},
 	}
 	return runVersionedMigrations(db, migrationScopeMain, migrations)
 }
 
+// ensureUserDisplayNameUniqueIndexWithDB creates the display_name unique index when
+// config.WalletUniqueDisplayName is enabled. It is toggled by config rather than
+// versioned, so it runs on every startup and is idempotent.
+func ensureUserDisplayNameUniqueIndexWithDB(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("database handle is nil")
+	}
+	if !config.WalletUniqueDisplayName {
+		return nil
+	}
+	return db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS uniq_users_display_name
+		ON users (display_name)
+		WHERE display_name <> ''
+	`).Error
+}
+
 func backfillOpenAITextProviderModelEndpointCandidatesWithDB(db *gorm.DB) error {
 	if db == nil {
 		return fmt.Errorf("database handle is nil")
@@ -124,6 +124,14 @@ func UpdateOptionMap(key string, value string) (err error) {
 		delete(config.OptionMap, key)
 		return nil
 	}
+	switch key {
+	case WalletLoginEnabledSettingKey:
+		walletLoginEnabledSetting.invalidate()
+	case WalletBindEnabledSettingKey:
+		walletBindEnabledSetting.invalidate()
+	case WalletAutoRegisterEnabledSettingKey:
+		walletAutoRegisterEnabledSetting.invalidate()
+	}
 	config.OptionMap[key] = value
 	if strings.HasSuffix(key, "Enabled") {
 		boolValue := value == "true"
@@ -2,6 +2,7 @@ package model
 
 type OptionRepository struct {
 	AllOption    func() ([]*Option, error)
+	GetOption    func(key string) (*Option, error)
 	UpdateOption func(key string, value string) error
 }
 
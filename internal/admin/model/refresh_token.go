@@ -0,0 +1,138 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/common/random"
+)
+
+const RefreshTokensTableName = "refresh_tokens"
+
+var ErrRefreshTokenInvalid = errors.New("refresh token 无效或已过期")
+
+// RefreshToken is a long-lived opaque token exchanged for a new access token.
+// Only its SHA-256 hash is stored, and each token can be used once.
+type RefreshToken struct {
+	Id            string `json:"id" gorm:"type:char(36);primaryKey"`
+	UserId        string `json:"user_id" gorm:"type:char(36);not null;index"`
+	Hash          string `json:"-" gorm:"type:char(64);not null;uniqueIndex"`
+	WalletAddress string `json:"wallet_address" gorm:"type:varchar(64);not null;default:''"`
+	ExpiresAt     int64  `json:"expires_at" gorm:"bigint;not null;index"`
+	Revoked       bool   `json:"revoked" gorm:"not null;default:false"`
+	CreatedAt     int64  `json:"created_at" gorm:"bigint"`
+}
+
+func (RefreshToken) TableName() string {
+	return RefreshTokensTableName
+}
+
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+func newRefreshTokenValue() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+func refreshTokenTTL() time.Duration {
+	days := config.WalletRefreshTokenExpireDays
+	if days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// IssueRefreshToken creates a refresh token for the user; the plain value is
+// returned once and never stored.
+func IssueRefreshToken(userId, walletAddress string) (string, time.Time, error) {
+	return issueRefreshTokenWithDB(DB, userId, walletAddress)
+}
+
+func issueRefreshTokenWithDB(tx *gorm.DB, userId, walletAddress string) (string, time.Time, error) {
+	token, err := newRefreshTokenValue()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(refreshTokenTTL())
+	record := RefreshToken{
+		Id:            random.GetUUID(),
+		UserId:        userId,
+		Hash:          HashRefreshToken(token),
+		WalletAddress: NormalizeWalletAddress(walletAddress),
+		ExpiresAt:     expiresAt.Unix(),
+		CreatedAt:     helper.GetTimestamp(),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// RotateRefreshToken consumes token and issues its replacement. Presenting a
+// token that was already rotated means it leaked, so every refresh token of
+// that user is revoked.
+func RotateRefreshToken(token string) (*RefreshToken, string, time.Time, error) {
+	hash := HashRefreshToken(token)
+	var (
+		old       RefreshToken
+		newToken  string
+		expiresAt time.Time
+		reused    bool
+	)
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&old, "hash = ?", hash).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+		if old.Revoked {
+			reused = true
+			return ErrRefreshTokenInvalid
+		}
+		if old.ExpiresAt <= helper.GetTimestamp() {
+			return ErrRefreshTokenInvalid
+		}
+		// the revoked = false guard makes concurrent rotations of one token race
+		// for a single winner
+		result := tx.Model(&RefreshToken{}).Where("id = ? AND revoked = ?", old.Id, false).Update("revoked", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return ErrRefreshTokenInvalid
+		}
+		var err error
+		newToken, expiresAt, err = issueRefreshTokenWithDB(tx, old.UserId, old.WalletAddress)
+		return err
+	})
+	if reused {
+		logger.SysWarnf("refresh token reuse detected user=%s, revoking all refresh tokens", old.UserId)
+		if revokeErr := RevokeUserRefreshTokens(old.UserId); revokeErr != nil {
+			logger.SysErrorf("revoke refresh tokens for user %s failed: %v", old.UserId, revokeErr)
+		}
+	}
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	return &old, newToken, expiresAt, nil
+}
+
+func RevokeUserRefreshTokens(userId string) error {
+	return DB.Model(&RefreshToken{}).Where("user_id = ? AND revoked = ?", strings.TrimSpace(userId), false).Update("revoked", true).Error
+}
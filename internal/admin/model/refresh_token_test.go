@@ -0,0 +1,24 @@
+package model
+
+import "testing"
+
+func TestRefreshTokenValueAndHash(t *testing.T) {
+	first, err := newRefreshTokenValue()
+	if err != nil {
+		t.Fatalf("newRefreshTokenValue error: %v", err)
+	}
+	second, _ := newRefreshTokenValue()
+	if len(first) != 64 || first == second {
+		t.Fatalf("refresh tokens = %q, %q; want distinct 64-char values", first, second)
+	}
+	hash := HashRefreshToken(first)
+	if len(hash) != 64 || hash == first {
+		t.Fatalf("HashRefreshToken(%q) = %q", first, hash)
+	}
+	if HashRefreshToken(" "+first+"\n") != hash {
+		t.Fatalf("HashRefreshToken should ignore surrounding whitespace")
+	}
+	if HashRefreshToken(second) == hash {
+		t.Fatalf("different tokens hashed to the same value")
+	}
+}
@@ -35,6 +35,7 @@ type Token struct {
 	UsedQuota      int64   `json:"used_quota" gorm:"bigint;default:0"`
 	Models         *string `json:"models" gorm:"type:text"`
 	Subnet         *string `json:"subnet" gorm:"default:''"`
+	ChannelId      string  `json:"channel_id" gorm:"type:varchar(64);index;default:''"`
 }
 
 func (Token) TableName() string {
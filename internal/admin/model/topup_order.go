@@ -1150,6 +1150,7 @@ func FulfillTopupOrderWithDB(db *gorm.DB, orderID string) (TopupOrder, bool, err
 	}
 	result := TopupOrder{}
 	fulfilledNow := false
+	quotaCredited := false
 	previousStatus := ""
 	err := db.Transaction(func(tx *gorm.DB) error {
 		order := TopupOrder{}
@@ -1194,6 +1195,7 @@ func FulfillTopupOrderWithDB(db *gorm.DB, orderID string) (TopupOrder, bool, err
 					Update("quota", gorm.Expr("quota + ?", order.Quota)).Error; err != nil {
 					return err
 				}
+				quotaCredited = true
 			}
 			if lot.ExpiresAt > 0 {
 				order.CreditExpiresAt = lot.ExpiresAt
@@ -1239,6 +1241,9 @@ func FulfillTopupOrderWithDB(db *gorm.DB, orderID string) (TopupOrder, bool, err
 	if err != nil {
 		return TopupOrder{}, false, err
 	}
+	if quotaCredited {
+		InvalidateUserCache(result.UserID)
+	}
 	if fulfilledNow {
 		logTopupOrderLifecycle("fulfilled", result, previousStatus, result.StatusMessage)
 	}
@@ -1264,6 +1269,7 @@ func GrantTopupPlanToUserWithDB(db *gorm.DB, userID string, username string, pla
 	}
 	now := helper.GetTimestamp()
 	result := TopupOrder{}
+	quotaCredited := false
 	err = db.Transaction(func(tx *gorm.DB) error {
 		user := User{}
 		if err := tx.Select("id").First(&user, "id = ?", normalizedUserID).Error; err != nil {
@@ -1329,6 +1335,7 @@ func GrantTopupPlanToUserWithDB(db *gorm.DB, userID string, username string, pla
 				Update("quota", gorm.Expr("quota + ?", order.Quota)).Error; err != nil {
 				return err
 			}
+			quotaCredited = true
 		}
 		if lot.ExpiresAt > 0 && lot.ExpiresAt != order.CreditExpiresAt {
 			order.CreditExpiresAt = lot.ExpiresAt
@@ -1344,6 +1351,9 @@ func GrantTopupPlanToUserWithDB(db *gorm.DB, userID string, username string, pla
 	if err != nil {
 		return TopupOrder{}, err
 	}
+	if quotaCredited {
+		InvalidateUserCache(normalizedUserID)
+	}
 	logTopupOrderLifecycle("granted", result, "", result.StatusMessage)
 	return result, nil
 }
@@ -1,9 +1,14 @@
 package model
 
 import (
+	"context"
+	"database/sql"
 	"net/url"
 	"testing"
 
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
 	"github.com/yeying-community/router/common/config"
 )
 
@@ -220,3 +225,74 @@ func TestTopupOrderSigningStringHelpers(t *testing.T) {
 		t.Fatalf("unexpected signing string: got %q want %q", got, want)
 	}
 }
+
+// dryRunConnPool lets a dry-run database open transactions; no statement
+// reaches it.
+type dryRunConnPool struct{}
+
+func (dryRunConnPool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, sql.ErrConnDone
+}
+
+func (dryRunConnPool) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, sql.ErrConnDone
+}
+
+func (dryRunConnPool) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, sql.ErrConnDone
+}
+
+func (dryRunConnPool) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+func (pool dryRunConnPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return &dryRunTx{pool}, nil
+}
+
+type dryRunTx struct{ dryRunConnPool }
+
+func (*dryRunTx) Commit() error   { return nil }
+func (*dryRunTx) Rollback() error { return nil }
+
+func TestFulfillTopupOrderWithDB_InvalidatesCachedQuota(t *testing.T) {
+	order := TopupOrder{
+		Id:           "topup-cache-order",
+		UserID:       "topup-cache-user",
+		Status:       TopupOrderStatusPaid,
+		BusinessType: TopupOrderBusinessBalance,
+		Quota:        500,
+		PaidAt:       1,
+	}
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true, ConnPool: dryRunConnPool{}})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	_ = db.Callback().Query().After("gorm:query").Register("test:rows", func(tx *gorm.DB) {
+		switch dest := tx.Statement.Dest.(type) {
+		case *TopupOrder:
+			*dest = order
+		case *UserBalanceLot:
+			*dest = UserBalanceLot{Id: "topup-cache-lot", UserID: order.UserID, SourceType: UserBalanceLotSourceTopup, SourceID: order.Id, TotalYYC: order.Quota, RemainingYYC: order.Quota}
+		}
+	})
+	// the balance lot insert reports a new row, so the quota is credited
+	_ = db.Callback().Create().After("gorm:create").Register("test:created", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	})
+	var credited bool
+	_ = db.Callback().Update().After("gorm:update").Register("test:quota", func(tx *gorm.DB) {
+		if tx.Statement.Table == "users" {
+			credited = true
+		}
+	})
+
+	CacheSetUser(&User{Id: order.UserID, Quota: 100})
+	defer InvalidateUserCache(order.UserID)
+	if _, fulfilled, err := FulfillTopupOrderWithDB(db, order.Id); err != nil || !fulfilled || !credited {
+		t.Fatalf("FulfillTopupOrderWithDB fulfilled = %t credited = %t err = %v", fulfilled, credited, err)
+	}
+	if cached, ok := CacheGetUserById(order.UserID); ok {
+		t.Fatalf("cached quota = %d after the order was credited, want the entry dropped", cached.Quota)
+	}
+}
@@ -3,8 +3,6 @@ package model
 import (
 	"context"
 	"strings"
-
-	"github.com/yeying-community/router/common/config"
 )
 
 const (
@@ -18,6 +16,8 @@ const (
 	UserStatusEnabled  = 1 // don't use 0, 0 is the default value!
 	UserStatusDisabled = 2 // also don't use 0
 	UserStatusDeleted  = 3
+	// UserStatusPendingApproval marks wallet auto-registered users awaiting admin approval.
+	UserStatusPendingApproval = 4
 )
 
 // User if you add sensitive fields, don't forget to clean them in setupLogin function.
@@ -51,24 +51,28 @@ type User struct {
 	HasPassword                bool   `json:"has_password" gorm:"column:has_password;default:false"`
 	CreatedAt                  int64  `json:"created_at" gorm:"bigint;index"`
 	UpdatedAt                  int64  `json:"updated_at" gorm:"bigint;index"`
+	LastLoginAt                int64  `json:"last_login_at" gorm:"bigint;default:0"`
 	CanManageUsers             bool   `json:"can_manage_users" gorm:"-"`
+	// WalletType is the type of WalletAddress when the user is created, stored
+	// on its user_wallets row; empty means Ethereum.
+	WalletType string `json:"-" gorm:"-"`
+	// Wallets lists every bound wallet; WalletAddress is the primary one.
+	Wallets []UserWallet `json:"wallets,omitempty" gorm:"-"`
 }
 
+// NormalizeWalletAddress lower-cases hex (Ethereum) addresses. Base58 Solana
+// addresses are case-sensitive and only trimmed.
 func NormalizeWalletAddress(address string) string {
-	return strings.ToLower(strings.TrimSpace(address))
+	address = strings.TrimSpace(address)
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
 }
 
 func IsRootWalletAddress(address string) bool {
 	normalized := NormalizeWalletAddress(address)
-	if normalized == "" {
-		return false
-	}
-	for _, configured := range config.RootWalletAddresses {
-		if normalized == NormalizeWalletAddress(configured) {
-			return true
-		}
-	}
-	return false
+	return normalized != "" && rootWalletAddressSet()[normalized]
 }
 
 func EffectiveRole(user *User) int {
@@ -172,8 +176,22 @@ func (user *User) FillUserByUsername() error {
 	return mustUserRepo().FillUserByUsername(user)
 }
 
+// FillUserByWalletAddress serves repeated lookups from a short-lived cache
+// keyed by the normalized address; only found users are cached.
 func (user *User) FillUserByWalletAddress() error {
-	return mustUserRepo().FillUserByWalletAddress(user)
+	address := ""
+	if user.WalletAddress != nil {
+		address = *user.WalletAddress
+	}
+	if cached, ok := CacheGetUserByWalletAddress(address); ok {
+		*user = cached
+		return nil
+	}
+	if err := mustUserRepo().FillUserByWalletAddress(user); err != nil {
+		return err
+	}
+	CacheSetUserByWalletAddress(address, user)
+	return nil
 }
 
 func IsEmailAlreadyTaken(email string) bool {
@@ -204,6 +222,10 @@ func IsUsernameAlreadyTaken(username string) bool {
 	return mustUserRepo().IsUsernameAlreadyTaken(username)
 }
 
+func IsDisplayNameAlreadyTaken(name string) bool {
+	return mustUserRepo().IsDisplayNameAlreadyTaken(name)
+}
+
 func ResetUserPasswordByEmail(email string, password string) error {
 	return mustUserRepo().ResetUserPasswordByEmail(email, password)
 }
@@ -275,3 +297,7 @@ func updateUserRequestCount(id string, count int) {
 func GetUsernameById(id string) string {
 	return mustUserRepo().GetUsernameById(id)
 }
+
+func UpdateUserLastLoginAt(id string, timestamp int64) error {
+	return mustUserRepo().UpdateUserLastLoginAt(id, timestamp)
+}
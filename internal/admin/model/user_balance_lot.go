@@ -316,6 +316,9 @@ func ExpireUserBalanceLotsWithDB(db *gorm.DB, userID string, now int64) (int64,
 	}); err != nil {
 		return 0, err
 	}
+	if expiredTotal > 0 {
+		InvalidateUserCache(userID)
+	}
 	return expiredTotal, nil
 }
 
@@ -392,6 +395,7 @@ func ConsumeUserBalanceLotsWithDB(db *gorm.DB, userID string, quota int64, now i
 	if err != nil {
 		return 0, err
 	}
+	InvalidateUserCache(normalizedUserID)
 	return consumed, nil
 }
 
@@ -0,0 +1,215 @@
+package model
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+// UserCache is a small LRU cache for user rows, keyed by id unless stored with SetKey.
+// Entries expire after ttl; any write through the user repository must invalidate the entry.
+type UserCache struct {
+	mu        sync.Mutex
+	capacity  int
+	ttl       time.Duration
+	ll        *list.List
+	items     map[string]*list.Element
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+type userCacheEntry struct {
+	key      string
+	user     User
+	expireAt time.Time
+}
+
+type UserCacheStats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+	Size      int   `json:"size"`
+	Capacity  int   `json:"capacity"`
+}
+
+func NewUserCache(capacity int, ttl time.Duration) *UserCache {
+	return &UserCache{
+		capacity: capacity,
+		ttl:      ttl,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (cache *UserCache) enabled() bool {
+	return cache != nil && cache.capacity > 0 && cache.ttl > 0
+}
+
+// Get returns a copy of the cached user if present and not expired.
+func (cache *UserCache) Get(id string) (User, bool) {
+	if !cache.enabled() {
+		return User{}, false
+	}
+	key := strings.TrimSpace(id)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	element, ok := cache.items[key]
+	if !ok {
+		cache.misses++
+		return User{}, false
+	}
+	entry := element.Value.(*userCacheEntry)
+	if time.Now().After(entry.expireAt) {
+		cache.ll.Remove(element)
+		delete(cache.items, key)
+		cache.misses++
+		return User{}, false
+	}
+	cache.ll.MoveToFront(element)
+	cache.hits++
+	return entry.user, true
+}
+
+func (cache *UserCache) Set(user User) {
+	cache.SetKey(user.Id, user)
+}
+
+// SetKey stores user under key instead of its id.
+func (cache *UserCache) SetKey(key string, user User) {
+	if !cache.enabled() {
+		return
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	expireAt := time.Now().Add(cache.ttl)
+	if element, ok := cache.items[key]; ok {
+		element.Value = &userCacheEntry{key: key, user: user, expireAt: expireAt}
+		cache.ll.MoveToFront(element)
+		return
+	}
+	cache.items[key] = cache.ll.PushFront(&userCacheEntry{key: key, user: user, expireAt: expireAt})
+	for cache.ll.Len() > cache.capacity {
+		oldest := cache.ll.Back()
+		if oldest == nil {
+			break
+		}
+		cache.ll.Remove(oldest)
+		delete(cache.items, oldest.Value.(*userCacheEntry).key)
+		cache.evictions++
+	}
+}
+
+func (cache *UserCache) Invalidate(id string) {
+	if cache == nil {
+		return
+	}
+	key := strings.TrimSpace(id)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if element, ok := cache.items[key]; ok {
+		cache.ll.Remove(element)
+		delete(cache.items, key)
+	}
+}
+
+// InvalidateUser drops every entry holding the user with the given id, whatever
+// key it was stored under.
+func (cache *UserCache) InvalidateUser(id string) {
+	if cache == nil {
+		return
+	}
+	id = strings.TrimSpace(id)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for key, element := range cache.items {
+		if element.Value.(*userCacheEntry).user.Id == id {
+			cache.ll.Remove(element)
+			delete(cache.items, key)
+		}
+	}
+}
+
+func (cache *UserCache) Stats() UserCacheStats {
+	if cache == nil {
+		return UserCacheStats{}
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return UserCacheStats{
+		Hits:      cache.hits,
+		Misses:    cache.misses,
+		Evictions: cache.evictions,
+		Size:      cache.ll.Len(),
+		Capacity:  cache.capacity,
+	}
+}
+
+// walletUserCacheTTL is short on purpose: the wallet cache only has to
+// absorb the repeated lookups within one login or bind request.
+const walletUserCacheTTL = 5 * time.Second
+
+var (
+	userCacheOnce sync.Once
+	userCache     *UserCache
+
+	walletUserCacheOnce sync.Once
+	walletUserCache     *UserCache
+)
+
+func getUserCache() *UserCache {
+	userCacheOnce.Do(func() {
+		userCache = NewUserCache(config.UserCacheSize, time.Duration(config.UserCacheTTLSeconds)*time.Second)
+	})
+	return userCache
+}
+
+func getWalletUserCache() *UserCache {
+	walletUserCacheOnce.Do(func() {
+		walletUserCache = NewUserCache(config.UserCacheSize, walletUserCacheTTL)
+	})
+	return walletUserCache
+}
+
+func CacheGetUserById(id string) (User, bool) {
+	return getUserCache().Get(id)
+}
+
+func CacheSetUser(user *User) {
+	if user == nil {
+		return
+	}
+	getUserCache().Set(*user)
+}
+
+// InvalidateUserCache drops the user from both the id and the wallet address caches.
+func InvalidateUserCache(id string) {
+	getUserCache().Invalidate(id)
+	getWalletUserCache().InvalidateUser(id)
+}
+
+func CacheGetUserByWalletAddress(address string) (User, bool) {
+	return getWalletUserCache().Get(NormalizeWalletAddress(address))
+}
+
+func CacheSetUserByWalletAddress(address string, user *User) {
+	if user == nil || strings.TrimSpace(user.Id) == "" {
+		return
+	}
+	getWalletUserCache().SetKey(NormalizeWalletAddress(address), *user)
+}
+
+func InvalidateWalletUserCache(address string) {
+	getWalletUserCache().Invalidate(NormalizeWalletAddress(address))
+}
+
+func GetUserCacheStats() UserCacheStats {
+	return getUserCache().Stats()
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestUserCache_SetKeyEviction(t *testing.T) {
+	cache := NewUserCache(2, walletUserCacheTTL)
+	cache.SetKey("0xa", User{Id: "u1"})
+	cache.SetKey("0xb", User{Id: "u1"})
+	cache.SetKey("0xc", User{Id: "u2"})
+	if _, ok := cache.Get("0xa"); ok {
+		t.Fatalf("oldest key was not evicted")
+	}
+	cache.InvalidateUser("u1")
+	if _, ok := cache.Get("0xb"); ok {
+		t.Fatalf("InvalidateUser kept an entry of u1")
+	}
+	if cached, ok := cache.Get("0xc"); !ok || cached.Id != "u2" {
+		t.Fatalf("Get(0xc) = %+v, %v, want u2", cached, ok)
+	}
+}
+
+func TestFillUserByWalletAddress_Cached(t *testing.T) {
+	prevRepo := userRepo
+	defer func() { userRepo = prevRepo }()
+	calls := 0
+	userRepo = UserRepository{
+		GetUserById: func(id string, selectAll bool) (*User, error) { return nil, gorm.ErrRecordNotFound },
+		FillUserByWalletAddress: func(user *User) error {
+			calls++
+			if *user.WalletAddress == "0xcac4e00000000000000000000000000000000002" {
+				return nil
+			}
+			user.Id = "wallet-cache-user"
+			return nil
+		},
+	}
+
+	lookup := func(address string) User {
+		user := User{WalletAddress: &address}
+		if err := user.FillUserByWalletAddress(); err != nil {
+			t.Fatalf("FillUserByWalletAddress error: %v", err)
+		}
+		return user
+	}
+	lookup("0xcac4e00000000000000000000000000000000001")
+	if user := lookup("0xCAC4E00000000000000000000000000000000001"); user.Id != "wallet-cache-user" || calls != 1 {
+		t.Fatalf("second lookup: id=%q calls=%d, want a cache hit", user.Id, calls)
+	}
+
+	InvalidateUserCache("wallet-cache-user")
+	lookup("0xcac4e00000000000000000000000000000000001")
+	if calls != 2 {
+		t.Fatalf("calls = %d after invalidation, want 2", calls)
+	}
+
+	lookup("0xcac4e00000000000000000000000000000000002")
+	lookup("0xcac4e00000000000000000000000000000000002")
+	if calls != 4 {
+		t.Fatalf("calls = %d, unknown addresses must not be cached", calls)
+	}
+}
+
+func benchmarkWalletUserRepo(b *testing.B) func() {
+	prevDB, prevRepo := DB, userRepo
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		b.Fatalf("gorm.Open error: %v", err)
+	}
+	DB = db
+	userRepo = UserRepository{
+		GetUserById: func(id string, selectAll bool) (*User, error) { return nil, gorm.ErrRecordNotFound },
+		FillUserByWalletAddress: func(user *User) error {
+			DB.Where(User{WalletAddress: user.WalletAddress}).First(user)
+			user.Id = "wallet-bench-user"
+			return nil
+		},
+	}
+	return func() { DB, userRepo = prevDB, prevRepo }
+}
+
+func BenchmarkFillUserByWalletAddressCached(b *testing.B) {
+	defer benchmarkWalletUserRepo(b)()
+	address := "0xbe4c000000000000000000000000000000000001"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		user := User{WalletAddress: &address}
+		_ = user.FillUserByWalletAddress()
+	}
+}
+
+// BenchmarkFillUserByWalletAddressUncached goes straight to the repository,
+// kept as a baseline.
+func BenchmarkFillUserByWalletAddressUncached(b *testing.B) {
+	defer benchmarkWalletUserRepo(b)()
+	address := "0xbe4c000000000000000000000000000000000001"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		user := User{WalletAddress: &address}
+		_ = mustUserRepo().FillUserByWalletAddress(&user)
+	}
+}
+
+func TestRefreshUserCaches_DropsEveryUser(t *testing.T) {
+	CacheSetUser(&User{Id: "merge-source"})
+	CacheSetUser(&User{Id: "merge-target"})
+	RefreshUserCaches("merge-source", " merge-target ")
+	for _, id := range []string{"merge-source", "merge-target"} {
+		if _, ok := CacheGetUserById(id); ok {
+			t.Fatalf("user %s still cached", id)
+		}
+	}
+}
@@ -75,6 +75,7 @@ func syncUserPackageSubscriptionsWithDB(db *gorm.DB, userID string, now int64) e
 	if effectiveNow <= 0 {
 		effectiveNow = helper.GetTimestamp()
 	}
+	defer InvalidateUserCache(normalizedUserID)
 	return db.Transaction(func(tx *gorm.DB) error {
 		if err := markExpiredUserPackageSubscriptionsWithDB(tx, normalizedUserID, effectiveNow); err != nil {
 			return err
@@ -262,6 +263,7 @@ func AssignServicePackageToUserWithDB(db *gorm.DB, packageID string, userID stri
 	if err != nil {
 		return UserPackageSubscription{}, err
 	}
+	InvalidateUserCache(normalizedUserID)
 	return subscription, nil
 }
 
@@ -377,6 +379,7 @@ func UpgradeServicePackageForUserWithDB(db *gorm.DB, packageID string, userID st
 	if err != nil {
 		return UserPackageSubscription{}, err
 	}
+	InvalidateUserCache(normalizedUserID)
 	return returnSubscription, nil
 }
 
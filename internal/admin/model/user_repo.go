@@ -28,6 +28,7 @@ type UserRepository struct {
 	IsOidcIdAlreadyTaken                     func(oidcId string) bool
 	IsWalletAddressAlreadyTaken              func(address string) bool
 	IsUsernameAlreadyTaken                   func(username string) bool
+	IsDisplayNameAlreadyTaken                func(name string) bool
 	ResetUserPasswordByEmail                 func(email string, password string) error
 	IsAdmin                                  func(userId string) bool
 	IsUserEnabled                            func(userId string) (bool, error)
@@ -46,6 +47,7 @@ type UserRepository struct {
 	UpdateUserUsedQuotaDirect                func(id string, quota int64)
 	UpdateUserRequestCountDirect             func(id string, count int)
 	GetUsernameById                          func(id string) string
+	UpdateUserLastLoginAt                    func(id string, timestamp int64) error
 }
 
 var userRepo UserRepository
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func rootWalletAddressesForBenchmark(n int) (map[string]bool, []string) {
+	set := make(map[string]bool, n)
+	list := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		addr := fmt.Sprintf("0x%040x", i)
+		set[addr] = true
+		list = append(list, addr)
+	}
+	return set, list
+}
+
+func TestIsRootWalletAddress(t *testing.T) {
+	prev := config.RootWalletAddresses
+	config.RootWalletAddresses = map[string]bool{"0xabc0000000000000000000000000000000000001": true}
+	defer func() { config.RootWalletAddresses = prev }()
+
+	if !IsRootWalletAddress(" 0xABC0000000000000000000000000000000000001 ") {
+		t.Fatalf("IsRootWalletAddress did not match mixed-case address")
+	}
+	if IsRootWalletAddress("0xabc0000000000000000000000000000000000002") || IsRootWalletAddress("") {
+		t.Fatalf("IsRootWalletAddress matched an unknown address")
+	}
+}
+
+func BenchmarkIsRootWalletAddress1000(b *testing.B) {
+	set, list := rootWalletAddressesForBenchmark(1000)
+	prev := config.RootWalletAddresses
+	config.RootWalletAddresses = set
+	defer func() { config.RootWalletAddresses = prev }()
+	target := list[len(list)-1]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		IsRootWalletAddress(target)
+	}
+}
+
+// BenchmarkIsRootWalletAddressLinear1000 is the previous slice scan, kept as a baseline.
+func BenchmarkIsRootWalletAddressLinear1000(b *testing.B) {
+	_, list := rootWalletAddressesForBenchmark(1000)
+	target := list[len(list)-1]
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		normalized := NormalizeWalletAddress(target)
+		for _, configured := range list {
+			if normalized == NormalizeWalletAddress(configured) {
+				break
+			}
+		}
+	}
+}
+
+func TestNormalizeRootWalletAddresses(t *testing.T) {
+	got := normalizeRootWalletAddresses([]string{
+		" 0xABC0000000000000000000000000000000000002",
+		"0xabc0000000000000000000000000000000000001",
+		"",
+		"0xabc0000000000000000000000000000000000002",
+	})
+	want := []string{
+		"0xabc0000000000000000000000000000000000001",
+		"0xabc0000000000000000000000000000000000002",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("normalizeRootWalletAddresses = %v, want %v", got, want)
+	}
+	if _, err := ReplaceRootWalletAddresses([]string{" ", ""}); err != ErrRootWalletAddressesEmpty {
+		t.Fatalf("ReplaceRootWalletAddresses(blank) err = %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package model
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/logger"
+	"gorm.io/gorm"
+)
+
+const UserWalletsTableName = "user_wallets"
+
+var ErrWalletBoundToOtherUser = errors.New("该钱包已绑定其他账户")
+
+// UserWallet is one wallet address bound to a user. users.wallet_address keeps
+// the primary address; every bound address, the primary included, has a row
+// here and can be used to log in.
+type UserWallet struct {
+	Address    string `json:"address" gorm:"primaryKey;type:varchar(64)"`
+	UserId     string `json:"user_id" gorm:"type:char(36);not null;index"`
+	ChainId    string `json:"chain_id" gorm:"type:varchar(32);not null;default:''"`
+	WalletType string `json:"wallet_type" gorm:"type:varchar(16);not null;default:'ethereum'"`
+	CreatedAt  int64  `json:"created_at" gorm:"bigint"`
+}
+
+func (UserWallet) TableName() string {
+	return UserWalletsTableName
+}
+
+func GetUserWallets(userId string) ([]UserWallet, error) {
+	var wallets []UserWallet
+	err := DB.Where("user_id = ?", strings.TrimSpace(userId)).Order("created_at asc").Find(&wallets).Error
+	return wallets, err
+}
+
+func GetUserWalletByAddress(address string) (*UserWallet, error) {
+	wallet := UserWallet{}
+	if err := DB.First(&wallet, "address = ?", NormalizeWalletAddress(address)).Error; err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// HydrateUserWallets loads the bound wallets into user.Wallets.
+func HydrateUserWallets(user *User) error {
+	if user == nil || strings.TrimSpace(user.Id) == "" {
+		return nil
+	}
+	wallets, err := GetUserWallets(user.Id)
+	if err != nil {
+		return err
+	}
+	user.Wallets = wallets
+	return nil
+}
+
+// UserHasWalletAddress reports whether address is the primary or any bound
+// wallet of user.
+func UserHasWalletAddress(user *User, address string) bool {
+	addr := NormalizeWalletAddress(address)
+	if user == nil || addr == "" {
+		return false
+	}
+	if user.WalletAddress != nil && NormalizeWalletAddress(*user.WalletAddress) == addr {
+		return true
+	}
+	wallet, err := GetUserWalletByAddress(addr)
+	return err == nil && wallet.UserId == user.Id
+}
+
+// BindUserWalletWithDB adds address to the user's wallets and makes it the
+// primary address when the user has none. An empty walletType means Ethereum.
+func BindUserWalletWithDB(tx *gorm.DB, user *User, address string, chainId string, walletType string) error {
+	addr := NormalizeWalletAddress(address)
+	if user == nil || strings.TrimSpace(user.Id) == "" || addr == "" {
+		return errors.New("用户或钱包地址为空")
+	}
+	existing := UserWallet{}
+	err := tx.First(&existing, "address = ?", addr).Error
+	switch {
+	case err == nil:
+		if existing.UserId != user.Id {
+			return ErrWalletBoundToOtherUser
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := tx.Create(&UserWallet{
+			Address:    addr,
+			UserId:     user.Id,
+			ChainId:    strings.TrimSpace(chainId),
+			WalletType: walletTypeOrDefault(walletType),
+			CreatedAt:  helper.GetTimestamp(),
+		}).Error; err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+	if user.WalletAddress == nil || strings.TrimSpace(*user.WalletAddress) == "" {
+		if err := tx.Model(&User{}).Where("id = ?", user.Id).Update("wallet_address", addr).Error; err != nil {
+			return err
+		}
+		user.WalletAddress = &addr
+	}
+	// existing wallets stay bound, so the tokens issued for them stay valid
+	InvalidateUserCache(user.Id)
+	return nil
+}
+
+// UnbindUserWallet removes address from the user's wallets in a transaction
+// and revokes the user's wallet sessions once it has committed.
+func UnbindUserWallet(user *User, address string) error {
+	if err := DB.Transaction(func(tx *gorm.DB) error {
+		return UnbindUserWalletWithDB(tx, user, address)
+	}); err != nil {
+		return err
+	}
+	RevokeUserWalletSessions(user.Id)
+	return nil
+}
+
+// UnbindUserWalletWithDB removes address from the user's wallets. When it was
+// the primary address the oldest remaining wallet takes its place. The caller
+// revokes the user's wallet sessions after tx commits.
+func UnbindUserWalletWithDB(tx *gorm.DB, user *User, address string) error {
+	addr := NormalizeWalletAddress(address)
+	if user == nil || addr == "" {
+		return errors.New("用户或钱包地址为空")
+	}
+	result := tx.Where("address = ? AND user_id = ?", addr, user.Id).Delete(&UserWallet{})
+	if result.Error != nil {
+		return result.Error
+	}
+	isPrimary := user.WalletAddress != nil && NormalizeWalletAddress(*user.WalletAddress) == addr
+	if result.RowsAffected == 0 && !isPrimary {
+		return errors.New("该钱包未绑定到当前账户")
+	}
+	if isPrimary {
+		var next *string
+		remaining := UserWallet{}
+		if err := tx.Where("user_id = ?", user.Id).Order("created_at asc").First(&remaining).Error; err == nil {
+			next = &remaining.Address
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err := tx.Model(&User{}).Where("id = ?", user.Id).Update("wallet_address", next).Error; err != nil {
+			return err
+		}
+		user.WalletAddress = next
+	}
+	InvalidateUserCache(user.Id)
+	return nil
+}
+
+// DeleteUserWalletsWithDB unbinds every wallet of a user, e.g. when the
+// account is deleted or rejected.
+func DeleteUserWalletsWithDB(tx *gorm.DB, userId string) error {
+	return tx.Where("user_id = ?", strings.TrimSpace(userId)).Delete(&UserWallet{}).Error
+}
+
+// ReleaseWalletAddress frees addr when it is still held by a deleted user, so
+// it can be bound to another account. The owner is updated through the model,
+// so User hooks run, and every wallet row of the deleted user is dropped. It
+// is a no-op when addr is unbound or belongs to an active user.
+func ReleaseWalletAddress(addr string) error {
+	addr = NormalizeWalletAddress(addr)
+	if addr == "" {
+		return errors.New("钱包地址为空")
+	}
+	owner := User{WalletAddress: &addr}
+	if err := owner.FillUserByWalletAddress(); err != nil {
+		return err
+	}
+	if owner.Id == "" || owner.Status != UserStatusDeleted {
+		return nil
+	}
+	previous := owner.WalletAddress
+	if owner.WalletAddress != nil && NormalizeWalletAddress(*owner.WalletAddress) == addr {
+		if err := DB.Model(&owner).Update("wallet_address", nil).Error; err != nil {
+			return err
+		}
+	}
+	if err := DeleteUserWalletsWithDB(DB, owner.Id); err != nil {
+		return err
+	}
+	InvalidateUserCache(owner.Id)
+	RevokeOnWalletAddressChange(owner.Id, previous, nil)
+	logger.SysLogf("wallet address %s released from deleted user %s", addr, owner.Id)
+	return nil
+}
+
+// RevokeUserWalletSessions invalidates the wallet JWTs and refresh tokens of a
+// user whose wallet addresses changed, so no token keeps asserting an address
+// the user may no longer own. Revocations go to the store selected by
+// auth.nonce_store, which survives restarts when it is Redis.
+func RevokeUserWalletSessions(userId string) {
+	if err := common.RevokeWalletJWTsForUser(userId); err != nil {
+		logger.SysErrorf("revoke wallet tokens for user %s failed: %v", userId, err)
+	}
+	if err := RevokeUserRefreshTokens(userId); err != nil {
+		logger.SysErrorf("revoke refresh tokens for user %s failed: %v", userId, err)
+	}
+}
+
+// WalletAddressChanged reports whether a primary address moved away from
+// previous. Addresses are compared normalized and case-insensitively, so a
+// change in checksum casing alone is not a change.
+func WalletAddressChanged(previous *string, next *string) bool {
+	if previous == nil || NormalizeWalletAddress(*previous) == "" {
+		return false
+	}
+	if next == nil {
+		return true
+	}
+	return !strings.EqualFold(NormalizeWalletAddress(*previous), NormalizeWalletAddress(*next))
+}
+
+// RevokeOnWalletAddressChange revokes the wallet sessions of userId when its
+// primary address moved from previous to next. Call it after the change has
+// committed.
+func RevokeOnWalletAddressChange(userId string, previous *string, next *string) {
+	if WalletAddressChanged(previous, next) {
+		RevokeUserWalletSessions(userId)
+	}
+}
+
+func walletTypeOrDefault(walletType string) string {
+	if walletType = strings.TrimSpace(walletType); walletType != "" {
+		return walletType
+	}
+	return common.WalletTypeEthereum
+}
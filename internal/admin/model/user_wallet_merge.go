@@ -0,0 +1,115 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yeying-community/router/common/blacklist"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/random"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MergeWalletUser folds sourceID, typically an account auto-registered on
+// wallet login, into targetID and deletes it. The target receives the
+// source's wallets, tokens, API keys, balance lots and quota; it also takes
+// over the source's group and package subscriptions when it has none of its
+// own. Everything runs in one transaction, so a failed merge changes nothing.
+func MergeWalletUser(sourceID string, targetID string) error {
+	sourceID = strings.TrimSpace(sourceID)
+	targetID = strings.TrimSpace(targetID)
+	if sourceID == "" || targetID == "" {
+		return errors.New("source_id 和 target_id 不能为空")
+	}
+	if sourceID == targetID {
+		return errors.New("不能合并同一个用户")
+	}
+	var targetAddress, mergedAddress *string
+	var movedTokenKeys []string
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		source, err := loadMergeUserWithDB(tx, sourceID)
+		if err != nil {
+			return err
+		}
+		target, err := loadMergeUserWithDB(tx, targetID)
+		if err != nil {
+			return err
+		}
+		targetAddress, mergedAddress = target.WalletAddress, target.WalletAddress
+
+		// the primary address is unique, so it leaves the source first
+		if err := tx.Model(&User{}).Where("id = ?", source.Id).Updates(map[string]interface{}{
+			"username":       fmt.Sprintf("deleted_%s", random.GetUUID()),
+			"status":         UserStatusDeleted,
+			"wallet_address": nil,
+			"quota":          0,
+			"updated_at":     helper.GetTimestamp(),
+		}).Error; err != nil {
+			return err
+		}
+		// cached tokens still name the source until they are dropped
+		if err := tx.Model(&Token{}).Where("user_id = ?", source.Id).Pluck("key", &movedTokenKeys).Error; err != nil {
+			return err
+		}
+		for _, owned := range []any{&UserWallet{}, &Token{}, &ApiKey{}, &UserBalanceLot{}} {
+			if err := tx.Model(owned).Where("user_id = ?", source.Id).Update("user_id", target.Id).Error; err != nil {
+				return err
+			}
+		}
+
+		targetUpdates := map[string]interface{}{
+			"quota":         gorm.Expr("quota + ?", source.Quota),
+			"used_quota":    gorm.Expr("used_quota + ?", source.UsedQuota),
+			"request_count": gorm.Expr("request_count + ?", source.RequestCount),
+			"updated_at":    helper.GetTimestamp(),
+		}
+		if (target.WalletAddress == nil || strings.TrimSpace(*target.WalletAddress) == "") && source.WalletAddress != nil {
+			targetUpdates["wallet_address"] = *source.WalletAddress
+			mergedAddress = source.WalletAddress
+		}
+		if strings.TrimSpace(target.Group) == "" {
+			targetUpdates["group"] = source.Group
+		}
+		if err := tx.Model(&User{}).Where("id = ?", target.Id).Updates(targetUpdates).Error; err != nil {
+			return err
+		}
+
+		var targetSubscriptions int64
+		if err := tx.Model(&UserPackageSubscription{}).Where("user_id = ?", target.Id).Count(&targetSubscriptions).Error; err != nil {
+			return err
+		}
+		if targetSubscriptions == 0 {
+			if err := tx.Model(&UserPackageSubscription{}).Where("user_id = ?", source.Id).Update("user_id", target.Id).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	blacklist.BanUser(sourceID)
+	RefreshUserCaches(sourceID, targetID)
+	RefreshTokenCaches(movedTokenKeys...)
+	// tokens issued to the source name an account that no longer exists
+	RevokeUserWalletSessions(sourceID)
+	RevokeOnWalletAddressChange(targetID, targetAddress, mergedAddress)
+	return nil
+}
+
+func loadMergeUserWithDB(tx *gorm.DB, id string) (*User, error) {
+	user := User{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Omit("password").Where("id = ?", id).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("用户 %s 不存在", id)
+		}
+		return nil, err
+	}
+	if user.Status == UserStatusDeleted {
+		return nil, fmt.Errorf("用户 %s 已被删除", id)
+	}
+	return &user, nil
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestMergeWalletUser_RejectsInvalidPairs(t *testing.T) {
+	prevDB := DB
+	defer func() { DB = prevDB }()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	var statements []string
+	capture := func(tx *gorm.DB) { statements = append(statements, tx.Statement.SQL.String()) }
+	_ = db.Callback().Update().After("gorm:update").Register("test:capture", capture)
+	DB = db
+
+	cases := []struct {
+		name    string
+		source  string
+		target  string
+		wantErr string
+	}{
+		{"empty source", " ", "target", "不能为空"},
+		{"same user", "user-1", " user-1 ", "同一个用户"},
+	}
+	for _, tt := range cases {
+		err := MergeWalletUser(tt.source, tt.target)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Fatalf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+	if len(statements) != 0 {
+		t.Fatalf("rejected merges issued updates: %q", statements)
+	}
+}
+
+func TestLoadMergeUserWithDB_LocksRow(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	var statements []string
+	_ = db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	})
+	_, _ = loadMergeUserWithDB(db, "user-1")
+	if len(statements) != 1 || !strings.HasSuffix(statements[0], "FOR UPDATE") {
+		t.Fatalf("statements = %q, want a SELECT ... FOR UPDATE", statements)
+	}
+}
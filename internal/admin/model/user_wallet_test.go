@@ -0,0 +1,123 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/yeying-community/router/common"
+)
+
+func TestUserHasWalletAddress_PrimaryAddress(t *testing.T) {
+	primary := "0xAbCdEf0000000000000000000000000000000001"
+	user := &User{Id: "u1", WalletAddress: &primary}
+	if !UserHasWalletAddress(user, " 0xabcdef0000000000000000000000000000000001 ") {
+		t.Fatalf("expected primary address to match case-insensitively")
+	}
+	if UserHasWalletAddress(nil, primary) {
+		t.Fatalf("expected nil user not to match")
+	}
+	if UserHasWalletAddress(user, "  ") {
+		t.Fatalf("expected empty address not to match")
+	}
+}
+
+func TestUserWalletTableName(t *testing.T) {
+	if got := (UserWallet{}).TableName(); got != UserWalletsTableName {
+		t.Fatalf("TableName() = %q, want %q", got, UserWalletsTableName)
+	}
+}
+
+func TestNormalizeWalletAddress_KeepsSolanaCase(t *testing.T) {
+	if got := NormalizeWalletAddress(" 0xAbC "); got != "0xabc" {
+		t.Fatalf("NormalizeWalletAddress(hex) = %q", got)
+	}
+	solana := "4Nd1mBQtrMJVYVfKf2PJy9NZUZdTAsp7D4xWLs4gDB4T"
+	if got := NormalizeWalletAddress(" " + solana + " "); got != solana {
+		t.Fatalf("NormalizeWalletAddress(base58) = %q, want %q", got, solana)
+	}
+}
+
+func TestReleaseWalletAddress_DeletedOwner(t *testing.T) {
+	prevDB, prevRepo := DB, userRepo
+	defer func() { DB, userRepo = prevDB, prevRepo }()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	var statements []string
+	capture := func(tx *gorm.DB) { statements = append(statements, tx.Statement.SQL.String()) }
+	_ = db.Callback().Update().After("gorm:update").Register("test:capture", capture)
+	_ = db.Callback().Delete().After("gorm:delete").Register("test:capture", capture)
+	DB = db
+
+	addr := "0xabcdef0000000000000000000000000000000001"
+	owner := User{Id: "deleted-user", Status: UserStatusDeleted, WalletAddress: &addr}
+	userRepo = UserRepository{
+		GetUserById: func(id string, selectAll bool) (*User, error) { return nil, gorm.ErrRecordNotFound },
+		FillUserByWalletAddress: func(user *User) error {
+			*user = owner
+			return nil
+		},
+	}
+	if err := ReleaseWalletAddress(" 0xABCDEF0000000000000000000000000000000001 "); err != nil {
+		t.Fatalf("ReleaseWalletAddress error: %v", err)
+	}
+	if len(statements) != 3 || !strings.HasPrefix(statements[0], "UPDATE") || !strings.HasPrefix(statements[1], "DELETE") ||
+		!strings.Contains(statements[2], "refresh_tokens") {
+		t.Fatalf("statements = %q, want the owner update, the wallet delete, then the refresh token revoke", statements)
+	}
+	if !common.IsWalletJWTRevoked(&common.WalletClaims{UserID: owner.Id, RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))}}) {
+		t.Fatalf("expected the released owner's wallet tokens to be revoked")
+	}
+
+	owner.Status = UserStatusEnabled
+	statements = nil
+	if err := ReleaseWalletAddress(addr); err != nil || len(statements) != 0 {
+		t.Fatalf("active owner: err=%v statements=%q, want a no-op", err, statements)
+	}
+}
+
+func TestWalletAddressChanged(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	solana := "4Nd1mBQtrMJVYVfKf2PJy9NZUZdTAsp7D4xWLs4gDB4T"
+	cases := []struct {
+		name     string
+		previous *string
+		next     *string
+		want     bool
+	}{
+		{"no previous address", nil, ptr("0xabcdef0000000000000000000000000000000001"), false},
+		{"empty previous address", ptr(" "), ptr("0xabcdef0000000000000000000000000000000001"), false},
+		{"checksum casing only", ptr("0xAbCdEf0000000000000000000000000000000001"), ptr(" 0xabcdef0000000000000000000000000000000001"), false},
+		{"different address", ptr("0xabcdef0000000000000000000000000000000001"), ptr("0xabcdef0000000000000000000000000000000002"), true},
+		{"address cleared", ptr("0xabcdef0000000000000000000000000000000001"), nil, true},
+		{"same solana address", ptr(solana), ptr(" " + solana), false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WalletAddressChanged(tt.previous, tt.next); got != tt.want {
+				t.Fatalf("WalletAddressChanged() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnbindUserWalletWithDB_LeavesRevokeToCaller(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	addr := "0xabcdef0000000000000000000000000000000003"
+	user := &User{Id: "unbind-user", WalletAddress: &addr}
+	if err := UnbindUserWalletWithDB(db, user, addr); err != nil {
+		t.Fatalf("UnbindUserWalletWithDB error: %v", err)
+	}
+	if common.IsWalletJWTRevoked(&common.WalletClaims{UserID: user.Id, RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))}}) {
+		t.Fatalf("wallet tokens revoked before the transaction committed")
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/yeying-community/router/common/helper"
+)
+
+const WalletLoginHistoryTableName = "wallet_login_history"
+
+// WalletLoginHistory is one wallet login attempt. UserId is empty when the
+// address belongs to no account; Reason holds the error for failed attempts.
+type WalletLoginHistory struct {
+	Id            int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserId        string `json:"user_id" gorm:"type:char(36);not null;default:'';index:idx_wallet_login_history_user,priority:1"`
+	WalletAddress string `json:"wallet_address" gorm:"type:varchar(128);not null;default:'';index"`
+	ChainId       string `json:"chain_id" gorm:"type:varchar(64);not null;default:''"`
+	Ip            string `json:"ip" gorm:"type:varchar(64);not null;default:''"`
+	UserAgent     string `json:"user_agent" gorm:"type:varchar(512);not null;default:''"`
+	Success       bool   `json:"success" gorm:"not null;default:false"`
+	Reason        string `json:"reason" gorm:"type:varchar(255);not null;default:''"`
+	CreatedAt     int64  `json:"created_at" gorm:"bigint;index:idx_wallet_login_history_user,priority:2"`
+}
+
+func (WalletLoginHistory) TableName() string {
+	return WalletLoginHistoryTableName
+}
+
+func CreateWalletLoginHistory(entry *WalletLoginHistory) error {
+	entry.UserAgent = truncateWalletLoginHistoryField(entry.UserAgent, 512)
+	entry.Reason = truncateWalletLoginHistoryField(entry.Reason, 255)
+	if entry.CreatedAt == 0 {
+		entry.CreatedAt = helper.GetTimestamp()
+	}
+	return DB.Create(entry).Error
+}
+
+// ListWalletLoginHistoryPage returns the user's attempts newest first.
+func ListWalletLoginHistoryPage(userId string, page int, pageSize int) ([]WalletLoginHistory, int64, error) {
+	var rows []WalletLoginHistory
+	var total int64
+	query := DB.Model(&WalletLoginHistory{}).Where("user_id = ?", strings.TrimSpace(userId))
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at desc, id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&rows).Error
+	return rows, total, err
+}
+
+func truncateWalletLoginHistoryField(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
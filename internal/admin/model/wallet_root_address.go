@@ -0,0 +1,142 @@
+package model
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/logger"
+)
+
+const WalletRootAddressesTableName = "wallet_root_addresses"
+
+// walletRootAddressCacheTTL bounds how long another instance keeps using a
+// root address list after an admin replaces it.
+const walletRootAddressCacheTTL = 60 * time.Second
+
+var ErrRootWalletAddressesEmpty = errors.New("至少需要保留一个 root 钱包地址")
+
+// WalletRootAddress is a wallet that gets the root role. The table is seeded
+// from bootstrap.root_wallet_address and replaces it once it has rows.
+type WalletRootAddress struct {
+	Address   string `json:"address" gorm:"type:varchar(128);primaryKey"`
+	CreatedAt int64  `json:"created_at" gorm:"bigint"`
+}
+
+func (WalletRootAddress) TableName() string {
+	return WalletRootAddressesTableName
+}
+
+var rootWalletAddressCache struct {
+	mu       sync.Mutex
+	set      map[string]bool
+	loadedAt time.Time
+}
+
+// rootWalletAddressSet returns the table contents, or config.RootWalletAddresses
+// while there is no DB or the table is empty, so a fresh install is never left
+// without a root.
+func rootWalletAddressSet() map[string]bool {
+	if DB == nil {
+		return config.RootWalletAddresses
+	}
+	rootWalletAddressCache.mu.Lock()
+	defer rootWalletAddressCache.mu.Unlock()
+	if rootWalletAddressCache.set != nil && time.Since(rootWalletAddressCache.loadedAt) < walletRootAddressCacheTTL {
+		return rootWalletAddressCache.set
+	}
+	var rows []WalletRootAddress
+	if err := DB.Find(&rows).Error; err != nil {
+		logger.SysErrorf("load root wallet addresses failed: %v", err)
+		return config.RootWalletAddresses
+	}
+	set := config.RootWalletAddresses
+	if len(rows) > 0 {
+		set = make(map[string]bool, len(rows))
+		for _, row := range rows {
+			set[row.Address] = true
+		}
+	}
+	rootWalletAddressCache.set = set
+	rootWalletAddressCache.loadedAt = time.Now()
+	return set
+}
+
+func invalidateRootWalletAddressCache() {
+	rootWalletAddressCache.mu.Lock()
+	rootWalletAddressCache.set = nil
+	rootWalletAddressCache.mu.Unlock()
+}
+
+// ListRootWalletAddresses returns the effective root addresses, sorted.
+func ListRootWalletAddresses() []string {
+	set := rootWalletAddressSet()
+	addresses := make([]string, 0, len(set))
+	for address := range set {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// ReplaceRootWalletAddresses stores addresses as the complete root list.
+func ReplaceRootWalletAddresses(addresses []string) ([]string, error) {
+	normalized := normalizeRootWalletAddresses(addresses)
+	if len(normalized) == 0 {
+		return nil, ErrRootWalletAddressesEmpty
+	}
+	now := helper.GetTimestamp()
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&WalletRootAddress{}).Error; err != nil {
+			return err
+		}
+		rows := make([]WalletRootAddress, 0, len(normalized))
+		for _, address := range normalized {
+			rows = append(rows, WalletRootAddress{Address: address, CreatedAt: now})
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateRootWalletAddressCache()
+	return normalized, nil
+}
+
+func normalizeRootWalletAddresses(addresses []string) []string {
+	seen := make(map[string]bool, len(addresses))
+	normalized := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		address = NormalizeWalletAddress(address)
+		if address == "" || seen[address] {
+			continue
+		}
+		seen[address] = true
+		normalized = append(normalized, address)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func seedWalletRootAddressesWithDB(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&WalletRootAddress{}); err != nil {
+		return err
+	}
+	addresses := make([]string, 0, len(config.RootWalletAddresses))
+	for address := range config.RootWalletAddresses {
+		addresses = append(addresses, address)
+	}
+	now := helper.GetTimestamp()
+	for _, address := range normalizeRootWalletAddresses(addresses) {
+		row := WalletRootAddress{Address: address, CreatedAt: now}
+		if err := tx.Where(WalletRootAddress{Address: address}).FirstOrCreate(&row).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+)
+
+// Wallet login switches that admins can flip at runtime. They live in
+// system_settings under their own keys, separate from the legacy
+// WalletLoginEnabled/AutoRegisterEnabled rows that UpdateOptionMap ignores.
+// Without a row the config.yaml value applies.
+const (
+	WalletLoginEnabledSettingKey        = "wallet_login_enabled"
+	WalletBindEnabledSettingKey         = "wallet_bind_enabled"
+	WalletAutoRegisterEnabledSettingKey = "wallet_auto_register_enabled"
+)
+
+// walletSettingCacheTTL bounds how long another instance keeps serving a
+// value after an admin changes it.
+const walletSettingCacheTTL = 30 * time.Second
+
+type cachedBoolSetting struct {
+	key      string
+	fallback func() bool
+
+	mu       sync.Mutex
+	value    bool
+	loadedAt time.Time
+}
+
+func (s *cachedBoolSetting) get() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < walletSettingCacheTTL {
+		return s.value
+	}
+	s.value = s.load()
+	s.loadedAt = time.Now()
+	return s.value
+}
+
+func (s *cachedBoolSetting) load() bool {
+	if DB == nil || optionRepo.GetOption == nil {
+		return s.fallback()
+	}
+	option, err := optionRepo.GetOption(s.key)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.SysErrorf("load setting %s failed: %v", s.key, err)
+		}
+		return s.fallback()
+	}
+	value, err := strconv.ParseBool(option.Value)
+	if err != nil {
+		logger.SysErrorf("invalid setting %s=%q: %v", s.key, option.Value, err)
+		return s.fallback()
+	}
+	return value
+}
+
+func (s *cachedBoolSetting) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+var (
+	walletLoginEnabledSetting = &cachedBoolSetting{
+		key:      WalletLoginEnabledSettingKey,
+		fallback: func() bool { return config.WalletLoginEnabled },
+	}
+	walletBindEnabledSetting = &cachedBoolSetting{
+		key:      WalletBindEnabledSettingKey,
+		fallback: func() bool { return config.WalletBindEnabled },
+	}
+	walletAutoRegisterEnabledSetting = &cachedBoolSetting{
+		key:      WalletAutoRegisterEnabledSettingKey,
+		fallback: func() bool { return config.AutoRegisterEnabled },
+	}
+)
+
+// WalletLoginEnabled reports whether wallet challenge and login requests are
+// accepted.
+func WalletLoginEnabled() bool {
+	return walletLoginEnabledSetting.get()
+}
+
+// WalletBindEnabled reports whether wallets can be bound to existing accounts,
+// independently of WalletLoginEnabled.
+func WalletBindEnabled() bool {
+	return walletBindEnabledSetting.get()
+}
+
+// WalletAutoRegisterEnabled reports whether an unknown wallet gets an account
+// on first login.
+func WalletAutoRegisterEnabled() bool {
+	return walletAutoRegisterEnabledSetting.get()
+}
+
+// SetWalletLoginEnabled persists the switch; this instance sees it at once,
+// others within walletSettingCacheTTL.
+func SetWalletLoginEnabled(enabled bool) error {
+	return UpdateOption(WalletLoginEnabledSettingKey, strconv.FormatBool(enabled))
+}
+
+// SetWalletBindEnabled persists the wallet binding switch.
+func SetWalletBindEnabled(enabled bool) error {
+	return UpdateOption(WalletBindEnabledSettingKey, strconv.FormatBool(enabled))
+}
+
+// SetWalletAutoRegisterEnabled persists the auto registration switch.
+func SetWalletAutoRegisterEnabled(enabled bool) error {
+	return UpdateOption(WalletAutoRegisterEnabledSettingKey, strconv.FormatBool(enabled))
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestCachedBoolSetting(t *testing.T) {
+	prevDB, prevRepo := DB, optionRepo
+	defer func() { DB, optionRepo = prevDB, prevRepo }()
+
+	fallback := true
+	setting := &cachedBoolSetting{key: "k", fallback: func() bool { return fallback }}
+
+	DB = nil
+	if !setting.get() {
+		t.Fatalf("without a DB the setting should use the fallback")
+	}
+
+	DB = &gorm.DB{}
+	stored := &Option{Key: "k", Value: "false"}
+	optionRepo.GetOption = func(key string) (*Option, error) {
+		if stored == nil {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return stored, nil
+	}
+	if !setting.get() {
+		t.Fatalf("cached value should be served until invalidated")
+	}
+	setting.invalidate()
+	if setting.get() {
+		t.Fatalf("stored false should override the fallback")
+	}
+
+	stored.Value = "maybe"
+	setting.invalidate()
+	if !setting.get() {
+		t.Fatalf("invalid stored value should use the fallback")
+	}
+
+	stored, fallback = nil, false
+	setting.invalidate()
+	if setting.get() {
+		t.Fatalf("missing row should use the fallback")
+	}
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/random"
+)
+
+const WebAuthnCredentialsTableName = "user_webauthn_credentials"
+
+// WebAuthnCredential is a passkey registered by a user. CredentialId is the
+// base64url credential ID; the remaining fields are what the authenticator
+// reported at registration, with SignCount and LastUsedAt kept current.
+type WebAuthnCredential struct {
+	Id              string `json:"id" gorm:"type:char(36);primaryKey"`
+	UserId          string `json:"user_id" gorm:"type:char(36);not null;index"`
+	CredentialId    string `json:"credential_id" gorm:"type:varchar(1024);not null;uniqueIndex"`
+	PublicKey       []byte `json:"-" gorm:"not null"`
+	AttestationType string `json:"attestation_type" gorm:"type:varchar(32);not null;default:''"`
+	AAGUID          []byte `json:"-"`
+	Transports      string `json:"transports" gorm:"type:varchar(128);not null;default:''"`
+	UserVerified    bool   `json:"user_verified" gorm:"not null;default:false"`
+	BackupEligible  bool   `json:"backup_eligible" gorm:"not null;default:false"`
+	BackupState     bool   `json:"backup_state" gorm:"not null;default:false"`
+	SignCount       int64  `json:"sign_count" gorm:"bigint;not null;default:0"`
+	CloneWarning    bool   `json:"clone_warning" gorm:"not null;default:false"`
+	LastUsedAt      int64  `json:"last_used_at" gorm:"bigint;not null;default:0"`
+	CreatedAt       int64  `json:"created_at" gorm:"bigint"`
+}
+
+func (WebAuthnCredential) TableName() string {
+	return WebAuthnCredentialsTableName
+}
+
+func CreateWebAuthnCredential(credential *WebAuthnCredential) error {
+	if credential.Id == "" {
+		credential.Id = random.GetUUID()
+	}
+	credential.CreatedAt = helper.GetTimestamp()
+	return DB.Create(credential).Error
+}
+
+func GetWebAuthnCredentialsByUserId(userId string) ([]WebAuthnCredential, error) {
+	var credentials []WebAuthnCredential
+	err := DB.Where("user_id = ?", strings.TrimSpace(userId)).Order("created_at asc").Find(&credentials).Error
+	return credentials, err
+}
+
+// UpdateWebAuthnCredentialUsage records the authenticator state after a
+// successful assertion.
+func UpdateWebAuthnCredentialUsage(credentialId string, signCount int64, cloneWarning bool, backupState bool) error {
+	return DB.Model(&WebAuthnCredential{}).Where("credential_id = ?", credentialId).Updates(map[string]interface{}{
+		"sign_count":    signCount,
+		"clone_warning": cloneWarning,
+		"backup_state":  backupState,
+		"last_used_at":  helper.GetTimestamp(),
+	}).Error
+}
@@ -3,6 +3,7 @@ package monitor
 import (
 	"fmt"
 
+	"github.com/yeying-community/router/common"
 	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/common/logger"
 	"github.com/yeying-community/router/common/message"
@@ -31,6 +32,7 @@ func notifyRootUser(subject string, content string) {
 func DisableChannel(channelId string, channelName string, reason string) {
 	model.UpdateChannelStatusById(channelId, model.ChannelStatusAutoDisabled)
 	logger.SysLog(fmt.Sprintf("channel #%s has been disabled: %s", channelId, reason))
+	common.SendSlackAlert(fmt.Sprintf("channel %s (#%s) has been auto-disabled: %s", channelName, channelId, reason))
 	subject := fmt.Sprintf("渠道状态变更提醒")
 	content := message.EmailTemplate(
 		subject,
@@ -47,6 +49,8 @@ func DisableChannel(channelId string, channelName string, reason string) {
 func MetricDisableChannel(channelId string, successRate float64) {
 	model.UpdateChannelStatusById(channelId, model.ChannelStatusAutoDisabled)
 	logger.SysLog(fmt.Sprintf("channel #%s has been disabled due to low success rate: %.2f", channelId, successRate*100))
+	common.SendSlackAlert(fmt.Sprintf("channel #%s has been auto-disabled: error rate %.2f%% exceeds threshold %.2f%%",
+		channelId, (1-successRate)*100, (1-config.MetricSuccessRateThreshold)*100))
 	subject := fmt.Sprintf("渠道状态变更提醒")
 	content := message.EmailTemplate(
 		subject,
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// dailyCounter counts events for the current local day and resets at midnight.
+type dailyCounter struct {
+	mu    sync.Mutex
+	day   string
+	count int64
+}
+
+func (d *dailyCounter) add(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rollover(now)
+	d.count++
+}
+
+func (d *dailyCounter) get(now time.Time) int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rollover(now)
+	return d.count
+}
+
+func (d *dailyCounter) rollover(now time.Time) {
+	day := now.Format("2006-01-02")
+	if d.day != day {
+		d.day = day
+		d.count = 0
+	}
+}
+
+var (
+	relayErrorsToday         dailyCounter
+	walletLoginFailuresToday dailyCounter
+)
+
+// RecordRelayError counts a failed upstream relay attempt for today's stats.
+// These counters are per process and are not persisted.
+func RecordRelayError() {
+	relayErrorsToday.add(time.Now())
+}
+
+// RecordWalletLoginFailure counts a rejected wallet login for today's stats.
+func RecordWalletLoginFailure() {
+	walletLoginFailuresToday.add(time.Now())
+}
+
+func RelayErrorsToday() int64 {
+	return relayErrorsToday.get(time.Now())
+}
+
+func WalletLoginFailuresToday() int64 {
+	return walletLoginFailuresToday.get(time.Now())
+}
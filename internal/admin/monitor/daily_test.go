@@ -0,0 +1,24 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyCounter_ResetsAtMidnight(t *testing.T) {
+	var counter dailyCounter
+	evening := time.Date(2026, 3, 1, 23, 59, 0, 0, time.Local)
+	counter.add(evening)
+	counter.add(evening.Add(30 * time.Second))
+	if got := counter.get(evening.Add(59 * time.Second)); got != 2 {
+		t.Fatalf("count before midnight = %d, want 2", got)
+	}
+	nextDay := evening.Add(time.Minute)
+	if got := counter.get(nextDay); got != 0 {
+		t.Fatalf("count after midnight = %d, want 0", got)
+	}
+	counter.add(nextDay)
+	if got := counter.get(nextDay); got != 1 {
+		t.Fatalf("count on the next day = %d, want 1", got)
+	}
+}
@@ -2,6 +2,7 @@ package channel
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/yeying-community/router/common/config"
@@ -28,8 +29,11 @@ func init() {
 	})
 }
 
-func buildChannelListQuery(db *gorm.DB, keyword string) *gorm.DB {
+func buildChannelListQuery(db *gorm.DB, keyword string, includeDeleted bool) *gorm.DB {
 	query := db.Model(&model.Channel{})
+	if !includeDeleted {
+		query = query.Where("status <> ?", model.ChannelStatusDeleted)
+	}
 	normalizedKeyword := strings.ToLower(strings.TrimSpace(keyword))
 	if normalizedKeyword == "" {
 		return query
@@ -43,7 +47,7 @@ func buildChannelListQuery(db *gorm.DB, keyword string) *gorm.DB {
 	)
 }
 
-func ListPage(page int, pageSize int, keyword string) ([]*model.Channel, int64, error) {
+func ListPage(page int, pageSize int, keyword string, includeDeleted bool) ([]*model.Channel, int64, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -51,7 +55,7 @@ func ListPage(page int, pageSize int, keyword string) ([]*model.Channel, int64,
 		pageSize = config.ItemsPerPage
 	}
 	total := int64(0)
-	query := buildChannelListQuery(model.DB, keyword)
+	query := buildChannelListQuery(model.DB, keyword, includeDeleted)
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -73,13 +77,14 @@ func ListPage(page int, pageSize int, keyword string) ([]*model.Channel, int64,
 func GetAll(startIdx int, num int, status string) ([]*model.Channel, error) {
 	var channels []*model.Channel
 	var err error
+	query := model.DB.Order("created_time desc").Where("status <> ?", model.ChannelStatusDeleted)
 	switch status {
 	case "all":
-		err = model.DB.Order("created_time desc").Find(&channels).Error
+		err = query.Find(&channels).Error
 	case "disabled":
-		err = model.DB.Order("created_time desc").Where("status = ? or status = ?", model.ChannelStatusAutoDisabled, model.ChannelStatusManuallyDisabled).Find(&channels).Error
+		err = query.Where("status = ? or status = ?", model.ChannelStatusAutoDisabled, model.ChannelStatusManuallyDisabled).Find(&channels).Error
 	default:
-		err = model.DB.Order("created_time desc").Limit(num).Offset(startIdx).Omit("key").Find(&channels).Error
+		err = query.Limit(num).Offset(startIdx).Omit("key").Find(&channels).Error
 	}
 	if err != nil {
 		return nil, err
@@ -113,7 +118,7 @@ func GetBasicByID(id string) (*model.Channel, error) {
 
 func GetAllBasic(startIdx int, num int, status string, selectAll bool) ([]*model.Channel, error) {
 	var channels []*model.Channel
-	query := model.DB.Order("created_time desc")
+	query := model.DB.Order("created_time desc").Where("status <> ?", model.ChannelStatusDeleted)
 	if !selectAll {
 		query = query.Omit("key")
 	}
@@ -144,6 +149,9 @@ func prepareChannelForCreate(channel *model.Channel) error {
 	if err := channel.ValidateIdentifier(); err != nil {
 		return err
 	}
+	if err := channel.ValidateTimeout(); err != nil {
+		return err
+	}
 	channel.NormalizeProtocol()
 	channel.NormalizeModelConfigState()
 	if channel.CreatedTime == 0 {
@@ -228,6 +236,9 @@ func Update(channel *model.Channel) error {
 		if err := channel.ValidateIdentifier(); err != nil {
 			return err
 		}
+		if err := channel.ValidateTimeout(); err != nil {
+			return err
+		}
 		if err := ensureChannelIdentifierUniqueWithDB(tx, channel); err != nil {
 			return err
 		}
@@ -237,6 +248,12 @@ func Update(channel *model.Channel) error {
 				return err
 			}
 		}
+		if channel.TimeoutProvided {
+			// Updates(struct) skips zero values, so resetting to 0 needs an explicit column update.
+			if err := tx.Model(&model.Channel{}).Where("id = ?", channel.Id).Update("timeout_seconds", channel.TimeoutSeconds).Error; err != nil {
+				return err
+			}
+		}
 		if err := tx.Model(&model.Channel{}).Where("id = ?", channel.Id).Omit("name").Updates(channel).Error; err != nil {
 			return err
 		}
@@ -313,6 +330,131 @@ func DeleteByID(id string) error {
 	return Delete(&channel)
 }
 
+// SoftDeleteByID marks a channel as deleted, schedules its permanent removal and
+// disables every token bound to it. It returns the number of disabled tokens.
+func SoftDeleteByID(id string) (int64, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return 0, errors.New("渠道 ID 不能为空")
+	}
+	now := helper.GetTimestamp()
+	var tokensDisabled int64
+	err := model.DB.Transaction(func(tx *gorm.DB) error {
+		channel := model.Channel{}
+		if err := tx.Omit("key").First(&channel, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if channel.Status == model.ChannelStatusDeleted {
+			return errors.New("渠道已删除")
+		}
+		if err := tx.Model(&model.Channel{}).Where("id = ?", id).Updates(map[string]any{
+			"status":         model.ChannelStatusDeleted,
+			"hard_delete_at": now + model.ChannelHardDeleteRetentionSeconds,
+			"updated_at":     now,
+		}).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&model.Token{}).
+			Where("channel_id = ? AND status = ?", id, model.TokenStatusEnabled).
+			Update("status", model.TokenStatusDisabled)
+		tokensDisabled = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := model.UpdateGroupModelRouteStatus(id, false); err != nil {
+		logger.SysError("failed to update ability status: " + err.Error())
+	}
+	logger.SysLogf("channel soft deleted: channel_id=%s tokens_disabled=%d", id, tokensDisabled)
+	return tokensDisabled, nil
+}
+
+// PurgeSoftDeleted permanently removes soft-deleted channels whose retention
+// window has passed, together with their consume logs.
+func PurgeSoftDeleted(now int64) (int64, error) {
+	channelIDs := make([]string, 0)
+	if err := model.DB.Model(&model.Channel{}).
+		Where("status = ? AND hard_delete_at > 0 AND hard_delete_at <= ?", model.ChannelStatusDeleted, now).
+		Pluck("id", &channelIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(channelIDs) == 0 {
+		return 0, nil
+	}
+	if err := model.LOG_DB.Where("channel_id IN ?", channelIDs).Delete(&model.Log{}).Error; err != nil {
+		return 0, err
+	}
+	return deleteChannelsByQuery(model.DB.Where("id IN ?", channelIDs))
+}
+
+// BulkUpdate applies weight/priority/status changes to several channels in a
+// single transaction. Any missing or concurrently modified channel rolls back the batch.
+func BulkUpdate(items []model.ChannelBulkUpdate) ([]model.ChannelBulkUpdateResult, error) {
+	now := helper.GetTimestamp()
+	results := make([]model.ChannelBulkUpdateResult, 0, len(items))
+	statusChanged := make(map[string]int)
+	err := model.DB.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			id := strings.TrimSpace(item.Id)
+			channel := model.Channel{}
+			if err := tx.Omit("key").First(&channel, "id = ?", id).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("渠道不存在：%s", id)
+				}
+				return err
+			}
+			if channel.Status == model.ChannelStatusDeleted {
+				return fmt.Errorf("渠道已删除：%s", id)
+			}
+			updates := map[string]any{"updated_at": now}
+			fields := make([]string, 0, 3)
+			if item.Weight != nil {
+				updates["weight"] = *item.Weight
+				fields = append(fields, "weight")
+			}
+			if item.Priority != nil {
+				updates["priority"] = *item.Priority
+				fields = append(fields, "priority")
+			}
+			if item.Status != nil {
+				updates["status"] = *item.Status
+				fields = append(fields, "status")
+				statusChanged[id] = *item.Status
+			}
+			if len(fields) == 0 {
+				return fmt.Errorf("渠道 %s 未指定需要更新的字段", id)
+			}
+			query := tx.Model(&model.Channel{}).Where("id = ?", id)
+			if item.UpdatedAt > 0 {
+				query = query.Where("updated_at = ?", item.UpdatedAt)
+			}
+			result := query.Updates(updates)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("渠道 %s 已被其他操作修改，请刷新后重试", id)
+			}
+			results = append(results, model.ChannelBulkUpdateResult{
+				Id:            id,
+				UpdatedFields: fields,
+				UpdatedAt:     now,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for id, status := range statusChanged {
+		if err := model.UpdateGroupModelRouteStatus(id, status == model.ChannelStatusEnabled); err != nil {
+			logger.SysError("failed to update ability status: " + err.Error())
+		}
+	}
+	return results, nil
+}
+
 func DeleteDisabled() (int64, error) {
 	return deleteChannelsByQuery(model.DB.Where("status = ? or status = ?", model.ChannelStatusAutoDisabled, model.ChannelStatusManuallyDisabled))
 }
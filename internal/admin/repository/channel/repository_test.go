@@ -0,0 +1,259 @@
+package channel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// dryRunConnPool lets a dry-run database open transactions; no statement
+// reaches it.
+type dryRunConnPool struct{}
+
+func (dryRunConnPool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, sql.ErrConnDone
+}
+
+func (dryRunConnPool) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, sql.ErrConnDone
+}
+
+func (dryRunConnPool) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, sql.ErrConnDone
+}
+
+func (dryRunConnPool) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+func (pool dryRunConnPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return &dryRunTx{pool}, nil
+}
+
+type dryRunTx struct{ dryRunConnPool }
+
+func (*dryRunTx) Commit() error   { return nil }
+func (*dryRunTx) Rollback() error { return nil }
+
+// channelDB is a dry-run database that serves channel for channel lookups and
+// channelIDs for id plucks, and records every statement it is sent.
+type channelDB struct {
+	channel    model.Channel
+	channelIDs []string
+	// tokensDisabled and channelsUpdated are reported as the rows affected
+	// by token and channel updates
+	tokensDisabled  int64
+	channelsUpdated int64
+	statements     []string
+	routeStatus    map[string]bool
+}
+
+func useChannelDB(t *testing.T, fake *channelDB) {
+	t.Helper()
+	prevDB, prevLogDB := model.DB, model.LOG_DB
+	t.Cleanup(func() { model.DB, model.LOG_DB = prevDB, prevLogDB })
+	// the dialector has no savepoints, so nested transactions join the outer one
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true, ConnPool: dryRunConnPool{}, DisableNestedTransaction: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	record := func(tx *gorm.DB) {
+		fake.statements = append(fake.statements, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}
+	_ = db.Callback().Query().After("gorm:query").Register("test:rows", func(tx *gorm.DB) {
+		record(tx)
+		switch dest := tx.Statement.Dest.(type) {
+		case *model.Channel:
+			*dest = fake.channel
+		case *[]string:
+			*dest = append([]string(nil), fake.channelIDs...)
+		}
+	})
+	_ = db.Callback().Update().After("gorm:update").Register("test:record", func(tx *gorm.DB) {
+		record(tx)
+		switch tx.Statement.Table {
+		case model.APITokensTableName:
+			tx.RowsAffected = fake.tokensDisabled
+		case "channels":
+			tx.RowsAffected = fake.channelsUpdated
+		}
+	})
+	_ = db.Callback().Delete().After("gorm:delete").Register("test:record", func(tx *gorm.DB) {
+		record(tx)
+		if tx.Statement.Table == "channels" {
+			tx.RowsAffected = int64(len(fake.channelIDs))
+		}
+	})
+	model.DB = db
+	model.LOG_DB = db
+
+	fake.routeStatus = map[string]bool{}
+	model.BindGroupModelRouteRepository(model.GroupModelRouteRepository{
+		GetRandomSatisfiedChannel: func(group string, modelName string, ignoreFirstPriority bool) (*model.Channel, error) {
+			return nil, nil
+		},
+		ListSatisfiedChannels: func(group string, modelName string) ([]*model.Channel, error) { return nil, nil },
+		UpdateGroupModelRouteStatus: func(channelId string, status bool) error {
+			fake.routeStatus[channelId] = status
+			return nil
+		},
+	})
+}
+
+// statement returns the first recorded statement starting with prefix.
+func (fake *channelDB) statement(t *testing.T, prefix string) string {
+	t.Helper()
+	for _, stmt := range fake.statements {
+		if strings.HasPrefix(stmt, prefix) {
+			return stmt
+		}
+	}
+	t.Fatalf("statements = %q, want one starting with %s", fake.statements, prefix)
+	return ""
+}
+
+func (fake *channelDB) writes() []string {
+	writes := []string{}
+	for _, stmt := range fake.statements {
+		if strings.HasPrefix(stmt, "UPDATE") || strings.HasPrefix(stmt, "DELETE") {
+			writes = append(writes, stmt)
+		}
+	}
+	return writes
+}
+
+func TestSoftDeleteByID_DisablesBoundTokens(t *testing.T) {
+	fake := &channelDB{
+		channel:        model.Channel{Id: "soft-delete", Status: model.ChannelStatusEnabled},
+		tokensDisabled: 2,
+	}
+	useChannelDB(t, fake)
+
+	disabled, err := SoftDeleteByID(" soft-delete ")
+	if err != nil || disabled != 2 {
+		t.Fatalf("SoftDeleteByID = %d, %v, want 2 disabled tokens", disabled, err)
+	}
+	channelUpdate := fake.statement(t, "UPDATE `channels`")
+	var hardDeleteAt, updatedAt int64
+	if _, err := fmt.Sscanf(channelUpdate, "UPDATE `channels` SET `hard_delete_at`=%d,`status`=5,`updated_at`=%d WHERE id = \"soft-delete\"", &hardDeleteAt, &updatedAt); err != nil {
+		t.Fatalf("channel update = %q, want status 5 for the channel: %v", channelUpdate, err)
+	}
+	if hardDeleteAt-updatedAt != model.ChannelHardDeleteRetentionSeconds {
+		t.Fatalf("hard_delete_at = %d, want %d seconds after %d", hardDeleteAt, model.ChannelHardDeleteRetentionSeconds, updatedAt)
+	}
+	tokenUpdate := fake.statement(t, "UPDATE `"+model.APITokensTableName+"`")
+	if !strings.Contains(tokenUpdate, "`status`=2") || !strings.Contains(tokenUpdate, `channel_id = "soft-delete" AND status = 1`) {
+		t.Fatalf("token update = %q, want the channel's enabled tokens disabled", tokenUpdate)
+	}
+	if enabled, ok := fake.routeStatus["soft-delete"]; !ok || enabled {
+		t.Fatalf("route status = %v, want the channel's routes disabled", fake.routeStatus)
+	}
+	for _, stmt := range fake.writes() {
+		if strings.HasPrefix(stmt, "DELETE") {
+			t.Fatalf("soft delete removed rows: %q", stmt)
+		}
+	}
+}
+
+func TestSoftDeleteByID_RejectsDeletedChannel(t *testing.T) {
+	fake := &channelDB{channel: model.Channel{Id: "soft-deleted", Status: model.ChannelStatusDeleted}}
+	useChannelDB(t, fake)
+
+	if _, err := SoftDeleteByID("soft-deleted"); err == nil {
+		t.Fatalf("SoftDeleteByID succeeded for a deleted channel")
+	}
+	if writes := fake.writes(); len(writes) != 0 {
+		t.Fatalf("writes = %q, want none", writes)
+	}
+}
+
+func TestPurgeSoftDeleted_RemovesChannelsAndLogs(t *testing.T) {
+	fake := &channelDB{channelIDs: []string{"purge-a", "purge-b"}}
+	useChannelDB(t, fake)
+
+	if purged, err := PurgeSoftDeleted(1000); err != nil || purged != 2 {
+		t.Fatalf("PurgeSoftDeleted = %d, %v, want 2 purged", purged, err)
+	}
+	if due := fake.statement(t, "SELECT"); !strings.Contains(due, "status = 5 AND hard_delete_at > 0 AND hard_delete_at <= 1000") {
+		t.Fatalf("due query = %q, want soft-deleted channels past their retention", due)
+	}
+	if logDelete := fake.statement(t, "DELETE FROM `"+model.EventLogsTableName+"`"); !strings.Contains(logDelete, `channel_id IN ("purge-a","purge-b")`) {
+		t.Fatalf("log delete = %q, want the purged channels' logs", logDelete)
+	}
+	if channelDelete := fake.statement(t, "DELETE FROM `channels`"); !strings.Contains(channelDelete, `id IN ("purge-a","purge-b")`) {
+		t.Fatalf("channel delete = %q, want the purged channels", channelDelete)
+	}
+}
+
+func TestPurgeSoftDeleted_NothingDue(t *testing.T) {
+	fake := &channelDB{}
+	useChannelDB(t, fake)
+
+	if purged, err := PurgeSoftDeleted(1000); err != nil || purged != 0 {
+		t.Fatalf("PurgeSoftDeleted = %d, %v, want nothing purged", purged, err)
+	}
+	if writes := fake.writes(); len(writes) != 0 {
+		t.Fatalf("writes = %q, want none", writes)
+	}
+}
+
+func TestBulkUpdate_AppliesChangesWithOptimisticLock(t *testing.T) {
+	fake := &channelDB{channel: model.Channel{Id: "bulk", Status: model.ChannelStatusEnabled}, channelsUpdated: 1}
+	useChannelDB(t, fake)
+
+	weight, status := uint(7), model.ChannelStatusManuallyDisabled
+	priority := int64(3)
+	results, err := BulkUpdate([]model.ChannelBulkUpdate{
+		{Id: "bulk-a", Weight: &weight, UpdatedAt: 42},
+		{Id: "bulk-b", Priority: &priority, Status: &status},
+	})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("BulkUpdate = %+v, %v, want two results", results, err)
+	}
+	if fields := strings.Join(results[0].UpdatedFields, ","); fields != "weight" {
+		t.Fatalf("bulk-a updated fields = %s, want weight", fields)
+	}
+	if fields := strings.Join(results[1].UpdatedFields, ","); fields != "priority,status" {
+		t.Fatalf("bulk-b updated fields = %s, want priority,status", fields)
+	}
+	updates := fake.writes()
+	if len(updates) != 2 {
+		t.Fatalf("writes = %q, want one update per channel", updates)
+	}
+	if !strings.Contains(updates[0], "`weight`=7") || !strings.Contains(updates[0], `id = "bulk-a" AND updated_at = 42`) {
+		t.Fatalf("bulk-a update = %q, want the weight guarded by updated_at", updates[0])
+	}
+	if strings.Contains(updates[1], "updated_at =") || !strings.Contains(updates[1], "`priority`=3") || !strings.Contains(updates[1], "`status`=2") {
+		t.Fatalf("bulk-b update = %q, want priority and status without a lock", updates[1])
+	}
+	if enabled, ok := fake.routeStatus["bulk-b"]; !ok || enabled || len(fake.routeStatus) != 1 {
+		t.Fatalf("route status = %v, want only bulk-b's routes disabled", fake.routeStatus)
+	}
+}
+
+func TestBulkUpdate_RollsBackOnConflict(t *testing.T) {
+	weight := uint(1)
+	conflicts := map[string]*channelDB{
+		"concurrent change": {channel: model.Channel{Id: "bulk", Status: model.ChannelStatusEnabled}},
+		"deleted channel":   {channel: model.Channel{Id: "bulk", Status: model.ChannelStatusDeleted}, channelsUpdated: 1},
+	}
+	for name, fake := range conflicts {
+		t.Run(name, func(t *testing.T) {
+			useChannelDB(t, fake)
+			status := model.ChannelStatusEnabled
+			if _, err := BulkUpdate([]model.ChannelBulkUpdate{{Id: "bulk", Weight: &weight, Status: &status, UpdatedAt: 42}}); err == nil {
+				t.Fatalf("BulkUpdate succeeded, want an error")
+			}
+			if len(fake.routeStatus) != 0 {
+				t.Fatalf("route status = %v, want no route changes after a rollback", fake.routeStatus)
+			}
+		})
+	}
+}
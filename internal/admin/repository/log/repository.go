@@ -10,6 +10,7 @@ import (
 	"github.com/yeying-community/router/common/logger"
 	"github.com/yeying-community/router/common/random"
 	"github.com/yeying-community/router/internal/admin/model"
+	"gorm.io/gorm"
 )
 
 func hydrateLogsWithChannelNames(logs []*model.Log) error {
@@ -205,6 +206,26 @@ func GetAll(logType int, startTimestamp int64, endTimestamp int64, modelName str
 	return logs, err
 }
 
+// StreamConsumeLogs walks consume logs in [startTimestamp, endTimestamp] batch by
+// batch (keyset-paginated on the primary key by FindInBatches), handing each
+// batch to fn. It stops when ctx is cancelled or fn returns an error.
+func StreamConsumeLogs(ctx context.Context, startTimestamp int64, endTimestamp int64, batchSize int, fn func([]*model.Log) error) error {
+	tx := model.LOG_DB.WithContext(ctx).Where("type = ?", model.LogTypeConsume)
+	if startTimestamp != 0 {
+		tx = tx.Where("created_at >= ?", startTimestamp)
+	}
+	if endTimestamp != 0 {
+		tx = tx.Where("created_at <= ?", endTimestamp)
+	}
+	var records []*model.Log
+	return tx.FindInBatches(&records, batchSize, func(batch *gorm.DB, _ int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(records)
+	}).Error
+}
+
 func GetUser(userId string, logType int, startTimestamp int64, endTimestamp int64, modelName string, tokenName string, startIdx int, num int) ([]*model.Log, error) {
 	var tx = model.LOG_DB
 	if logType == model.LogTypeAll {
@@ -5,6 +5,7 @@ import "github.com/yeying-community/router/internal/admin/model"
 func init() {
 	model.BindOptionRepository(model.OptionRepository{
 		AllOption:    All,
+		GetOption:    Get,
 		UpdateOption: Update,
 	})
 }
@@ -15,6 +16,14 @@ func All() ([]*model.Option, error) {
 	return options, err
 }
 
+func Get(key string) (*model.Option, error) {
+	var option model.Option
+	if err := model.DB.Where("key = ?", key).First(&option).Error; err != nil {
+		return nil, err
+	}
+	return &option, nil
+}
+
 func Update(key string, value string) error {
 	option := model.Option{Key: key}
 	model.DB.FirstOrCreate(&option, model.Option{Key: key})
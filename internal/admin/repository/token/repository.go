@@ -144,7 +144,7 @@ func Create(token *model.Token) error {
 }
 
 func Update(token *model.Token) error {
-	return model.DB.Model(token).Select("name", "status", "expired_time", "remain_quota", "unlimited_quota", "models", "subnet").Updates(token).Error
+	return model.DB.Model(token).Select("name", "status", "expired_time", "remain_quota", "unlimited_quota", "models", "subnet", "channel_id").Updates(token).Error
 }
 
 func SelectUpdate(token *model.Token) error {
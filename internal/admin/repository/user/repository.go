@@ -44,6 +44,7 @@ func init() {
 		IsOidcIdAlreadyTaken:                     IsOidcIdAlreadyTaken,
 		IsWalletAddressAlreadyTaken:              IsWalletAddressAlreadyTaken,
 		IsUsernameAlreadyTaken:                   IsUsernameAlreadyTaken,
+		IsDisplayNameAlreadyTaken:                IsDisplayNameAlreadyTaken,
 		ResetUserPasswordByEmail:                 ResetUserPasswordByEmail,
 		IsAdmin:                                  IsAdmin,
 		IsUserEnabled:                            IsUserEnabled,
@@ -62,6 +63,7 @@ func init() {
 		UpdateUserUsedQuotaDirect:                UpdateUsedQuotaDirect,
 		UpdateUserRequestCountDirect:             UpdateRequestCountDirect,
 		GetUsernameById:                          GetUsernameById,
+		UpdateUserLastLoginAt:                    UpdateLastLoginAt,
 	})
 }
 
@@ -90,6 +92,37 @@ func GetAll(startIdx int, num int, order string) ([]*model.User, error) {
 	return users, err
 }
 
+func GetAllByStatus(status int, startIdx int, num int) ([]*model.User, int64, error) {
+	query := model.DB.Model(&model.User{}).Where("status = ?", status)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []*model.User
+	err := query.Omit("password").Order("created_at desc").Limit(num).Offset(startIdx).Find(&users).Error
+	return users, total, err
+}
+
+// UpdateStatus changes a user's status; clearWallet also unbinds the wallet
+// address so it can be registered again.
+func UpdateStatus(id string, status int, clearWallet bool) error {
+	updates := map[string]any{"status": status}
+	if clearWallet {
+		updates["wallet_address"] = nil
+	}
+	err := model.DB.Model(&model.User{}).Where("id = ?", strings.TrimSpace(id)).Updates(updates).Error
+	if err == nil && clearWallet {
+		err = model.DeleteUserWalletsWithDB(model.DB, id)
+	}
+	if err == nil && status != model.UserStatusEnabled {
+		if revokeErr := common.RevokeWalletJWTsForUser(id); revokeErr != nil {
+			logger.SysErrorf("revoke wallet tokens for user %s failed: %v", id, revokeErr)
+		}
+	}
+	model.InvalidateUserCache(id)
+	return err
+}
+
 func Search(keyword string) ([]*model.User, error) {
 	var users []*model.User
 	trimmedKeyword := strings.TrimSpace(keyword)
@@ -180,8 +213,8 @@ func Create(ctx context.Context, user *model.User, inviterId string) error {
 		if trimmed == "" {
 			user.WalletAddress = nil
 		} else {
-			lower := strings.ToLower(trimmed)
-			user.WalletAddress = &lower
+			normalized := model.NormalizeWalletAddress(trimmed)
+			user.WalletAddress = &normalized
 		}
 	}
 	if strings.TrimSpace(user.Id) == "" {
@@ -210,6 +243,22 @@ func Create(ctx context.Context, user *model.User, inviterId string) error {
 	if result.Error != nil {
 		return result.Error
 	}
+	if user.WalletAddress != nil {
+		walletType := user.WalletType
+		if walletType == "" {
+			walletType = common.WalletTypeEthereum
+		}
+		if err := model.DB.Create(&model.UserWallet{
+			Address:    *user.WalletAddress,
+			UserId:     user.Id,
+			WalletType: walletType,
+			CreatedAt:  user.CreatedAt,
+		}).Error; err != nil {
+			logger.SysError(fmt.Sprintf("bind wallet for user %s failed: %s", user.Id, err.Error()))
+		}
+		model.InvalidateWalletUserCache(*user.WalletAddress)
+	}
+	model.InvalidateUserCache(user.Id)
 	if newUserRewardQuota > 0 {
 		model.RecordLog(ctx, user.Id, model.LogTypeSystem, fmt.Sprintf("新用户注册赠送 %s", common.LogQuota(newUserRewardQuota)))
 	}
@@ -255,8 +304,8 @@ func Update(user *model.User, updatePassword bool) error {
 		if trimmed == "" {
 			user.WalletAddress = nil
 		} else {
-			lower := strings.ToLower(trimmed)
-			user.WalletAddress = &lower
+			normalized := model.NormalizeWalletAddress(trimmed)
+			user.WalletAddress = &normalized
 		}
 	}
 	if strings.TrimSpace(user.Group) != "" {
@@ -272,6 +321,9 @@ func Update(user *model.User, updatePassword bool) error {
 	user.QuotaResetTimezone = model.NormalizeUserQuotaResetTimezoneForWrite(user.QuotaResetTimezone)
 	if user.Status == model.UserStatusDisabled {
 		blacklist.BanUser(user.Id)
+		if err := common.RevokeWalletJWTsForUser(user.Id); err != nil {
+			logger.SysErrorf("revoke wallet tokens for user %s failed: %v", user.Id, err)
+		}
 	} else if user.Status == model.UserStatusEnabled {
 		blacklist.UnbanUser(user.Id)
 	}
@@ -290,10 +342,19 @@ func Update(user *model.User, updatePassword bool) error {
 		updates["password"] = user.Password
 		updates["has_password"] = true
 	}
+	var previous model.User
 	if user.WalletAddress != nil {
 		updates["wallet_address"] = user.WalletAddress
+		if err := model.DB.Select("wallet_address").Where("id = ?", user.Id).First(&previous).Error; err != nil {
+			previous.WalletAddress = nil
+		}
+	}
+	err = model.DB.Model(&model.User{}).Where("id = ?", user.Id).Updates(updates).Error
+	model.InvalidateUserCache(user.Id)
+	if err == nil && user.WalletAddress != nil {
+		model.RevokeOnWalletAddressChange(user.Id, previous.WalletAddress, user.WalletAddress)
 	}
-	return model.DB.Model(&model.User{}).Where("id = ?", user.Id).Updates(updates).Error
+	return err
 }
 
 func Delete(user *model.User) error {
@@ -301,6 +362,9 @@ func Delete(user *model.User) error {
 		return errors.New("id 为空！")
 	}
 	blacklist.BanUser(user.Id)
+	if err := common.RevokeWalletJWTsForUser(user.Id); err != nil {
+		logger.SysErrorf("revoke wallet tokens for user %s failed: %v", user.Id, err)
+	}
 	user.Username = fmt.Sprintf("deleted_%s", random.GetUUID())
 	user.Status = model.UserStatusDeleted
 	user.WalletAddress = nil
@@ -310,7 +374,13 @@ func Delete(user *model.User) error {
 		"wallet_address": nil,
 		"updated_at":     helper.GetTimestamp(),
 	}).Error
+	model.InvalidateUserCache(user.Id)
 	model.DB.Where("user_id = ?", user.Id).Delete(&model.Token{})
+	_ = model.DeleteUserWalletsWithDB(model.DB, user.Id)
+	_ = model.RevokeUserRefreshTokens(user.Id)
+	model.DB.Where("user_id = ?", user.Id).Delete(&model.ApiKey{})
+	model.DB.Where("user_id = ?", user.Id).Delete(&model.WebAuthnCredential{})
+	model.DB.Where("user_id = ?", user.Id).Delete(&model.WalletLoginHistory{})
 	return err
 }
 
@@ -337,7 +407,13 @@ func FillByID(user *model.User) error {
 	if strings.TrimSpace(user.Id) == "" {
 		return errors.New("id 为空！")
 	}
-	model.DB.Where(model.User{Id: user.Id}).First(user)
+	if cached, ok := model.CacheGetUserById(user.Id); ok {
+		*user = cached
+		return nil
+	}
+	if err := model.DB.Where(model.User{Id: user.Id}).First(user).Error; err == nil {
+		model.CacheSetUser(user)
+	}
 	return nil
 }
 
@@ -393,7 +469,15 @@ func FillByWalletAddress(user *model.User) error {
 	if user.WalletAddress == nil || *user.WalletAddress == "" {
 		return errors.New("wallet address 为空！")
 	}
-	model.DB.Where(model.User{WalletAddress: user.WalletAddress}).First(user)
+	if model.DB.Where(model.User{WalletAddress: user.WalletAddress}).First(user).Error == nil {
+		return nil
+	}
+	// not a primary address; look it up among the secondary wallets
+	wallet, err := model.GetUserWalletByAddress(*user.WalletAddress)
+	if err != nil {
+		return nil
+	}
+	model.DB.Where("id = ?", wallet.UserId).First(user)
 	return nil
 }
 
@@ -421,13 +505,22 @@ func IsWalletAddressAlreadyTaken(address string) bool {
 	if address == "" {
 		return false
 	}
-	return model.DB.Where("wallet_address = ?", address).Find(&model.User{}).RowsAffected == 1
+	if model.DB.Where("wallet_address = ?", address).Find(&model.User{}).RowsAffected == 1 {
+		return true
+	}
+	return model.DB.Where("address = ?", model.NormalizeWalletAddress(address)).Find(&model.UserWallet{}).RowsAffected == 1
 }
 
 func IsUsernameAlreadyTaken(username string) bool {
 	return model.DB.Where("username = ?", username).Find(&model.User{}).RowsAffected == 1
 }
 
+func IsDisplayNameAlreadyTaken(name string) bool {
+	var count int64
+	model.DB.Model(&model.User{}).Where("display_name = ?", name).Count(&count)
+	return count > 0
+}
+
 func ResetUserPasswordByEmail(email string, password string) error {
 	if email == "" || password == "" {
 		return errors.New("邮箱地址或密码为空！")
@@ -436,10 +529,17 @@ func ResetUserPasswordByEmail(email string, password string) error {
 	if err != nil {
 		return err
 	}
+	var ids []string
+	if err := model.DB.Model(&model.User{}).Where("email = ?", email).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
 	err = model.DB.Model(&model.User{}).Where("email = ?", email).Updates(map[string]any{
 		"password":   hashedPassword,
 		"updated_at": helper.GetTimestamp(),
 	}).Error
+	for _, id := range ids {
+		model.InvalidateUserCache(id)
+	}
 	return err
 }
 
@@ -517,7 +617,9 @@ func IncreaseQuota(id string, quota int64) error {
 }
 
 func IncreaseQuotaDirect(id string, quota int64) error {
-	return model.DB.Model(&model.User{}).Where("id = ?", id).Update("quota", gorm.Expr("quota + ?", quota)).Error
+	err := model.DB.Model(&model.User{}).Where("id = ?", id).Update("quota", gorm.Expr("quota + ?", quota)).Error
+	model.InvalidateUserCache(id)
+	return err
 }
 
 func DecreaseQuota(id string, quota int64) error {
@@ -532,7 +634,9 @@ func DecreaseQuota(id string, quota int64) error {
 }
 
 func DecreaseQuotaDirect(id string, quota int64) error {
-	return model.DB.Model(&model.User{}).Where("id = ?", id).Update("quota", gorm.Expr("quota - ?", quota)).Error
+	err := model.DB.Model(&model.User{}).Where("id = ?", id).Update("quota", gorm.Expr("quota - ?", quota)).Error
+	model.InvalidateUserCache(id)
+	return err
 }
 
 func GetRootEmail() string {
@@ -557,6 +661,7 @@ func UpdateUsedQuotaAndRequestCountDirect(id string, quota int64, count int) {
 			"request_count": gorm.Expr("request_count + ?", count),
 		},
 	).Error
+	model.InvalidateUserCache(id)
 	if err != nil {
 		logger.SysError("failed to update user used quota and request count: " + err.Error())
 	}
@@ -568,6 +673,7 @@ func UpdateUsedQuotaDirect(id string, quota int64) {
 			"used_quota": gorm.Expr("used_quota + ?", quota),
 		},
 	).Error
+	model.InvalidateUserCache(id)
 	if err != nil {
 		logger.SysError("failed to update user used quota: " + err.Error())
 	}
@@ -575,6 +681,7 @@ func UpdateUsedQuotaDirect(id string, quota int64) {
 
 func UpdateRequestCountDirect(id string, count int) {
 	err := model.DB.Model(&model.User{}).Where("id = ?", id).Update("request_count", gorm.Expr("request_count + ?", count)).Error
+	model.InvalidateUserCache(id)
 	if err != nil {
 		logger.SysError("failed to update user request count: " + err.Error())
 	}
@@ -586,6 +693,12 @@ func GetUsernameById(id string) string {
 	return username
 }
 
+func UpdateLastLoginAt(id string, timestamp int64) error {
+	err := model.DB.Model(&model.User{}).Where("id = ?", strings.TrimSpace(id)).Update("last_login_at", timestamp).Error
+	model.InvalidateUserCache(id)
+	return err
+}
+
 func AccessTokenExists(token string) (bool, error) {
 	var user model.User
 	err := model.DB.Where("access_token = ?", token).First(&user).Error
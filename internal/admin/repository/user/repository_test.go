@@ -0,0 +1,48 @@
+package user
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+func TestFillByID_FreshAfterWrite(t *testing.T) {
+	prevDB := model.DB
+	defer func() { model.DB = prevDB }()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	model.DB = db
+
+	writes := []struct {
+		name  string
+		write func(id string)
+	}{
+		{"IncreaseQuotaDirect", func(id string) { _ = IncreaseQuotaDirect(id, 5) }},
+		{"DecreaseQuotaDirect", func(id string) { _ = DecreaseQuotaDirect(id, 5) }},
+		{"UpdateUsedQuotaAndRequestCountDirect", func(id string) { UpdateUsedQuotaAndRequestCountDirect(id, 5, 1) }},
+		{"UpdateUsedQuotaDirect", func(id string) { UpdateUsedQuotaDirect(id, 5) }},
+		{"UpdateRequestCountDirect", func(id string) { UpdateRequestCountDirect(id, 1) }},
+		{"UpdateLastLoginAt", func(id string) { _ = UpdateLastLoginAt(id, 1) }},
+	}
+	for _, tt := range writes {
+		t.Run(tt.name, func(t *testing.T) {
+			id := "fresh-" + tt.name
+			model.CacheSetUser(&model.User{Id: id, Quota: 100, RequestCount: 7})
+			tt.write(id)
+			// the dry-run DB returns no row, so a cache hit is the only way
+			// the stale values could come back
+			user := model.User{Id: id}
+			if err := FillByID(&user); err != nil {
+				t.Fatalf("FillByID error: %v", err)
+			}
+			if user.Quota == 100 || user.RequestCount == 7 {
+				t.Fatalf("FillByID returned the cached row after %s", tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package channel
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/logger"
+)
+
+const channelPurgeLoopIntervalSeconds = 60 * 60
+
+var startChannelPurgeWorkerOnce sync.Once
+
+// StartChannelPurgeWorker periodically hard-deletes soft-deleted channels whose
+// retention window has elapsed.
+func StartChannelPurgeWorker() {
+	startChannelPurgeWorkerOnce.Do(func() {
+		go runChannelPurgeWorker()
+	})
+}
+
+func runChannelPurgeWorker() {
+	logger.SysLog("[channel.purge] worker started")
+	ticker := time.NewTicker(channelPurgeLoopIntervalSeconds * time.Second)
+	defer ticker.Stop()
+
+	for {
+		runChannelPurgeOnce()
+		<-ticker.C
+	}
+}
+
+func runChannelPurgeOnce() {
+	rows, err := PurgeSoftDeleted(helper.GetTimestamp())
+	if err != nil {
+		logger.SysWarnf("[channel.purge] purge failed: %s", err.Error())
+		return
+	}
+	if rows > 0 {
+		logger.SysLogf("[channel.purge] purged channels=%d", rows)
+	}
+}
@@ -0,0 +1,41 @@
+package channel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+func TestRunChannelPurgeOnce_PurgesDueChannels(t *testing.T) {
+	prevDB := model.DB
+	defer func() { model.DB = prevDB }()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	statements := []string{}
+	_ = db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, db.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	})
+	model.DB = db
+
+	before := time.Now().Unix()
+	runChannelPurgeOnce()
+	after := time.Now().Unix()
+
+	if len(statements) != 1 {
+		t.Fatalf("statements = %q, want the due-channel query only", statements)
+	}
+	var cutoff int64
+	if _, err := fmt.Sscanf(statements[0], "SELECT `id` FROM `channels` WHERE status = 5 AND hard_delete_at > 0 AND hard_delete_at <= %d", &cutoff); err != nil {
+		t.Fatalf("statement = %q, want the soft-deleted channels past their retention: %v", statements[0], err)
+	}
+	if cutoff < before || cutoff > after {
+		t.Fatalf("cutoff = %d, want the current time between %d and %d", cutoff, before, after)
+	}
+}
@@ -13,8 +13,8 @@ func GetAllBasic(start, num int, status string, selectAll bool) ([]*model.Channe
 	return channelrepo.GetAllBasic(start, num, status, selectAll)
 }
 
-func ListPage(page int, pageSize int, keyword string) ([]*model.Channel, int64, error) {
-	return channelrepo.ListPage(page, pageSize, keyword)
+func ListPage(page int, pageSize int, keyword string, includeDeleted bool) ([]*model.Channel, int64, error) {
+	return channelrepo.ListPage(page, pageSize, keyword, includeDeleted)
 }
 
 func GetByID(id string) (*model.Channel, error) {
@@ -33,6 +33,18 @@ func DeleteByID(id string) error {
 	return channelrepo.DeleteByID(id)
 }
 
+func SoftDeleteByID(id string) (int64, error) {
+	return channelrepo.SoftDeleteByID(id)
+}
+
+func PurgeSoftDeleted(now int64) (int64, error) {
+	return channelrepo.PurgeSoftDeleted(now)
+}
+
+func BulkUpdate(items []model.ChannelBulkUpdate) ([]model.ChannelBulkUpdateResult, error) {
+	return channelrepo.BulkUpdate(items)
+}
+
 func DeleteDisabled() (int64, error) {
 	return channelrepo.DeleteDisabled()
 }
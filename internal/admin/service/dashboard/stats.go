@@ -0,0 +1,171 @@
+package dashboard
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/admin/monitor"
+)
+
+const statsRefreshIntervalSeconds = 30
+
+// Stats is the admin dashboard snapshot served by GET /api/v1/admin/stats/dashboard.
+type Stats struct {
+	UsersTotal              int64   `json:"users_total"`
+	UsersEnabled            int64   `json:"users_enabled"`
+	UsersNewToday           int64   `json:"users_new_today"`
+	WalletLoginsToday       int64   `json:"wallet_logins_today"`
+	WalletLoginsFailedToday int64   `json:"wallet_logins_failed_today"`
+	RelayRequestsToday      int64   `json:"relay_requests_today"`
+	RelayErrorsToday        int64   `json:"relay_errors_today"`
+	RelayTokensToday        int64   `json:"relay_tokens_today"`
+	ActiveChannels          int64   `json:"active_channels"`
+	DisabledChannels        int64   `json:"disabled_channels"`
+	TotalCostCentsToday     int64   `json:"total_cost_cents_today"`
+	CacheHitRate            float64 `json:"cache_hit_rate"`
+	AvgRelayLatencyMsP50    float64 `json:"avg_relay_latency_ms_p50"`
+	AvgRelayLatencyMsP99    float64 `json:"avg_relay_latency_ms_p99"`
+	GeneratedAt             int64   `json:"generated_at"`
+}
+
+var (
+	statsSnapshot             atomic.Pointer[Stats]
+	startStatsWorkerOnce      sync.Once
+	refreshStatsSnapshotMutex sync.Mutex
+)
+
+// StartStatsWorker refreshes the dashboard stats snapshot in the background.
+func StartStatsWorker() {
+	startStatsWorkerOnce.Do(func() {
+		go runStatsWorker()
+	})
+}
+
+func runStatsWorker() {
+	logger.SysLog("[dashboard.stats] worker started")
+	ticker := time.NewTicker(statsRefreshIntervalSeconds * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if _, err := RefreshStats(); err != nil {
+			logger.SysWarnf("[dashboard.stats] refresh failed: %s", err.Error())
+		}
+		<-ticker.C
+	}
+}
+
+// GetStats returns the cached snapshot, computing it on demand when the worker
+// has not produced one yet or it is older than the refresh interval.
+func GetStats() (*Stats, error) {
+	if snapshot := statsSnapshot.Load(); snapshot != nil && helper.GetTimestamp()-snapshot.GeneratedAt < statsRefreshIntervalSeconds {
+		return snapshot, nil
+	}
+	return RefreshStats()
+}
+
+// RefreshStats recomputes the snapshot and publishes it.
+func RefreshStats() (*Stats, error) {
+	refreshStatsSnapshotMutex.Lock()
+	defer refreshStatsSnapshotMutex.Unlock()
+	if snapshot := statsSnapshot.Load(); snapshot != nil && helper.GetTimestamp()-snapshot.GeneratedAt < 1 {
+		return snapshot, nil
+	}
+	stats, err := computeStats(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	statsSnapshot.Store(stats)
+	return stats, nil
+}
+
+type relayTodayRow struct {
+	Requests   int64   `gorm:"column:requests"`
+	Tokens     int64   `gorm:"column:tokens"`
+	Quota      int64   `gorm:"column:quota"`
+	LatencyP50 float64 `gorm:"column:latency_p50"`
+	LatencyP99 float64 `gorm:"column:latency_p99"`
+}
+
+func computeStats(now time.Time) (*Stats, error) {
+	year, month, day := now.Date()
+	todayStart := time.Date(year, month, day, 0, 0, 0, 0, now.Location()).Unix()
+	stats := &Stats{
+		WalletLoginsFailedToday: monitor.WalletLoginFailuresToday(),
+		RelayErrorsToday:        monitor.RelayErrorsToday(),
+		GeneratedAt:             now.Unix(),
+	}
+	if cacheStats := model.GetUserCacheStats(); cacheStats.Hits+cacheStats.Misses > 0 {
+		stats.CacheHitRate = float64(cacheStats.Hits) / float64(cacheStats.Hits+cacheStats.Misses)
+	}
+
+	var relay relayTodayRow
+	queries := []func() error{
+		func() error {
+			return model.DB.Model(&model.User{}).Where("status <> ?", model.UserStatusDeleted).Count(&stats.UsersTotal).Error
+		},
+		func() error {
+			return model.DB.Model(&model.User{}).Where("status = ?", model.UserStatusEnabled).Count(&stats.UsersEnabled).Error
+		},
+		func() error {
+			return model.DB.Model(&model.User{}).Where("created_at >= ? AND status <> ?", todayStart, model.UserStatusDeleted).Count(&stats.UsersNewToday).Error
+		},
+		func() error {
+			// last_login_at is only written by wallet logins, so this counts distinct wallet users today.
+			return model.DB.Model(&model.User{}).Where("last_login_at >= ?", todayStart).Count(&stats.WalletLoginsToday).Error
+		},
+		func() error {
+			return model.DB.Model(&model.Channel{}).Where("status = ?", model.ChannelStatusEnabled).Count(&stats.ActiveChannels).Error
+		},
+		func() error {
+			return model.DB.Model(&model.Channel{}).Where("status IN ?", []int{model.ChannelStatusManuallyDisabled, model.ChannelStatusAutoDisabled}).Count(&stats.DisabledChannels).Error
+		},
+		func() error {
+			return model.LOG_DB.Table(model.EventLogsTableName).
+				Select(`COUNT(*) AS requests,
+					COALESCE(SUM(prompt_tokens + completion_tokens), 0) AS tokens,
+					COALESCE(SUM(quota), 0) AS quota,
+					COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY elapsed_time), 0) AS latency_p50,
+					COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY elapsed_time), 0) AS latency_p99`).
+				Where("type = ? AND created_at >= ?", model.LogTypeConsume, todayStart).
+				Scan(&relay).Error
+		},
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	wg.Add(len(queries))
+	for _, query := range queries {
+		go func(query func() error) {
+			defer wg.Done()
+			if err := query(); err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				errMu.Unlock()
+			}
+		}(query)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	stats.RelayRequestsToday = relay.Requests
+	stats.RelayTokensToday = relay.Tokens
+	stats.AvgRelayLatencyMsP50 = relay.LatencyP50
+	stats.AvgRelayLatencyMsP99 = relay.LatencyP99
+	if config.QuotaPerUnit > 0 {
+		stats.TotalCostCentsToday = int64(math.Round(float64(relay.Quota) / config.QuotaPerUnit * 100))
+	}
+	return stats, nil
+}
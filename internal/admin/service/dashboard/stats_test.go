@@ -0,0 +1,164 @@
+package dashboard
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// statsConn is a database/sql connection that answers the dashboard queries:
+// the relay aggregate with relay and each count with the value of the first
+// counts entry whose key the query contains. It records every query.
+type statsConn struct {
+	counts map[string]int64
+	relay  []driver.Value
+
+	mu      sync.Mutex
+	queries []statsQuery
+}
+
+type statsQuery struct {
+	sql  string
+	args []driver.NamedValue
+}
+
+func (c *statsConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *statsConn) Driver() driver.Driver                        { return nil }
+func (c *statsConn) Prepare(string) (driver.Stmt, error)          { return nil, driver.ErrSkip }
+func (c *statsConn) Close() error                                 { return nil }
+func (c *statsConn) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+
+func (c *statsConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.mu.Lock()
+	c.queries = append(c.queries, statsQuery{sql: query, args: args})
+	c.mu.Unlock()
+	if strings.Contains(query, "percentile_cont") {
+		return &statsRows{columns: []string{"requests", "tokens", "quota", "latency_p50", "latency_p99"}, values: c.relay}, nil
+	}
+	for key, count := range c.counts {
+		if strings.Contains(query, key) {
+			return &statsRows{columns: []string{"count"}, values: []driver.Value{count}}, nil
+		}
+	}
+	return &statsRows{columns: []string{"count"}, values: []driver.Value{int64(0)}}, nil
+}
+
+func (c *statsConn) recorded() []statsQuery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]statsQuery(nil), c.queries...)
+}
+
+// statsRows is a single-row result.
+type statsRows struct {
+	columns []string
+	values  []driver.Value
+	done    bool
+}
+
+func (r *statsRows) Columns() []string { return r.columns }
+func (r *statsRows) Close() error      { return nil }
+
+func (r *statsRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.values)
+	return nil
+}
+
+func useStatsDB(t *testing.T, conn *statsConn) {
+	t.Helper()
+	prevDB, prevLogDB := model.DB, model.LOG_DB
+	t.Cleanup(func() { model.DB, model.LOG_DB = prevDB, prevLogDB })
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{ConnPool: sql.OpenDB(conn)})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	model.DB = db
+	model.LOG_DB = db
+}
+
+func TestComputeStats(t *testing.T) {
+	prevQuotaPerUnit := config.QuotaPerUnit
+	defer func() { config.QuotaPerUnit = prevQuotaPerUnit }()
+	config.QuotaPerUnit = 500000
+
+	conn := &statsConn{
+		counts: map[string]int64{
+			"FROM `users` WHERE status <> ?":    40,
+			"FROM `users` WHERE status = ?":     35,
+			"created_at >= ? AND status <> ?":   4,
+			"last_login_at >= ?":                12,
+			"FROM `channels` WHERE status = ?":  6,
+			"FROM `channels` WHERE status IN (": 2,
+		},
+		relay: []driver.Value{int64(900), int64(120000), int64(1234567), float64(210), float64(1800)},
+	}
+	useStatsDB(t, conn)
+
+	now := time.Date(2026, 3, 1, 15, 4, 5, 0, time.Local)
+	stats, err := computeStats(now)
+	if err != nil {
+		t.Fatalf("computeStats error: %v", err)
+	}
+	want := Stats{
+		UsersTotal: 40, UsersEnabled: 35, UsersNewToday: 4, WalletLoginsToday: 12,
+		ActiveChannels: 6, DisabledChannels: 2,
+		RelayRequestsToday: 900, RelayTokensToday: 120000,
+		// 1234567 quota at 500000 per unit is $2.469134, rounded to cents
+		TotalCostCentsToday:  247,
+		AvgRelayLatencyMsP50: 210, AvgRelayLatencyMsP99: 1800,
+		GeneratedAt: now.Unix(),
+	}
+	// the process-wide counters are covered by the monitor package
+	stats.WalletLoginsFailedToday, stats.RelayErrorsToday, stats.CacheHitRate = 0, 0, 0
+	if *stats != want {
+		t.Fatalf("stats = %+v, want %+v", *stats, want)
+	}
+
+	todayStart := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local).Unix()
+	for _, query := range conn.recorded() {
+		if !strings.Contains(query.sql, "created_at >= ?") && !strings.Contains(query.sql, "last_login_at >= ?") {
+			continue
+		}
+		found := false
+		for _, arg := range query.args {
+			found = found || arg.Value == todayStart
+		}
+		if !found {
+			t.Fatalf("query %q args %v, want the day to start at %d", query.sql, query.args, todayStart)
+		}
+	}
+}
+
+func TestGetStats_ServesFreshSnapshot(t *testing.T) {
+	prev := statsSnapshot.Load()
+	defer statsSnapshot.Store(prev)
+	conn := &statsConn{relay: []driver.Value{int64(0), int64(0), int64(0), float64(0), float64(0)}}
+	useStatsDB(t, conn)
+
+	cached := &Stats{UsersTotal: 7, GeneratedAt: helper.GetTimestamp()}
+	statsSnapshot.Store(cached)
+	if stats, err := GetStats(); err != nil || stats != cached || len(conn.recorded()) != 0 {
+		t.Fatalf("GetStats = %+v, %v after %d queries, want the cached snapshot", stats, err, len(conn.recorded()))
+	}
+
+	statsSnapshot.Store(&Stats{UsersTotal: 7, GeneratedAt: helper.GetTimestamp() - statsRefreshIntervalSeconds})
+	if stats, err := GetStats(); err != nil || stats.UsersTotal != 0 || len(conn.recorded()) == 0 {
+		t.Fatalf("GetStats = %+v, %v, want a recomputed snapshot for a stale one", stats, err)
+	}
+}
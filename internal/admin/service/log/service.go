@@ -1,6 +1,8 @@
 package log
 
 import (
+	"context"
+
 	"github.com/yeying-community/router/internal/admin/model"
 	logrepo "github.com/yeying-community/router/internal/admin/repository/log"
 )
@@ -9,6 +11,10 @@ func GetAll(logType int, startTimestamp int64, endTimestamp int64, modelName str
 	return logrepo.GetAll(logType, startTimestamp, endTimestamp, modelName, username, tokenName, groupID, startIdx, num, channel)
 }
 
+func StreamConsumeLogs(ctx context.Context, startTimestamp int64, endTimestamp int64, batchSize int, fn func([]*model.Log) error) error {
+	return logrepo.StreamConsumeLogs(ctx, startTimestamp, endTimestamp, batchSize, fn)
+}
+
 func GetUser(userId string, logType int, startTimestamp int64, endTimestamp int64, modelName string, tokenName string, startIdx int, num int) ([]*model.Log, error) {
 	return logrepo.GetUser(userId, logType, startTimestamp, endTimestamp, modelName, tokenName, startIdx, num)
 }
@@ -0,0 +1,44 @@
+package user
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+const jwtTokenPruneLoopIntervalSeconds = 60 * 60
+
+var startJWTTokenPruneWorkerOnce sync.Once
+
+// StartJWTTokenPruneWorker periodically deletes jwt_tokens rows of wallet JWTs
+// that have expired.
+func StartJWTTokenPruneWorker() {
+	startJWTTokenPruneWorkerOnce.Do(func() {
+		go runJWTTokenPruneWorker()
+	})
+}
+
+func runJWTTokenPruneWorker() {
+	logger.SysLog("[jwt_tokens.prune] worker started")
+	ticker := time.NewTicker(jwtTokenPruneLoopIntervalSeconds * time.Second)
+	defer ticker.Stop()
+
+	for {
+		runJWTTokenPruneOnce()
+		<-ticker.C
+	}
+}
+
+func runJWTTokenPruneOnce() {
+	rows, err := model.PruneExpiredJWTTokens(helper.GetTimestamp())
+	if err != nil {
+		logger.SysWarnf("[jwt_tokens.prune] prune failed: %s", err.Error())
+		return
+	}
+	if rows > 0 {
+		logger.SysLogf("[jwt_tokens.prune] pruned tokens=%d", rows)
+	}
+}
@@ -2,7 +2,12 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/common/message"
 	"github.com/yeying-community/router/internal/admin/model"
 	userrepo "github.com/yeying-community/router/internal/admin/repository/user"
 )
@@ -15,6 +20,62 @@ func GetAll(start, num int, order string) ([]*model.User, error) {
 	return userrepo.GetAll(start, num, order)
 }
 
+func GetAllByStatus(status int, start, num int) ([]*model.User, int64, error) {
+	return userrepo.GetAllByStatus(status, start, num)
+}
+
+// ApprovePending enables a user awaiting approval and emails them when possible.
+func ApprovePending(id string) (*model.User, error) {
+	user, err := getPendingUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := userrepo.UpdateStatus(user.Id, model.UserStatusEnabled, false); err != nil {
+		return nil, err
+	}
+	user.Status = model.UserStatusEnabled
+	notifyApprovalResult(user, "账户审批通过", "<p>您好！</p><p>您的账户已通过管理员审批，现在可以使用钱包登录。</p>")
+	return user, nil
+}
+
+// RejectPending disables a user awaiting approval, unbinds the wallet and
+// emails them when possible.
+func RejectPending(id string) (*model.User, error) {
+	user, err := getPendingUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := userrepo.UpdateStatus(user.Id, model.UserStatusDisabled, true); err != nil {
+		return nil, err
+	}
+	user.Status = model.UserStatusDisabled
+	user.WalletAddress = nil
+	notifyApprovalResult(user, "账户审批未通过", "<p>您好！</p><p>您的账户注册申请未通过管理员审批。</p>")
+	return user, nil
+}
+
+func getPendingUser(id string) (*model.User, error) {
+	user, err := userrepo.GetByID(id, false)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != model.UserStatusPendingApproval {
+		return nil, errors.New("该用户不在待审批状态")
+	}
+	return user, nil
+}
+
+func notifyApprovalResult(user *model.User, subject string, content string) {
+	if strings.TrimSpace(user.Email) == "" {
+		return
+	}
+	go func() {
+		if err := message.SendEmail(subject, user.Email, message.EmailTemplate(subject, content)); err != nil {
+			logger.SysError(fmt.Sprintf("failed to send approval email to user %s: %s", user.Id, err.Error()))
+		}
+	}()
+}
+
 func Search(keyword string) ([]*model.User, error) {
 	return userrepo.Search(keyword)
 }
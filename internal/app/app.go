@@ -1,9 +1,15 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
@@ -15,21 +21,31 @@ import (
 	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/common/i18n"
 	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/common/tracing"
 	"github.com/yeying-community/router/internal/admin/controller/channel"
 	task "github.com/yeying-community/router/internal/admin/controller/task"
 	"github.com/yeying-community/router/internal/admin/model"
 	_ "github.com/yeying-community/router/internal/admin/repository/bootstrap"
 	billingsvc "github.com/yeying-community/router/internal/admin/service/billing"
+	channelsvc "github.com/yeying-community/router/internal/admin/service/channel"
+	dashboardsvc "github.com/yeying-community/router/internal/admin/service/dashboard"
 	topupsvc "github.com/yeying-community/router/internal/admin/service/topup"
+	usersvc "github.com/yeying-community/router/internal/admin/service/user"
+	"github.com/yeying-community/router/internal/relay"
 	"github.com/yeying-community/router/internal/relay/adaptor/openai"
 	"github.com/yeying-community/router/internal/transport/http/middleware"
 	"github.com/yeying-community/router/internal/transport/http/router"
 )
 
+// serverShutdownTimeout bounds how long a signalled shutdown waits for
+// in-flight requests.
+const serverShutdownTimeout = 10 * time.Second
+
 // Run starts the HTTP server.
 func Run() {
 	common.Init()
 	logger.SetupLogger()
+	common.InitSlackAlert()
 	logger.SysLogf("Router %s started", common.Version)
 	validateStartupAuthConfig()
 
@@ -47,6 +63,7 @@ func Run() {
 	if err != nil {
 		logger.FatalLog("database init error: " + err.Error())
 	}
+	model.UseJWTTokenStore()
 	defer func() {
 		err := model.CloseDB()
 		if err != nil {
@@ -59,6 +76,13 @@ func Run() {
 	if err != nil {
 		logger.FatalLog("failed to initialize Redis: " + err.Error())
 	}
+	if err := common.InitWalletNonceStore(); err != nil {
+		logger.FatalLog("failed to initialize wallet nonce store: " + err.Error())
+	}
+	// background workers that support it stop when the server returns
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	common.StartWalletNonceCleanup(ctx, common.WalletNonceCleanupInterval())
 
 	// Initialize options
 	model.InitOptionMap()
@@ -85,8 +109,12 @@ func Run() {
 		task.StartAsyncTaskWorkers()
 		billingsvc.StartFXAutoSyncWorker()
 		topupsvc.StartTopupReconcileWorker()
+		channelsvc.StartChannelPurgeWorker()
+		usersvc.StartJWTTokenPruneWorker()
+		dashboardsvc.StartStatsWorker()
 	}
 	openai.InitTokenEncoders()
+	relay.InitResponsePostProcessors()
 	client.Init()
 
 	// Initialize i18n
@@ -94,14 +122,30 @@ func Run() {
 		logger.FatalLog("failed to initialize i18n: " + err.Error())
 	}
 
+	shutdownTracing := func(context.Context) error { return nil }
+	if config.OtelEnabled {
+		shutdownTracing, err = tracing.Setup(ctx, "router", config.OtelExporterEndpoint)
+		if err != nil {
+			logger.FatalLog("failed to initialize tracing: " + err.Error())
+		}
+		logger.SysLog("OpenTelemetry tracing enabled")
+	}
+
 	// Initialize HTTP server
 	server := gin.New()
-	server.Use(gin.Recovery())
+	server.Use(middleware.RecoveryWithLogger())
+	if config.OtelEnabled {
+		server.Use(middleware.OtelTracing("router"))
+	}
 	// This will cause SSE not to work!!!
 	//server.Use(gzip.Gzip(gzip.DefaultCompression))
 	server.Use(middleware.TraceID())
+	server.Use(middleware.RequestID())
+	server.Use(middleware.VersionHeader())
 	server.Use(middleware.Language())
 	middleware.SetUpLogger(server)
+	server.Use(middleware.NewApiLogger(logger.LogDir, config.LogRotateMaxSizeMB, config.LogRotateMaxAgeDays))
+	server.Use(middleware.SlowRequestLogger(time.Duration(config.SlowRequestThresholdMs) * time.Millisecond))
 	// Initialize session store
 	store := cookie.NewStore([]byte(config.CookieSecret))
 	server.Use(sessions.Sessions("session", store))
@@ -109,13 +153,43 @@ func Run() {
 	router.SetRouter(server, rootapp.BuildFS)
 	var port = strconv.Itoa(*common.Port)
 	logger.SysLogf("server started on http://localhost:%s", port)
-	err = server.Run(":" + port)
+	// SIGINT and SIGTERM drain in-flight requests so that the flushes below
+	// run before the process exits
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	httpServer := &http.Server{Addr: ":" + port, Handler: server}
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- httpServer.ListenAndServe() }()
+	select {
+	case err = <-serverErr:
+	case <-signalCtx.Done():
+		logger.SysLog("shutting down server")
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		if err := httpServer.Shutdown(drainCtx); err != nil {
+			logger.SysErrorf("graceful shutdown failed: %v", err)
+		}
+		cancelDrain()
+	}
+	stopSignals()
+	cancel()
+	logger.FlushApiLog()
+	logger.StopApiLogForwarding()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.SysErrorf("flush tracing spans failed: %v", err)
+	}
+	cancelShutdown()
 	if err != nil {
 		logger.FatalLog("failed to start HTTP server: " + err.Error())
 	}
 }
 
 func validateStartupAuthConfig() {
+	if config.WalletJWTAlgorithm == common.WalletJWTAlgorithmRS256 {
+		if !common.WalletJWTSigningConfigured() {
+			logger.SysError("auth.jwt_algorithm is RS256 without auth.jwt_rsa_private_key_file; this instance can verify wallet tokens but cannot issue them.")
+		}
+		return
+	}
 	if strings.TrimSpace(config.JWTSecret) == "" {
 		logger.SysError("auth.jwt_secret is empty; wallet login routes remain enabled, but wallet access/refresh token issuance and verification will fail until it is configured.")
 	}
@@ -1,8 +1,8 @@
 package anthropic
 
 import (
-	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,18 +15,11 @@ import (
 	"github.com/yeying-community/router/common/helper"
 	"github.com/yeying-community/router/common/image"
 	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/relay/adaptor"
 	"github.com/yeying-community/router/internal/relay/adaptor/openai"
 	"github.com/yeying-community/router/internal/relay/model"
 )
 
-const anthropicScannerMaxTokenSize = 8 * 1024 * 1024
-
-func newAnthropicStreamScanner(body io.Reader) *bufio.Scanner {
-	scanner := bufio.NewScanner(body)
-	scanner.Buffer(make([]byte, 0, 64*1024), anthropicScannerMaxTokenSize)
-	return scanner
-}
-
 func stopReasonClaude2OpenAI(reason *string) string {
 	if reason == nil {
 		return ""
@@ -316,19 +309,7 @@ func ResponseClaude2OpenAI(claudeResponse *Response) *openai.TextResponse {
 
 func StreamHandler(c *gin.Context, resp *http.Response) (*model.ErrorWithStatusCode, *model.Usage) {
 	createdTime := helper.GetTimestamp()
-	scanner := newAnthropicStreamScanner(resp.Body)
-	scanner.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-		if atEOF && len(data) == 0 {
-			return 0, nil, nil
-		}
-		if i := strings.Index(string(data), "\n"); i >= 0 {
-			return i + 1, data[0:i], nil
-		}
-		if atEOF {
-			return len(data), data, nil
-		}
-		return 0, nil, nil
-	})
+	reader := adaptor.NewSSEReader(resp.Body)
 
 	common.SetEventStreamHeaders(c)
 
@@ -337,16 +318,23 @@ func StreamHandler(c *gin.Context, resp *http.Response) (*model.ErrorWithStatusC
 	var id string
 	var lastToolCallChoice openai.ChatCompletionsStreamResponseChoice
 
-	for scanner.Scan() {
-		data := scanner.Text()
-		if len(data) < 6 || !strings.HasPrefix(data, "data:") {
-			continue
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			lowerMessage := strings.ToLower(strings.TrimSpace(err.Error()))
+			if strings.Contains(lowerMessage, "context canceled") {
+				logger.SysWarnf("[anthropic.stream] read_stopped reason=context_canceled err=%q", err.Error())
+			} else {
+				logger.SysErrorf("[anthropic.stream] read_failed err=%q", err.Error())
+			}
+			break
 		}
-		data = strings.TrimPrefix(data, "data:")
-		data = strings.TrimSpace(data)
 
 		var claudeResponse StreamResponse
-		err := json.Unmarshal([]byte(data), &claudeResponse)
+		err = json.Unmarshal([]byte(event.Data), &claudeResponse)
 		if err != nil {
 			logger.SysError("error unmarshalling stream response: " + err.Error())
 			continue
@@ -394,15 +382,6 @@ func StreamHandler(c *gin.Context, resp *http.Response) (*model.ErrorWithStatusC
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		lowerMessage := strings.ToLower(strings.TrimSpace(err.Error()))
-		if strings.Contains(lowerMessage, "context canceled") {
-			logger.SysWarnf("[anthropic.stream] read_stopped reason=context_canceled err=%q", err.Error())
-		} else {
-			logger.SysErrorf("[anthropic.stream] read_failed err=%q", err.Error())
-		}
-	}
-
 	render.Done(c)
 
 	err := resp.Body.Close()
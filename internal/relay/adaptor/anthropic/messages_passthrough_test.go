@@ -3,22 +3,14 @@ package anthropic
 import (
 	"io"
 	"net/http"
-	"net/http/httptest"
 	"strings"
 	"testing"
 
-	"github.com/gin-gonic/gin"
+	"github.com/yeying-community/router/internal/testutil"
 )
 
-func newAnthropicPassthroughTestContext() (*gin.Context, *httptest.ResponseRecorder) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(recorder)
-	return ctx, recorder
-}
-
 func TestRelayMessagesResponsePreservesAnthropicShape(t *testing.T) {
-	ctx, recorder := newAnthropicPassthroughTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/messages", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header: http.Header{
@@ -53,7 +45,7 @@ func TestRelayMessagesResponsePreservesAnthropicShape(t *testing.T) {
 }
 
 func TestRelayMessagesStreamResponsePreservesAnthropicSSE(t *testing.T) {
-	ctx, recorder := newAnthropicPassthroughTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/messages", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header: http.Header{
@@ -86,7 +78,7 @@ func TestRelayMessagesStreamResponsePreservesAnthropicSSE(t *testing.T) {
 }
 
 func TestRelayMessagesStreamResponseUsesLatestCumulativeUsage(t *testing.T) {
-	ctx, _ := newAnthropicPassthroughTestContext()
+	ctx, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/messages", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header: http.Header{
@@ -114,7 +106,7 @@ func TestRelayMessagesStreamResponseUsesLatestCumulativeUsage(t *testing.T) {
 }
 
 func TestRelayMessagesStreamResponseSupportsLargeAnthropicDataLine(t *testing.T) {
-	ctx, recorder := newAnthropicPassthroughTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/messages", nil)
 	largeText := strings.Repeat("a", 70*1024)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
@@ -145,7 +137,7 @@ func TestRelayMessagesStreamResponseSupportsLargeAnthropicDataLine(t *testing.T)
 }
 
 func TestRelayMessagesStreamAsChatResponse(t *testing.T) {
-	ctx, recorder := newAnthropicPassthroughTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/messages", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header: http.Header{
@@ -182,7 +174,7 @@ func TestRelayMessagesStreamAsChatResponse(t *testing.T) {
 }
 
 func TestRelayMessagesResponseSkipsUpstreamCORSHeaders(t *testing.T) {
-	ctx, recorder := newAnthropicPassthroughTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/messages", nil)
 	recorder.Header().Set("Access-Control-Allow-Origin", "http://localhost:3020")
 	recorder.Header().Set("Access-Control-Allow-Credentials", "true")
 	resp := &http.Response{
@@ -215,7 +207,7 @@ func TestRelayMessagesResponseSkipsUpstreamCORSHeaders(t *testing.T) {
 }
 
 func TestRelayMessagesStreamAsChatResponseSkipsUpstreamCORSHeaders(t *testing.T) {
-	ctx, recorder := newAnthropicPassthroughTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/messages", nil)
 	recorder.Header().Set("Access-Control-Allow-Origin", "http://localhost:3020")
 	recorder.Header().Set("Access-Control-Allow-Credentials", "true")
 	resp := &http.Response{
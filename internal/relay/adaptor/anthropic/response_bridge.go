@@ -1,9 +1,9 @@
 package anthropic
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +11,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/internal/relay/adaptor"
 	"github.com/yeying-community/router/internal/relay/adaptor/openai"
 	"github.com/yeying-community/router/internal/relay/model"
 )
@@ -76,19 +77,7 @@ func relayMessagesStreamResponse(c *gin.Context, resp *http.Response) (*model.Us
 	copyResponseHeaders(c, resp.Header)
 	c.Writer.WriteHeader(resp.StatusCode)
 
-	scanner := newAnthropicStreamScanner(resp.Body)
-	scanner.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-		if atEOF && len(data) == 0 {
-			return 0, nil, nil
-		}
-		if i := strings.IndexByte(string(data), '\n'); i >= 0 {
-			return i + 1, data[:i], nil
-		}
-		if atEOF {
-			return len(data), data, nil
-		}
-		return 0, nil, nil
-	})
+	scanner := adaptor.NewSSELineScanner(resp.Body)
 
 	usage := &model.Usage{}
 	for scanner.Scan() {
@@ -98,13 +87,11 @@ func relayMessagesStreamResponse(c *gin.Context, resp *http.Response) (*model.Us
 		}
 		c.Writer.Flush()
 
-		if !strings.HasPrefix(line, "data:") {
-			continue
-		}
-		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-		if data == "" || data == "[DONE]" {
+		parsed, err := adaptor.ParseSSELine(scanner.Bytes())
+		if err != nil || parsed.Data == "" {
 			continue
 		}
+		data := parsed.Data
 
 		var claudeResponse StreamResponse
 		if err := json.Unmarshal([]byte(data), &claudeResponse); err != nil {
@@ -160,9 +147,7 @@ func relayMessagesStreamAsChatResponse(c *gin.Context, resp *http.Response, prom
 		return fallbackUsage, respErr
 	}
 
-	scanner := newAnthropicStreamScanner(bytes.NewReader(responseBody))
-	scanner.Split(bufio.ScanLines)
-	currentEvent := ""
+	reader := adaptor.NewSSEReader(bytes.NewReader(responseBody))
 	var responseTextBuilder strings.Builder
 	responseID := ""
 	responseModel := strings.TrimSpace(modelName)
@@ -170,24 +155,15 @@ func relayMessagesStreamAsChatResponse(c *gin.Context, resp *http.Response, prom
 	usage := &model.Usage{
 		PromptTokens: promptTokens,
 	}
-	for scanner.Scan() {
-		line := strings.TrimSuffix(scanner.Text(), "\r")
-		if line == "" {
-			currentEvent = ""
-			continue
-		}
-		if strings.HasPrefix(line, "event:") {
-			currentEvent = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
-			_ = currentEvent
-			continue
-		}
-		if !strings.HasPrefix(line, "data:") {
-			continue
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
 		}
-		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-		if data == "" || data == "[DONE]" {
-			continue
+		if err != nil {
+			return nil, openai.ErrorWrapper(err, "read_response_body_failed", http.StatusInternalServerError)
 		}
+		data := event.Data
 
 		var payload StreamResponse
 		if err := json.Unmarshal([]byte(data), &payload); err != nil {
@@ -235,10 +211,6 @@ func relayMessagesStreamAsChatResponse(c *gin.Context, resp *http.Response, prom
 			}
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, openai.ErrorWrapper(err, "read_response_body_failed", http.StatusInternalServerError)
-	}
-
 	responseText := strings.TrimSpace(responseTextBuilder.String())
 	if usage.CompletionTokens == 0 && responseText != "" {
 		usage.CompletionTokens = openai.CountTokenText(responseText, modelName)
@@ -9,9 +9,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yeying-community/router/common/client"
+	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/common/ctxkey"
 	"github.com/yeying-community/router/common/logger"
 	relaylogging "github.com/yeying-community/router/internal/relay/logging"
@@ -19,10 +21,14 @@ import (
 )
 
 func SetupCommonRequestHeader(c *gin.Context, req *http.Request, meta *meta.Meta) {
-	req.Header.Set("Content-Type", c.Request.Header.Get("Content-Type"))
-	req.Header.Set("Accept", c.Request.Header.Get("Accept"))
-	if userAgent := strings.TrimSpace(c.Request.Header.Get("User-Agent")); userAgent != "" {
-		req.Header.Set("User-Agent", userAgent)
+	sanitized := SanitizeUpstreamHeaders(c.Request.Header)
+	for name, values := range sanitized {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", sanitized.Get("Content-Type"))
+	req.Header.Set("Accept", sanitized.Get("Accept"))
+	if strings.TrimSpace(req.Header.Get("User-Agent")) == "" {
+		req.Header.Del("User-Agent")
 	}
 	if meta.IsStream && c.Request.Header.Get("Accept") == "" {
 		req.Header.Set("Accept", "text/event-stream")
@@ -45,12 +51,15 @@ func DoRequestHelper(a Adaptor, c *gin.Context, meta *meta.Meta, requestBody io.
 		metaModelName = strings.TrimSpace(meta.ActualModelName)
 	}
 	c.Set(ctxkey.UpstreamURL, fullRequestURL)
-	req, err := http.NewRequestWithContext(c.Request.Context(), c.Request.Method, fullRequestURL, requestBody)
+	reqCtx, cancel := withChannelTimeout(c)
+	req, err := http.NewRequestWithContext(reqCtx, c.Request.Method, fullRequestURL, requestBody)
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("new request failed: %w", err)
 	}
 	err = a.SetupRequestHeader(c, req, meta)
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("setup request header failed: %w", err)
 	}
 	if metaChannelID != "" {
@@ -67,6 +76,7 @@ func DoRequestHelper(a Adaptor, c *gin.Context, meta *meta.Meta, requestBody io.
 	}
 	resp, err := DoRequest(c, req)
 	if err != nil {
+		cancel()
 		fields := relaylogging.NewFields("UPSTREAM_ERR").
 			String("method", req.Method).
 			String("url", fullRequestURL).
@@ -87,6 +97,8 @@ func DoRequestHelper(a Adaptor, c *gin.Context, meta *meta.Meta, requestBody io.
 		}
 		return nil, fmt.Errorf("do request failed: %w", err)
 	}
+	// the deadline must also cover reading a streamed body, so it is released on Close
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
 	c.Set(ctxkey.UpstreamStatus, resp.StatusCode)
 	respFields := relaylogging.NewFields("UPSTREAM_RESP").
 		String("method", req.Method).
@@ -111,6 +123,35 @@ func DoRequestHelper(a Adaptor, c *gin.Context, meta *meta.Meta, requestBody io.
 	return resp, nil
 }
 
+// withChannelTimeout derives the upstream request context from the selected
+// channel's timeout_seconds. A channel without its own timeout only uses the
+// relay.timeout_seconds limit of the shared HTTP client.
+func withChannelTimeout(c *gin.Context) (context.Context, context.CancelFunc) {
+	ctx := c.Request.Context()
+	timeoutSeconds := c.GetInt(ctxkey.ChannelTimeout)
+	if timeoutSeconds <= 0 {
+		logger.Debugf(ctx, "upstream timeout: channel_id=%s timeout_seconds=%d source=global", c.GetString(ctxkey.ChannelId), config.RelayTimeout)
+		return context.WithCancel(ctx)
+	}
+	if timeoutSeconds != config.RelayTimeout {
+		logger.Infof(ctx, "upstream timeout: channel_id=%s timeout_seconds=%d source=channel default_seconds=%d", c.GetString(ctxkey.ChannelId), timeoutSeconds, config.RelayTimeout)
+	} else {
+		logger.Debugf(ctx, "upstream timeout: channel_id=%s timeout_seconds=%d source=channel", c.GetString(ctxkey.ChannelId), timeoutSeconds)
+	}
+	return context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+}
+
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
 func maskHeaders(header http.Header) map[string]string {
 	masked := make(map[string]string, len(header))
 	for key, values := range header {
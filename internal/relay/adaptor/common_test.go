@@ -0,0 +1,84 @@
+package adaptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestWithChannelTimeout(t *testing.T) {
+	prevTimeout := config.RelayTimeout
+	defer func() { config.RelayTimeout = prevTimeout }()
+	config.RelayTimeout = 30
+
+	tests := []struct {
+		name         string
+		channel      int
+		wantDeadline bool
+	}{
+		{name: "unset falls back to the shared client", channel: 0, wantDeadline: false},
+		{name: "negative falls back to the shared client", channel: -5, wantDeadline: false},
+		{name: "per-channel timeout", channel: 5, wantDeadline: true},
+		{name: "per-channel timeout equal to the default", channel: 30, wantDeadline: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
+			c.Set(ctxkey.ChannelTimeout, tt.channel)
+			ctx, cancel := withChannelTimeout(c)
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			if ok != tt.wantDeadline {
+				t.Fatalf("deadline set = %t, want %t", ok, tt.wantDeadline)
+			}
+			if ok {
+				remaining := time.Until(deadline)
+				want := time.Duration(tt.channel) * time.Second
+				if remaining > want || remaining < want-time.Second {
+					t.Fatalf("deadline in %v, want about %v", remaining, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithChannelTimeout_Expires(t *testing.T) {
+	c, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
+	c.Set(ctxkey.ChannelTimeout, 1)
+	ctx, cancel := withChannelTimeout(c)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			t.Fatalf("ctx.Err() = %v, want deadline exceeded", ctx.Err())
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("channel timeout did not expire")
+	}
+}
+
+func TestCancelOnCloseBody(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	body := &cancelOnCloseBody{ReadCloser: io.NopCloser(strings.NewReader("data: {}\n\n")), cancel: cancel}
+
+	if _, err := io.ReadAll(body); err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("context canceled before the body was closed")
+	}
+	if err := body.Close(); err != nil {
+		t.Fatalf("close body: %v", err)
+	}
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Fatalf("ctx.Err() after Close = %v, want canceled", ctx.Err())
+	}
+}
@@ -280,25 +280,21 @@ func relayMessagesStreamResponse(c *gin.Context, resp *http.Response, promptToke
 	copyUpstreamResponseHeaders(c, resp.Header, false)
 	c.Writer.WriteHeader(resp.StatusCode)
 
-	scanner := newOpenAIStreamScanner(resp.Body)
+	scanner := adaptor.NewSSELineScanner(resp.Body)
 
 	usage := &model.Usage{
 		PromptTokens: promptTokens,
 	}
 	var completionText strings.Builder
 	for scanner.Scan() {
-		rawLine := scanner.Text()
-		line := strings.TrimSuffix(rawLine, "\r")
-		_, _ = c.Writer.Write([]byte(rawLine + "\n"))
+		_, _ = c.Writer.Write([]byte(scanner.Text() + "\n"))
 		c.Writer.Flush()
 
-		if !strings.HasPrefix(line, "data:") {
-			continue
-		}
-		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-		if data == "" || data == "[DONE]" {
+		parsed, err := adaptor.ParseSSELine(scanner.Bytes())
+		if err != nil || parsed.Data == "" {
 			continue
 		}
+		data := parsed.Data
 
 		payload := map[string]any{}
 		if err := json.Unmarshal([]byte(data), &payload); err != nil {
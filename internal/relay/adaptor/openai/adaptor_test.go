@@ -3,25 +3,21 @@ package openai
 import (
 	"io"
 	"net/http"
-	"net/http/httptest"
 	"strings"
 	"testing"
 
-	"github.com/gin-gonic/gin"
 	relaychannel "github.com/yeying-community/router/internal/relay/channel"
 	"github.com/yeying-community/router/internal/relay/meta"
 	"github.com/yeying-community/router/internal/relay/relaymode"
+	"github.com/yeying-community/router/internal/testutil"
 )
 
 func TestSetupRequestHeaderSetsJSONAcceptForNonStream(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(recorder)
-	ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	ctx, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	ctx.Request.Header.Set("Accept", "text/event-stream")
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	req := httptest.NewRequest(http.MethodPost, "https://example.com/v1/chat/completions", nil)
+	req := testutil.NewTestRequest(http.MethodPost, "https://example.com/v1/chat/completions", nil)
 	adaptor := &Adaptor{ChannelProtocol: relaychannel.OpenAI}
 	meta := &meta.Meta{APIKey: "sk-test", ChannelProtocol: relaychannel.OpenAI}
 
@@ -34,14 +30,11 @@ func TestSetupRequestHeaderSetsJSONAcceptForNonStream(t *testing.T) {
 }
 
 func TestSetupRequestHeaderSetsSSEAcceptForStream(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(recorder)
-	ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	ctx, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	ctx.Request.Header.Set("Accept", "application/json")
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	req := httptest.NewRequest(http.MethodPost, "https://example.com/v1/chat/completions", nil)
+	req := testutil.NewTestRequest(http.MethodPost, "https://example.com/v1/chat/completions", nil)
 	adaptor := &Adaptor{ChannelProtocol: relaychannel.OpenAI}
 	meta := &meta.Meta{
 		APIKey:          "sk-test",
@@ -58,14 +51,11 @@ func TestSetupRequestHeaderSetsSSEAcceptForStream(t *testing.T) {
 }
 
 func TestSetupRequestHeaderPreservesAudioAcceptForSpeech(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(recorder)
-	ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/audio/speech", nil)
+	ctx, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/audio/speech", nil)
 	ctx.Request.Header.Set("Accept", "audio/mpeg")
 	ctx.Request.Header.Set("Content-Type", "application/json")
 
-	req := httptest.NewRequest(http.MethodPost, "https://example.com/v1/audio/speech", nil)
+	req := testutil.NewTestRequest(http.MethodPost, "https://example.com/v1/audio/speech", nil)
 	adaptor := &Adaptor{ChannelProtocol: relaychannel.OpenAI}
 	meta := &meta.Meta{
 		APIKey:          "sk-test",
@@ -82,10 +72,7 @@ func TestSetupRequestHeaderPreservesAudioAcceptForSpeech(t *testing.T) {
 }
 
 func TestDoResponseRelaysRawResponseForRealtime(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(recorder)
-	ctx.Request = httptest.NewRequest(http.MethodPost, "/v1/realtime/client_secrets", nil)
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/realtime/client_secrets", nil)
 
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
@@ -1,7 +1,6 @@
 package openai
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -16,49 +15,31 @@ import (
 	"github.com/yeying-community/router/common"
 	"github.com/yeying-community/router/common/conv"
 	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/relay/adaptor"
 	"github.com/yeying-community/router/internal/relay/model"
 	"github.com/yeying-community/router/internal/relay/relaymode"
 )
 
-const (
-	dataPrefix                = "data: "
-	done                      = "[DONE]"
-	dataPrefixLength          = len(dataPrefix)
-	openaiScannerMaxTokenSize = 8 * 1024 * 1024
-)
-
-func newOpenAIStreamScanner(body io.Reader) *bufio.Scanner {
-	scanner := bufio.NewScanner(body)
-	scanner.Split(bufio.ScanLines)
-	scanner.Buffer(make([]byte, 0, 64*1024), openaiScannerMaxTokenSize)
-	return scanner
-}
-
 func StreamHandler(c *gin.Context, resp *http.Response, relayMode int) (*model.ErrorWithStatusCode, string, *model.Usage) {
 	responseText := ""
-	scanner := newOpenAIStreamScanner(resp.Body)
+	reader := adaptor.NewSSEReader(resp.Body)
 	var usage *model.Usage
 
 	common.SetEventStreamHeaders(c)
 
-	doneRendered := false
-	for scanner.Scan() {
-		data := scanner.Text()
-		if len(data) < dataPrefixLength { // ignore blank line or wrong format
-			continue
-		}
-		if data[:dataPrefixLength] != dataPrefix && data[:dataPrefixLength] != done {
-			continue
-		}
-		if strings.HasPrefix(data[dataPrefixLength:], done) {
-			render.StringData(c, data)
-			doneRendered = true
-			continue
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				logOpenAIStreamReadError("chat", err)
+			}
+			break
 		}
+		data := event.Data
 		switch relayMode {
 		case relaymode.ChatCompletions:
 			var streamResponse ChatCompletionsStreamResponse
-			err := json.Unmarshal([]byte(data[dataPrefixLength:]), &streamResponse)
+			err := json.Unmarshal([]byte(data), &streamResponse)
 			if err != nil {
 				logger.SysError("error unmarshalling stream response: " + err.Error())
 				render.StringData(c, data) // if error happened, pass the data to client
@@ -78,7 +59,7 @@ func StreamHandler(c *gin.Context, resp *http.Response, relayMode int) (*model.E
 		case relaymode.Completions:
 			render.StringData(c, data)
 			var streamResponse CompletionsStreamResponse
-			err := json.Unmarshal([]byte(data[dataPrefixLength:]), &streamResponse)
+			err := json.Unmarshal([]byte(data), &streamResponse)
 			if err != nil {
 				logger.SysError("error unmarshalling stream response: " + err.Error())
 				continue
@@ -89,13 +70,7 @@ func StreamHandler(c *gin.Context, resp *http.Response, relayMode int) (*model.E
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		logOpenAIStreamReadError("chat", err)
-	}
-
-	if !doneRendered {
-		render.Done(c)
-	}
+	render.Done(c)
 
 	err := resp.Body.Close()
 	if err != nil {
@@ -120,7 +95,7 @@ type responsesStreamTextPayload struct {
 
 func StreamResponsesHandler(c *gin.Context, resp *http.Response, modelName string, promptTokens int) (*model.ErrorWithStatusCode, *model.Usage) {
 	responseText := ""
-	scanner := newOpenAIStreamScanner(resp.Body)
+	scanner := adaptor.NewSSELineScanner(resp.Body)
 	var usage *model.Usage
 	currentEvent := ""
 	doneRendered := false
@@ -128,29 +103,25 @@ func StreamResponsesHandler(c *gin.Context, resp *http.Response, modelName strin
 	common.SetEventStreamHeaders(c)
 
 	for scanner.Scan() {
-		rawLine := scanner.Text()
-		line := strings.TrimSuffix(rawLine, "\r")
+		line := scanner.Text()
 
-		_, _ = c.Writer.Write([]byte(rawLine + "\n"))
+		_, _ = c.Writer.Write([]byte(line + "\n"))
 		c.Writer.Flush()
 
-		if line == "" {
+		if strings.TrimSpace(line) == "" {
 			currentEvent = ""
 			continue
 		}
-		if strings.HasPrefix(line, "event:") {
-			currentEvent = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
-			continue
-		}
-		if !strings.HasPrefix(line, dataPrefix) {
+		parsed, err := adaptor.ParseSSELine(scanner.Bytes())
+		if err != nil {
+			doneRendered = true
 			continue
 		}
-
-		data := strings.TrimSpace(strings.TrimPrefix(line, dataPrefix))
-		if data == done {
-			doneRendered = true
+		if parsed.Event != "" {
+			currentEvent = parsed.Event
 			continue
 		}
+		data := parsed.Data
 		if data == "" {
 			continue
 		}
@@ -238,7 +209,7 @@ func logOpenAIStreamReadError(kind string, err error) {
 	case errors.Is(err, context.Canceled) || strings.Contains(lowerMessage, "context canceled"):
 		logger.SysWarnf("[openai.stream] read_stopped kind=%s reason=context_canceled err=%q", strings.TrimSpace(kind), err.Error())
 	case strings.Contains(lowerMessage, "token too long"):
-		logger.SysErrorf("[openai.stream] read_failed kind=%s reason=scanner_token_too_long max_token_bytes=%d err=%q", strings.TrimSpace(kind), openaiScannerMaxTokenSize, err.Error())
+		logger.SysErrorf("[openai.stream] read_failed kind=%s reason=scanner_token_too_long err=%q", strings.TrimSpace(kind), err.Error())
 	default:
 		logger.SysErrorf("[openai.stream] read_failed kind=%s err=%q", strings.TrimSpace(kind), err.Error())
 	}
@@ -0,0 +1,37 @@
+package openai
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/yeying-community/router/internal/relay/relaymode"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestStreamHandler_TolerantSSE(t *testing.T) {
+	c, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
+	upstream := "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\r\n\r\n" +
+		": keep-alive\n\n" +
+		"data: data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"lo\"}}]}\n\n" +
+		"data:{\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":2,\"total_tokens\":5}}\r\n\r\n" +
+		"data: [DONE]\r\n\r\n" +
+		"data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"ignored\"}}]}\n\n"
+	resp := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(upstream))}
+
+	errResp, text, usage := StreamHandler(c, resp, relaymode.ChatCompletions)
+	if errResp != nil {
+		t.Fatalf("StreamHandler error = %+v", errResp)
+	}
+	if text != "Hello" {
+		t.Fatalf("response text = %q, want %q", text, "Hello")
+	}
+	if usage == nil || usage.TotalTokens != 5 {
+		t.Fatalf("usage = %+v, want total 5", usage)
+	}
+	body := recorder.Body.String()
+	if strings.Count(body, "data: [DONE]") != 1 || strings.Contains(body, "ignored") {
+		t.Fatalf("body = %q, want a single [DONE] and nothing after it", body)
+	}
+}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/yeying-community/router/common"
 	"github.com/yeying-community/router/common/render"
+	"github.com/yeying-community/router/internal/relay/adaptor"
 	"github.com/yeying-community/router/internal/relay/model"
 )
 
@@ -197,9 +199,8 @@ func relayResponsesAsChatResponse(c *gin.Context, resp *http.Response, modelName
 
 func StreamResponsesAsChatHandler(c *gin.Context, resp *http.Response, modelName string, promptTokens int) (*model.ErrorWithStatusCode, *model.Usage) {
 	responseText := ""
-	scanner := newOpenAIStreamScanner(resp.Body)
+	reader := adaptor.NewSSEReader(resp.Body)
 	var usage *model.Usage
-	currentEvent := ""
 	firstDelta := true
 	sawDelta := false
 	finishRendered := false
@@ -207,23 +208,16 @@ func StreamResponsesAsChatHandler(c *gin.Context, resp *http.Response, modelName
 
 	common.SetEventStreamHeaders(c)
 
-	for scanner.Scan() {
-		line := strings.TrimSuffix(scanner.Text(), "\r")
-		if line == "" {
-			currentEvent = ""
-			continue
-		}
-		if strings.HasPrefix(line, "event:") {
-			currentEvent = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
-			continue
-		}
-		if !strings.HasPrefix(line, dataPrefix) {
-			continue
-		}
-		data := strings.TrimSpace(strings.TrimPrefix(line, dataPrefix))
-		if data == done {
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
 			break
 		}
+		if err != nil {
+			return ErrorWrapper(err, "read_response_body_failed", http.StatusInternalServerError), usage
+		}
+		currentEvent := event.Event
+		data := event.Data
 		envelope := responsesStreamEnvelope{}
 		_ = json.Unmarshal([]byte(data), &envelope)
 		if envelope.Usage != nil {
@@ -332,9 +326,6 @@ func StreamResponsesAsChatHandler(c *gin.Context, resp *http.Response, modelName
 			finishRendered = true
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return ErrorWrapper(err, "read_response_body_failed", http.StatusInternalServerError), usage
-	}
 	if err := resp.Body.Close(); err != nil {
 		return ErrorWrapper(err, "close_response_body_failed", http.StatusInternalServerError), usage
 	}
@@ -3,22 +3,14 @@ package openai
 import (
 	"io"
 	"net/http"
-	"net/http/httptest"
 	"strings"
 	"testing"
 
-	"github.com/gin-gonic/gin"
+	"github.com/yeying-community/router/internal/testutil"
 )
 
-func newBridgeTestContext() (*gin.Context, *httptest.ResponseRecorder) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(recorder)
-	return ctx, recorder
-}
-
 func TestRelayResponsesAsChatResponse(t *testing.T) {
-	ctx, recorder := newBridgeTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header: http.Header{
@@ -51,7 +43,7 @@ func TestRelayResponsesAsChatResponse(t *testing.T) {
 }
 
 func TestRelayResponsesAsChatResponse_ExtractsMessageOutputTextFromOutput(t *testing.T) {
-	ctx, recorder := newBridgeTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header:     http.Header{},
@@ -85,7 +77,7 @@ func TestRelayResponsesAsChatResponse_ExtractsMessageOutputTextFromOutput(t *tes
 }
 
 func TestRelayResponsesAsChatResponse_ExtractsMessageTextTypeFromOutput(t *testing.T) {
-	ctx, recorder := newBridgeTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header:     http.Header{},
@@ -115,7 +107,7 @@ func TestRelayResponsesAsChatResponse_ExtractsMessageTextTypeFromOutput(t *testi
 }
 
 func TestRelayResponsesAsChatResponse_CapturesImageGenerationCalls(t *testing.T) {
-	ctx, _ := newBridgeTestContext()
+	ctx, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header:     http.Header{},
@@ -142,7 +134,7 @@ func TestRelayResponsesAsChatResponse_CapturesImageGenerationCalls(t *testing.T)
 }
 
 func TestRelayMessagesResponse(t *testing.T) {
-	ctx, recorder := newBridgeTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header: http.Header{
@@ -174,7 +166,7 @@ func TestRelayMessagesResponse(t *testing.T) {
 }
 
 func TestRelayMessagesStreamResponse(t *testing.T) {
-	ctx, recorder := newBridgeTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header: http.Header{
@@ -205,7 +197,7 @@ func TestRelayMessagesStreamResponse(t *testing.T) {
 }
 
 func TestRelayResponsesResponseSkipsUpstreamCORSHeaders(t *testing.T) {
-	ctx, recorder := newBridgeTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	recorder.Header().Set("Access-Control-Allow-Origin", "http://localhost:3020")
 	recorder.Header().Set("Access-Control-Allow-Credentials", "true")
 	resp := &http.Response{
@@ -243,7 +235,7 @@ func TestRelayResponsesResponseSkipsUpstreamCORSHeaders(t *testing.T) {
 }
 
 func TestRelayResponsesResponse_CapturesImageGenerationCalls(t *testing.T) {
-	ctx, _ := newBridgeTestContext()
+	ctx, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header:     http.Header{},
@@ -266,7 +258,7 @@ func TestRelayResponsesResponse_CapturesImageGenerationCalls(t *testing.T) {
 }
 
 func TestRelayMessagesResponseSkipsUpstreamCORSHeaders(t *testing.T) {
-	ctx, recorder := newBridgeTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	recorder.Header().Set("Access-Control-Allow-Origin", "http://localhost:3020")
 	recorder.Header().Set("Access-Control-Allow-Credentials", "true")
 	resp := &http.Response{
@@ -302,7 +294,7 @@ func TestRelayMessagesResponseSkipsUpstreamCORSHeaders(t *testing.T) {
 }
 
 func TestStreamResponsesAsChatHandlerNoDuplicateContentFromCompleted(t *testing.T) {
-	ctx, recorder := newBridgeTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header: http.Header{
@@ -337,7 +329,7 @@ func TestStreamResponsesAsChatHandlerNoDuplicateContentFromCompleted(t *testing.
 }
 
 func TestStreamResponsesAsChatHandlerUsesCompletedTextWhenNoDelta(t *testing.T) {
-	ctx, recorder := newBridgeTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header: http.Header{
@@ -364,7 +356,7 @@ func TestStreamResponsesAsChatHandlerUsesCompletedTextWhenNoDelta(t *testing.T)
 }
 
 func TestStreamResponsesAsChatHandlerUsesCompletedOutputWhenNoDelta(t *testing.T) {
-	ctx, recorder := newBridgeTestContext()
+	ctx, recorder := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	resp := &http.Response{
 		StatusCode: http.StatusOK,
 		Header: http.Header{
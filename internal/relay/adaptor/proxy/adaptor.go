@@ -65,6 +65,9 @@ func (a *Adaptor) GetRequestURL(meta *meta.Meta) (string, error) {
 
 func (a *Adaptor) SetupRequestHeader(c *gin.Context, req *http.Request, meta *meta.Meta) error {
 	for k, v := range c.Request.Header {
+		if adaptor.IsSensitiveUpstreamHeader(k) {
+			continue
+		}
 		req.Header.Set(k, v[0])
 	}
 
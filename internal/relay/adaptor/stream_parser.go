@@ -0,0 +1,172 @@
+package adaptor
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	sseDone          = "[DONE]"
+	sseMaxLineLength = 10 * 1024 * 1024
+)
+
+// SSEEvent is a server-sent event. ParseSSELine fills at most one field per
+// line; SSEReader merges the lines of an event and joins multi-line data with
+// "\n".
+type SSEEvent struct {
+	Event   string
+	Data    string
+	ID      string
+	Retry   int
+	HasData bool
+}
+
+// ParseSSELine parses one line of an SSE stream. Blank lines, comments and
+// unknown fields yield an empty event. Upstream quirks are tolerated: trailing
+// "\r", repeated "data:" prefixes and bare JSON lines without a "data:"
+// prefix. "data: [DONE]" returns io.EOF.
+func ParseSSELine(line []byte) (SSEEvent, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] == ':' {
+		return SSEEvent{}, nil
+	}
+	if line[0] == '{' || line[0] == '[' {
+		return sseDataEvent(string(line))
+	}
+	field, value, found := bytes.Cut(line, []byte(":"))
+	if !found {
+		return SSEEvent{}, nil
+	}
+	text := strings.TrimSpace(string(value))
+	switch string(field) {
+	case "data":
+		for strings.HasPrefix(text, "data:") {
+			text = strings.TrimSpace(text[len("data:"):])
+		}
+		return sseDataEvent(text)
+	case "event":
+		return SSEEvent{Event: text}, nil
+	case "id":
+		return SSEEvent{ID: text}, nil
+	case "retry":
+		retry, err := strconv.Atoi(text)
+		if err != nil || retry < 0 {
+			return SSEEvent{}, nil
+		}
+		return SSEEvent{Retry: retry}, nil
+	default:
+		return SSEEvent{}, nil
+	}
+}
+
+func sseDataEvent(data string) (SSEEvent, error) {
+	event := SSEEvent{Data: data, HasData: true}
+	if data == sseDone {
+		return event, io.EOF
+	}
+	return event, nil
+}
+
+// SSEReader reads complete events from an upstream SSE stream, accepting
+// "\n", "\r\n" and "\r" line endings, including mixed within one stream.
+type SSEReader struct {
+	scanner *bufio.Scanner
+	done    bool
+}
+
+func NewSSEReader(r io.Reader) *SSEReader {
+	return &SSEReader{scanner: NewSSELineScanner(r)}
+}
+
+// NewSSELineScanner returns a scanner yielding the lines of an SSE stream
+// without their line endings. Handlers that pass upstream lines through
+// verbatim use it with ParseSSELine.
+func NewSSELineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), sseMaxLineLength)
+	scanner.Split(scanSSELines)
+	return scanner
+}
+
+// Next returns the next event that carries data. It returns io.EOF once
+// "[DONE]" is received or the stream ends.
+func (r *SSEReader) Next() (SSEEvent, error) {
+	if r.done {
+		return SSEEvent{}, io.EOF
+	}
+	var (
+		event SSEEvent
+		data  []string
+	)
+	for r.scanner.Scan() {
+		line := r.scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			if len(data) > 0 {
+				event.Data = strings.Join(data, "\n")
+				event.HasData = true
+				return event, nil
+			}
+			event = SSEEvent{}
+			continue
+		}
+		parsed, err := ParseSSELine(line)
+		if err != nil {
+			r.done = true
+			if len(data) > 0 {
+				// "[DONE]" arrived without a blank line closing the previous event
+				event.Data = strings.Join(data, "\n")
+				event.HasData = true
+				return event, nil
+			}
+			return SSEEvent{}, err
+		}
+		switch {
+		case parsed.HasData:
+			data = append(data, parsed.Data)
+		case parsed.Event != "":
+			event.Event = parsed.Event
+		case parsed.ID != "":
+			event.ID = parsed.ID
+		case parsed.Retry > 0:
+			event.Retry = parsed.Retry
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return SSEEvent{}, err
+	}
+	if len(data) > 0 {
+		event.Data = strings.Join(data, "\n")
+		event.HasData = true
+		return event, nil
+	}
+	return SSEEvent{}, io.EOF
+}
+
+func scanSSELines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if !atEOF {
+			// a trailing '\r' may be the first half of "\r\n"
+			return 0, nil, nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
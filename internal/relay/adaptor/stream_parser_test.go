@@ -0,0 +1,102 @@
+package adaptor
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSSELine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    SSEEvent
+		wantEOF bool
+	}{
+		{name: "data", line: `data: {"a":1}`, want: SSEEvent{Data: `{"a":1}`, HasData: true}},
+		{name: "crlf", line: "data: {\"a\":1}\r", want: SSEEvent{Data: `{"a":1}`, HasData: true}},
+		{name: "no space", line: `data:{"a":1}`, want: SSEEvent{Data: `{"a":1}`, HasData: true}},
+		{name: "double prefix", line: `data: data: {"a":1}`, want: SSEEvent{Data: `{"a":1}`, HasData: true}},
+		{name: "bare json", line: `  {"a":1}  `, want: SSEEvent{Data: `{"a":1}`, HasData: true}},
+		{name: "non json", line: "data: hello world", want: SSEEvent{Data: "hello world", HasData: true}},
+		{name: "event", line: "event: message_start", want: SSEEvent{Event: "message_start"}},
+		{name: "id", line: "id: 42", want: SSEEvent{ID: "42"}},
+		{name: "retry", line: "retry: 3000", want: SSEEvent{Retry: 3000}},
+		{name: "bad retry", line: "retry: soon", want: SSEEvent{}},
+		{name: "comment", line: ": keep-alive", want: SSEEvent{}},
+		{name: "blank", line: " \r", want: SSEEvent{}},
+		{name: "unknown field", line: "foo: bar", want: SSEEvent{}},
+		{name: "done", line: "data: [DONE]", want: SSEEvent{Data: "[DONE]", HasData: true}, wantEOF: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSSELine([]byte(tt.line))
+		if tt.wantEOF != errors.Is(err, io.EOF) {
+			t.Fatalf("%s: ParseSSELine error = %v, wantEOF %t", tt.name, err, tt.wantEOF)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: ParseSSELine = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSSEReaderFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []SSEEvent
+	}{
+		{
+			fixture: "openai.txt",
+			want: []SSEEvent{
+				{Data: `{"id":"chatcmpl-9x","object":"chat.completion.chunk","created":1718000000,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"role":"assistant","content":""},"finish_reason":null}]}`, HasData: true},
+				{Data: `{"id":"chatcmpl-9x","object":"chat.completion.chunk","created":1718000000,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}`, HasData: true},
+				{Data: `{"id":"chatcmpl-9x","object":"chat.completion.chunk","created":1718000000,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`, HasData: true},
+			},
+		},
+		{
+			fixture: "anthropic.txt",
+			want: []SSEEvent{
+				{Event: "message_start", Data: `{"type":"message_start","message":{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-5-sonnet-20241022","content":[],"usage":{"input_tokens":12,"output_tokens":1}}}`, HasData: true},
+				{Event: "ping", Data: `{"type": "ping"}`, HasData: true},
+				{Event: "content_block_delta", Data: `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`, HasData: true},
+				{Event: "message_stop", Data: `{"type":"message_stop"}`, HasData: true},
+			},
+		},
+		{
+			fixture: "gemini.txt",
+			want: []SSEEvent{
+				{Data: `{"candidates": [{"content": {"parts": [{"text": "Hello"}],"role": "model"},"index": 0}],"usageMetadata": {"promptTokenCount": 4,"totalTokenCount": 5},"modelVersion": "gemini-1.5-flash"}`, HasData: true},
+				{Data: `{"candidates": [{"content": {"parts": [{"text": " world"}],"role": "model"},"finishReason": "STOP","index": 0}],"usageMetadata": {"promptTokenCount": 4,"candidatesTokenCount": 2,"totalTokenCount": 6},"modelVersion": "gemini-1.5-flash"}`, HasData: true},
+			},
+		},
+		{
+			fixture: "malformed.txt",
+			want: []SSEEvent{
+				{ID: "7", Retry: 3000, Data: `{"a":1}`, HasData: true},
+				{Data: "line one\nline two", HasData: true},
+				{Data: `{"bare":true}`, HasData: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		file, err := os.Open(filepath.Join("testdata", "sse", tt.fixture))
+		if err != nil {
+			t.Fatalf("open fixture %s: %v", tt.fixture, err)
+		}
+		reader := NewSSEReader(file)
+		for i, want := range tt.want {
+			got, err := reader.Next()
+			if err != nil {
+				t.Fatalf("%s: event %d error = %v", tt.fixture, i, err)
+			}
+			if got != want {
+				t.Fatalf("%s: event %d = %+v, want %+v", tt.fixture, i, got, want)
+			}
+		}
+		if _, err := reader.Next(); !errors.Is(err, io.EOF) {
+			t.Fatalf("%s: trailing Next error = %v, want io.EOF", tt.fixture, err)
+		}
+		_ = file.Close()
+	}
+}
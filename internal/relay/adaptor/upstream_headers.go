@@ -0,0 +1,47 @@
+package adaptor
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+// upstreamForwardedHeaders are copied from the client request to every upstream.
+var upstreamForwardedHeaders = []string{"Content-Type", "Accept", "User-Agent"}
+
+// IsSensitiveUpstreamHeader reports whether a client header must never reach an
+// upstream provider. Authorization is replaced by the channel's API key.
+func IsSensitiveUpstreamHeader(name string) bool {
+	canonical := http.CanonicalHeaderKey(name)
+	switch canonical {
+	case "Authorization", "Cookie", "X-Session-Token":
+		return true
+	}
+	return strings.HasPrefix(canonical, "X-Forwarded-User-") || strings.HasPrefix(canonical, "X-Router-")
+}
+
+// SanitizeUpstreamHeaders returns the client headers that may be forwarded
+// upstream: Content-Type, Accept, User-Agent (overridden by
+// relay.upstream_user_agent) and relay.passthrough_headers. Sensitive headers
+// are dropped even when listed as passthrough.
+func SanitizeUpstreamHeaders(headers http.Header) http.Header {
+	sanitized := make(http.Header)
+	for _, name := range upstreamForwardedHeaders {
+		if values := headers.Values(name); len(values) > 0 {
+			sanitized[name] = append([]string(nil), values...)
+		}
+	}
+	if userAgent := strings.TrimSpace(config.RelayUpstreamUserAgent); userAgent != "" {
+		sanitized.Set("User-Agent", userAgent)
+	}
+	for _, name := range config.RelayPassthroughHeaders {
+		if IsSensitiveUpstreamHeader(name) {
+			continue
+		}
+		if values := headers.Values(name); len(values) > 0 {
+			sanitized[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+	return sanitized
+}
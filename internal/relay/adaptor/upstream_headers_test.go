@@ -0,0 +1,42 @@
+package adaptor
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+func TestSanitizeUpstreamHeaders_DropsSessionCookie(t *testing.T) {
+	prevUA, prevPassthrough := config.RelayUpstreamUserAgent, config.RelayPassthroughHeaders
+	config.RelayUpstreamUserAgent = "router-relay/1.0"
+	config.RelayPassthroughHeaders = []string{"anthropic-beta", "Cookie"}
+	defer func() { config.RelayUpstreamUserAgent, config.RelayPassthroughHeaders = prevUA, prevPassthrough }()
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	headers.Set("Accept", "text/event-stream")
+	headers.Set("User-Agent", "curl/8.0")
+	headers.Set("Cookie", "session=secret")
+	headers.Set("Authorization", "Bearer sk-user")
+	headers.Set("X-Session-Token", "tok")
+	headers.Set("X-Forwarded-User-Email", "a@example.com")
+	headers.Set("X-Router-Trace", "1")
+	headers.Set("Anthropic-Beta", "tools-2024-04-04")
+
+	sanitized := SanitizeUpstreamHeaders(headers)
+	for _, name := range []string{"Cookie", "Authorization", "X-Session-Token", "X-Forwarded-User-Email", "X-Router-Trace"} {
+		if sanitized.Get(name) != "" {
+			t.Fatalf("header %s forwarded upstream", name)
+		}
+	}
+	if got := sanitized.Get("User-Agent"); got != "router-relay/1.0" {
+		t.Fatalf("User-Agent = %q", got)
+	}
+	if got := sanitized.Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	if got := sanitized.Get("Anthropic-Beta"); got != "tools-2024-04-04" {
+		t.Fatalf("passthrough Anthropic-Beta = %q", got)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	relaymeta "github.com/yeying-community/router/internal/relay/meta"
 	relaymodel "github.com/yeying-community/router/internal/relay/model"
 	"github.com/yeying-community/router/internal/relay/relaymode"
+	"github.com/yeying-community/router/internal/testutil"
 )
 
 func TestGetRequestBodyMessagesPassThroughConvertsAnthropicImageURLToBase64(t *testing.T) {
@@ -117,11 +118,8 @@ func TestGetRequestBodyMessagesPassThroughConvertsAnthropicImageURLToBase64(t *t
 
 func newPolicyTestContext(t *testing.T, body string) *gin.Context {
 	t.Helper()
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(recorder)
-	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
+	c, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/messages", nil)
+	req := testutil.NewTestRequest(http.MethodPost, "/v1/messages", body)
 	c.Request = req
 	return c
 }
@@ -3,21 +3,17 @@ package controller
 import (
 	"bytes"
 	"mime/multipart"
-	"net/http/httptest"
+	"net/http"
 	"strings"
 	"testing"
 
-	"github.com/gin-gonic/gin"
 	adminmodel "github.com/yeying-community/router/internal/admin/model"
 	relaymodel "github.com/yeying-community/router/internal/relay/model"
+	"github.com/yeying-community/router/internal/testutil"
 )
 
 func TestGetImageRequestAppliesDefaults(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(recorder)
-	ctx.Request = httptest.NewRequest("POST", "/v1/images/generations", strings.NewReader(`{"prompt":"draw a city skyline"}`))
-	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/images/generations", `{"prompt":"draw a city skyline"}`)
 
 	req, err := getImageRequest(ctx, 0)
 	if err != nil {
@@ -38,7 +34,6 @@ func TestGetImageRequestAppliesDefaults(t *testing.T) {
 }
 
 func TestGetImageEditRequestParsesMultipartForm(t *testing.T) {
-	gin.SetMode(gin.TestMode)
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 	if err := writer.WriteField("model", "gpt-image-2"); err != nil {
@@ -58,9 +53,7 @@ func TestGetImageEditRequestParsesMultipartForm(t *testing.T) {
 		t.Fatalf("writer.Close() error = %v", err)
 	}
 
-	recorder := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(recorder)
-	ctx.Request = httptest.NewRequest("POST", "/v1/images/edits", body)
+	ctx, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/images/edits", body)
 	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
 
 	req, form, err := getImageEditRequest(ctx)
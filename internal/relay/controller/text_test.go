@@ -1,28 +1,22 @@
 package controller
 
 import (
-	"net/http/httptest"
+	"net/http"
 	"testing"
 
-	"github.com/gin-gonic/gin"
 	"github.com/yeying-community/router/internal/relay/meta"
+	"github.com/yeying-community/router/internal/testutil"
 )
 
 func TestLogTextStreamAcceptConflictIgnoresAlignedStreamAndAccept(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(recorder)
-	ctx.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	ctx, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 	ctx.Request.Header.Set("Accept", "text/event-stream")
 
 	logTextStreamAcceptConflict(ctx, &meta.Meta{IsStream: true})
 }
 
 func TestLogTextStreamAcceptConflictIgnoresEmptyAccept(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	recorder := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(recorder)
-	ctx.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	ctx, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/chat/completions", nil)
 
 	logTextStreamAcceptConflict(ctx, &meta.Meta{IsStream: false})
 }
@@ -0,0 +1,106 @@
+package relay
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+)
+
+const redactedPlaceholder = "[REDACTED]"
+
+// ResponsePostProcessor rewrites a response body (or a single SSE event for
+// streaming responses) before it is delivered to the client.
+type ResponsePostProcessor interface {
+	Process(ctx context.Context, body []byte) ([]byte, error)
+}
+
+type namedResponsePostProcessor struct {
+	name      string
+	processor ResponsePostProcessor
+}
+
+var (
+	responsePostProcessorsLock sync.RWMutex
+	responsePostProcessors     []namedResponsePostProcessor
+)
+
+// RegisterResponsePostProcessor adds p to the chain, replacing any processor
+// already registered under name. Processors run in registration order.
+func RegisterResponsePostProcessor(name string, p ResponsePostProcessor) {
+	responsePostProcessorsLock.Lock()
+	defer responsePostProcessorsLock.Unlock()
+	for i := range responsePostProcessors {
+		if responsePostProcessors[i].name == name {
+			responsePostProcessors[i].processor = p
+			return
+		}
+	}
+	responsePostProcessors = append(responsePostProcessors, namedResponsePostProcessor{name: name, processor: p})
+}
+
+func HasResponsePostProcessors() bool {
+	responsePostProcessorsLock.RLock()
+	defer responsePostProcessorsLock.RUnlock()
+	return len(responsePostProcessors) > 0
+}
+
+// ApplyResponsePostProcessors runs every registered processor over body.
+func ApplyResponsePostProcessors(ctx context.Context, body []byte) ([]byte, error) {
+	responsePostProcessorsLock.RLock()
+	processors := responsePostProcessors
+	responsePostProcessorsLock.RUnlock()
+	var err error
+	for _, entry := range processors {
+		body, err = entry.processor.Process(ctx, body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// RegexRedactor replaces every match of Patterns with Replacement
+// ("[REDACTED]" when empty).
+type RegexRedactor struct {
+	Patterns    []*regexp.Regexp
+	Replacement string
+}
+
+func (r *RegexRedactor) Process(_ context.Context, body []byte) ([]byte, error) {
+	replacement := []byte(r.Replacement)
+	if r.Replacement == "" {
+		replacement = []byte(redactedPlaceholder)
+	}
+	for _, pattern := range r.Patterns {
+		body = pattern.ReplaceAllLiteral(body, replacement)
+	}
+	return body, nil
+}
+
+// InitResponsePostProcessors registers the built-in redactor from
+// relay.response_redact_patterns (env RESPONSE_REDACT_PATTERNS). Invalid
+// patterns are logged and skipped.
+func InitResponsePostProcessors() {
+	patterns := make([]*regexp.Regexp, 0, len(config.ResponseRedactPatterns))
+	for _, raw := range config.ResponseRedactPatterns {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(raw)
+		if err != nil {
+			logger.SysErrorf("invalid response redact pattern %q: %v", raw, err)
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return
+	}
+	RegisterResponsePostProcessor("regex_redactor", &RegexRedactor{Patterns: patterns})
+	logger.SysLogf("response redaction enabled with %d pattern(s)", len(patterns))
+}
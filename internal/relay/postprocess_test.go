@@ -0,0 +1,28 @@
+package relay
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestRegexRedactorProcess(t *testing.T) {
+	redactor := &RegexRedactor{Patterns: []*regexp.Regexp{
+		regexp.MustCompile(`sk-[A-Za-z0-9]{8,}`),
+		regexp.MustCompile(`\d{3}-\d{4}-\d{4}`),
+	}}
+	got, err := redactor.Process(context.Background(), []byte(`{"text":"key sk-abcdef123456 phone 138-1234-5678"}`))
+	if err != nil {
+		t.Fatalf("Process error: %v", err)
+	}
+	want := `{"text":"key [REDACTED] phone [REDACTED]"}`
+	if string(got) != want {
+		t.Fatalf("Process = %s, want %s", got, want)
+	}
+
+	redactor.Replacement = "***"
+	got, _ = redactor.Process(context.Background(), []byte("sk-abcdef123456"))
+	if string(got) != "***" {
+		t.Fatalf("Process with custom replacement = %s", got)
+	}
+}
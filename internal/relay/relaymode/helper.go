@@ -1,14 +1,38 @@
 package relaymode
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/yeying-community/router/common/config"
+)
 
 func NormalizePath(path string) string {
 	if strings.HasPrefix(path, "/api/v1/public/") {
 		return "/v1/" + strings.TrimPrefix(path, "/api/v1/public/")
 	}
+	if config.AzureOpenAIMode {
+		if _, canonical, ok := AzureDeploymentPath(path); ok {
+			return canonical
+		}
+	}
 	return path
 }
 
+// AzureDeploymentPath splits an Azure OpenAI style path,
+// /openai/deployments/<deployment>/<operation>, into the deployment name and
+// the canonical /v1/<operation> path.
+func AzureDeploymentPath(path string) (deployment string, canonical string, ok bool) {
+	rest, found := strings.CutPrefix(path, "/openai/deployments/")
+	if !found {
+		return "", "", false
+	}
+	deployment, operation, found := strings.Cut(rest, "/")
+	if !found || deployment == "" || operation == "" {
+		return "", "", false
+	}
+	return deployment, "/v1/" + operation, true
+}
+
 func GetByPath(path string) int {
 	path = NormalizePath(path)
 	relayMode := Unknown
@@ -1,6 +1,10 @@
 package relaymode
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/yeying-community/router/common/config"
+)
 
 func TestGetByPath_Videos(t *testing.T) {
 	if got := GetByPath("/v1/videos"); got != Videos {
@@ -76,3 +80,24 @@ func TestGetByPath_Realtime(t *testing.T) {
 		}
 	}
 }
+
+func TestGetByPath_AzureDeployment(t *testing.T) {
+	prev := config.AzureOpenAIMode
+	defer func() { config.AzureOpenAIMode = prev }()
+
+	path := "/openai/deployments/gpt-4o-prod/chat/completions"
+	config.AzureOpenAIMode = false
+	if got := GetByPath(path); got != Unknown {
+		t.Fatalf("GetByPath(%s)=%d with Azure mode off, want %d", path, got, Unknown)
+	}
+	config.AzureOpenAIMode = true
+	if got := GetByPath(path); got != ChatCompletions {
+		t.Fatalf("GetByPath(%s)=%d, want %d", path, got, ChatCompletions)
+	}
+	if deployment, canonical, ok := AzureDeploymentPath("/openai/deployments/text-embed/embeddings"); !ok || deployment != "text-embed" || canonical != "/v1/embeddings" {
+		t.Fatalf("AzureDeploymentPath = %q, %q, %t", deployment, canonical, ok)
+	}
+	if _, _, ok := AzureDeploymentPath("/openai/deployments/only-name"); ok {
+		t.Fatalf("AzureDeploymentPath accepted a path without an operation")
+	}
+}
@@ -0,0 +1,59 @@
+package testutil
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/i18n"
+)
+
+func TestNewTestGinContext(t *testing.T) {
+	c, recorder := NewTestGinContext(http.MethodPost, "/api/v1/public/auth/wallet/nonce", map[string]string{"address": "0xabc"})
+	if recorder == nil || c.Request.Method != http.MethodPost {
+		t.Fatalf("unexpected context: %+v", c.Request)
+	}
+	body, _ := io.ReadAll(c.Request.Body)
+	if string(body) != `{"address":"0xabc"}` {
+		t.Fatalf("body = %s", body)
+	}
+	if got := c.Request.Header.Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+	if c.GetString(helper.TraceIDKey) != TestTraceID || c.GetString(i18n.ContextKey) != TestLocale {
+		t.Fatalf("trace id / locale not pre-populated")
+	}
+
+	SetSessionUser(c, "u-1", 10)
+	if got := sessions.Default(c).Get("id"); got != "u-1" {
+		t.Fatalf("session id = %v", got)
+	}
+	if c.GetString(ctxkey.Id) != "u-1" || c.GetInt(ctxkey.Role) != 10 {
+		t.Fatalf("context user keys not set")
+	}
+
+	SetBearerToken(c, "sk-test")
+	if got := c.Request.Header.Get("Authorization"); got != "Bearer sk-test" {
+		t.Fatalf("Authorization = %q", got)
+	}
+}
+
+func TestServeTestRequest(t *testing.T) {
+	engine := NewTestEngine()
+	engine.POST("/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusCreated, c.ContentType(), body)
+	})
+	recorder := ServeTestRequest(engine, NewTestRequest(http.MethodPost, "/echo", []byte(`{"ok":true}`)))
+	if recorder.Code != http.StatusCreated || recorder.Body.String() != `{"ok":true}` {
+		t.Fatalf("status = %d body = %q", recorder.Code, recorder.Body.String())
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+}
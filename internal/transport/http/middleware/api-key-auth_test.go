@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestUserOrApiKeyAuth_RegisteredKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey error: %v", err)
+	}
+	registered := model.ApiKey{Id: "key-1", UserId: "user-1", PublicKey: common.EncodeApiKeyPublicKey(pub)}
+	owner := &model.User{Id: "user-1", Username: "machine", Role: model.RoleCommonUser, Status: model.UserStatusEnabled}
+
+	prevDB := model.DB
+	defer func() { model.DB = prevDB }()
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	// the dry-run DB returns no rows, so the registered key is served here
+	_ = db.Callback().Query().After("gorm:query").Register("test:api_key", func(tx *gorm.DB) {
+		if key, ok := tx.Statement.Dest.(*model.ApiKey); ok && len(tx.Statement.Vars) > 0 && tx.Statement.Vars[0] == registered.Id {
+			*key = registered
+			return
+		}
+		_ = tx.AddError(gorm.ErrRecordNotFound)
+	})
+	model.DB = db
+	model.BindUserRepository(model.UserRepository{
+		GetUserById: func(id string, selectAll bool) (*model.User, error) {
+			if id == owner.Id {
+				return owner, nil
+			}
+			return nil, gorm.ErrRecordNotFound
+		},
+		ValidateAccessToken: func(token string) *model.User { return nil },
+	})
+
+	engine := testutil.NewTestEngine()
+	engine.Use(sessions.Sessions("session", cookie.NewStore([]byte("test-secret"))))
+	engine.GET("/api/v1/public/token/", UserOrApiKeyAuth(), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(ctxkey.Id))
+	})
+	sign := func(keyID string, signer ed25519.PrivateKey, path string, ts int64) *http.Request {
+		req := testutil.NewTestRequest(http.MethodGet, "/api/v1/public/token/", nil)
+		sig := ed25519.Sign(signer, common.ApiKeySigningPayload(http.MethodGet, path, ts))
+		req.Header.Set("Authorization", "ApiKey "+keyID+":"+base64.StdEncoding.EncodeToString(sig))
+		req.Header.Set(common.ApiKeyTimestampHeader, strconv.FormatInt(ts, 10))
+		return req
+	}
+
+	recorder := testutil.ServeTestRequest(engine, sign(registered.Id, priv, "/api/v1/public/token/", time.Now().Unix()))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != owner.Id {
+		t.Fatalf("registered key: status = %d body = %q, want 200 and the owner id", recorder.Code, recorder.Body.String())
+	}
+
+	_, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	rejected := map[string]*http.Request{
+		"unknown key":     sign("key-2", priv, "/api/v1/public/token/", time.Now().Unix()),
+		"wrong signer":    sign(registered.Id, otherPriv, "/api/v1/public/token/", time.Now().Unix()),
+		"other path":      sign(registered.Id, priv, "/api/v1/public/log", time.Now().Unix()),
+		"stale timestamp": sign(registered.Id, priv, "/api/v1/public/token/", time.Now().Add(-2*common.ApiKeyMaxClockSkew).Unix()),
+	}
+	for name, req := range rejected {
+		if recorder := testutil.ServeTestRequest(engine, req); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: status = %d, want 401", name, recorder.Code)
+		}
+	}
+
+	// without an ApiKey header the UserAuth path applies
+	if recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/api/v1/public/token/", nil)); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("no credentials: status = %d, want 401", recorder.Code)
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/logger"
+)
+
+// apiLogEntry is one line of api.log. It carries the fields of the text access
+// log printed to stdout plus the response size and the caller's request id;
+// relay requests also get the token, channel and token usage.
+type apiLogEntry struct {
+	Time      string  `json:"time"`
+	TraceID   string  `json:"trace_id,omitempty"`
+	RequestID string  `json:"request_id,omitempty"`
+	Status    int     `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	ClientIP  string  `json:"client_ip"`
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	BytesSent int     `json:"bytes_sent"`
+	Model     string  `json:"model,omitempty"`
+	TokenID   string  `json:"token_id,omitempty"`
+	ChannelID string  `json:"channel_id,omitempty"`
+	// pointers so a relay request that used zero tokens still logs 0
+	InputTokens  *int `json:"input_tokens,omitempty"`
+	OutputTokens *int `json:"output_tokens,omitempty"`
+}
+
+// NewApiLogger writes a JSON access log line per request to logDir/api.log,
+// rotated at maxSizeMB and kept for maxAgeDays (compressed when
+// logging.rotate_compress is set). With logging.syslog_enabled each line is
+// also forwarded to logging.syslog_addr in the background. Call
+// logger.FlushApiLog and logger.StopApiLogForwarding before exiting.
+func NewApiLogger(logDir string, maxSizeMB int, maxAgeDays int) gin.HandlerFunc {
+	logger.SetupApiLogFile(logDir, maxSizeMB, maxAgeDays)
+	if config.SyslogEnabled {
+		if err := logger.SetupApiLogForwarding(config.SyslogProtocol, config.SyslogAddr); err != nil {
+			logger.SysErrorf("api log forwarding to %s disabled: %v", config.SyslogAddr, err)
+		} else {
+			logger.SysLogf("api log forwarded to %s (%s)", config.SyslogAddr, config.SyslogProtocol)
+		}
+	}
+	return func(c *gin.Context) {
+		start := time.Now()
+		// token_id, channel_id and the usage are set by auth, the distributor
+		// and the relay, so everything is read after c.Next returns
+		c.Next()
+		bytesSent := c.Writer.Size()
+		if bytesSent < 0 {
+			bytesSent = 0
+		}
+		line, err := json.Marshal(apiLogEntry{
+			Time:         start.Format(time.RFC3339Nano),
+			TraceID:      c.GetString(helper.TraceIDKey),
+			RequestID:    c.GetString(helper.RequestIdKey),
+			Status:       c.Writer.Status(),
+			LatencyMs:    float64(time.Since(start).Microseconds()) / 1000,
+			ClientIP:     c.ClientIP(),
+			Method:       c.Request.Method,
+			Path:         c.Request.URL.Path,
+			BytesSent:    bytesSent,
+			Model:        c.GetString(ctxkey.ModelName),
+			TokenID:      c.GetString(ctxkey.TokenId),
+			ChannelID:    c.GetString(ctxkey.ChannelId),
+			InputTokens:  contextInt(c, ctxkey.InputTokens),
+			OutputTokens: contextInt(c, ctxkey.OutputTokens),
+		})
+		if err != nil {
+			return
+		}
+		logger.WriteApiLog(append(line, '\n'))
+	}
+}
+
+func contextInt(c *gin.Context, key string) *int {
+	value, ok := c.Get(key)
+	if !ok {
+		return nil
+	}
+	n, ok := value.(int)
+	if !ok {
+		return nil
+	}
+	return &n
+}
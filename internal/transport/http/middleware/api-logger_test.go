@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestApiLogger_LogsValuesSetDownstream(t *testing.T) {
+	dir := t.TempDir()
+	logger.LogDir = dir
+	engine := testutil.NewTestEngine()
+	engine.Use(NewApiLogger(dir, 1, 1))
+	// stands in for TokenAuth and Distribute, which run after the logger
+	engine.Use(func(c *gin.Context) {
+		c.Set(ctxkey.TokenId, "tok-1")
+		c.Set(ctxkey.ChannelId, "ch-1")
+		c.Set(ctxkey.ModelName, "gpt-4o-mini")
+		c.Next()
+	})
+	engine.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Set(ctxkey.InputTokens, 12)
+		c.Set(ctxkey.OutputTokens, 0)
+		c.Status(http.StatusOK)
+	})
+
+	testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodPost, "/v1/chat/completions", nil))
+	logger.FlushApiLog()
+
+	raw, err := os.ReadFile(filepath.Join(dir, "api.log"))
+	if err != nil {
+		t.Fatalf("read api.log: %v", err)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		t.Fatalf("api.log line %q: %v", raw, err)
+	}
+	want := map[string]any{
+		"token_id":      "tok-1",
+		"channel_id":    "ch-1",
+		"model":         "gpt-4o-mini",
+		"input_tokens":  float64(12),
+		"output_tokens": float64(0),
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Fatalf("%s = %v, want %v (line %s)", key, entry[key], value, raw)
+		}
+	}
+}
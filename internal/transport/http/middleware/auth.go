@@ -12,11 +12,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/yeying-community/router/common"
 	"github.com/yeying-community/router/common/blacklist"
-	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/common/ctxkey"
 	"github.com/yeying-community/router/common/logger"
 	"github.com/yeying-community/router/common/network"
-	"github.com/yeying-community/router/common/random"
 	"github.com/yeying-community/router/internal/admin/model"
 )
 
@@ -78,49 +76,36 @@ func authHelper(c *gin.Context, minRole int) {
 
 		// Try wallet JWT first
 		if bearer != "" {
-			if claims, err := common.VerifyWalletJWT(bearer); err == nil {
+			if claims, renewed, err := common.VerifyWalletJWTWithRenewal(bearer); err == nil {
 				logger.Loginf(c.Request.Context(), "auth wallet jwt verified uid=%s addr=%s", claims.UserID, claims.WalletAddress)
-				user := model.User{Id: claims.UserID}
-				foundById := false
-				if strings.TrimSpace(claims.UserID) != "" {
-					if err := user.FillUserById(); err == nil {
-						foundById = true
-					} else {
-						logger.Loginf(c.Request.Context(), "auth wallet jwt FillUserById fail uid=%s err=%v", claims.UserID, err)
-					}
-				}
-
-				if !foundById && claims.WalletAddress != "" {
-					addr := strings.ToLower(claims.WalletAddress)
-					user = model.User{WalletAddress: &addr}
-					if err := user.FillUserByWalletAddress(); err == nil {
-						logger.Loginf(c.Request.Context(), "auth wallet jwt fallback by address success addr=%s uid=%s", claims.WalletAddress, user.Id)
-						foundById = true
-					} else {
-						logger.Loginf(c.Request.Context(), "auth wallet jwt fallback by address fail addr=%s err=%v", claims.WalletAddress, err)
-					}
-				}
-
-				if foundById {
-					matched := user.WalletAddress != nil && strings.ToLower(*user.WalletAddress) == strings.ToLower(claims.WalletAddress)
-					enabled := user.Status == model.UserStatusEnabled
-					notBanned := !blacklist.IsUserBanned(user.Id)
-					if matched && enabled && notBanned {
-						effectiveRole, _ := computeEffectiveAuthRole(&user)
-						username = user.Username
-						role = effectiveRole
-						id = user.Id
-						status = user.Status
-						logger.Loginf(c.Request.Context(), "auth via wallet jwt success user=%s addr=%s", user.Id, claims.WalletAddress)
-					} else {
-						logger.Loginf(c.Request.Context(), "auth wallet jwt reject uid=%s matched=%t enabled=%t notBanned=%t db_addr=%v token_addr=%s status=%d", user.Id, matched, enabled, notBanned, user.WalletAddress, claims.WalletAddress, user.Status)
-					}
+				if user, ok := walletJWTUser(c.Request.Context(), claims); ok {
+					setRenewedWalletToken(c, renewed)
+					effectiveRole, _ := computeEffectiveAuthRole(user)
+					username = user.Username
+					role = effectiveRole
+					id = user.Id
+					status = user.Status
+					logger.Loginf(c.Request.Context(), "auth via wallet jwt success user=%s addr=%s", user.Id, claims.WalletAddress)
 				}
 			} else {
 				logger.Loginf(c.Request.Context(), "auth wallet jwt verify failed err=%v token_len=%d", err, len(bearer))
 			}
 		}
 
+		// Then a token from the external issuer, when one is configured
+		if username == nil && bearer != "" && common.ExternalJWTEnabled() {
+			if user, addr, err := authenticateExternalJWT(c.Request.Context(), bearer); err == nil {
+				effectiveRole, _ := computeEffectiveAuthRole(user)
+				username = user.Username
+				role = effectiveRole
+				id = user.Id
+				status = user.Status
+				logger.Loginf(c.Request.Context(), "auth via external jwt success user=%s addr=%s", user.Id, addr)
+			} else {
+				logger.Loginf(c.Request.Context(), "auth external jwt verify failed err=%v", err)
+			}
+		}
+
 		if username == nil {
 			user := model.ValidateAccessToken(bearer)
 			if user != nil && user.Username != "" {
@@ -150,6 +135,7 @@ func authHelper(c *gin.Context, minRole int) {
 			role = effectiveRole
 			status = freshUser.Status
 			c.Set(ctxkey.CanManageUsers, canManageUsers)
+			c.Set(ctxkey.User, freshUser)
 		} else {
 			c.Set(ctxkey.CanManageUsers, false)
 		}
@@ -183,6 +169,47 @@ func authHelper(c *gin.Context, minRole int) {
 	c.Next()
 }
 
+// walletJWTUser loads the user a verified wallet JWT was issued to, falling
+// back to the wallet address for tokens minted before the user ID claim, and
+// reports whether the user still owns that wallet (or, for passkey tokens, is
+// that user) and may log in.
+func walletJWTUser(ctx context.Context, claims *common.WalletClaims) (*model.User, bool) {
+	user := model.User{Id: claims.UserID}
+	found := false
+	if strings.TrimSpace(claims.UserID) != "" {
+		if err := user.FillUserById(); err == nil {
+			found = true
+		} else {
+			logger.Loginf(ctx, "auth wallet jwt FillUserById fail uid=%s err=%v", claims.UserID, err)
+		}
+	}
+	if !found && claims.WalletAddress != "" {
+		addr := model.NormalizeWalletAddress(claims.WalletAddress)
+		user = model.User{WalletAddress: &addr}
+		if err := user.FillUserByWalletAddress(); err == nil {
+			logger.Loginf(ctx, "auth wallet jwt fallback by address success addr=%s uid=%s", claims.WalletAddress, user.Id)
+			found = true
+		} else {
+			logger.Loginf(ctx, "auth wallet jwt fallback by address fail addr=%s err=%v", claims.WalletAddress, err)
+		}
+	}
+	if !found {
+		return nil, false
+	}
+	matched := model.UserHasWalletAddress(&user, claims.WalletAddress)
+	if claims.WebAuthnCredentialID != "" && claims.WalletAddress == "" {
+		// passkey tokens carry no wallet; they were issued to this user ID
+		matched = user.Id == claims.UserID
+	}
+	enabled := user.Status == model.UserStatusEnabled
+	notBanned := !blacklist.IsUserBanned(user.Id)
+	if !matched || !enabled || !notBanned {
+		logger.Loginf(ctx, "auth wallet jwt reject uid=%s matched=%t enabled=%t notBanned=%t db_addr=%v token_addr=%s status=%d", user.Id, matched, enabled, notBanned, user.WalletAddress, claims.WalletAddress, user.Status)
+		return nil, false
+	}
+	return &user, true
+}
+
 func UserAuth() func(c *gin.Context) {
 	return func(c *gin.Context) {
 		authHelper(c, model.RoleCommonUser)
@@ -216,73 +243,12 @@ func TokenAuth() func(c *gin.Context) {
 
 		// 1) 尝试钱包 JWT
 		if claims, err := common.VerifyWalletJWT(auth); err == nil {
-			user := model.User{Id: claims.UserID}
-			found := false
-			if strings.TrimSpace(claims.UserID) != "" {
-				if err := user.FillUserById(); err == nil {
-					found = true
-				} else {
-					logger.Loginf(ctx, "token auth wallet jwt FillUserById fail uid=%s err=%v", claims.UserID, err)
-				}
-			}
-			if !found && claims.WalletAddress != "" {
-				addr := strings.ToLower(claims.WalletAddress)
-				user = model.User{WalletAddress: &addr}
-				if err := user.FillUserByWalletAddress(); err == nil {
-					found = true
-					logger.Loginf(ctx, "token auth wallet jwt fallback by address success addr=%s uid=%s", claims.WalletAddress, user.Id)
-				} else {
-					logger.Loginf(ctx, "token auth wallet jwt fallback by address fail addr=%s err=%v", claims.WalletAddress, err)
-				}
-			}
-			if !found {
-				abortWithMessage(c, http.StatusUnauthorized, "token 对应的用户不存在")
+			user, ok := walletJWTUser(ctx, claims)
+			if !ok {
+				abortWithMessage(c, http.StatusUnauthorized, "token 对应的用户不存在、已被封禁或已解绑钱包")
 				return
 			}
-			if user.Status != model.UserStatusEnabled || blacklist.IsUserBanned(user.Id) {
-				logger.Loginf(ctx, "token auth wallet jwt banned/disabled uid=%s status=%d", user.Id, user.Status)
-				abortWithMessage(c, http.StatusForbidden, "用户已被封禁")
-				return
-			}
-			requestModel, err := getRequestModel(c)
-			if err != nil && shouldCheckModel(c) {
-				abortWithMessage(c, http.StatusBadRequest, err.Error())
-				return
-			}
-			requestModel, err = hydrateVideoTaskRelayContext(c, requestModel)
-			if err != nil && shouldCheckModel(c) {
-				abortWithMessage(c, http.StatusBadRequest, err.Error())
-				return
-			}
-			c.Set(ctxkey.RequestModel, requestModel)
-			c.Set(ctxkey.Id, user.Id)
-
-			// 自动选择该用户的第一个可用 sk 作为默认 key（便于 JWT 直连）
-			if token, terr := model.GetFirstAvailableToken(user.Id); terr == nil {
-				// subnet 检查
-				if token.Subnet != nil && *token.Subnet != "" {
-					if !network.IsIpInSubnets(ctx, c.ClientIP(), *token.Subnet) {
-						logger.Loginf(ctx, "token auth wallet jwt subnet deny user=%s ip=%s subnet=%s", token.UserId, c.ClientIP(), *token.Subnet)
-						abortWithMessage(c, http.StatusForbidden, fmt.Sprintf("该令牌只能在指定网段使用：%s，当前 ip：%s", *token.Subnet, c.ClientIP()))
-						return
-					}
-				}
-				if token.Models != nil && *token.Models != "" {
-					c.Set(ctxkey.AvailableModels, *token.Models)
-					if requestModel != "" && !isModelInList(requestModel, *token.Models) {
-						abortWithMessage(c, http.StatusForbidden, fmt.Sprintf("该令牌无权使用模型：%s", requestModel))
-						return
-					}
-				}
-				c.Set(ctxkey.TokenId, token.Id)
-				c.Set(ctxkey.TokenName, token.Name)
-				logger.Loginf(ctx, "token auth via wallet jwt success user=%s addr=%s use_token=%s", user.Id, claims.WalletAddress, token.Id)
-			} else {
-				c.Set(ctxkey.TokenId, "")
-				c.Set(ctxkey.TokenName, "wallet_jwt")
-				logger.Loginf(ctx, "token auth via wallet jwt success user=%s addr=%s no_token_found", user.Id, claims.WalletAddress)
-			}
-			c.Next()
+			setUserRelayContext(c, user, "wallet_jwt", model.NormalizeWalletAddress(claims.WalletAddress))
 			return
 		}
 
@@ -296,54 +262,23 @@ func TokenAuth() func(c *gin.Context) {
 				return
 			}
 			addr := strings.ToLower(address)
-			user, err := findOrCreateWalletUser(addr, ctx)
+			user, err := resolveWalletUser(ctx, addr)
 			if err != nil {
 				logger.Loginf(ctx, "token auth ucan resolve user failed addr=%s err=%v", addr, err)
 				abortWithMessage(c, http.StatusUnauthorized, err.Error())
 				return
 			}
+			if user.Status == model.UserStatusPendingApproval {
+				logger.Loginf(ctx, "token auth ucan user pending approval uid=%s", user.Id)
+				abortWithMessage(c, http.StatusForbidden, "账户正在审批，请等待管理员确认")
+				return
+			}
 			if user.Status != model.UserStatusEnabled || blacklist.IsUserBanned(user.Id) {
 				logger.Loginf(ctx, "token auth ucan banned/disabled uid=%s status=%d", user.Id, user.Status)
 				abortWithMessage(c, http.StatusForbidden, "用户已被封禁")
 				return
 			}
-			requestModel, err := getRequestModel(c)
-			if err != nil && shouldCheckModel(c) {
-				abortWithMessage(c, http.StatusBadRequest, err.Error())
-				return
-			}
-			requestModel, err = hydrateVideoTaskRelayContext(c, requestModel)
-			if err != nil && shouldCheckModel(c) {
-				abortWithMessage(c, http.StatusBadRequest, err.Error())
-				return
-			}
-			c.Set(ctxkey.RequestModel, requestModel)
-			c.Set(ctxkey.Id, user.Id)
-
-			if token, terr := model.GetFirstAvailableToken(user.Id); terr == nil {
-				if token.Subnet != nil && *token.Subnet != "" {
-					if !network.IsIpInSubnets(ctx, c.ClientIP(), *token.Subnet) {
-						logger.Loginf(ctx, "token auth ucan subnet deny user=%s ip=%s subnet=%s", token.UserId, c.ClientIP(), *token.Subnet)
-						abortWithMessage(c, http.StatusForbidden, fmt.Sprintf("该令牌只能在指定网段使用：%s，当前 ip：%s", *token.Subnet, c.ClientIP()))
-						return
-					}
-				}
-				if token.Models != nil && *token.Models != "" {
-					c.Set(ctxkey.AvailableModels, *token.Models)
-					if requestModel != "" && !isModelInList(requestModel, *token.Models) {
-						abortWithMessage(c, http.StatusForbidden, fmt.Sprintf("该令牌无权使用模型：%s", requestModel))
-						return
-					}
-				}
-				c.Set(ctxkey.TokenId, token.Id)
-				c.Set(ctxkey.TokenName, token.Name)
-				logger.Loginf(ctx, "token auth via ucan success user=%s addr=%s use_token=%s", user.Id, addr, token.Id)
-			} else {
-				c.Set(ctxkey.TokenId, "")
-				c.Set(ctxkey.TokenName, "ucan")
-				logger.Loginf(ctx, "token auth via ucan success user=%s addr=%s no_token_found", user.Id, addr)
-			}
-			c.Next()
+			setUserRelayContext(c, user, "ucan", addr)
 			return
 		}
 
@@ -393,7 +328,7 @@ func TokenAuth() func(c *gin.Context) {
 		c.Set(ctxkey.RequestModel, requestModel)
 		if token.Models != nil && *token.Models != "" {
 			c.Set(ctxkey.AvailableModels, *token.Models)
-			if requestModel != "" && !isModelInList(requestModel, *token.Models) {
+			if requestModel != "" && !isModelInListGlob(requestModel, *token.Models) {
 				abortWithMessage(c, http.StatusForbidden, fmt.Sprintf("该令牌无权使用模型：%s", requestModel))
 				return
 			}
@@ -408,6 +343,10 @@ func TokenAuth() func(c *gin.Context) {
 			}
 		}
 
+		if c.GetString(ctxkey.SpecificChannelId) == "" && strings.TrimSpace(token.ChannelId) != "" {
+			c.Set(ctxkey.SpecificChannelId, strings.TrimSpace(token.ChannelId))
+		}
+
 		// set channel id for proxy relay
 		if channelId := c.Param("channelid"); channelId != "" {
 			c.Set(ctxkey.SpecificChannelId, channelId)
@@ -419,43 +358,69 @@ func TokenAuth() func(c *gin.Context) {
 	}
 }
 
-func findOrCreateWalletUser(addr string, ctx context.Context) (*model.User, error) {
-	user := model.User{WalletAddress: &addr}
-	if !model.IsWalletAddressAlreadyTaken(addr) {
-		if config.AutoRegisterEnabled {
-			return autoCreateWalletUser(addr, ctx)
-		}
-		return nil, errors.New("未找到钱包绑定的账户，请先绑定或由管理员开启自动注册")
+// setUserRelayContext prepares a relay request authenticated as user rather
+// than by an sk- token, then continues the chain. The user's first available
+// token is used as the default key (便于 JWT 直连) so its subnet and model
+// limits still apply. via names the credential in logs and, when the user has
+// no token, stands in for the token name.
+func setUserRelayContext(c *gin.Context, user *model.User, via string, addr string) {
+	ctx := c.Request.Context()
+	requestModel, err := getRequestModel(c)
+	if err != nil && shouldCheckModel(c) {
+		abortWithMessage(c, http.StatusBadRequest, err.Error())
+		return
 	}
-
-	if err := user.FillUserByWalletAddress(); err != nil {
-		return nil, err
+	requestModel, err = hydrateVideoTaskRelayContext(c, requestModel)
+	if err != nil && shouldCheckModel(c) {
+		abortWithMessage(c, http.StatusBadRequest, err.Error())
+		return
 	}
-	if user.Status == model.UserStatusDeleted {
-		_ = model.DB.Model(&user).Update("wallet_address", nil)
-		return findOrCreateWalletUser(addr, ctx)
+	c.Set(ctxkey.RequestModel, requestModel)
+	c.Set(ctxkey.Id, user.Id)
+	c.Set(ctxkey.User, user)
+
+	if token, terr := model.GetFirstAvailableToken(user.Id); terr == nil {
+		// subnet 检查
+		if token.Subnet != nil && *token.Subnet != "" {
+			if !network.IsIpInSubnets(ctx, c.ClientIP(), *token.Subnet) {
+				logger.Loginf(ctx, "token auth %s subnet deny user=%s ip=%s subnet=%s", via, token.UserId, c.ClientIP(), *token.Subnet)
+				abortWithMessage(c, http.StatusForbidden, fmt.Sprintf("该令牌只能在指定网段使用：%s，当前 ip：%s", *token.Subnet, c.ClientIP()))
+				return
+			}
+		}
+		if token.Models != nil && *token.Models != "" {
+			c.Set(ctxkey.AvailableModels, *token.Models)
+			if requestModel != "" && !isModelInListGlob(requestModel, *token.Models) {
+				abortWithMessage(c, http.StatusForbidden, fmt.Sprintf("该令牌无权使用模型：%s", requestModel))
+				return
+			}
+		}
+		c.Set(ctxkey.TokenId, token.Id)
+		c.Set(ctxkey.TokenName, token.Name)
+		logger.Loginf(ctx, "token auth via %s success user=%s addr=%s use_token=%s", via, user.Id, addr, token.Id)
+	} else {
+		c.Set(ctxkey.TokenId, "")
+		c.Set(ctxkey.TokenName, via)
+		logger.Loginf(ctx, "token auth via %s success user=%s addr=%s no_token_found", via, user.Id, addr)
 	}
-	return &user, nil
+	c.Next()
 }
 
-func autoCreateWalletUser(addr string, ctx context.Context) (*model.User, error) {
-	username := "wallet_" + random.GetRandomString(6)
-	for model.IsUsernameAlreadyTaken(username) {
-		username = "wallet_" + random.GetRandomString(6)
-	}
-	user := model.User{
-		Username:      username,
-		Password:      random.GetRandomString(16),
-		DisplayName:   username,
-		Role:          model.RoleCommonUser,
-		Status:        model.UserStatusEnabled,
-		WalletAddress: &addr,
-		HasPassword:   false,
-	}
-	if err := user.Insert(ctx, ""); err != nil {
-		return nil, err
+// walletUserResolver finds the user bound to a wallet address, registering one
+// when auto registration is on. The auth controller binds it so wallets that
+// sign in through middleware follow the same signup rules as wallet login.
+var walletUserResolver func(ctx context.Context, addr string) (*model.User, error)
+
+// SetWalletUserResolver registers resolver for wallet-authenticated requests.
+func SetWalletUserResolver(resolver func(ctx context.Context, addr string) (*model.User, error)) {
+	walletUserResolver = resolver
+}
+
+func resolveWalletUser(ctx context.Context, addr string) (*model.User, error) {
+	if walletUserResolver == nil {
+		return nil, errors.New("钱包登录未启用")
 	}
-	return &user, nil
+	return walletUserResolver(ctx, addr)
 }
 
 func normalizeSessionUserID(id interface{}) string {
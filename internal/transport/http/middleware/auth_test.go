@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestUserAuth_RejectsMissingOrInvalidBearer(t *testing.T) {
+	prevSecret := config.JWTSecret
+	defer func() { config.JWTSecret = prevSecret }()
+	config.JWTSecret = "test-secret"
+	model.BindUserRepository(model.UserRepository{
+		GetUserById:         func(id string, selectAll bool) (*model.User, error) { return nil, gorm.ErrRecordNotFound },
+		ValidateAccessToken: func(token string) *model.User { return nil },
+	})
+
+	for _, header := range []string{"", "Basic abc", "Bearer not-a-jwt", "sk-123"} {
+		c, recorder := testutil.NewTestGinContext(http.MethodGet, "/api/v1/public/user/self", nil)
+		if header != "" {
+			c.Request.Header.Set("Authorization", header)
+		}
+		UserAuth()(c)
+		if recorder.Code != http.StatusUnauthorized || !c.IsAborted() {
+			t.Fatalf("Authorization %q status = %d aborted = %t, want 401", header, recorder.Code, c.IsAborted())
+		}
+	}
+}
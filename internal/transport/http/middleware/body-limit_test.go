@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestBodySizeLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int64
+		body    string
+		chunked bool
+		want    int
+	}{
+		{name: "within limit", limit: 8, body: "12345678", want: http.StatusOK},
+		{name: "declared length over limit", limit: 8, body: "123456789", want: http.StatusRequestEntityTooLarge},
+		{name: "chunked body over limit", limit: 8, body: "123456789", chunked: true, want: http.StatusRequestEntityTooLarge},
+		{name: "disabled", limit: 0, body: strings.Repeat("x", 1<<12), want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := testutil.NewTestEngine()
+			engine.POST("/", BodySizeLimit(tt.limit), func(c *gin.Context) {
+				if _, err := io.ReadAll(c.Request.Body); err != nil {
+					c.Status(http.StatusRequestEntityTooLarge)
+					return
+				}
+				c.Status(http.StatusOK)
+			})
+			req := testutil.NewTestRequest(http.MethodPost, "/", tt.body)
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			recorder := testutil.ServeTestRequest(engine, req)
+			if recorder.Code != tt.want {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package middleware
 import (
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -10,17 +12,97 @@ import (
 	"github.com/yeying-community/router/common/config"
 )
 
-func CORS() gin.HandlerFunc {
+// CORSConfig is the cross-origin policy for a set of routes. An empty
+// AllowedOrigins echoes any non-empty Origin; "*" and "*.example.com" style
+// patterns are accepted.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowCredentials bool
+	MaxAgeSecs       int
+}
+
+var defaultCORSMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+
+// DefaultCORSConfig is the global policy from the cors section of config.yaml.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins:   config.CorsAllowedOrigins,
+		AllowedMethods:   defaultCORSMethods,
+		AllowCredentials: config.CorsAllowCredentials,
+		MaxAgeSecs:       config.CorsMaxAgeSeconds,
+	}
+}
+
+type corsOverride struct {
+	prefix  string
+	handler gin.HandlerFunc
+}
+
+var (
+	corsOverridesMutex sync.RWMutex
+	corsOverrides      []corsOverride
+)
+
+// SetCORSOverride applies cfg instead of the global policy to every path under
+// pathPrefix; the longest matching prefix wins. It must be registered on the
+// path itself rather than as route middleware, because preflight requests are
+// answered by the global handler before routing.
+func SetCORSOverride(pathPrefix string, cfg CORSConfig) {
+	handler := newCORSHandler(cfg)
+	corsOverridesMutex.Lock()
+	defer corsOverridesMutex.Unlock()
+	for i := range corsOverrides {
+		if corsOverrides[i].prefix == pathPrefix {
+			corsOverrides[i].handler = handler
+			return
+		}
+	}
+	corsOverrides = append(corsOverrides, corsOverride{prefix: pathPrefix, handler: handler})
+}
+
+func corsOverrideFor(path string) gin.HandlerFunc {
+	corsOverridesMutex.RLock()
+	defer corsOverridesMutex.RUnlock()
+	var matched *corsOverride
+	for i := range corsOverrides {
+		if strings.HasPrefix(path, corsOverrides[i].prefix) && (matched == nil || len(corsOverrides[i].prefix) > len(matched.prefix)) {
+			matched = &corsOverrides[i]
+		}
+	}
+	if matched == nil {
+		return nil
+	}
+	return matched.handler
+}
+
+// CORS applies cfg, or the override registered for the request path. Preflight
+// OPTIONS requests are answered here and never reach the route handlers.
+func CORS(cfg CORSConfig) gin.HandlerFunc {
+	handler := newCORSHandler(cfg)
+	return func(c *gin.Context) {
+		if override := corsOverrideFor(c.Request.URL.Path); override != nil {
+			override(c)
+			return
+		}
+		handler(c)
+	}
+}
+
+func newCORSHandler(cfg CORSConfig) gin.HandlerFunc {
 	corsConfig := cors.DefaultConfig()
-	corsConfig.AllowCredentials = true
-	if len(config.CorsAllowedOrigins) == 0 {
+	corsConfig.AllowCredentials = cfg.AllowCredentials
+	if cfg.MaxAgeSecs > 0 {
+		corsConfig.MaxAge = time.Duration(cfg.MaxAgeSecs) * time.Second
+	}
+	if len(cfg.AllowedOrigins) == 0 {
 		corsConfig.AllowOriginFunc = func(origin string) bool {
 			return origin != ""
 		}
 	} else {
-		allowed := make([]string, 0, len(config.CorsAllowedOrigins))
+		allowed := make([]string, 0, len(cfg.AllowedOrigins))
 		allowAll := false
-		for _, origin := range config.CorsAllowedOrigins {
+		for _, origin := range cfg.AllowedOrigins {
 			origin = strings.TrimSpace(origin)
 			if origin == "" {
 				continue
@@ -50,7 +132,10 @@ func CORS() gin.HandlerFunc {
 			return false
 		}
 	}
-	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	corsConfig.AllowMethods = defaultCORSMethods
+	if len(cfg.AllowedMethods) > 0 {
+		corsConfig.AllowMethods = cfg.AllowedMethods
+	}
 	corsConfig.AllowHeaders = []string{
 		"Authorization",
 		"Content-Type",
@@ -59,6 +144,7 @@ func CORS() gin.HandlerFunc {
 		"X-Requested-With",
 		"X-Request-Id",
 	}
+	corsConfig.ExposeHeaders = []string{WalletRenewedTokenHeader}
 	return cors.New(corsConfig)
 }
 
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestCORS_PreflightAndWalletOverride(t *testing.T) {
+	defer func() {
+		corsOverridesMutex.Lock()
+		corsOverrides = nil
+		corsOverridesMutex.Unlock()
+	}()
+	SetCORSOverride("/api/v1/public/oauth/wallet", CORSConfig{
+		AllowedOrigins: []string{"https://dapp.example.com"},
+		MaxAgeSecs:     60,
+	})
+
+	called := false
+	engine := testutil.NewTestEngine()
+	engine.Use(CORS(CORSConfig{AllowedOrigins: []string{"https://router.example.com"}, AllowCredentials: true}))
+	handler := func(c *gin.Context) {
+		called = true
+		c.Status(http.StatusOK)
+	}
+	engine.POST("/api/v1/public/oauth/wallet/login", handler)
+	engine.OPTIONS("/api/v1/public/oauth/wallet/login", handler)
+	engine.GET("/api/v1/user/self", handler)
+
+	preflight := testutil.NewTestRequest(http.MethodOptions, "/api/v1/public/oauth/wallet/login", nil)
+	preflight.Header.Set("Origin", "https://dapp.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	recorder := testutil.ServeTestRequest(engine, preflight)
+	if called {
+		t.Fatalf("preflight reached the route handler")
+	}
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d, want 204", recorder.Code)
+	}
+	if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != "https://dapp.example.com" {
+		t.Fatalf("Access-Control-Allow-Origin = %q", got)
+	}
+	if got := recorder.Header().Get("Access-Control-Max-Age"); got != "60" {
+		t.Fatalf("Access-Control-Max-Age = %q, want 60", got)
+	}
+	if got := recorder.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Fatalf("override sent Access-Control-Allow-Credentials = %q", got)
+	}
+
+	// the dApp origin is only admitted on the wallet routes
+	req := testutil.NewTestRequest(http.MethodGet, "/api/v1/user/self", nil)
+	req.Header.Set("Origin", "https://dapp.example.com")
+	recorder = testutil.ServeTestRequest(engine, req)
+	if recorder.Code != http.StatusForbidden {
+		t.Fatalf("foreign origin status = %d, want 403", recorder.Code)
+	}
+
+	req = testutil.NewTestRequest(http.MethodGet, "/api/v1/user/self", nil)
+	req.Header.Set("Origin", "https://router.example.com")
+	recorder = testutil.ServeTestRequest(engine, req)
+	if !called || recorder.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("global policy not applied: called=%t headers=%v", called, recorder.Header())
+	}
+}
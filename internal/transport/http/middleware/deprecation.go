@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yeying-community/router/common/env"
+	"github.com/yeying-community/router/common/logger"
+)
+
+var (
+	deprecationSunsetOverridesOnce sync.Once
+	deprecationSunsetOverrides     map[string]time.Time
+)
+
+// Deprecated marks an endpoint as scheduled for removal by setting the
+// Deprecation, Sunset and successor Link response headers. The sunset date can be
+// overridden per route path via DEPRECATION_SUNSET_OVERRIDES, a JSON object of
+// path -> date (YYYY-MM-DD or RFC3339).
+func Deprecated(sunsetDate time.Time, link string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sunset := sunsetDate
+		if override, ok := loadDeprecationSunsetOverrides()[c.FullPath()]; ok {
+			sunset = override
+		}
+		c.Header("Deprecation", "true")
+		if !sunset.IsZero() {
+			c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		if link = strings.TrimSpace(link); link != "" {
+			c.Header("Link", "<"+link+">; rel=\"successor-version\"")
+		}
+		c.Next()
+	}
+}
+
+func loadDeprecationSunsetOverrides() map[string]time.Time {
+	deprecationSunsetOverridesOnce.Do(func() {
+		deprecationSunsetOverrides = parseDeprecationSunsetOverrides(env.String("DEPRECATION_SUNSET_OVERRIDES", ""))
+	})
+	return deprecationSunsetOverrides
+}
+
+func parseDeprecationSunsetOverrides(raw string) map[string]time.Time {
+	result := make(map[string]time.Time)
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return result
+	}
+	entries := make(map[string]string)
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		logger.SysWarnf("invalid DEPRECATION_SUNSET_OVERRIDES: %s", err.Error())
+		return result
+	}
+	for path, value := range entries {
+		path = strings.TrimSpace(path)
+		value = strings.TrimSpace(value)
+		if path == "" || value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			parsed, err = time.Parse(time.DateOnly, value)
+		}
+		if err != nil {
+			logger.SysWarnf("invalid DEPRECATION_SUNSET_OVERRIDES date path=%s value=%s", path, value)
+			continue
+		}
+		result[path] = parsed
+	}
+	return result
+}
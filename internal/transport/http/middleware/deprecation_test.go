@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestDeprecated_SetsHeaders(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	sunset := time.Date(2027, 4, 16, 0, 0, 0, 0, time.UTC)
+	engine.GET("/legacy", Deprecated(sunset, "/api/v1/next"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/legacy", nil))
+
+	if got := recorder.Header().Get("Deprecation"); got != "true" {
+		t.Fatalf("Deprecation header = %q, want %q", got, "true")
+	}
+	if got, want := recorder.Header().Get("Sunset"), "Fri, 16 Apr 2027 00:00:00 GMT"; got != want {
+		t.Fatalf("Sunset header = %q, want %q", got, want)
+	}
+	if got, want := recorder.Header().Get("Link"), `</api/v1/next>; rel="successor-version"`; got != want {
+		t.Fatalf("Link header = %q, want %q", got, want)
+	}
+}
+
+func TestParseDeprecationSunsetOverrides(t *testing.T) {
+	overrides := parseDeprecationSunsetOverrides(`{"/a":"2027-01-02","/b":"2027-03-04T05:06:07Z","/c":"bogus"}`)
+	if len(overrides) != 2 {
+		t.Fatalf("parseDeprecationSunsetOverrides returned %d entries, want 2", len(overrides))
+	}
+	if got := overrides["/a"]; !got.Equal(time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("override /a = %v", got)
+	}
+	if got := overrides["/b"]; !got.Equal(time.Date(2027, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Fatalf("override /b = %v", got)
+	}
+}
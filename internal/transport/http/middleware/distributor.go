@@ -96,6 +96,7 @@ func SetupContextForSelectedChannel(c *gin.Context, channel *model.Channel, mode
 	c.Set(ctxkey.Channel, channelProtocol)
 	c.Set(ctxkey.ChannelId, channel.Id)
 	c.Set(ctxkey.ChannelName, channel.DisplayName())
+	c.Set(ctxkey.ChannelTimeout, channel.TimeoutSeconds)
 	c.Set(ctxkey.ChannelModelConfigs, channel.GetSelectedModelConfigs())
 	mapping := channel.GetModelMapping()
 	if groupID := c.GetString(ctxkey.Group); groupID != "" {
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestGeoBlock(t *testing.T) {
+	countries := map[string]string{
+		"203.0.113.1": "CN",
+		"203.0.113.2": "US",
+		"203.0.113.3": "",
+	}
+	lookup := func(ip net.IP) (string, error) {
+		return countries[ip.String()], nil
+	}
+	cases := []struct {
+		name   string
+		cfg    GeoBlockConfig
+		remote string
+		want   int
+	}{
+		{"blocked country", GeoBlockConfig{BlockedCountryCodes: []string{"cn"}}, "203.0.113.1", http.StatusForbidden},
+		{"other country", GeoBlockConfig{BlockedCountryCodes: []string{"CN"}}, "203.0.113.2", http.StatusOK},
+		{"unknown with blocklist", GeoBlockConfig{BlockedCountryCodes: []string{"CN"}}, "203.0.113.3", http.StatusOK},
+		{"allowed country", GeoBlockConfig{AllowedCountryCodes: []string{"US"}}, "203.0.113.2", http.StatusOK},
+		{"outside allowlist", GeoBlockConfig{AllowedCountryCodes: []string{"US"}}, "203.0.113.1", http.StatusForbidden},
+		{"unknown with allowlist", GeoBlockConfig{AllowedCountryCodes: []string{"US"}}, "203.0.113.3", http.StatusForbidden},
+		{"private bypass", GeoBlockConfig{AllowedCountryCodes: []string{"US"}}, "10.1.2.3", http.StatusOK},
+		{"loopback bypass", GeoBlockConfig{AllowedCountryCodes: []string{"US"}}, "127.0.0.1", http.StatusOK},
+	}
+	for _, tc := range cases {
+		engine := testutil.NewTestEngine()
+		engine.POST("/verify", newGeoBlock(tc.cfg, lookup), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		req := testutil.NewTestRequest(http.MethodPost, "/verify", nil)
+		req.RemoteAddr = tc.remote + ":40000"
+		recorder := testutil.ServeTestRequest(engine, req)
+		if recorder.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d", tc.name, recorder.Code, tc.want)
+		}
+	}
+}
+
+func TestGeoBlock_ProxyHeaders(t *testing.T) {
+	prev := config.TrustProxyHeaders
+	defer func() { config.TrustProxyHeaders = prev }()
+	lookup := func(ip net.IP) (string, error) {
+		if ip.String() == "203.0.113.1" {
+			return "CN", nil
+		}
+		return "US", nil
+	}
+	for _, trust := range []bool{false, true} {
+		config.TrustProxyHeaders = trust
+		engine := testutil.NewTestEngine()
+		engine.POST("/verify", newGeoBlock(GeoBlockConfig{BlockedCountryCodes: []string{"CN"}}, lookup), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		req := testutil.NewTestRequest(http.MethodPost, "/verify", nil)
+		req.RemoteAddr = "203.0.113.1:40000"
+		req.Header.Set("X-Forwarded-For", "198.51.100.7")
+		want := http.StatusForbidden
+		if trust {
+			want = http.StatusOK
+		}
+		if recorder := testutil.ServeTestRequest(engine, req); recorder.Code != want {
+			t.Fatalf("trust proxy headers %t: status = %d, want %d", trust, recorder.Code, want)
+		}
+	}
+}
+
+func TestWalletGeoBlock_MissingDatabaseFailsClosed(t *testing.T) {
+	prevEnabled, prevPath := config.WalletGeoBlockEnabled, config.GeoIPDBPath
+	defer func() { config.WalletGeoBlockEnabled, config.GeoIPDBPath = prevEnabled, prevPath }()
+	config.WalletGeoBlockEnabled = true
+	config.GeoIPDBPath = filepath.Join(t.TempDir(), "missing.mmdb")
+
+	if _, err := GeoBlock(GeoBlockConfig{DBPath: config.GeoIPDBPath}); err == nil {
+		t.Fatalf("GeoBlock with a missing database returned no error")
+	}
+	engine := testutil.NewTestEngine()
+	engine.POST("/verify", WalletGeoBlock(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	req := testutil.NewTestRequest(http.MethodPost, "/verify", nil)
+	req.RemoteAddr = "203.0.113.2:40000"
+	if recorder := testutil.ServeTestRequest(engine, req); recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", recorder.Code)
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestGzip(t *testing.T) {
+	large := strings.Repeat("a", 2048)
+	engine := testutil.NewTestEngine()
+	engine.Use(Gzip(1024))
+	engine.GET("/large", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": large})
+	})
+	engine.GET("/small", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"data": "ok"})
+	})
+	engine.GET("/image", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte(large))
+	})
+	engine.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.String(http.StatusOK, "data: %s\n\n", large)
+	})
+
+	tests := []struct {
+		path           string
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{"/large", "gzip, deflate", true},
+		{"/large", "", false},
+		{"/small", "gzip", false},
+		{"/image", "gzip", false},
+		{"/stream", "gzip", false},
+	}
+	for _, tt := range tests {
+		req := testutil.NewTestRequest(http.MethodGet, tt.path, nil)
+		if tt.acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+		}
+		w := testutil.ServeTestRequest(engine, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", tt.path, w.Code)
+		}
+		gotGzip := w.Header().Get("Content-Encoding") == "gzip"
+		if gotGzip != tt.wantGzip {
+			t.Fatalf("%s accept %q: gzip = %v, want %v", tt.path, tt.acceptEncoding, gotGzip, tt.wantGzip)
+		}
+		if tt.acceptEncoding != "" && w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Fatalf("%s: Vary = %q", tt.path, w.Header().Get("Vary"))
+		}
+		body := w.Body.String()
+		if gotGzip {
+			reader, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatalf("%s: gzip reader: %v", tt.path, err)
+			}
+			raw, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("%s: decompress: %v", tt.path, err)
+			}
+			body = string(raw)
+		}
+		if tt.path != "/small" && !strings.Contains(body, large) {
+			t.Fatalf("%s: body lost, got %d bytes", tt.path, len(body))
+		}
+	}
+}
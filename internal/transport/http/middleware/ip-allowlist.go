@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/common/network"
+)
+
+// IPAllowlist rejects requests from outside allowedCIDRs with 403. Entries are
+// CIDR ranges or bare IPv4/IPv6 addresses, parsed once here. An empty list
+// allows everyone. The client is the TCP peer unless
+// config.TrustProxyHeaders is set, in which case X-Forwarded-For / X-Real-IP
+// are honoured as gin resolves them; only enable that behind a proxy that
+// overwrites those headers.
+func IPAllowlist(allowedCIDRs []string) gin.HandlerFunc {
+	trustProxyHeaders := config.TrustProxyHeaders
+	ranges, err := network.ParseCIDRs(allowedCIDRs)
+	if err != nil {
+		logger.FatalLogf("invalid IP allowlist: %v", err)
+	}
+	return func(c *gin.Context) {
+		if len(ranges) == 0 {
+			c.Next()
+			return
+		}
+		clientIP := c.RemoteIP()
+		if trustProxyHeaders {
+			clientIP = c.ClientIP()
+		}
+		if ipInRanges(net.ParseIP(clientIP), ranges) {
+			c.Next()
+			return
+		}
+		logger.Warnf(c.Request.Context(), "ip allowlist rejected ip=%s path=%s", clientIP, c.Request.URL.Path)
+		abortWithMessage(c, http.StatusForbidden, "当前 IP 无权访问管理接口")
+	}
+}
+
+func ipInRanges(ip net.IP, ranges []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range ranges {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestIPAllowlist(t *testing.T) {
+	prev := config.TrustProxyHeaders
+	defer func() { config.TrustProxyHeaders = prev }()
+
+	newEngine := func(allowed []string) *gin.Engine {
+		engine := testutil.NewTestEngine()
+		engine.GET("/admin", IPAllowlist(allowed), func(c *gin.Context) { c.Status(http.StatusOK) })
+		return engine
+	}
+	allowed := []string{"10.0.0.0/8", "192.168.1.7", "2001:db8::/32", "::1"}
+
+	cases := []struct {
+		name       string
+		allowed    []string
+		trustProxy bool
+		remoteAddr string
+		forwarded  string
+		want       int
+	}{
+		{"ipv4 in cidr", allowed, false, "10.1.2.3:4000", "", http.StatusOK},
+		{"ipv4 single address", allowed, false, "192.168.1.7:4000", "", http.StatusOK},
+		{"ipv4 outside", allowed, false, "192.168.1.8:4000", "", http.StatusForbidden},
+		{"ipv6 in cidr", allowed, false, "[2001:db8:1::5]:4000", "", http.StatusOK},
+		{"ipv6 single address", allowed, false, "[::1]:4000", "", http.StatusOK},
+		{"ipv6 outside", allowed, false, "[2001:db9::1]:4000", "", http.StatusForbidden},
+		{"forwarded header ignored by default", allowed, false, "203.0.113.9:4000", "10.0.0.1", http.StatusForbidden},
+		{"forwarded header trusted", allowed, true, "203.0.113.9:4000", "10.0.0.1", http.StatusOK},
+		{"empty allowlist allows all", nil, false, "203.0.113.9:4000", "", http.StatusOK},
+	}
+	for _, tc := range cases {
+		config.TrustProxyHeaders = tc.trustProxy
+		engine := newEngine(tc.allowed)
+		req := testutil.NewTestRequest(http.MethodGet, "/admin", nil)
+		req.RemoteAddr = tc.remoteAddr
+		if tc.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tc.forwarded)
+		}
+		w := testutil.ServeTestRequest(engine, req)
+		if w.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d", tc.name, w.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/blacklist"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// WalletRenewedTokenHeader carries an access token re-signed with the current
+// auth.jwt_secret when the request's token only verified with a fallback
+// secret. Clients should replace their stored token with it.
+const WalletRenewedTokenHeader = "X-Renewed-Token"
+
+func setRenewedWalletToken(c *gin.Context, renewed string) {
+	if renewed != "" {
+		c.Header(WalletRenewedTokenHeader, renewed)
+	}
+}
+
+// JWTAuth authenticates a request by its login session or, failing that, by a
+// wallet JWT in "Authorization: Bearer <token>", so clients without cookies
+// can call the same routes. The user must be enabled and not banned, and a
+// wallet JWT must still match a wallet bound to its user. When neither
+// succeeds the request is rejected with 401.
+func JWTAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, walletAddress, ok := jwtAuthUser(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"message": "无权进行此操作，未登录或 token 无效",
+			})
+			c.Abort()
+			return
+		}
+		effectiveRole, canManageUsers := computeEffectiveAuthRole(user)
+		c.Set(ctxkey.Id, user.Id)
+		c.Set(ctxkey.Username, user.Username)
+		c.Set(ctxkey.Role, effectiveRole)
+		c.Set(ctxkey.CanManageUsers, canManageUsers)
+		c.Set(ctxkey.User, user)
+		if walletAddress != "" {
+			c.Set(ctxkey.WalletAddress, walletAddress)
+		}
+		c.Next()
+	}
+}
+
+// TokenOrJWTAuth is TokenAuth for relay routes that also accepts the JWTAuth
+// credentials: a request without an Authorization header is checked against
+// the login session, anything else by TokenAuth, whose wallet JWT branch
+// applies the same checks as JWTAuth.
+func TokenOrJWTAuth() gin.HandlerFunc {
+	tokenAuth := TokenAuth()
+	return func(c *gin.Context) {
+		if strings.TrimSpace(c.GetHeader("Authorization")) != "" {
+			tokenAuth(c)
+			return
+		}
+		user, ok := sessionUser(c)
+		if !ok {
+			abortWithMessage(c, http.StatusUnauthorized, "未提供令牌")
+			return
+		}
+		walletAddress := ""
+		if user.WalletAddress != nil {
+			walletAddress = model.NormalizeWalletAddress(*user.WalletAddress)
+		}
+		setUserRelayContext(c, user, "session", walletAddress)
+	}
+}
+
+// jwtAuthUser resolves the JWTAuth user: the session user when the session is
+// valid, else the user of the Bearer wallet JWT. walletAddress is the wallet
+// the token was issued for, or the user's primary wallet for sessions.
+func jwtAuthUser(c *gin.Context) (*model.User, string, bool) {
+	ctx := c.Request.Context()
+	if user, ok := sessionUser(c); ok {
+		walletAddress := ""
+		if user.WalletAddress != nil {
+			walletAddress = model.NormalizeWalletAddress(*user.WalletAddress)
+		}
+		return user, walletAddress, true
+	}
+	bearer := extractBearerToken(c)
+	if bearer == "" {
+		return nil, "", false
+	}
+	claims, renewed, err := common.VerifyWalletJWTWithRenewal(bearer)
+	if err != nil {
+		logger.Loginf(ctx, "jwt auth wallet jwt verify failed err=%v", err)
+		return nil, "", false
+	}
+	user, ok := walletJWTUser(ctx, claims)
+	if !ok {
+		return nil, "", false
+	}
+	setRenewedWalletToken(c, renewed)
+	logger.Loginf(ctx, "jwt auth via wallet jwt success user=%s addr=%s", user.Id, claims.WalletAddress)
+	return user, model.NormalizeWalletAddress(claims.WalletAddress), true
+}
+
+// sessionUser loads the enabled, non-banned user of the login session.
+func sessionUser(c *gin.Context) (*model.User, bool) {
+	userID := normalizeSessionUserID(sessions.Default(c).Get("id"))
+	if userID == "" || blacklist.IsUserBanned(userID) {
+		return nil, false
+	}
+	user, err := model.GetUserById(userID, false)
+	if err != nil || user == nil || user.Status != model.UserStatusEnabled {
+		logger.Loginf(c.Request.Context(), "jwt auth session user unavailable id=%s err=%v", userID, err)
+		return nil, false
+	}
+	return user, true
+}
+
+func extractBearerToken(c *gin.Context) string {
+	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
+	if len(authHeader) < 7 || !strings.EqualFold(authHeader[:7], "bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(authHeader[7:])
+}
+
+// authenticateExternalJWT verifies a token against the external JWKS and maps it
+// to the local user bound to the wallet address in its wallet_address or sub
+// claim. authHelper tries it after local wallet JWT verification fails.
+func authenticateExternalJWT(ctx context.Context, token string) (*model.User, string, error) {
+	claims, err := common.VerifyExternalJWT(token)
+	if err != nil {
+		return nil, "", err
+	}
+	addr, _ := (*claims)["wallet_address"].(string)
+	if addr == "" {
+		addr, _ = (*claims)["sub"].(string)
+	}
+	addr = strings.ToLower(strings.TrimSpace(addr))
+	if !common.IsValidEthAddress(addr) {
+		return nil, "", errors.New("token 缺少钱包地址")
+	}
+	user := model.User{WalletAddress: &addr}
+	if err := user.FillUserByWalletAddress(); err != nil {
+		logger.Loginf(ctx, "external jwt auth FillUserByWalletAddress fail addr=%s err=%v", addr, err)
+		return nil, "", errors.New("token 对应的用户不存在")
+	}
+	if user.Status != model.UserStatusEnabled || blacklist.IsUserBanned(user.Id) {
+		return nil, "", errors.New("用户已被封禁")
+	}
+	return &user, addr, nil
+}
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/blacklist"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+const (
+	jwtAuthWallet      = "0x00000000000000000000000000000000000000aa"
+	jwtAuthOtherWallet = "0x00000000000000000000000000000000000000bb"
+)
+
+// useJWTAuthUsers serves users by ID and access token from users and answers
+// every other query, such as secondary wallet lookups, with no rows.
+func useJWTAuthUsers(t *testing.T, users ...*model.User) {
+	t.Helper()
+	prevSecret, prevAlgorithm := config.JWTSecret, config.WalletJWTAlgorithm
+	prevDB := model.DB
+	t.Cleanup(func() {
+		config.JWTSecret, config.WalletJWTAlgorithm = prevSecret, prevAlgorithm
+		model.DB = prevDB
+	})
+	config.JWTSecret = "test-secret"
+	config.WalletJWTAlgorithm = common.WalletJWTAlgorithmHS256
+
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("gorm.Open error: %v", err)
+	}
+	_ = db.Callback().Query().After("gorm:query").Register("test:not_found", func(tx *gorm.DB) {
+		_ = tx.AddError(gorm.ErrRecordNotFound)
+	})
+	model.DB = db
+
+	byID := func(id string) (*model.User, bool) {
+		for _, user := range users {
+			if user.Id == id {
+				copied := *user
+				return &copied, true
+			}
+		}
+		return nil, false
+	}
+	model.BindUserRepository(model.UserRepository{
+		GetUserById: func(id string, selectAll bool) (*model.User, error) {
+			if user, ok := byID(id); ok {
+				return user, nil
+			}
+			return nil, gorm.ErrRecordNotFound
+		},
+		FillUserById: func(user *model.User) error {
+			if found, ok := byID(user.Id); ok {
+				*user = *found
+				return nil
+			}
+			return gorm.ErrRecordNotFound
+		},
+		FillUserByWalletAddress: func(user *model.User) error { return gorm.ErrRecordNotFound },
+		ValidateAccessToken: func(token string) *model.User {
+			for _, user := range users {
+				if token != "" && user.AccessToken == token {
+					copied := *user
+					return &copied
+				}
+			}
+			return nil
+		},
+	})
+}
+
+func walletJWT(t *testing.T, userID, address string) string {
+	t.Helper()
+	token, _, err := common.GenerateWalletJWT(userID, address)
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT error: %v", err)
+	}
+	return token
+}
+
+func TestJWTAuth_BearerWalletJWT(t *testing.T) {
+	wallet := jwtAuthWallet
+	owner := &model.User{Id: "jwt-owner", Username: "owner", Role: model.RoleCommonUser, Status: model.UserStatusEnabled, WalletAddress: &wallet}
+	disabled := &model.User{Id: "jwt-disabled", Username: "disabled", Role: model.RoleCommonUser, Status: model.UserStatusDisabled, WalletAddress: &wallet}
+	banned := &model.User{Id: "jwt-banned", Username: "banned", Role: model.RoleCommonUser, Status: model.UserStatusEnabled, WalletAddress: &wallet}
+	useJWTAuthUsers(t, owner, disabled, banned)
+	blacklist.BanUser(banned.Id)
+	defer blacklist.UnbanUser(banned.Id)
+
+	engine := testutil.NewTestEngine()
+	engine.Use(sessions.Sessions("session", cookie.NewStore([]byte("test-secret"))))
+	engine.GET("/api/v1/public/oauth/wallet/bind", JWTAuth(), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(ctxkey.Id)+" "+c.GetString(ctxkey.WalletAddress))
+	})
+	request := func(token string) *http.Request {
+		req := testutil.NewTestRequest(http.MethodGet, "/api/v1/public/oauth/wallet/bind", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req
+	}
+
+	recorder := testutil.ServeTestRequest(engine, request(walletJWT(t, owner.Id, wallet)))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != owner.Id+" "+wallet {
+		t.Fatalf("valid token: status = %d body = %q, want 200 with the owner and wallet", recorder.Code, recorder.Body.String())
+	}
+
+	rejected := map[string]string{
+		"no credentials": "",
+		"not a jwt":      "not-a-jwt",
+		"unbound wallet": walletJWT(t, owner.Id, jwtAuthOtherWallet),
+		"disabled user":  walletJWT(t, disabled.Id, wallet),
+		"banned user":    walletJWT(t, banned.Id, wallet),
+		"unknown user":   walletJWT(t, "jwt-missing", jwtAuthOtherWallet),
+	}
+	for name, token := range rejected {
+		if recorder := testutil.ServeTestRequest(engine, request(token)); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: status = %d, want 401", name, recorder.Code)
+		}
+	}
+}
+
+func TestJWTAuth_Session(t *testing.T) {
+	wallet := jwtAuthWallet
+	owner := &model.User{Id: "jwt-session", Username: "session", Role: model.RoleAdminUser, Status: model.UserStatusEnabled, WalletAddress: &wallet}
+	disabled := &model.User{Id: "jwt-session-disabled", Username: "disabled", Role: model.RoleCommonUser, Status: model.UserStatusDisabled}
+	useJWTAuthUsers(t, owner, disabled)
+
+	c, recorder := testutil.NewTestGinContext(http.MethodGet, "/api/v1/public/oauth/wallet/bind", nil)
+	testutil.SetSessionUser(c, owner.Id, model.RoleCommonUser)
+	// an invalid Bearer token does not matter once the session is valid
+	testutil.SetBearerToken(c, "not-a-jwt")
+	JWTAuth()(c)
+	if c.IsAborted() || c.GetString(ctxkey.Id) != owner.Id || c.GetInt(ctxkey.Role) != model.RoleAdminUser || c.GetString(ctxkey.WalletAddress) != wallet {
+		t.Fatalf("session: aborted = %t status = %d keys = %v, want the session user with its stored role", c.IsAborted(), recorder.Code, c.Keys)
+	}
+
+	c, recorder = testutil.NewTestGinContext(http.MethodGet, "/api/v1/public/oauth/wallet/bind", nil)
+	testutil.SetSessionUser(c, disabled.Id, model.RoleCommonUser)
+	JWTAuth()(c)
+	if !c.IsAborted() || recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("disabled session user: aborted = %t status = %d, want 401", c.IsAborted(), recorder.Code)
+	}
+}
+
+func TestTokenAuth_RejectsWalletJWTForUnboundWallet(t *testing.T) {
+	wallet := jwtAuthWallet
+	owner := &model.User{Id: "token-owner", Username: "owner", Role: model.RoleCommonUser, Status: model.UserStatusEnabled, WalletAddress: &wallet}
+	useJWTAuthUsers(t, owner)
+
+	c, recorder := testutil.NewTestGinContext(http.MethodGet, "/v1/models", nil)
+	testutil.SetBearerToken(c, walletJWT(t, owner.Id, jwtAuthOtherWallet))
+	TokenOrJWTAuth()(c)
+	if !c.IsAborted() || recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d aborted = %t, want 401 for a token whose wallet the user no longer holds", recorder.Code, c.IsAborted())
+	}
+}
+
+func TestTokenOrJWTAuth_RequiresSessionWithoutAuthorization(t *testing.T) {
+	useJWTAuthUsers(t)
+
+	c, recorder := testutil.NewTestGinContext(http.MethodGet, "/v1/models", nil)
+	TokenOrJWTAuth()(c)
+	if !c.IsAborted() || recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d aborted = %t, want 401 without a session or token", recorder.Code, c.IsAborted())
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/blacklist"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+const (
+	maintenanceRetryAfterSeconds = 60
+	defaultMaintenanceMessage    = "系统维护中，请稍后再试"
+)
+
+// MaintenanceState is the current maintenance mode configuration.
+type MaintenanceState struct {
+	Enabled    bool   `json:"enabled"`
+	Message    string `json:"message"`
+	AllowAdmin bool   `json:"allow_admin"`
+}
+
+var maintenanceMode atomic.Bool
+var maintenanceState atomic.Pointer[MaintenanceState]
+
+// SetMaintenanceMode switches maintenance mode on or off for the whole process.
+func SetMaintenanceMode(state MaintenanceState) {
+	state.Message = strings.TrimSpace(state.Message)
+	if state.Message == "" {
+		state.Message = defaultMaintenanceMessage
+	}
+	maintenanceState.Store(&state)
+	maintenanceMode.Store(state.Enabled)
+}
+
+func GetMaintenanceState() MaintenanceState {
+	if state := maintenanceState.Load(); state != nil {
+		return *state
+	}
+	return MaintenanceState{Message: defaultMaintenanceMessage}
+}
+
+// Maintenance rejects non-admin requests with 503 while maintenance mode is
+// enabled. Admin APIs, the system status endpoints and the health probes stay
+// reachable; with allow_admin, root users bypass maintenance on every route,
+// whether they sign in with a session, a wallet JWT or an access token.
+func Maintenance() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !maintenanceMode.Load() || isMaintenanceExemptPath(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+		state := GetMaintenanceState()
+		if state.AllowAdmin && isRootRequest(c) {
+			c.Next()
+			return
+		}
+		c.Header("Retry-After", strconv.Itoa(maintenanceRetryAfterSeconds))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success":     false,
+			"message":     state.Message,
+			"retry_after": maintenanceRetryAfterSeconds,
+		})
+		c.Abort()
+	}
+}
+
+func isMaintenanceExemptPath(path string) bool {
+	return strings.HasPrefix(path, "/api/v1/admin/") || strings.HasPrefix(path, "/api/v1/system/") ||
+		path == "/health" || strings.HasPrefix(path, "/health/")
+}
+
+// isRootRequest reports whether the request comes from a root user, by the
+// session role or else by the user of a Bearer wallet JWT or access token.
+func isRootRequest(c *gin.Context) bool {
+	if role, ok := sessions.Default(c).Get("role").(int); ok {
+		return role >= model.RoleRootUser
+	}
+	bearer := extractBearerToken(c)
+	if bearer == "" {
+		return false
+	}
+	var user *model.User
+	if claims, err := common.VerifyWalletJWT(bearer); err == nil {
+		user, _ = walletJWTUser(c.Request.Context(), claims)
+	} else {
+		user = model.ValidateAccessToken(bearer)
+	}
+	if user == nil || user.Status != model.UserStatusEnabled || blacklist.IsUserBanned(user.Id) {
+		return false
+	}
+	role, _ := computeEffectiveAuthRole(user)
+	return role >= model.RoleRootUser
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestMaintenance_BlocksNonAdminRoutes(t *testing.T) {
+	SetMaintenanceMode(MaintenanceState{Enabled: true, Message: "升级数据库"})
+	defer SetMaintenanceMode(MaintenanceState{})
+
+	engine := testutil.NewTestEngine()
+	engine.Use(Maintenance())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	engine.GET("/v1/models", ok)
+	engine.GET("/api/v1/admin/user/", ok)
+	engine.GET("/api/v1/system/maintenance", ok)
+	engine.GET("/health/ready", ok)
+
+	recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/v1/models", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", recorder.Code)
+	}
+	if got := recorder.Header().Get("Retry-After"); got != "60" {
+		t.Fatalf("Retry-After = %q, want 60", got)
+	}
+
+	for _, path := range []string{"/api/v1/admin/user/", "/api/v1/system/maintenance", "/health/ready"} {
+		recorder = testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s status = %d, want 200", path, recorder.Code)
+		}
+	}
+}
+
+func TestMaintenance_AllowAdminAcceptsRootBearer(t *testing.T) {
+	rootWallet, memberWallet := jwtAuthWallet, jwtAuthOtherWallet
+	prevRoots := config.RootWalletAddresses
+	config.RootWalletAddresses = map[string]bool{rootWallet: true}
+	defer func() { config.RootWalletAddresses = prevRoots }()
+	root := &model.User{Id: "maint-root", Username: "root", Role: model.RoleCommonUser, Status: model.UserStatusEnabled, WalletAddress: &rootWallet, AccessToken: "root-access-token"}
+	member := &model.User{Id: "maint-member", Username: "member", Role: model.RoleRootUser, Status: model.UserStatusEnabled, WalletAddress: &memberWallet, AccessToken: "member-access-token"}
+	useJWTAuthUsers(t, root, member)
+	SetMaintenanceMode(MaintenanceState{Enabled: true, AllowAdmin: true})
+	defer SetMaintenanceMode(MaintenanceState{})
+
+	engine := testutil.NewTestEngine()
+	engine.Use(sessions.Sessions("session", cookie.NewStore([]byte("test-secret"))))
+	engine.Use(Maintenance())
+	engine.GET("/v1/models", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := map[string]struct {
+		token string
+		want  int
+	}{
+		"root wallet jwt":     {token: walletJWT(t, root.Id, rootWallet), want: http.StatusOK},
+		"root access token":   {token: root.AccessToken, want: http.StatusOK},
+		"member wallet jwt":   {token: walletJWT(t, member.Id, memberWallet), want: http.StatusServiceUnavailable},
+		"member access token": {token: member.AccessToken, want: http.StatusServiceUnavailable},
+		"unknown token":       {token: "not-a-token", want: http.StatusServiceUnavailable},
+		"no credentials":      {want: http.StatusServiceUnavailable},
+	}
+	for name, tt := range tests {
+		req := testutil.NewTestRequest(http.MethodGet, "/v1/models", nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		if recorder := testutil.ServeTestRequest(engine, req); recorder.Code != tt.want {
+			t.Fatalf("%s: status = %d, want %d", name, recorder.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+)
+
+// ModelExtractor parses the requested model once and stores it under
+// ctxkey.ModelName for handlers and the access log. Routes that need a model
+// (see shouldCheckModel) are rejected with 400 when it cannot be read.
+// TokenAuth fills the same key, so relay routes don't need this middleware.
+func ModelExtractor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		modelName, err := getRequestModel(c)
+		if err != nil && shouldCheckModel(c) {
+			abortWithMessage(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		c.Set(ctxkey.ModelName, modelName)
+		c.Next()
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestModelExtractor_ChatCompletions(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	var got string
+	engine.POST("/v1/chat/completions", ModelExtractor(), func(c *gin.Context) {
+		got = c.GetString("model_name")
+		// a second lookup is served from the context, not the body
+		if again, err := getRequestModel(c); err != nil || again != got {
+			t.Errorf("getRequestModel after extractor = %q, %v", again, err)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodPost, "/v1/chat/completions", `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`))
+	if recorder.Code != http.StatusOK || got != "gpt-4o-mini" {
+		t.Fatalf("status=%d %s=%q, want 200 and gpt-4o-mini", recorder.Code, ctxkey.ModelName, got)
+	}
+
+	recorder = testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodPost, "/v1/chat/completions", `{"model":`))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("malformed body status = %d, want 400", recorder.Code)
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const noCacheControl = "no-store, no-cache, must-revalidate, max-age=0"
+
+// CachePolicy tunes the headers written by NoCache for a route.
+type CachePolicy struct {
+	// VaryAll adds "Vary: *" so intermediaries treat every response as unique.
+	VaryAll bool
+}
+
+// NoCache stops browsers and proxies from caching responses, used on auth
+// endpoints that return nonces and tokens. At most one policy is used.
+func NoCache(policy ...CachePolicy) gin.HandlerFunc {
+	var p CachePolicy
+	if len(policy) > 0 {
+		p = policy[0]
+	}
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", noCacheControl)
+		c.Header("Pragma", "no-cache")
+		c.Header("Expires", "0")
+		if p.VaryAll {
+			c.Header("Vary", "*")
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestNoCache_SetsHeaders(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.POST("/api/v1/public/auth/challenge", NoCache(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+	engine.GET("/api/v1/public/oauth/wallet/nonce", NoCache(CachePolicy{VaryAll: true}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodPost, "/api/v1/public/auth/challenge", nil))
+	cacheControl := recorder.Header().Get("Cache-Control")
+	if !strings.Contains(cacheControl, "no-store") || strings.Contains(cacheControl, "public") {
+		t.Fatalf("Cache-Control = %q", cacheControl)
+	}
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		if value, ok := strings.CutPrefix(directive, "max-age="); ok {
+			if maxAge, err := strconv.Atoi(value); err != nil || maxAge > 0 {
+				t.Fatalf("Cache-Control max-age = %q", value)
+			}
+		}
+	}
+	if got := recorder.Header().Get("Pragma"); got != "no-cache" {
+		t.Fatalf("Pragma = %q", got)
+	}
+	if got := recorder.Header().Get("Expires"); got != "0" {
+		t.Fatalf("Expires = %q", got)
+	}
+	if got := recorder.Header().Get("Vary"); got != "" {
+		t.Fatalf("Vary = %q, want empty without VaryAll", got)
+	}
+
+	recorder = testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/api/v1/public/oauth/wallet/nonce", nil))
+	if got := recorder.Header().Get("Vary"); got != "*" {
+		t.Fatalf("Vary = %q, want *", got)
+	}
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/logger"
+)
+
+const (
+	walletNonceRateLimitMark = "rateLimit:walletNonce:"
+	// only the address field is needed, so the peeked body is kept small
+	walletNonceBodyPeekLimit = 4 << 10
+)
+
+// WalletNonceRateLimit throttles nonce issuance with config.WalletNonceRateLimit,
+// counting the client IP and the requested wallet address in separate buckets so
+// neither rotating addresses nor rotating IPs gets around it. Every nonce route
+// draws from the same buckets.
+func WalletNonceRateLimit() gin.HandlerFunc {
+	limit, window, err := common.ParseRateLimit(config.WalletNonceRateLimit)
+	if err != nil {
+		logger.SysErrorf("invalid wallet nonce rate limit %q, disabled: %v", config.WalletNonceRateLimit, err)
+	}
+	if limit <= 0 || window <= 0 {
+		return newWalletNonceRateLimiter(nil, limit, window)
+	}
+	store := common.SharedTokenBucketStore(walletNonceRateLimitMark, float64(limit)/window.Seconds(), limit, window+config.RateLimitKeyExpirationDuration)
+	return newWalletNonceRateLimiter(store, limit, window)
+}
+
+func newWalletNonceRateLimiter(store *common.TokenBucketStore, limit int, window time.Duration) gin.HandlerFunc {
+	if limit <= 0 || window <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return func(c *gin.Context) {
+		keys := []string{"ip:" + c.ClientIP()}
+		if addr := walletNonceRequestAddress(c); addr != "" {
+			keys = append(keys, "addr:"+addr)
+		}
+		for _, key := range keys {
+			var allowed bool
+			var retryAfter time.Duration
+			if common.RedisEnabled && common.RDB != nil {
+				allowed, retryAfter = allowRateLimitRedis(c, walletNonceRateLimitMark, key, limit, window)
+			} else {
+				bucket := store.GetOrCreate(key)
+				allowed = bucket.Allow()
+				if !allowed {
+					retryAfter = bucket.RetryAfter()
+				}
+			}
+			if !allowed {
+				logger.Loginf(c.Request.Context(), "wallet nonce rate limited key=%s retry_after=%s", key, retryAfter)
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				common.AbortWithError(c, http.StatusTooManyRequests, common.ProtoCodeResourceExhausted, "请求过于频繁，请稍后再试")
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// allowRateLimitRedis counts requests in a fixed window shared by all
+// instances; Redis errors fail open.
+func allowRateLimitRedis(c *gin.Context, mark string, key string, limit int, window time.Duration) (bool, time.Duration) {
+	ctx := c.Request.Context()
+	redisKey := mark + key
+	count, err := common.RDB.Incr(ctx, redisKey).Result()
+	if err != nil {
+		logger.SysErrorf("rate limit incr failed key=%s err=%v", redisKey, err)
+		return true, 0
+	}
+	if count == 1 {
+		common.RDB.Expire(ctx, redisKey, window)
+	}
+	if count <= int64(limit) {
+		return true, 0
+	}
+	ttl, err := common.RDB.TTL(ctx, redisKey).Result()
+	if err != nil || ttl <= 0 {
+		ttl = window
+	}
+	return false, ttl
+}
+
+// walletNonceRequestAddress reads the wallet address from the query string or,
+// for the JSON challenge endpoints, from the body, which is restored for the
+// handler.
+func walletNonceRequestAddress(c *gin.Context) string {
+	if addr := c.Query("address"); addr != "" {
+		return strings.ToLower(strings.TrimSpace(addr))
+	}
+	if c.Request.Body == nil || c.Request.Method == http.MethodGet {
+		return ""
+	}
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, walletNonceBodyPeekLimit))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(raw), c.Request.Body))
+	var payload struct {
+		Address string `json:"address"`
+	}
+	if json.Unmarshal(raw, &payload) != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(payload.Address))
+}
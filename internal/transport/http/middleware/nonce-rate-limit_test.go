@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func newTestNonceStore(limit int, window time.Duration) *common.TokenBucketStore {
+	return common.NewTokenBucketStore(float64(limit)/window.Seconds(), limit, window)
+}
+
+func TestWalletNonceRateLimit_Buckets(t *testing.T) {
+	const (
+		addrA = "0x1111111111111111111111111111111111111111"
+		addrB = "0x2222222222222222222222222222222222222222"
+	)
+	type call struct {
+		ip, addr string
+		want     int
+	}
+	tests := []struct {
+		name  string
+		calls []call
+	}{
+		{
+			name: "burst up to the limit then blocked",
+			calls: []call{
+				{"10.0.0.1", addrA, http.StatusOK},
+				{"10.0.0.1", addrA, http.StatusOK},
+				{"10.0.0.1", addrA, http.StatusOK},
+				{"10.0.0.1", addrA, http.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "per ip across addresses",
+			calls: []call{
+				{"10.0.0.2", addrA, http.StatusOK},
+				{"10.0.0.2", addrB, http.StatusOK},
+				{"10.0.0.2", "", http.StatusOK},
+				{"10.0.0.2", addrB, http.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "per address across ips",
+			calls: []call{
+				{"10.0.0.3", addrB, http.StatusOK},
+				{"10.0.0.4", strings.ToUpper(addrB[:2]) + addrB[2:], http.StatusOK},
+				{"10.0.0.5", addrB, http.StatusOK},
+				{"10.0.0.6", addrB, http.StatusTooManyRequests},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := testutil.NewTestEngine()
+			engine.GET("/nonce", newWalletNonceRateLimiter(newTestNonceStore(3, time.Minute), 3, time.Minute), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			for i, call := range tt.calls {
+				req := testutil.NewTestRequest(http.MethodGet, "/nonce?address="+call.addr, nil)
+				req.RemoteAddr = call.ip + ":1234"
+				recorder := testutil.ServeTestRequest(engine, req)
+				if recorder.Code != call.want {
+					t.Fatalf("call #%d status = %d, want %d", i+1, recorder.Code, call.want)
+				}
+				if call.want == http.StatusTooManyRequests {
+					retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+					if err != nil || retryAfter <= 0 || retryAfter > 60 {
+						t.Fatalf("Retry-After = %q", recorder.Header().Get("Retry-After"))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWalletNonceRateLimit_ReadsAddressFromBody(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.POST("/challenge", newWalletNonceRateLimiter(newTestNonceStore(1, time.Minute), 1, time.Minute), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	body := `{"address":"0x1111111111111111111111111111111111111111"}`
+	for i, ip := range []string{"10.0.1.1", "10.0.1.2"} {
+		req := testutil.NewTestRequest(http.MethodPost, "/challenge", body)
+		req.RemoteAddr = ip + ":1234"
+		recorder := testutil.ServeTestRequest(engine, req)
+		if i == 0 && (recorder.Code != http.StatusOK || recorder.Body.String() != body) {
+			t.Fatalf("first call status = %d body = %q, want body passed through", recorder.Code, recorder.Body.String())
+		}
+		if i == 1 && recorder.Code != http.StatusTooManyRequests {
+			t.Fatalf("second call from another ip status = %d, want 429", recorder.Code)
+		}
+	}
+}
+
+func TestWalletNonceRateLimit_DisabledPassesThrough(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.GET("/nonce", newWalletNonceRateLimiter(nil, 0, 0), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	for i := 0; i < 10; i++ {
+		recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/nonce", nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("call #%d status = %d, want 200", i+1, recorder.Code)
+		}
+	}
+}
+
+func TestWalletNonceRateLimit_SharedAcrossRoutes(t *testing.T) {
+	prev := config.WalletNonceRateLimit
+	defer func() { config.WalletNonceRateLimit = prev }()
+	config.WalletNonceRateLimit = "1/m"
+
+	engine := testutil.NewTestEngine()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	engine.GET("/oauth/wallet/nonce", WalletNonceRateLimit(), ok)
+	engine.POST("/auth/challenge", WalletNonceRateLimit(), ok)
+
+	first := testutil.NewTestRequest(http.MethodGet, "/oauth/wallet/nonce", nil)
+	first.RemoteAddr = "198.51.100.9:1234"
+	if recorder := testutil.ServeTestRequest(engine, first); recorder.Code != http.StatusOK {
+		t.Fatalf("first call status = %d, want 200", recorder.Code)
+	}
+	second := testutil.NewTestRequest(http.MethodPost, "/auth/challenge", nil)
+	second.RemoteAddr = "198.51.100.9:1234"
+	if recorder := testutil.ServeTestRequest(engine, second); recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("call on the other nonce route status = %d, want 429", recorder.Code)
+	}
+}
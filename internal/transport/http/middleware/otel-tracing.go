@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yeying-community/router/common/ctxkey"
+)
+
+// OtelTracing starts a server span named "<method> <route>" for every
+// request, as a child of the caller's W3C traceparent when there is one, and
+// puts it in c.Request.Context() so downstream calls and logs can use it. The
+// span ends after the handlers with the status code and, once the auth
+// middleware has resolved them, the user and channel.
+func OtelTracing(serviceName string) gin.HandlerFunc {
+	tracer := otel.Tracer(serviceName)
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", c.Request.Method),
+				attribute.String("http.route", route),
+			),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.status_code", status))
+		if userID, ok := c.Get(ctxkey.Id); ok {
+			span.SetAttributes(attribute.String("user_id", fmt.Sprint(userID)))
+		}
+		if channelID, ok := c.Get(ctxkey.ChannelId); ok {
+			span.SetAttributes(attribute.String("channel_id", fmt.Sprint(channelID)))
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
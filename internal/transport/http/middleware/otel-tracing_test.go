@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestOtelTracingPropagatesTraceParent(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(prev)
+
+	var got trace.SpanContext
+	engine := testutil.NewTestEngine()
+	engine.Use(OtelTracing("router-test"))
+	engine.GET("/wallet/:id", func(c *gin.Context) {
+		got = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	req := testutil.NewTestRequest(http.MethodGet, "/wallet/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	testutil.ServeTestRequest(engine, req)
+	if got.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("handler trace id = %s, want the incoming traceparent's", got.TraceID())
+	}
+}
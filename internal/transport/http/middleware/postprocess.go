@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/relay"
+)
+
+// ResponsePostProcess runs the registered relay response post-processors on
+// relay output. JSON/text bodies are buffered and processed once; SSE streams
+// are processed one event at a time. Other content types pass through.
+func ResponsePostProcess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !relay.HasResponsePostProcessors() {
+			c.Next()
+			return
+		}
+		writer := &postProcessWriter{ResponseWriter: c.Writer, c: c}
+		c.Writer = writer
+		c.Next()
+		writer.finish()
+	}
+}
+
+const (
+	postProcessModeUnknown = iota
+	postProcessModePassthrough
+	postProcessModeBuffered
+	postProcessModeSSE
+)
+
+type postProcessWriter struct {
+	gin.ResponseWriter
+	c      *gin.Context
+	mode   int
+	buffer bytes.Buffer
+}
+
+func (w *postProcessWriter) detectMode() {
+	if w.mode != postProcessModeUnknown {
+		return
+	}
+	contentType := strings.ToLower(w.Header().Get("Content-Type"))
+	switch {
+	case strings.Contains(contentType, "text/event-stream"):
+		w.mode = postProcessModeSSE
+	case strings.Contains(contentType, "json"), strings.HasPrefix(contentType, "text/"):
+		w.mode = postProcessModeBuffered
+		w.Header().Del("Content-Length")
+	default:
+		w.mode = postProcessModePassthrough
+	}
+}
+
+func (w *postProcessWriter) Write(data []byte) (int, error) {
+	w.detectMode()
+	switch w.mode {
+	case postProcessModeBuffered:
+		return w.buffer.Write(data)
+	case postProcessModeSSE:
+		w.buffer.Write(data)
+		if err := w.writeCompleteEvents(); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	default:
+		return w.ResponseWriter.Write(data)
+	}
+}
+
+func (w *postProcessWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *postProcessWriter) Flush() {
+	if w.mode == postProcessModeBuffered {
+		return
+	}
+	w.ResponseWriter.Flush()
+}
+
+// writeCompleteEvents processes and forwards every complete SSE event
+// (terminated by a blank line) held in the buffer.
+func (w *postProcessWriter) writeCompleteEvents() error {
+	for {
+		end := sseEventEnd(w.buffer.Bytes())
+		if end < 0 {
+			return nil
+		}
+		event := make([]byte, end)
+		copy(event, w.buffer.Next(end))
+		if err := w.writeProcessed(event); err != nil {
+			return err
+		}
+	}
+}
+
+// sseEventEnd returns the length of the first event in data up to and
+// including the blank line that ends it, or -1 if it is incomplete. Lines may
+// end in "\n" or "\r\n", mixed within one stream.
+func sseEventEnd(data []byte) int {
+	lineStart := 0
+	for i, b := range data {
+		if b != '\n' {
+			continue
+		}
+		line := data[lineStart:i]
+		if len(line) == 0 || (len(line) == 1 && line[0] == '\r') {
+			return i + 1
+		}
+		lineStart = i + 1
+	}
+	return -1
+}
+
+func (w *postProcessWriter) writeProcessed(body []byte) error {
+	processed, err := relay.ApplyResponsePostProcessors(w.c.Request.Context(), body)
+	if err != nil {
+		// Never deliver an unprocessed body when a processor fails.
+		logger.Errorf(w.c.Request.Context(), "response post-process failed: %v", err)
+		return err
+	}
+	_, err = w.ResponseWriter.Write(processed)
+	return err
+}
+
+func (w *postProcessWriter) finish() {
+	if w.buffer.Len() == 0 {
+		return
+	}
+	body := w.buffer.Bytes()
+	w.buffer.Reset()
+	_ = w.writeProcessed(body)
+	if w.mode == postProcessModeSSE {
+		w.ResponseWriter.Flush()
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/internal/relay"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+// recordingRedactor redacts emails and records each body it was given.
+type recordingRedactor struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recordingRedactor) Process(ctx context.Context, body []byte) ([]byte, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, string(body))
+	r.mu.Unlock()
+	redactor := &relay.RegexRedactor{Patterns: []*regexp.Regexp{regexp.MustCompile(`[a-z]+@example\.com`)}}
+	return redactor.Process(ctx, body)
+}
+
+func registerRecordingRedactor() *recordingRedactor {
+	processor := &recordingRedactor{}
+	relay.RegisterResponsePostProcessor("test:recording", processor)
+	return processor
+}
+
+func TestResponsePostProcess_Buffered(t *testing.T) {
+	processor := registerRecordingRedactor()
+	engine := testutil.NewTestEngine()
+	engine.Use(ResponsePostProcess())
+	engine.POST("/v1/chat/completions", func(c *gin.Context) {
+		c.Header("Content-Type", "application/json")
+		c.Writer.WriteHeader(http.StatusOK)
+		_, _ = c.Writer.WriteString(`{"content":"mail `)
+		_, _ = c.Writer.WriteString(`alice@example.com"}`)
+	})
+
+	recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodPost, "/v1/chat/completions", nil))
+	if got := recorder.Body.String(); got != `{"content":"mail [REDACTED]"}` {
+		t.Fatalf("body = %q", got)
+	}
+	if len(processor.calls) != 1 {
+		t.Fatalf("processor ran %d times, want once over the whole body", len(processor.calls))
+	}
+}
+
+func TestResponsePostProcess_StreamedPerEvent(t *testing.T) {
+	for name, eol := range map[string]string{"LF": "\n", "CRLF": "\r\n"} {
+		t.Run(name, func(t *testing.T) {
+			processor := registerRecordingRedactor()
+			engine := testutil.NewTestEngine()
+			engine.Use(ResponsePostProcess())
+			var forwardedAfterFirst string
+			recorder := httptest.NewRecorder()
+			engine.POST("/v1/chat/completions", func(c *gin.Context) {
+				c.Header("Content-Type", "text/event-stream")
+				c.Writer.WriteHeader(http.StatusOK)
+				_, _ = c.Writer.WriteString(`data: {"delta":"bob@example.com"}` + eol + eol)
+				c.Writer.Flush()
+				forwardedAfterFirst = recorder.Body.String()
+				_, _ = c.Writer.WriteString("data: [DONE]" + eol + eol)
+			})
+
+			engine.ServeHTTP(recorder, testutil.NewTestRequest(http.MethodPost, "/v1/chat/completions", nil))
+			first := `data: {"delta":"[REDACTED]"}` + eol + eol
+			if forwardedAfterFirst != first {
+				t.Fatalf("first event not forwarded on its own: %q", forwardedAfterFirst)
+			}
+			if got := recorder.Body.String(); got != first+"data: [DONE]"+eol+eol {
+				t.Fatalf("body = %q", got)
+			}
+			if len(processor.calls) != 2 {
+				t.Fatalf("processor ran %d times, want once per event: %q", len(processor.calls), processor.calls)
+			}
+		})
+	}
+}
@@ -14,8 +14,6 @@ import (
 
 var timeFormat = "2006-01-02T15:04:05.000Z"
 
-var inMemoryRateLimiter common.InMemoryRateLimiter
-
 func redisRateLimiter(c *gin.Context, maxRequestNum int, duration int64, mark string) {
 	ctx := context.Background()
 	rdb := common.RDB
@@ -62,9 +60,8 @@ func redisRateLimiter(c *gin.Context, maxRequestNum int, duration int64, mark st
 	}
 }
 
-func memoryRateLimiter(c *gin.Context, maxRequestNum int, duration int64, mark string) {
-	key := mark + c.ClientIP()
-	if !inMemoryRateLimiter.Request(key, maxRequestNum, duration) {
+func memoryRateLimiter(c *gin.Context, store *common.TokenBucketStore, mark string) {
+	if !store.GetOrCreate(mark + c.ClientIP()).Allow() {
 		c.Status(http.StatusTooManyRequests)
 		c.Abort()
 		return
@@ -82,10 +79,12 @@ func rateLimitFactory(maxRequestNum int, duration int64, mark string) func(c *gi
 			redisRateLimiter(c, maxRequestNum, duration, mark)
 		}
 	} else {
-		// It's safe to call multi times.
-		inMemoryRateLimiter.Init(config.RateLimitKeyExpirationDuration)
+		// Refill maxRequestNum tokens per duration, allowing the whole quota as a
+		// burst. Every route using the same mark shares one store, as they share
+		// one Redis key.
+		store := common.SharedTokenBucketStore("rateLimit:"+mark, float64(maxRequestNum)/float64(duration), maxRequestNum, config.RateLimitKeyExpirationDuration)
 		return func(c *gin.Context) {
-			memoryRateLimiter(c, maxRequestNum, duration, mark)
+			memoryRateLimiter(c, store, mark)
 		}
 	}
 }
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"runtime/debug"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/helper"
 	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/common/metrics"
 )
 
 func RelayPanicRecover() gin.HandlerFunc {
@@ -32,3 +35,50 @@ func RelayPanicRecover() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RecoveryWithLogger replaces gin.Recovery as the outermost middleware. A
+// panic is logged through logger.SysError with the request id and a stack
+// trace stripped of runtime frames, counted in panics_recovered_total, and
+// answered with 500 unless the response had already started.
+// http.ErrAbortHandler is re-raised so net/http still drops the connection.
+func RecoveryWithLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			metrics.IncPanicsRecovered()
+			logger.SysError(fmt.Sprintf("panic recovered request_id=%s method=%s path=%s: %v\n%s",
+				c.GetString(helper.RequestIdKey), c.Request.Method, c.Request.URL.Path, recovered, filterPanicStack(debug.Stack())))
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			abortWithMessage(c, http.StatusInternalServerError, "服务器内部错误")
+		}()
+		c.Next()
+	}
+}
+
+// filterPanicStack drops the runtime frames (the panic machinery and
+// debug.Stack itself) from a debug.Stack trace, keeping the goroutine header
+// and each remaining function/location pair.
+func filterPanicStack(stack []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	if len(lines) == 0 {
+		return ""
+	}
+	filtered := []string{lines[0]}
+	for i := 1; i+1 < len(lines); i += 2 {
+		function := lines[i]
+		if strings.HasPrefix(function, "runtime.") || strings.HasPrefix(function, "runtime/debug.") || strings.HasPrefix(function, "panic(") {
+			continue
+		}
+		filtered = append(filtered, function, lines[i+1])
+	}
+	return strings.Join(filtered, "\n")
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/metrics"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestRecoveryWithLogger(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.Use(RecoveryWithLogger())
+	engine.GET("/panic", func(c *gin.Context) { panic("boom") })
+	engine.GET("/written", func(c *gin.Context) {
+		c.String(http.StatusAccepted, "partial")
+		panic("boom")
+	})
+
+	cases := []struct {
+		target string
+		want   int
+	}{
+		{"/panic", http.StatusInternalServerError},
+		{"/written", http.StatusAccepted},
+	}
+	for _, tc := range cases {
+		w := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, tc.target, nil))
+		if w.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d", tc.target, w.Code, tc.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := metrics.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "panics_recovered_total 2\n") {
+		t.Fatalf("panic counter not incremented:\n%s", buf.String())
+	}
+}
+
+func TestFilterPanicStack(t *testing.T) {
+	var stack string
+	func() {
+		defer func() {
+			recover()
+			stack = filterPanicStack(debug.Stack())
+		}()
+		panic("boom")
+	}()
+	if !strings.HasPrefix(stack, "goroutine ") {
+		t.Fatalf("goroutine header missing:\n%s", stack)
+	}
+	for _, frame := range []string{"runtime/debug.Stack", "panic("} {
+		if strings.Contains(stack, frame) {
+			t.Fatalf("stack still contains %q:\n%s", frame, stack)
+		}
+	}
+	if !strings.Contains(stack, "TestFilterPanicStack") {
+		t.Fatalf("caller frame missing:\n%s", stack)
+	}
+}
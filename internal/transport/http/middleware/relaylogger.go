@@ -23,43 +23,50 @@ func RelayLogger() gin.HandlerFunc {
 			String("ua", c.Request.UserAgent())
 		logger.RelayInfof(c.Request.Context(), begin.Build())
 
-		c.Next()
+		// Emit the END line from a defer so it runs after every downstream
+		// middleware (auth, distributor, relay) has populated the context.
+		defer func() {
+			status := c.Writer.Status()
+			end := relaylogging.NewFields("END").
+				String("method", c.Request.Method).
+				String("path", c.Request.URL.Path).
+				String("mode", relayModeName(c.Request.URL.Path)).
+				Int("status", status).
+				Duration("latency", time.Since(startedAt)).
+				String("ip", c.ClientIP()).
+				String("user_id", c.GetString(ctxkey.Id)).
+				String("token_id", c.GetString(ctxkey.TokenId)).
+				String("token_name", c.GetString(ctxkey.TokenName)).
+				String("channel_id", c.GetString(ctxkey.ChannelId)).
+				String("channel_name", c.GetString(ctxkey.ChannelName)).
+				String("group", c.GetString(ctxkey.Group)).
+				String("request_model", c.GetString(ctxkey.RequestModel)).
+				String("original_model", c.GetString(ctxkey.OriginalModel)).
+				String("model", c.GetString(ctxkey.ModelName)).
+				String("protocol", relayProtocolName(c)).
+				String("upstream_url", c.GetString(ctxkey.UpstreamURL)).
+				Int("upstream_status", c.GetInt(ctxkey.UpstreamStatus)).
+				Int("retry_count", c.GetInt(ctxkey.RelayRetryCount)).
+				String("termination", c.GetString(ctxkey.RelayTermination)).
+				String("error_type", c.GetString(ctxkey.RelayErrorType)).
+				String("error_code", c.GetString(ctxkey.RelayErrorCode)).
+				String("error", c.GetString(ctxkey.RelayError)).
+				Int("input_tokens", c.GetInt(ctxkey.InputTokens)).
+				Int("output_tokens", c.GetInt(ctxkey.OutputTokens))
 
-		status := c.Writer.Status()
-		end := relaylogging.NewFields("END").
-			String("method", c.Request.Method).
-			String("path", c.Request.URL.Path).
-			String("mode", relayModeName(c.Request.URL.Path)).
-			Int("status", status).
-			Duration("latency", time.Since(startedAt)).
-			String("ip", c.ClientIP()).
-			String("user_id", c.GetString(ctxkey.Id)).
-			String("token_id", c.GetString(ctxkey.TokenId)).
-			String("token_name", c.GetString(ctxkey.TokenName)).
-			String("channel_id", c.GetString(ctxkey.ChannelId)).
-			String("channel_name", c.GetString(ctxkey.ChannelName)).
-			String("group", c.GetString(ctxkey.Group)).
-			String("request_model", c.GetString(ctxkey.RequestModel)).
-			String("original_model", c.GetString(ctxkey.OriginalModel)).
-			String("protocol", relayProtocolName(c)).
-			String("upstream_url", c.GetString(ctxkey.UpstreamURL)).
-			Int("upstream_status", c.GetInt(ctxkey.UpstreamStatus)).
-			Int("retry_count", c.GetInt(ctxkey.RelayRetryCount)).
-			String("termination", c.GetString(ctxkey.RelayTermination)).
-			String("error_type", c.GetString(ctxkey.RelayErrorType)).
-			String("error_code", c.GetString(ctxkey.RelayErrorCode)).
-			String("error", c.GetString(ctxkey.RelayError))
+			switch {
+			case c.GetString(ctxkey.RelayTermination) != "":
+				logger.RelayWarnf(c.Request.Context(), end.Build())
+			case status >= 500 || c.GetString(ctxkey.RelayError) != "":
+				logger.RelayErrorf(c.Request.Context(), end.Build())
+			case status >= 400:
+				logger.RelayWarnf(c.Request.Context(), end.Build())
+			default:
+				logger.RelayInfof(c.Request.Context(), end.Build())
+			}
+		}()
 
-		switch {
-		case c.GetString(ctxkey.RelayTermination) != "":
-			logger.RelayWarnf(c.Request.Context(), end.Build())
-		case status >= 500 || c.GetString(ctxkey.RelayError) != "":
-			logger.RelayErrorf(c.Request.Context(), end.Build())
-		case status >= 400:
-			logger.RelayWarnf(c.Request.Context(), end.Build())
-		default:
-			logger.RelayInfof(c.Request.Context(), end.Build())
-		}
+		c.Next()
 	}
 }
 
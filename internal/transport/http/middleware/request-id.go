@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/random"
+)
+
+// maxRequestIDLength bounds client supplied ids before they reach log lines.
+const maxRequestIDLength = 128
+
+// RequestID takes the caller's X-Request-Id, or generates one, and makes it
+// available as helper.RequestIdKey on the gin context, in the request context
+// for the logger, and in the X-Request-Id response header. Install it before
+// the access log and anything that may abort the request.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimSpace(c.GetHeader(helper.XRequestIDHeader))
+		if !isValidRequestID(id) {
+			id = random.GetUUID()
+		}
+		c.Set(helper.RequestIdKey, id)
+		c.Request = c.Request.WithContext(helper.SetRequestID(c.Request.Context(), id))
+		c.Header(helper.XRequestIDHeader, id)
+		c.Next()
+	}
+}
+
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
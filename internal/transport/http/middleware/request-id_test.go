@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestRequestID(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.Use(RequestID())
+	var fromGin, fromContext string
+	engine.GET("/ping", func(c *gin.Context) {
+		fromGin = c.GetString(helper.RequestIdKey)
+		fromContext = helper.GetRequestID(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"propagated", "req-123", true},
+		{"missing", "", false},
+		{"control characters", "bad\nid", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		req := testutil.NewTestRequest(http.MethodGet, "/ping", nil)
+		if tt.header != "" {
+			req.Header.Set(helper.XRequestIDHeader, tt.header)
+		}
+		recorder := testutil.ServeTestRequest(engine, req)
+		got := recorder.Header().Get(helper.XRequestIDHeader)
+		if got == "" || got != fromGin || got != fromContext {
+			t.Fatalf("%s: header=%q gin=%q context=%q", tt.name, got, fromGin, fromContext)
+		}
+		if (got == tt.header) != tt.keep {
+			t.Fatalf("%s: request id = %q, header %q", tt.name, got, tt.header)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/config"
+)
+
+// SkipSecureHeadersKey, set to true on the context by a route handler or
+// middleware before the response is written, leaves the security headers out.
+const SkipSecureHeadersKey = "skip_secure_headers"
+
+// SecureHeadersConfig lists the security response headers to send; an empty
+// value leaves that header out. X-Content-Type-Options: nosniff is always sent.
+type SecureHeadersConfig struct {
+	HSTS                  string
+	ContentSecurityPolicy string
+	XFrameOptions         string
+	ReferrerPolicy        string
+	PermissionsPolicy     string
+}
+
+// DefaultSecureHeadersConfig is the policy from the secure_headers section of
+// config.yaml.
+func DefaultSecureHeadersConfig() SecureHeadersConfig {
+	return SecureHeadersConfig{
+		HSTS:                  config.SecureHeadersHSTS,
+		ContentSecurityPolicy: config.SecureHeadersContentSecurityPolicy,
+		XFrameOptions:         config.SecureHeadersXFrameOptions,
+		ReferrerPolicy:        config.SecureHeadersReferrerPolicy,
+		PermissionsPolicy:     config.SecureHeadersPermissionsPolicy,
+	}
+}
+
+// SecureHeaders adds cfg's headers to every response except the /health probes.
+// Headers are added when the response is first written, so a later handler
+// can still opt out through SkipSecureHeadersKey.
+func SecureHeaders(cfg SecureHeadersConfig) gin.HandlerFunc {
+	headers := [][2]string{{"X-Content-Type-Options", "nosniff"}}
+	for _, header := range [][2]string{
+		{"Strict-Transport-Security", cfg.HSTS},
+		{"Content-Security-Policy", cfg.ContentSecurityPolicy},
+		{"X-Frame-Options", cfg.XFrameOptions},
+		{"Referrer-Policy", cfg.ReferrerPolicy},
+		{"Permissions-Policy", cfg.PermissionsPolicy},
+	} {
+		if strings.TrimSpace(header[1]) != "" {
+			headers = append(headers, header)
+		}
+	}
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if path == "/health" || strings.HasPrefix(path, "/health/") {
+			c.Next()
+			return
+		}
+		writer := &secureHeadersWriter{ResponseWriter: c.Writer, c: c, headers: headers}
+		c.Writer = writer
+		c.Next()
+		// bodiless responses are flushed by gin after the chain returns
+		if !writer.Written() {
+			writer.apply()
+		}
+	}
+}
+
+type secureHeadersWriter struct {
+	gin.ResponseWriter
+	c       *gin.Context
+	headers [][2]string
+	applied bool
+}
+
+func (w *secureHeadersWriter) apply() {
+	if w.applied {
+		return
+	}
+	w.applied = true
+	if w.c.GetBool(SkipSecureHeadersKey) {
+		return
+	}
+	header := w.ResponseWriter.Header()
+	for _, h := range w.headers {
+		if header.Get(h[0]) == "" {
+			header.Set(h[0], h[1])
+		}
+	}
+}
+
+func (w *secureHeadersWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *secureHeadersWriter) Write(data []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *secureHeadersWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *secureHeadersWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.Use(SecureHeaders(SecureHeadersConfig{
+		ContentSecurityPolicy: "default-src 'self'",
+		XFrameOptions:         "DENY",
+	}))
+	engine.GET("/page", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	engine.GET("/empty", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	engine.GET("/embed", func(c *gin.Context) {
+		c.Set(SkipSecureHeadersKey, true)
+		c.String(http.StatusOK, "ok")
+	})
+	engine.GET("/health/live", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		path    string
+		wantCSP string
+		wantXFO string
+		wantCTO string
+	}{
+		{"/page", "default-src 'self'", "DENY", "nosniff"},
+		{"/empty", "default-src 'self'", "DENY", "nosniff"},
+		{"/embed", "", "", ""},
+		{"/health/live", "", "", ""},
+	}
+	for _, tt := range tests {
+		recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, tt.path, nil))
+		header := recorder.Header()
+		if header.Get("Content-Security-Policy") != tt.wantCSP || header.Get("X-Frame-Options") != tt.wantXFO || header.Get("X-Content-Type-Options") != tt.wantCTO {
+			t.Fatalf("%s headers = %v", tt.path, header)
+		}
+		if header.Get("Strict-Transport-Security") != "" {
+			t.Fatalf("%s sent HSTS without it being configured", tt.path)
+		}
+	}
+}
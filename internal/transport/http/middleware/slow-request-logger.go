@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/helper"
+	"github.com/yeying-community/router/common/logger"
+)
+
+// slowRequestWarnf is swapped out in tests.
+var slowRequestWarnf = logger.SysWarnf
+
+// SlowRequestLogger logs a warning for every request that takes longer than
+// threshold, with the user and channel that served it. It only measures the
+// handlers after it, so it can sit next to NewApiLogger in the chain.
+// threshold <= 0 disables it.
+func SlowRequestLogger(threshold time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if threshold <= 0 {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+		if latency <= threshold {
+			return
+		}
+		userID, _ := c.Get(ctxkey.Id)
+		channelID, _ := c.Get(ctxkey.ChannelId)
+		slowRequestWarnf("slow request method=%s path=%s status=%d latency=%s user_id=%v channel_id=%v trace_id=%s",
+			c.Request.Method, c.Request.URL.Path, c.Writer.Status(), latency, userID, channelID, c.GetString(helper.TraceIDKey))
+	}
+}
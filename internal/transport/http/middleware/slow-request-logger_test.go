@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestSlowRequestLogger(t *testing.T) {
+	var warnings []string
+	prev := slowRequestWarnf
+	slowRequestWarnf = func(format string, a ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, a...))
+	}
+	defer func() { slowRequestWarnf = prev }()
+
+	engine := testutil.NewTestEngine()
+	engine.Use(SlowRequestLogger(20 * time.Millisecond))
+	engine.GET("/fast", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	engine.GET("/slow", func(c *gin.Context) {
+		c.Set(ctxkey.Id, "user-1")
+		c.Set(ctxkey.ChannelId, 7)
+		time.Sleep(30 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+
+	testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/fast", nil))
+	if len(warnings) != 0 {
+		t.Fatalf("fast request logged: %v", warnings)
+	}
+	testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/slow", nil))
+	if len(warnings) != 1 {
+		t.Fatalf("slow request warnings = %v, want one", warnings)
+	}
+	for _, want := range []string{"method=GET", "path=/slow", "user_id=user-1", "channel_id=7", "latency="} {
+		if !strings.Contains(warnings[0], want) {
+			t.Fatalf("warning %q missing %q", warnings[0], want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+)
+
+// Timeout answers 504 when the handlers after it have not started the
+// response within d. The handlers run in their own goroutine on a context
+// that expires at the deadline. The 504 is written and flushed as soon as the
+// deadline passes, with a Content-Length so the client sees a complete
+// response even if a handler ignores its context; whatever the handlers write
+// afterwards is dropped. The middleware still waits for them to return before
+// handing the gin.Context back, so it is never shared with a recycled request.
+// Once the handlers have written or flushed anything the response is a stream
+// and runs to completion. d <= 0 disables it.
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		// the 504 is rendered on a copy, as the handlers still own c
+		shadow := c.Copy()
+		writer := newTimeoutWriter(ctx, c.Writer)
+		c.Writer = writer
+
+		done := make(chan struct{})
+		var panicked any
+		go func() {
+			defer close(done)
+			defer func() { panicked = recover() }()
+			c.Next()
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded) && writer.timeout(func(w gin.ResponseWriter) {
+			shadow.Writer = &contentLengthWriter{ResponseWriter: w}
+			abortWithMessage(shadow, http.StatusGatewayTimeout, fmt.Sprintf("请求处理超时（%s）", d))
+			w.Flush()
+		})
+		<-done
+		c.Writer = writer.ResponseWriter
+		if panicked != nil {
+			panic(panicked)
+		}
+		if timedOut {
+			for _, key := range []string{ctxkey.RelayError, ctxkey.RelayErrorType, ctxkey.RelayErrorCode} {
+				c.Set(key, shadow.GetString(key))
+			}
+			c.Abort()
+		}
+	}
+}
+
+// timeoutWriter drops every write once the request has timed out, and records
+// whether the response was started before that. A response not started by
+// the deadline of ctx is timed out even if the middleware has not noticed yet.
+// Headers set by the handlers are kept apart until the response starts, so a
+// late handler cannot touch the headers of the 504.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	mu       sync.Mutex
+	header   http.Header
+	started  bool
+	timedOut bool
+}
+
+func newTimeoutWriter(ctx context.Context, w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{ResponseWriter: w, ctx: ctx, header: w.Header().Clone()}
+}
+
+// timeout marks the response as timed out unless it has already started, and
+// then writes the timeout response with write.
+func (w *timeoutWriter) timeout(write func(gin.ResponseWriter)) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		return false
+	}
+	w.timedOut = true
+	header := w.ResponseWriter.Header()
+	header.Del("Content-Length")
+	header.Del("Content-Encoding")
+	write(w.ResponseWriter)
+	return true
+}
+
+// start copies the handlers' headers to the response the first time it is
+// written to, and reports false when the deadline passed before that. The
+// caller holds mu.
+func (w *timeoutWriter) start() bool {
+	if w.timedOut {
+		return false
+	}
+	if w.started {
+		return true
+	}
+	if errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+		return false
+	}
+	w.started = true
+	header := w.ResponseWriter.Header()
+	for key := range header {
+		header.Del(key)
+	}
+	for key, values := range w.header {
+		header[key] = values
+	}
+	return true
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		return w.ResponseWriter.Header()
+	}
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.start() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.start() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.start() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.start() {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// Hijack hands the connection over, e.g. for the realtime websocket, which is
+// then no longer subject to the timeout.
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.start() {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.Hijack()
+}
+
+// contentLengthWriter sets Content-Length from the first write, which holds
+// the whole rendered 504 body.
+type contentLengthWriter struct {
+	gin.ResponseWriter
+}
+
+func (w *contentLengthWriter) Write(data []byte) (int, error) {
+	if !w.Written() {
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	}
+	return w.ResponseWriter.Write(data)
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestTimeout(t *testing.T) {
+	canceled := make(chan bool, 1)
+	engine := testutil.NewTestEngine()
+	engine.Use(Timeout(20 * time.Millisecond))
+	engine.GET("/fast", func(c *gin.Context) {
+		c.String(http.StatusOK, "fast")
+	})
+	engine.GET("/slow", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+			canceled <- true
+		case <-time.After(time.Second):
+			canceled <- false
+		}
+		c.String(http.StatusOK, "too late")
+	})
+	engine.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.String(http.StatusOK, "data: first\n\n")
+		c.Writer.Flush()
+		time.Sleep(50 * time.Millisecond)
+		c.String(http.StatusOK, "data: second\n\n")
+	})
+
+	w := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "fast" {
+		t.Fatalf("fast: status=%d body=%q", w.Code, w.Body.String())
+	}
+
+	w = testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/slow", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("slow: status = %d, want 504", w.Code)
+	}
+	if !<-canceled {
+		t.Fatalf("slow: handler context was not canceled at the deadline")
+	}
+	if strings.Contains(w.Body.String(), "too late") {
+		t.Fatalf("slow: write after the deadline reached the client: %q", w.Body.String())
+	}
+
+	w = testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/stream", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "data: second") {
+		t.Fatalf("stream: status=%d body=%q, want the full stream", w.Code, w.Body.String())
+	}
+}
+
+func TestTimeout_RespondsWhileHandlerIgnoresContext(t *testing.T) {
+	release := make(chan struct{})
+	engine := testutil.NewTestEngine()
+	engine.Use(Timeout(20 * time.Millisecond))
+	engine.GET("/stuck", func(c *gin.Context) {
+		c.Header("X-Late", "1")
+		<-release
+		c.String(http.StatusOK, "too late")
+	})
+	server := httptest.NewServer(engine)
+	defer server.Close()
+	defer close(release)
+
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get(server.URL + "/stuck")
+	if err != nil {
+		t.Fatalf("client did not get the 504 while the handler was stuck: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if resp.StatusCode != http.StatusGatewayTimeout || !strings.Contains(string(body), "请求处理超时") {
+		t.Fatalf("status = %d body = %q, want 504", resp.StatusCode, body)
+	}
+	if resp.Header.Get("X-Late") != "" {
+		t.Fatalf("header set by the timed-out handler reached the client")
+	}
+}
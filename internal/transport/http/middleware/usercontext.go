@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// LoadUserContext stores the authenticated user in the context under
+// ctxkey.User. It must run after UserAuth/AdminAuth/RootAuth or TokenAuth;
+// when the auth middleware has already loaded the user the row is reused,
+// otherwise it is filled once through the user cache.
+func LoadUserContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(ctxkey.User); ok {
+			c.Next()
+			return
+		}
+		id := strings.TrimSpace(c.GetString(ctxkey.Id))
+		if id == "" {
+			c.Next()
+			return
+		}
+		user := model.User{Id: id}
+		if err := user.FillUserById(); err != nil {
+			logger.Loginf(c.Request.Context(), "load user context failed uid=%s err=%v", id, err)
+			c.Next()
+			return
+		}
+		c.Set(ctxkey.User, &user)
+		c.Next()
+	}
+}
+
+// CurrentUser returns the user stored by the auth middlewares or
+// LoadUserContext, or nil when the request is anonymous.
+func CurrentUser(c *gin.Context) *model.User {
+	value, ok := c.Get(ctxkey.User)
+	if !ok {
+		return nil
+	}
+	user, _ := value.(*model.User)
+	return user
+}
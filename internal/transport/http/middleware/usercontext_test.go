@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestLoadUserContext_ReusesUserFromAuth(t *testing.T) {
+	loaded := &model.User{Id: "u-1", Username: "alice"}
+
+	engine := testutil.NewTestEngine()
+	engine.Use(func(c *gin.Context) {
+		c.Set(ctxkey.Id, loaded.Id)
+		c.Set(ctxkey.User, loaded)
+	}, LoadUserContext())
+	var got *model.User
+	engine.GET("/self", func(c *gin.Context) {
+		got = CurrentUser(c)
+		c.Status(http.StatusOK)
+	})
+
+	testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/self", nil))
+	if got != loaded {
+		t.Fatalf("CurrentUser = %+v, want the user set by auth", got)
+	}
+}
+
+func TestLoadUserContext_AnonymousRequest(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.Use(LoadUserContext())
+	engine.GET("/public", func(c *gin.Context) {
+		if user := CurrentUser(c); user != nil {
+			t.Fatalf("CurrentUser = %+v, want nil", user)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, "/public", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", recorder.Code)
+	}
+}
@@ -7,38 +7,75 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/common/ctxkey"
 	"github.com/yeying-community/router/common/helper"
 	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/internal/relay/relaymode"
 )
 
+// abortWithMessage answers proto-aligned routes with the envelope of
+// common.AbortWithError and everything else, relay clients included, with the
+// OpenAI error shape.
 func abortWithMessage(c *gin.Context, statusCode int, message string) {
 	c.Set(ctxkey.RelayError, strings.TrimSpace(message))
 	c.Set(ctxkey.RelayErrorType, "one_api_error")
 	c.Set(ctxkey.RelayErrorCode, "request_aborted")
-	c.JSON(statusCode, gin.H{
-		"error": gin.H{
-			"message": helper.MessageWithTraceID(message, c.GetString(helper.TraceIDKey)),
-			"type":    "one_api_error",
-		},
-	})
-	c.Abort()
+	if isProtoRoute(c.Request.URL.Path) {
+		common.AbortWithError(c, statusCode, common.ProtoCodeForHTTPStatus(statusCode), message)
+	} else {
+		c.JSON(statusCode, gin.H{
+			"error": gin.H{
+				"message": helper.MessageWithTraceID(message, c.GetString(helper.TraceIDKey)),
+				"type":    "one_api_error",
+			},
+		})
+		c.Abort()
+	}
 	logger.Warnf(c.Request.Context(), "request aborted status=%d reason=%q path=%s", statusCode, strings.TrimSpace(message), c.Request.URL.Path)
 }
 
+// protoRoutePrefix covers the endpoints whose callers read status.code.
+const protoRoutePrefix = "/api/v1/public/common/"
+
+func isProtoRoute(path string) bool {
+	return strings.HasPrefix(path, protoRoutePrefix)
+}
+
 func normalizeRelayPath(path string) string {
 	if strings.HasPrefix(path, "/api/v1/public/") {
 		return "/v1/" + strings.TrimPrefix(path, "/api/v1/public/")
 	}
+	if config.AzureOpenAIMode {
+		if _, canonical, ok := relaymode.AzureDeploymentPath(path); ok {
+			return canonical
+		}
+	}
 	return path
 }
 
+// getRequestModel extracts the model from the body, path or query once per
+// request; later calls return the value cached under ctxkey.ModelName.
 func getRequestModel(c *gin.Context) (string, error) {
+	if cached, ok := c.Get(ctxkey.ModelName); ok {
+		if modelName, ok := cached.(string); ok {
+			return modelName, nil
+		}
+	}
 	var modelRequest ModelRequest
 	err := common.UnmarshalBodyReusable(c, &modelRequest)
 	if err != nil {
 		return "", fmt.Errorf("common.UnmarshalBodyReusable failed: %w", err)
 	}
+	if config.AzureOpenAIMode {
+		// Azure clients name the deployment in the path and usually omit model
+		if deployment, _, ok := relaymode.AzureDeploymentPath(c.Request.URL.Path); ok {
+			c.Set(ctxkey.DeploymentId, deployment)
+			if modelRequest.Model == "" {
+				modelRequest.Model = deployment
+			}
+		}
+	}
 	path := normalizeRelayPath(c.Request.URL.Path)
 	if strings.HasPrefix(path, "/v1/moderations") {
 		if modelRequest.Model == "" {
@@ -83,6 +120,7 @@ func getRequestModel(c *gin.Context) (string, error) {
 			}
 		}
 	}
+	c.Set(ctxkey.ModelName, modelRequest.Model)
 	return modelRequest.Model, nil
 }
 
@@ -95,3 +133,40 @@ func isModelInList(modelName string, models string) bool {
 	}
 	return false
 }
+
+// isModelInListGlob is isModelInList where entries may contain "*" wildcards,
+// e.g. "gpt-4*" or "claude-3-*-20240229". Exact entries are checked first.
+// With relay.model_list_glob_enabled off it matches exactly.
+func isModelInListGlob(modelName string, models string) bool {
+	if isModelInList(modelName, models) {
+		return true
+	}
+	if !config.ModelListGlobEnabled {
+		return false
+	}
+	for _, pattern := range strings.Split(models, ",") {
+		if strings.Contains(pattern, "*") && matchModelGlob(pattern, modelName) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchModelGlob reports whether name matches pattern, where each "*" stands
+// for any run of characters, "/" included.
+func matchModelGlob(pattern string, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		idx := strings.Index(name, part)
+		if idx < 0 {
+			return false
+		}
+		name = name[idx+len(part):]
+	}
+	return strings.HasSuffix(name, parts[last])
+}
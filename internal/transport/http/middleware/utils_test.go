@@ -2,16 +2,22 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
 	"mime/multipart"
-	"net/http/httptest"
+	"net/http"
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/testutil"
 )
 
 func TestGetRequestModel_VideosMultipart(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 	if err := writer.WriteField("model", "veo-3.0-generate-preview"); err != nil {
@@ -24,10 +30,8 @@ func TestGetRequestModel_VideosMultipart(t *testing.T) {
 		t.Fatalf("writer.Close error: %v", err)
 	}
 
-	req := httptest.NewRequest("POST", "/v1/videos", body)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	c, _ := gin.CreateTestContext(httptest.NewRecorder())
-	c.Request = req
+	c, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/videos", body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
 
 	modelName, err := getRequestModel(c)
 	if err != nil {
@@ -39,11 +43,7 @@ func TestGetRequestModel_VideosMultipart(t *testing.T) {
 }
 
 func TestGetRequestModel_VideoStatusQuery(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-
-	req := httptest.NewRequest("GET", "/v1/videos/task_123?model=veo-3.0-generate-preview", nil)
-	c, _ := gin.CreateTestContext(httptest.NewRecorder())
-	c.Request = req
+	c, _ := testutil.NewTestGinContext(http.MethodGet, "/v1/videos/task_123?model=veo-3.0-generate-preview", nil)
 
 	modelName, err := getRequestModel(c)
 	if err != nil {
@@ -55,12 +55,7 @@ func TestGetRequestModel_VideoStatusQuery(t *testing.T) {
 }
 
 func TestGetRequestModel_RealtimeQuery(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-
-	req := httptest.NewRequest("POST", "/v1/realtime/calls?model=gpt-realtime-2", bytes.NewBufferString(`{}`))
-	req.Header.Set("Content-Type", "application/json")
-	c, _ := gin.CreateTestContext(httptest.NewRecorder())
-	c.Request = req
+	c, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/realtime/calls?model=gpt-realtime-2", `{}`)
 
 	modelName, err := getRequestModel(c)
 	if err != nil {
@@ -72,12 +67,7 @@ func TestGetRequestModel_RealtimeQuery(t *testing.T) {
 }
 
 func TestGetRequestModel_RealtimeNestedSessionModel(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-
-	req := httptest.NewRequest("POST", "/v1/realtime/client_secrets", bytes.NewBufferString(`{"session":{"model":"gpt-realtime-1.5"}}`))
-	req.Header.Set("Content-Type", "application/json")
-	c, _ := gin.CreateTestContext(httptest.NewRecorder())
-	c.Request = req
+	c, _ := testutil.NewTestGinContext(http.MethodPost, "/v1/realtime/client_secrets", `{"session":{"model":"gpt-realtime-1.5"}}`)
 
 	modelName, err := getRequestModel(c)
 	if err != nil {
@@ -87,3 +77,116 @@ func TestGetRequestModel_RealtimeNestedSessionModel(t *testing.T) {
 		t.Fatalf("getRequestModel returned %q, want %q", modelName, "gpt-realtime-1.5")
 	}
 }
+
+func TestGetRequestModel_AzureDeployment(t *testing.T) {
+	prev := config.AzureOpenAIMode
+	config.AzureOpenAIMode = true
+	defer func() { config.AzureOpenAIMode = prev }()
+
+	if got := normalizeRelayPath("/openai/deployments/gpt-4o-prod/chat/completions"); got != "/v1/chat/completions" {
+		t.Fatalf("normalizeRelayPath returned %q, want /v1/chat/completions", got)
+	}
+
+	c, _ := testutil.NewTestGinContext(http.MethodPost, "/openai/deployments/gpt-4o-prod/chat/completions?api-version=2024-06-01", `{"messages":[]}`)
+
+	modelName, err := getRequestModel(c)
+	if err != nil {
+		t.Fatalf("getRequestModel returned error: %v", err)
+	}
+	if modelName != "gpt-4o-prod" {
+		t.Fatalf("getRequestModel returned %q, want %q", modelName, "gpt-4o-prod")
+	}
+	if got := c.GetString(ctxkey.DeploymentId); got != "gpt-4o-prod" {
+		t.Fatalf("deployment_id = %q, want %q", got, "gpt-4o-prod")
+	}
+
+	c, _ = testutil.NewTestGinContext(http.MethodPost, "/openai/deployments/gpt-4o-prod/chat/completions", `{"model":"gpt-4o"}`)
+	if modelName, _ = getRequestModel(c); modelName != "gpt-4o" {
+		t.Fatalf("explicit model replaced by deployment: %q", modelName)
+	}
+}
+
+func TestIsModelInListGlob(t *testing.T) {
+	prev := config.ModelListGlobEnabled
+	defer func() { config.ModelListGlobEnabled = prev }()
+	config.ModelListGlobEnabled = true
+
+	tests := []struct {
+		model  string
+		models string
+		want   bool
+	}{
+		{"gpt-4", "gpt-4*", true},
+		{"gpt-4-turbo", "gpt-4*", true},
+		{"gpt-4o", "gpt-4*", true},
+		{"gpt-3.5-turbo", "gpt-4*", false},
+		{"claude-3-opus-20240229", "claude-3-*-20240229", true},
+		{"claude-3-sonnet-20240229", "claude-3-*-20240229", true},
+		{"claude-3-haiku-20240307", "claude-3-*-20240229", false},
+		{"claude-3-20240229", "claude-3-*-20240229", false},
+		{"gpt-4o-mini", "gpt-4o-mini,claude-*", true},
+		{"gpt-4o", "gpt-4o-mini,claude-*", false},
+		{"meta-llama/llama-3-70b", "meta-llama/*", true},
+		{"anything", "*", true},
+		{"gpt-4*", "gpt-4*", true},
+	}
+	for _, tt := range tests {
+		if got := isModelInListGlob(tt.model, tt.models); got != tt.want {
+			t.Fatalf("isModelInListGlob(%q, %q) = %t, want %t", tt.model, tt.models, got, tt.want)
+		}
+	}
+
+	config.ModelListGlobEnabled = false
+	if isModelInListGlob("gpt-4o", "gpt-4*") {
+		t.Fatalf("wildcard matched with model_list_glob_enabled off")
+	}
+	if !isModelInListGlob("gpt-4o", "gpt-4*,gpt-4o") {
+		t.Fatalf("exact entry rejected with model_list_glob_enabled off")
+	}
+}
+
+func TestAbortWithMessage_ProtoRoutesShareHandlerEnvelope(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.POST("/api/v1/public/common/auth/verify", BodySizeLimit(8), func(c *gin.Context) {
+		common.AbortWithError(c, http.StatusRequestEntityTooLarge, common.ProtoCodeInvalidArgument, "handler error")
+	})
+	engine.POST("/v1/chat/completions", BodySizeLimit(8), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	decode := func(path string, body string) map[string]any {
+		req := testutil.NewTestRequest(http.MethodPost, path, body)
+		req.ContentLength = int64(len(body))
+		recorder := testutil.ServeTestRequest(engine, req)
+		var payload map[string]any
+		if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("%s: invalid JSON %q: %v", path, recorder.Body.String(), err)
+		}
+		return payload
+	}
+	keys := func(payload map[string]any) []string {
+		out := make([]string, 0, len(payload))
+		for key := range payload {
+			out = append(out, key)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	fromMiddleware := decode("/api/v1/public/common/auth/verify", `{"signature":"too long"}`)
+	fromHandler := decode("/api/v1/public/common/auth/verify", `{}`)
+	if got, want := keys(fromMiddleware), keys(fromHandler); !reflect.DeepEqual(got, want) {
+		t.Fatalf("middleware envelope keys = %v, handler keys = %v", got, want)
+	}
+	status, ok := fromMiddleware["status"].(map[string]any)
+	if !ok || status["code"] != float64(common.ProtoCodeInvalidArgument) || fromMiddleware["success"] != false {
+		t.Fatalf("middleware envelope = %v", fromMiddleware)
+	}
+	if handlerStatus, _ := fromHandler["status"].(map[string]any); !reflect.DeepEqual(keys(status), keys(handlerStatus)) {
+		t.Fatalf("status keys differ: %v vs %v", status, handlerStatus)
+	}
+
+	if relay := decode("/v1/chat/completions", `{"model":"too long"}`); relay["error"] == nil || relay["status"] != nil {
+		t.Fatalf("relay error shape changed: %v", relay)
+	}
+}
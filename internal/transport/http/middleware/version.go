@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+)
+
+const routerVersionHeader = "X-Router-Version"
+
+// VersionHeader tags every response with the running build, e.g.
+// "v1.2.3; commit=abc123; built=2026-10-16T00:00:00Z".
+func VersionHeader() gin.HandlerFunc {
+	parts := []string{common.Version}
+	if common.GitCommit != "" {
+		parts = append(parts, "commit="+common.GitCommit)
+	}
+	if common.BuildTime != "" {
+		parts = append(parts, "built="+common.BuildTime)
+	}
+	value := strings.Join(parts, "; ")
+	return func(c *gin.Context) {
+		c.Header(routerVersionHeader, value)
+		c.Next()
+	}
+}
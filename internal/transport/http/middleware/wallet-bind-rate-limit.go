@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/logger"
+)
+
+const walletBindRateLimitMark = "rateLimit:walletBind:"
+
+// WalletBindRateLimit throttles bind attempts per logged-in user with
+// config.WalletBindRateLimit. It must run after UserAuth; repeated binds with
+// different addresses are how an account would probe for address takeover.
+func WalletBindRateLimit() gin.HandlerFunc {
+	limit, window, err := common.ParseRateLimit(config.WalletBindRateLimit)
+	if err != nil {
+		logger.SysErrorf("invalid wallet bind rate limit %q, disabled: %v", config.WalletBindRateLimit, err)
+	}
+	return newWalletBindRateLimiter(limit, window)
+}
+
+func newWalletBindRateLimiter(limit int, window time.Duration) gin.HandlerFunc {
+	if limit <= 0 || window <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	store := common.NewTokenBucketStore(float64(limit)/window.Seconds(), limit, window+config.RateLimitKeyExpirationDuration)
+	return func(c *gin.Context) {
+		userID, ok := c.Get(ctxkey.Id)
+		if !ok {
+			c.Next()
+			return
+		}
+		key := fmt.Sprint(userID)
+		var allowed bool
+		var retryAfter time.Duration
+		if common.RedisEnabled && common.RDB != nil {
+			allowed, retryAfter = allowRateLimitRedis(c, walletBindRateLimitMark, key, limit, window)
+		} else {
+			bucket := store.GetOrCreate(key)
+			allowed = bucket.Allow()
+			if !allowed {
+				retryAfter = bucket.RetryAfter()
+			}
+		}
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			logger.Warnf(c.Request.Context(), "wallet bind rate limited user_id=%s ip=%s retry_after=%s, possible address takeover attempt", key, c.ClientIP(), retryAfter)
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			common.AbortWithError(c, http.StatusTooManyRequests, common.ProtoCodeResourceExhausted, fmt.Sprintf("钱包绑定尝试过于频繁，请在 %d 秒后重试", seconds))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestWalletBindRateLimit_PerUser(t *testing.T) {
+	engine := testutil.NewTestEngine()
+	engine.POST("/bind", func(c *gin.Context) {
+		if id, err := strconv.Atoi(c.Query("user")); err == nil {
+			c.Set(ctxkey.Id, id)
+		}
+	}, newWalletBindRateLimiter(2, time.Hour), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	calls := []struct {
+		user string
+		want int
+	}{
+		{"1", http.StatusOK},
+		{"1", http.StatusOK},
+		{"2", http.StatusOK},
+		{"1", http.StatusTooManyRequests},
+		{"2", http.StatusOK},
+		{"2", http.StatusTooManyRequests},
+		{"", http.StatusOK},
+	}
+	for i, call := range calls {
+		recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodPost, "/bind?user="+call.user, nil))
+		if recorder.Code != call.want {
+			t.Fatalf("call #%d status = %d, want %d", i+1, recorder.Code, call.want)
+		}
+		if call.want == http.StatusTooManyRequests {
+			retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+			if err != nil || retryAfter <= 0 || retryAfter > int(time.Hour.Seconds()) {
+				t.Fatalf("Retry-After = %q", recorder.Header().Get("Retry-After"))
+			}
+		}
+	}
+}
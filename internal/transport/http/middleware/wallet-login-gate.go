@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/internal/admin/model"
+)
+
+// WalletNoncePurposeBind is the purpose query value of a nonce requested to
+// bind a wallet rather than to log in with it.
+const WalletNoncePurposeBind = "bind"
+
+// WalletLoginGate rejects wallet challenge and login requests while an admin
+// has wallet login switched off. Token refresh and bound sessions are not
+// affected.
+func WalletLoginGate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if model.WalletLoginEnabled() {
+			c.Next()
+			return
+		}
+		rejectWalletGate(c, "管理员未开启钱包登录")
+	}
+}
+
+// WalletNonceGate gates nonce and challenge requests by the flow they are for:
+// purpose=bind needs wallet binding switched on, anything else wallet login.
+// Binding can thus be opened before login.
+func WalletNonceGate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.EqualFold(strings.TrimSpace(c.Query("purpose")), WalletNoncePurposeBind) {
+			WalletLoginGate()(c)
+			return
+		}
+		WalletBindGate()(c)
+	}
+}
+
+// WalletBindGate rejects wallet bind requests while an admin has wallet
+// binding switched off. Unbinding stays available.
+func WalletBindGate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if model.WalletBindEnabled() {
+			c.Next()
+			return
+		}
+		rejectWalletGate(c, "管理员未开启钱包绑定")
+	}
+}
+
+func rejectWalletGate(c *gin.Context, message string) {
+	if isProtoRoute(c.Request.URL.Path) {
+		common.AbortWithError(c, http.StatusForbidden, common.ProtoCodePermissionDenied, message)
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"success": false,
+		"message": message,
+	})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+// The switches are cached by model for 30 seconds, so the test sets them once
+// before the first lookup: login off, binding on.
+func TestWalletNonceGate(t *testing.T) {
+	prevLogin, prevBind := config.WalletLoginEnabled, config.WalletBindEnabled
+	config.WalletLoginEnabled, config.WalletBindEnabled = false, true
+	defer func() { config.WalletLoginEnabled, config.WalletBindEnabled = prevLogin, prevBind }()
+
+	engine := testutil.NewTestEngine()
+	engine.GET("/nonce", WalletNonceGate(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.POST("/bind", WalletBindGate(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{http.MethodGet, "/nonce", http.StatusForbidden},
+		{http.MethodGet, "/nonce?purpose=login", http.StatusForbidden},
+		{http.MethodGet, "/nonce?purpose=bind", http.StatusOK},
+		{http.MethodGet, "/nonce?purpose=BIND", http.StatusOK},
+		{http.MethodPost, "/bind", http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := testutil.ServeTestRequest(engine, testutil.NewTestRequest(tc.method, tc.target, nil))
+		if w.Code != tc.want {
+			t.Fatalf("%s %s: status = %d, want %d", tc.method, tc.target, w.Code, tc.want)
+		}
+	}
+}
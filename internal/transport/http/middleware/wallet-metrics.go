@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yeying-community/router/common/ctxkey"
+	"github.com/yeying-community/router/common/metrics"
+)
+
+// WalletMetrics times wallet login handlers for wallet_login_duration_seconds
+// and wallet_login_attempts_total. Only requests whose handler reached the
+// signature check (and set ctxkey.WalletLoginResult) are counted, so malformed
+// requests don't skew the histogram. It only records metrics and can sit next
+// to the request loggers.
+func WalletMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		value, ok := c.Get(ctxkey.WalletLoginResult)
+		if !ok {
+			return
+		}
+		success, _ := value.(bool)
+		metrics.ObserveWalletLogin(success, time.Since(start))
+	}
+}
@@ -1,15 +1,18 @@
 package router
 
 import (
-	"github.com/gin-contrib/gzip"
+	"time"
+
 	"github.com/gin-gonic/gin"
 
+	"github.com/yeying-community/router/common/config"
 	admin "github.com/yeying-community/router/internal/admin/controller"
 	auth "github.com/yeying-community/router/internal/admin/controller/auth"
 	adminbilling "github.com/yeying-community/router/internal/admin/controller/billing"
 	channel "github.com/yeying-community/router/internal/admin/controller/channel"
 	dashboard "github.com/yeying-community/router/internal/admin/controller/dashboard"
 	flow "github.com/yeying-community/router/internal/admin/controller/flow"
+	gql "github.com/yeying-community/router/internal/admin/controller/gql"
 	group "github.com/yeying-community/router/internal/admin/controller/group"
 	log "github.com/yeying-community/router/internal/admin/controller/log"
 	option "github.com/yeying-community/router/internal/admin/controller/option"
@@ -21,29 +24,55 @@ import (
 	"github.com/yeying-community/router/internal/transport/http/middleware"
 )
 
+// Request body limits: every /api group below except the relay routes uses
+// server.max_request_body_bytes; relay requests carry images and audio and are
+// left unlimited. Wallet nonce/challenge routes are capped at
+// middleware.WalletNonceBodyLimit and login/verify at
+// middleware.WalletLoginBodyLimit.
+
+// walletLegacySunset is when the legacy /oauth/wallet nonce+login flow is removed
+// in favour of the proto-aligned challenge/verify endpoints.
+var walletLegacySunset = time.Date(2027, time.April, 16, 0, 0, 0, 0, time.UTC)
+
 func SetApiRouter(engine *gin.Engine) {
+	setWalletCORS()
+	walletGeoBlock := middleware.WalletGeoBlock()
+	adminIPAllowlist := middleware.IPAllowlist(config.AdminIPAllowlist)
+
 	publicAuthRouter := engine.Group("/api/v1/public/common/auth")
-	publicAuthRouter.Use(gzip.Gzip(gzip.DefaultCompression))
-	publicAuthRouter.Use(middleware.GlobalAPIRateLimit())
+	publicAuthRouter.Use(middleware.Gzip(config.GzipMinSizeBytes), middleware.Timeout(requestTimeout("api")))
+	publicAuthRouter.Use(middleware.GlobalAPIRateLimit(), middleware.NoCache(), middleware.BodySizeLimit(config.MaxRequestBodyBytes))
 	{
-		publicAuthRouter.POST("/challenge", middleware.CriticalRateLimit(), auth.WalletChallengeProto)
-		publicAuthRouter.POST("/verify", middleware.CriticalRateLimit(), auth.WalletVerifyProto)
+		publicAuthRouter.POST("/challenge", middleware.BodySizeLimit(middleware.WalletNonceBodyLimit), middleware.CriticalRateLimit(), middleware.WalletNonceRateLimit(), walletGeoBlock, middleware.WalletNonceGate(), auth.WalletChallengeProto)
+		publicAuthRouter.POST("/verify", middleware.BodySizeLimit(middleware.WalletLoginBodyLimit), middleware.CriticalRateLimit(), walletGeoBlock, middleware.WalletLoginGate(), middleware.WalletMetrics(), auth.WalletVerifyProto)
 		publicAuthRouter.POST("/refreshToken", middleware.CriticalRateLimit(), auth.WalletRefreshToken)
 	}
 
 	web3AuthRouter := engine.Group("/api/v1/public/auth")
-	web3AuthRouter.Use(gzip.Gzip(gzip.DefaultCompression))
-	web3AuthRouter.Use(middleware.GlobalAPIRateLimit())
+	web3AuthRouter.Use(middleware.Gzip(config.GzipMinSizeBytes), middleware.Timeout(requestTimeout("api")))
+	web3AuthRouter.Use(middleware.GlobalAPIRateLimit(), middleware.NoCache(), middleware.BodySizeLimit(config.MaxRequestBodyBytes))
 	{
-		web3AuthRouter.POST("/challenge", middleware.CriticalRateLimit(), auth.WalletChallengeWeb3)
-		web3AuthRouter.POST("/verify", middleware.CriticalRateLimit(), auth.WalletVerifyWeb3)
+		web3AuthRouter.POST("/challenge", middleware.BodySizeLimit(middleware.WalletNonceBodyLimit), middleware.CriticalRateLimit(), middleware.WalletNonceRateLimit(), walletGeoBlock, middleware.WalletNonceGate(), auth.WalletChallengeWeb3)
+		web3AuthRouter.POST("/verify", middleware.BodySizeLimit(middleware.WalletLoginBodyLimit), middleware.CriticalRateLimit(), walletGeoBlock, middleware.WalletLoginGate(), middleware.WalletMetrics(), auth.WalletVerifyWeb3)
 		web3AuthRouter.POST("/refresh", middleware.CriticalRateLimit(), auth.WalletRefreshWeb3)
 		web3AuthRouter.POST("/logout", middleware.CriticalRateLimit(), auth.WalletLogoutWeb3)
+		web3AuthRouter.POST("/apikey/register", middleware.CriticalRateLimit(), middleware.UserAuth(), auth.RegisterApiKey)
+		web3AuthRouter.POST("/webauthn/register/begin", middleware.CriticalRateLimit(), middleware.UserAuth(), auth.WebAuthnRegisterBegin)
+		web3AuthRouter.POST("/webauthn/register/finish", middleware.BodySizeLimit(middleware.WalletLoginBodyLimit), middleware.CriticalRateLimit(), middleware.UserAuth(), auth.WebAuthnRegisterFinish)
+		web3AuthRouter.POST("/webauthn/login/begin", middleware.CriticalRateLimit(), walletGeoBlock, auth.WebAuthnLoginBegin)
+		web3AuthRouter.POST("/webauthn/login/finish", middleware.BodySizeLimit(middleware.WalletLoginBodyLimit), middleware.CriticalRateLimit(), walletGeoBlock, auth.WebAuthnLoginFinish)
 	}
 
+	engine.GET("/api/v1/auth/wallet/challenge-types", middleware.GlobalAPIRateLimit(), auth.WalletChallengeTypes)
+	engine.GET("/api/v1/system/version", middleware.GlobalAPIRateLimit(), admin.GetVersion)
+	engine.GET("/api/v1/system/maintenance", middleware.GlobalAPIRateLimit(), admin.GetMaintenanceStatus)
+	engine.GET("/health", admin.GetHealth)
+	engine.GET("/health/live", admin.GetHealthLive)
+	engine.GET("/health/ready", admin.GetHealth)
+
 	publicRouter := engine.Group("/api/v1/public")
-	publicRouter.Use(gzip.Gzip(gzip.DefaultCompression))
-	publicRouter.Use(middleware.GlobalAPIRateLimit())
+	publicRouter.Use(middleware.Gzip(config.GzipMinSizeBytes), middleware.Timeout(requestTimeout("api")))
+	publicRouter.Use(middleware.GlobalAPIRateLimit(), middleware.BodySizeLimit(config.MaxRequestBodyBytes))
 	{
 		publicRouter.GET("/profile", middleware.CriticalRateLimit(), auth.PublicProfile)
 
@@ -59,9 +88,22 @@ func SetApiRouter(engine *gin.Engine) {
 		publicRouter.GET("/reset_password", middleware.CriticalRateLimit(), admin.SendPasswordResetEmail)
 		publicRouter.POST("/user/reset", middleware.CriticalRateLimit(), admin.ResetPassword)
 
-		publicRouter.GET("/oauth/wallet/nonce", middleware.CriticalRateLimit(), auth.WalletNonce)
-		publicRouter.POST("/oauth/wallet/login", middleware.CriticalRateLimit(), auth.WalletLogin)
-		publicRouter.POST("/oauth/wallet/bind", middleware.CriticalRateLimit(), middleware.UserAuth(), auth.WalletBind)
+		publicRouter.GET("/oauth/wallet/nonce", middleware.BodySizeLimit(middleware.WalletNonceBodyLimit), middleware.CriticalRateLimit(), middleware.WalletNonceRateLimit(), middleware.NoCache(), middleware.Deprecated(walletLegacySunset, "/api/v1/public/common/auth/challenge"), walletGeoBlock, middleware.WalletNonceGate(), auth.WalletNonceGET)
+		publicRouter.POST("/oauth/wallet/nonce", middleware.BodySizeLimit(middleware.WalletNonceBodyLimit), middleware.CriticalRateLimit(), middleware.WalletNonceRateLimit(), middleware.NoCache(), middleware.Deprecated(walletLegacySunset, "/api/v1/public/common/auth/challenge"), walletGeoBlock, middleware.WalletNonceGate(), auth.WalletNonce)
+		publicRouter.GET("/oauth/wallet/challenge-types", auth.WalletChallengeTypes)
+		publicRouter.GET("/wallet/chains", auth.WalletChains)
+		publicRouter.GET("/oauth/wallet/status", middleware.NoCache(), middleware.UserAuth(), auth.WalletStatus)
+		publicRouter.GET("/oauth/wallet/history", middleware.NoCache(), middleware.UserAuth(), auth.WalletLoginHistory)
+		// the token is checked by the handler itself so expired tokens can be described
+		publicRouter.GET("/oauth/wallet/token/info", middleware.CriticalRateLimit(), middleware.NoCache(), auth.WalletTokenInfo)
+		publicRouter.POST("/oauth/wallet/login", middleware.BodySizeLimit(middleware.WalletLoginBodyLimit), middleware.CriticalRateLimit(), middleware.NoCache(), middleware.Deprecated(walletLegacySunset, "/api/v1/public/common/auth/verify"), walletGeoBlock, middleware.WalletLoginGate(), middleware.WalletMetrics(), auth.WalletLogin)
+		publicRouter.POST("/oauth/wallet/logout", middleware.CriticalRateLimit(), middleware.NoCache(), auth.WalletLogout)
+		publicRouter.POST("/oauth/wallet/bind", middleware.CriticalRateLimit(), middleware.WalletBindGate(), middleware.NoCache(), middleware.JWTAuth(), middleware.WalletBindRateLimit(), auth.WalletBind)
+		// wallet-first signup: prove the wallet, then create the password account
+		publicRouter.POST("/oauth/wallet/bind/init", middleware.BodySizeLimit(middleware.WalletLoginBodyLimit), middleware.CriticalRateLimit(), middleware.NoCache(), walletGeoBlock, middleware.WalletBindGate(), auth.WalletBindInit)
+		publicRouter.POST("/oauth/wallet/bind/complete", middleware.CriticalRateLimit(), middleware.NoCache(), middleware.WalletBindGate(), auth.WalletBindComplete)
+		publicRouter.POST("/oauth/wallet/unbind", middleware.CriticalRateLimit(), middleware.NoCache(), middleware.JWTAuth(), auth.WalletUnbind)
+		publicRouter.DELETE("/oauth/wallet/bind", middleware.CriticalRateLimit(), middleware.NoCache(), middleware.JWTAuth(), auth.WalletUnbind)
 		publicRouter.GET("/oauth/state", middleware.CriticalRateLimit(), auth.GenerateOAuthCode)
 		publicRouter.GET("/oauth/github", middleware.CriticalRateLimit(), auth.GitHubOAuth)
 		publicRouter.GET("/oauth/lark", middleware.CriticalRateLimit(), auth.LarkOAuth)
@@ -72,8 +114,9 @@ func SetApiRouter(engine *gin.Engine) {
 			publicUserRoute.POST("/login", middleware.CriticalRateLimit(), user.Login)
 			publicUserRoute.GET("/logout", user.Logout)
 
+			// self, token, log and model routes also accept ApiKey-signed requests
 			publicSelfRoute := publicUserRoute.Group("/")
-			publicSelfRoute.Use(middleware.UserAuth())
+			publicSelfRoute.Use(middleware.UserOrApiKeyAuth(), middleware.LoadUserContext())
 			{
 				publicSelfRoute.GET("/self", user.GetSelf)
 				publicSelfRoute.GET("/dashboard", user.GetUserDashboard)
@@ -106,7 +149,7 @@ func SetApiRouter(engine *gin.Engine) {
 		}
 
 		publicTokenRoute := publicRouter.Group("/token")
-		publicTokenRoute.Use(middleware.UserAuth())
+		publicTokenRoute.Use(middleware.UserOrApiKeyAuth())
 		{
 			publicTokenRoute.GET("/", token.GetAllTokens)
 			publicTokenRoute.GET("/search", token.SearchTokens)
@@ -117,7 +160,7 @@ func SetApiRouter(engine *gin.Engine) {
 		}
 
 		publicLogRoute := publicRouter.Group("/log")
-		publicLogRoute.Use(middleware.UserAuth())
+		publicLogRoute.Use(middleware.UserOrApiKeyAuth())
 		{
 			publicLogRoute.GET("/stat", log.GetLogsSelfStat)
 			publicLogRoute.GET("/options", log.GetUserLogFilterOptions)
@@ -129,19 +172,19 @@ func SetApiRouter(engine *gin.Engine) {
 		publicChannelRoute := publicRouter.Group("/channel")
 		{
 			// 模型列表对所有登录用户开放，方便前端展示供应商/模型
-			publicChannelRoute.GET("/models", middleware.UserAuth(), admin.DashboardListModels)
+			publicChannelRoute.GET("/models", middleware.UserOrApiKeyAuth(), admin.DashboardListModels)
 		}
 	}
 
 	publicModelsRouter := engine.Group("/api/v1/public/models")
-	publicModelsRouter.Use(middleware.TokenAuth())
+	publicModelsRouter.Use(middleware.Timeout(requestTimeout("relay")), middleware.TokenOrJWTAuth())
 	{
 		publicModelsRouter.GET("", admin.ListModels)
 		publicModelsRouter.GET("/:model", admin.RetrieveModel)
 	}
 
 	publicRelayRouter := engine.Group("/api/v1/public")
-	publicRelayRouter.Use(middleware.RelayLogger(), middleware.TokenAuth(), middleware.Distribute())
+	publicRelayRouter.Use(middleware.RelayLogger(), middleware.TokenOrJWTAuth(), middleware.LoadUserContext(), middleware.Distribute(), middleware.ResponsePostProcess())
 	{
 		publicRelayRouter.POST("/completions", admin.Relay)
 		publicRelayRouter.POST("/chat/completions", admin.Relay)
@@ -203,9 +246,15 @@ func SetApiRouter(engine *gin.Engine) {
 		publicRelayRouter.GET("/threads/:id/runs/:runsId/steps", admin.RelayNotImplemented)
 	}
 
+	// Registered outside the admin group: the export compresses and flushes its own
+	// stream, so it must not be wrapped by the group's gzip middleware.
+	engine.GET("/api/v1/admin/relay-logs/export", adminIPAllowlist, middleware.GlobalAPIRateLimit(), middleware.AdminAuth(), log.ExportRelayLogs)
+	// registered outside adminRouter: the CSV may exceed server.max_request_body_bytes
+	engine.POST("/api/v1/admin/wallet/import", adminIPAllowlist, middleware.GlobalAPIRateLimit(), middleware.AdminAuth(), middleware.BodySizeLimit(auth.WalletImportMaxBytes+(1<<20)), auth.ImportWalletBindings)
+
 	adminRouter := engine.Group("/api/v1/admin")
-	adminRouter.Use(gzip.Gzip(gzip.DefaultCompression))
-	adminRouter.Use(middleware.GlobalAPIRateLimit())
+	adminRouter.Use(adminIPAllowlist, middleware.Gzip(config.GzipMinSizeBytes), middleware.Timeout(requestTimeout("admin")))
+	adminRouter.Use(middleware.GlobalAPIRateLimit(), middleware.BodySizeLimit(config.MaxRequestBodyBytes))
 	{
 		adminUserRoute := adminRouter.Group("/user")
 		adminUserRoute.Use(middleware.AdminAuth())
@@ -228,6 +277,12 @@ func SetApiRouter(engine *gin.Engine) {
 			adminUserRoute.DELETE("/:id", user.DeleteUser)
 		}
 
+		adminCacheRoute := adminRouter.Group("/cache")
+		adminCacheRoute.Use(middleware.AdminAuth())
+		{
+			adminCacheRoute.GET("/users/stats", user.GetUserCacheStats)
+		}
+
 		adminOptionRoute := adminRouter.Group("/option")
 		adminOptionRoute.Use(middleware.RootAuth())
 		{
@@ -235,6 +290,14 @@ func SetApiRouter(engine *gin.Engine) {
 			adminOptionRoute.PUT("/", option.UpdateOption)
 		}
 
+		adminSettingsRoute := adminRouter.Group("/settings")
+		adminSettingsRoute.Use(middleware.RootAuth())
+		{
+			adminSettingsRoute.PUT("/wallet_login_enabled", option.UpdateWalletLoginEnabled)
+			adminSettingsRoute.PUT("/wallet_bind_enabled", option.UpdateWalletBindEnabled)
+			adminSettingsRoute.PUT("/wallet_auto_register_enabled", option.UpdateWalletAutoRegisterEnabled)
+		}
+
 		adminBillingRoute := adminRouter.Group("/billing")
 		adminBillingRoute.Use(middleware.AdminAuth())
 		{
@@ -276,6 +339,9 @@ func SetApiRouter(engine *gin.Engine) {
 		adminChannelsRoute.Use(middleware.AdminAuth())
 		{
 			adminChannelsRoute.GET("/", channel.GetChannels)
+			adminChannelsRoute.PATCH("/bulk", channel.BulkUpdateChannels)
+			adminChannelsRoute.PUT("/:id", channel.UpdateChannelById)
+			adminChannelsRoute.DELETE("/:id", channel.SoftDeleteChannel)
 		}
 		adminTasksRoute := adminRouter.Group("/tasks")
 		adminTasksRoute.Use(middleware.AdminAuth())
@@ -291,6 +357,46 @@ func SetApiRouter(engine *gin.Engine) {
 		{
 			adminDashboardRoute.GET("/", dashboard.GetDashboard)
 		}
+		adminUsersRoute := adminRouter.Group("/users")
+		adminUsersRoute.Use(middleware.AdminAuth())
+		{
+			adminUsersRoute.GET("", user.GetUsersByStatus)
+			adminUsersRoute.POST("/merge", user.MergeUsers)
+			adminUsersRoute.PUT("/:id/role", user.SetUserRole)
+			adminUsersRoute.POST("/:id/approve", user.ApproveUser)
+			adminUsersRoute.POST("/:id/reject", user.RejectUser)
+		}
+		adminSystemRoute := adminRouter.Group("/system")
+		adminSystemRoute.Use(middleware.RootAuth())
+		{
+			adminSystemRoute.PUT("/maintenance", admin.SetMaintenanceMode)
+		}
+		adminAuthRoute := adminRouter.Group("/auth")
+		adminAuthRoute.Use(middleware.AdminAuth())
+		{
+			adminAuthRoute.POST("/wallet/revoke", auth.RevokeWalletToken)
+		}
+		adminWalletRoute := adminRouter.Group("/wallet")
+		adminWalletRoute.Use(middleware.RootAuth())
+		{
+			adminWalletRoute.GET("/root-addresses", auth.GetRootWalletAddresses)
+			adminWalletRoute.PUT("/root-addresses", auth.UpdateRootWalletAddresses)
+		}
+		adminWalletNonceRoute := adminRouter.Group("/wallet/nonce")
+		adminWalletNonceRoute.Use(middleware.AdminAuth())
+		{
+			adminWalletNonceRoute.GET("/stats", admin.GetWalletNonceStats)
+		}
+		adminSLORoute := adminRouter.Group("/slo")
+		adminSLORoute.Use(middleware.AdminAuth())
+		{
+			adminSLORoute.GET("/wallet-auth", admin.GetWalletAuthSLO)
+		}
+		adminStatsRoute := adminRouter.Group("/stats")
+		adminStatsRoute.Use(middleware.AdminAuth())
+		{
+			adminStatsRoute.GET("/dashboard", dashboard.GetStatsDashboard)
+		}
 		adminFlowRoute := adminRouter.Group("/flow")
 		adminFlowRoute.Use(middleware.AdminAuth())
 		{
@@ -337,6 +443,15 @@ func SetApiRouter(engine *gin.Engine) {
 			adminLogRoute.GET("/:id", log.GetLog)
 		}
 
+		adminGraphQLRoute := adminRouter.Group("/graphql")
+		adminGraphQLRoute.Use(middleware.AdminAuth())
+		{
+			adminGraphQLRoute.POST("", gql.NewHandler())
+			if config.GraphQLPlaygroundEnabled {
+				adminGraphQLRoute.GET("", gql.NewPlaygroundHandler("/api/v1/admin/graphql"))
+			}
+		}
+
 		adminGroupRoute := adminRouter.Group("/group")
 		adminGroupRoute.Use(middleware.AdminAuth())
 		{
@@ -383,9 +498,22 @@ func SetApiRouter(engine *gin.Engine) {
 	}
 
 	internalRouter := engine.Group("/api/v1/internal")
-	internalRouter.Use(gzip.Gzip(gzip.DefaultCompression))
-	internalRouter.Use(middleware.GlobalAPIRateLimit())
+	internalRouter.Use(middleware.Gzip(config.GzipMinSizeBytes), middleware.Timeout(requestTimeout("api")))
+	internalRouter.Use(middleware.GlobalAPIRateLimit(), middleware.BodySizeLimit(config.MaxRequestBodyBytes))
 	{
 		// reserved for future internal endpoints
 	}
 }
+
+// setWalletCORS gives the wallet login routes their own origin list when
+// cors.wallet_allowed_origins is set.
+func setWalletCORS() {
+	if len(config.CorsWalletAllowedOrigins) == 0 {
+		return
+	}
+	walletCORS := middleware.DefaultCORSConfig()
+	walletCORS.AllowedOrigins = config.CorsWalletAllowedOrigins
+	for _, prefix := range []string{"/api/v1/public/common/auth", "/api/v1/public/auth", "/api/v1/public/oauth/wallet"} {
+		middleware.SetCORSOverride(prefix, walletCORS)
+	}
+}
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/yeying-community/router/common"
+	"github.com/yeying-community/router/common/config"
+	"github.com/yeying-community/router/internal/admin/model"
+	"github.com/yeying-community/router/internal/testutil"
+)
+
+func TestWalletBindRoutes_AcceptSessionOrBearer(t *testing.T) {
+	prevSecret, prevAlgorithm, prevRedis := config.JWTSecret, config.WalletJWTAlgorithm, common.RedisEnabled
+	defer func() {
+		config.JWTSecret, config.WalletJWTAlgorithm, common.RedisEnabled = prevSecret, prevAlgorithm, prevRedis
+	}()
+	config.JWTSecret = "test-secret"
+	common.RedisEnabled = false
+	config.WalletJWTAlgorithm = common.WalletJWTAlgorithmHS256
+
+	wallet := "0x00000000000000000000000000000000000000aa"
+	owner := model.User{Id: "bind-owner", Username: "owner", Role: model.RoleCommonUser, Status: model.UserStatusEnabled, WalletAddress: &wallet}
+	model.BindUserRepository(model.UserRepository{
+		GetUserById: func(id string, selectAll bool) (*model.User, error) {
+			if id == owner.Id {
+				user := owner
+				return &user, nil
+			}
+			return nil, gorm.ErrRecordNotFound
+		},
+		FillUserById: func(user *model.User) error {
+			if user.Id != owner.Id {
+				return gorm.ErrRecordNotFound
+			}
+			*user = owner
+			return nil
+		},
+		ValidateAccessToken: func(token string) *model.User { return nil },
+	})
+
+	engine := testutil.NewTestEngine()
+	engine.Use(sessions.Sessions("session", cookie.NewStore([]byte("test-secret"))))
+	engine.POST("/test/login", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set("id", owner.Id)
+		_ = session.Save()
+	})
+	SetApiRouter(engine)
+
+	login := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodPost, "/test/login", nil))
+	sessionCookie := login.Header().Get("Set-Cookie")
+	if sessionCookie == "" {
+		t.Fatalf("login set no session cookie")
+	}
+	token, _, err := common.GenerateWalletJWT(owner.Id, wallet)
+	if err != nil {
+		t.Fatalf("GenerateWalletJWT error: %v", err)
+	}
+
+	routes := []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/public/oauth/wallet/unbind"},
+		{http.MethodDelete, "/api/v1/public/oauth/wallet/bind"},
+	}
+	for _, route := range routes {
+		request := func(header, value string) *http.Request {
+			// an invalid address is rejected by the handler, after authentication
+			req := testutil.NewTestRequest(route.method, route.path, map[string]string{"address": "not-an-address"})
+			if header != "" {
+				req.Header.Set(header, value)
+			}
+			return req
+		}
+		authenticated := map[string]*http.Request{
+			"session": request("Cookie", strings.Split(sessionCookie, ";")[0]),
+			"bearer":  request("Authorization", "Bearer "+token),
+		}
+		for name, req := range authenticated {
+			recorder := testutil.ServeTestRequest(engine, req)
+			if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "无效的钱包地址") {
+				t.Fatalf("%s %s via %s: status = %d body = %s, want the handler's address check", route.method, route.path, name, recorder.Code, recorder.Body.String())
+			}
+		}
+		if recorder := testutil.ServeTestRequest(engine, request("", "")); recorder.Code != http.StatusUnauthorized {
+			t.Fatalf("%s %s without credentials: status = %d, want 401", route.method, route.path, recorder.Code)
+		}
+	}
+}
+
+func TestWalletChallengeTypes_ServedAtBothPaths(t *testing.T) {
+	prevRedis := common.RedisEnabled
+	defer func() { common.RedisEnabled = prevRedis }()
+	common.RedisEnabled = false
+
+	engine := testutil.NewTestEngine()
+	engine.Use(sessions.Sessions("session", cookie.NewStore([]byte("test-secret"))))
+	SetApiRouter(engine)
+	for _, path := range []string{"/api/v1/auth/wallet/challenge-types", "/api/v1/public/oauth/wallet/challenge-types"} {
+		recorder := testutil.ServeTestRequest(engine, testutil.NewTestRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"supported_types"`) {
+			t.Fatalf("%s: status = %d body = %s, want the challenge types", path, recorder.Code, recorder.Body.String())
+		}
+	}
+}
@@ -1,16 +1,16 @@
 package router
 
 import (
-	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 
+	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/internal/admin/controller/billing"
 	"github.com/yeying-community/router/internal/transport/http/middleware"
 )
 
 func SetDashboardRouter(engine *gin.Engine) {
 	apiRouter := engine.Group("/")
-	apiRouter.Use(gzip.Gzip(gzip.DefaultCompression))
+	apiRouter.Use(middleware.Gzip(config.GzipMinSizeBytes), middleware.Timeout(requestTimeout("dashboard")))
 	apiRouter.Use(middleware.GlobalAPIRateLimit())
 	apiRouter.Use(middleware.TokenAuth())
 	{
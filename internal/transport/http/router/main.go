@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/yeying-community/router/common"
 	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/common/logger"
+	"github.com/yeying-community/router/common/metrics"
 	"github.com/yeying-community/router/internal/transport/http/middleware"
 )
 
@@ -20,9 +22,21 @@ func SetRouter(engine *gin.Engine, buildFS embed.FS) {
 		panic(err)
 	}
 
-	engine.Use(middleware.CORS())
+	engine.Use(middleware.CORS(middleware.DefaultCORSConfig()))
+	engine.Use(middleware.Maintenance())
+	if config.SecureHeadersEnabled {
+		engine.Use(middleware.SecureHeaders(middleware.DefaultSecureHeadersConfig()))
+	}
 
 	SetApiRouter(engine)
+	if config.PrometheusMetricsEnabled {
+		engine.GET("/metrics", func(c *gin.Context) {
+			c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			if err := metrics.WritePrometheus(c.Writer); err != nil {
+				logger.SysErrorf("write prometheus metrics failed: %v", err)
+			}
+		})
+	}
 	if common.DisableOpenAICompat {
 		logger.SysLog("OpenAI-compatible routes disabled via feature.disable_openai_compat")
 	} else {
@@ -45,3 +59,15 @@ func SetRouter(engine *gin.Engine, buildFS embed.FS) {
 		c.Redirect(http.StatusMovedPermanently, fmt.Sprintf("%s%s", frontendBaseURL, c.Request.RequestURI))
 	})
 }
+
+// requestTimeout is the middleware.Timeout deadline of a route group:
+// server.request_timeout_by_group when it lists the group, otherwise
+// server.request_timeout_seconds. The "relay" group covers the model list
+// routes only; relayed requests run until the channel timeout instead.
+func requestTimeout(group string) time.Duration {
+	seconds, ok := config.RequestTimeoutByGroup[group]
+	if !ok {
+		seconds = config.RequestTimeoutSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -3,22 +3,24 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/yeying-community/router/common/config"
 	"github.com/yeying-community/router/internal/admin/controller"
 	"github.com/yeying-community/router/internal/transport/http/middleware"
 )
 
 func SetRelayRouter(engine *gin.Engine) {
-	engine.Use(middleware.CORS())
+	engine.Use(middleware.CORS(middleware.DefaultCORSConfig()))
 
 	modelsRouter := engine.Group("/v1/models")
-	modelsRouter.Use(middleware.TokenAuth())
+	modelsRouter.Use(middleware.Gzip(config.GzipMinSizeBytes), middleware.Timeout(requestTimeout("relay")), middleware.TokenOrJWTAuth())
 	{
 		modelsRouter.GET("", controller.ListModels)
 		modelsRouter.GET("/:model", controller.RetrieveModel)
 	}
 
+	// relay requests are bounded by the channel timeout, not middleware.Timeout
 	relayV1Router := engine.Group("/v1")
-	relayV1Router.Use(middleware.RelayLogger(), middleware.TokenAuth(), middleware.Distribute())
+	relayV1Router.Use(middleware.Gzip(config.GzipMinSizeBytes), middleware.RelayLogger(), middleware.TokenOrJWTAuth(), middleware.LoadUserContext(), middleware.Distribute(), middleware.ResponsePostProcess())
 	{
 		relayV1Router.POST("/completions", controller.Relay)
 		relayV1Router.POST("/chat/completions", controller.Relay)
@@ -79,4 +81,24 @@ func SetRelayRouter(engine *gin.Engine) {
 		relayV1Router.GET("/threads/:id/runs/:runsId/steps/:stepId", controller.RelayNotImplemented)
 		relayV1Router.GET("/threads/:id/runs/:runsId/steps", controller.RelayNotImplemented)
 	}
+
+	if config.AzureOpenAIMode {
+		setAzureRelayRouter(engine)
+	}
+}
+
+// setAzureRelayRouter serves the Azure OpenAI deployment paths; the middleware
+// maps them onto the matching /v1 relay mode.
+func setAzureRelayRouter(engine *gin.Engine) {
+	azureRouter := engine.Group("/openai/deployments/:deployment")
+	azureRouter.Use(middleware.Gzip(config.GzipMinSizeBytes), middleware.RelayLogger(), middleware.TokenOrJWTAuth(), middleware.LoadUserContext(), middleware.Distribute(), middleware.ResponsePostProcess())
+	{
+		azureRouter.POST("/completions", controller.Relay)
+		azureRouter.POST("/chat/completions", controller.Relay)
+		azureRouter.POST("/embeddings", controller.Relay)
+		azureRouter.POST("/images/generations", controller.Relay)
+		azureRouter.POST("/audio/transcriptions", controller.Relay)
+		azureRouter.POST("/audio/translations", controller.Relay)
+		azureRouter.POST("/audio/speech", controller.Relay)
+	}
 }